@@ -17,9 +17,25 @@
 //
 // @schemes http https
 //
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description JWT as "Bearer <token>"; required on every endpoint but health and workflow when authentication is enabled
+//
+// @securityDefinitions.apikey APIKeyAuth
+// @in header
+// @name X-API-Key
+// @description API key issued through /apikeys; accepted instead of a bearer token
+//
 // @tag.name tasks
 // @tag.description Task management operations
 //
+// @tag.name webhooks
+// @tag.description Outgoing webhook subscriptions and delivery logs
+//
+// @tag.name apikeys
+// @tag.description API keys for scripts and services (admin only)
+//
 // @tag.name health
 // @tag.description Health check and monitoring endpoints
 //
@@ -30,15 +46,24 @@ package main
 import (
 	"context"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
 	"strings"
 	"syscall"
+	"task-api/internal/apikeys"
+	"task-api/internal/auth"
 	"task-api/internal/config"
+	"task-api/internal/events"
+	"task-api/internal/interfaces"
+	"task-api/internal/middleware"
+	"task-api/internal/models"
+	"task-api/internal/resp"
 	"task-api/internal/routes"
 	"task-api/internal/storage"
+	"task-api/internal/webhooks"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -48,25 +73,153 @@ import (
 
 // Application represents the main application structure
 type Application struct {
-	server  *http.Server
-	storage *storage.MemoryStorage
-	config  *config.Config
+	server   *http.Server
+	storage  interfaces.TaskStorage
+	events   *events.Bus
+	webhooks *webhooks.Dispatcher
+	apiKeys  *apikeys.Store
+	limits   middleware.RateLimitStore
+	config   *config.Config
+}
+
+// newStorage creates the storage backend selected by configuration (Factory Pattern)
+func newStorage(cfg *config.Config) (interfaces.TaskStorage, error) {
+	switch cfg.StorageBackend {
+	case "", "memory":
+		mem := storage.NewMemoryStorage(cfg.MaxTasks)
+		mem.SetMaxRevisions(cfg.MaxRevisions)
+		return mem, nil
+	case "file":
+		return storage.NewFileStorage(storage.FileStorageConfig{
+			DataDir:          cfg.DataDir,
+			MaxTasks:         cfg.MaxTasks,
+			MaxRevisions:     cfg.MaxRevisions,
+			SnapshotInterval: cfg.SnapshotInterval,
+			SyncWrites:       cfg.SyncWrites,
+		})
+	case "sqlite":
+		return storage.NewSQLiteStorage(storage.SQLiteStorageConfig{
+			Path:         cfg.GetSQLitePath(),
+			MaxTasks:     cfg.MaxTasks,
+			MaxRevisions: cfg.MaxRevisions,
+		})
+	default:
+		return nil, fmt.Errorf("unknown storage backend: %s", cfg.StorageBackend)
+	}
+}
+
+// newRateLimitStore creates the shared rate limit store when a Redis URL is configured, or
+// returns nil for per-instance memory (Factory Pattern)
+func newRateLimitStore(cfg *config.Config) (middleware.RateLimitStore, error) {
+	if cfg.RateLimitRedisURL == "" {
+		return nil, nil
+	}
+	options, err := resp.ParseURL(cfg.RateLimitRedisURL)
+	if err != nil {
+		return nil, err
+	}
+
+	client := resp.NewClient(options)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx); err != nil {
+		client.Close()
+		return nil, err
+	}
+	return middleware.NewRedisRateLimitStore(client, cfg.RateLimitRedisPrefix), nil
 }
 
 // NewApplication creates a new application instance with dependency injection
 func NewApplication(cfg *config.Config) (*Application, error) {
+	// Install the task workflow before anything validates statuses
+	if cfg.WorkflowFile != "" {
+		workflow, err := models.LoadWorkflow(cfg.WorkflowFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load workflow: %w", err)
+		}
+		models.SetWorkflow(workflow)
+	}
+
 	// Create storage instance (Factory Pattern)
-	memStorage := storage.NewMemoryStorage(cfg.MaxTasks)
+	taskStorage, err := newStorage(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create storage: %w", err)
+	}
+
+	// Create the change feed bus the storage publishes to
+	eventBus := events.NewBus(events.BusConfig{
+		ReplaySize:       cfg.EventReplaySize,
+		SubscriberBuffer: cfg.EventClientBuffer,
+	})
+
+	// Deliver the change feed to registered webhooks
+	webhookStore, err := webhooks.NewStore(cfg.GetWebhooksPath(), cfg.WebhookLogSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load webhooks: %w", err)
+	}
+	dispatcher := webhooks.NewDispatcher(webhookStore, webhooks.DispatcherConfig{
+		MaxAttempts: cfg.WebhookMaxAttempts,
+		Timeout:     time.Duration(cfg.WebhookTimeoutSeconds) * time.Second,
+	})
+	dispatcher.Start(eventBus)
+
+	// Validate the API keys scripts and services authenticate with
+	apiKeys, err := apikeys.NewStore(cfg.GetAPIKeysPath())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load api keys: %w", err)
+	}
+
+	// Require bearer tokens when signing keys are configured
+	var verifier *auth.Verifier
+	if keyConfig := cfg.GetAuthKeys(); !keyConfig.IsEmpty() {
+		keys, err := auth.LoadKeySet(keyConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load authentication keys: %w", err)
+		}
+		verifier = auth.NewVerifier(keys, cfg.GetAuthVerifierConfig())
+	}
+
+	// Check the caller's roles against the built-in policy or the policy file
+	policy, err := cfg.GetRBACPolicy()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load authorization policy: %w", err)
+	}
+	authorizer, err := routes.NewAuthorizer(policy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load authorization policy: %w", err)
+	}
+
+	// Reject malformed trusted proxies before serving
+	if _, err := middleware.NewClientIPResolver(cfg.GetClientIPConfig()); err != nil {
+		return nil, fmt.Errorf("invalid trusted proxies: %w", err)
+	}
+
+	// Reject unknown rate limiting algorithms before serving
+	if _, err := middleware.ParseRateLimitAlgorithm(cfg.RateLimitAlgorithm); err != nil {
+		return nil, err
+	}
+
+	// Apply the built-in rate limit policies or those of the policy file
+	limits, err := cfg.GetRateLimitPolicies()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load rate limit policies: %w", err)
+	}
+
+	// Share rate limit counters with the other instances through Redis, if configured
+	limitStore, err := newRateLimitStore(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to rate limit store: %w", err)
+	}
 
 	// Create router based on environment
 	var router *gin.Engine
 	switch cfg.Environment {
 	case "debug", "development":
-		router = routes.SetupDevelopmentRouterWithConfig(memStorage, cfg)
+		router = routes.SetupDevelopmentRouterWithConfig(taskStorage, eventBus, dispatcher, verifier, apiKeys, authorizer, limits, limitStore, cfg)
 		// Add debug routes in development
-		routes.SetupDebugRoutes(router)
+		routes.SetupDebugRoutes(router, authorizer)
 	case "test":
-		router = routes.SetupTestRouter(memStorage)
+		router = routes.SetupTestRouter(taskStorage)
 	default:
 		// Parse allowed origins for production
 		var allowedOrigins []string
@@ -81,11 +234,11 @@ func NewApplication(cfg *config.Config) (*Application, error) {
 		} else {
 			allowedOrigins = []string{"*"}
 		}
-		router = routes.SetupProductionRouterWithConfig(memStorage, allowedOrigins, cfg)
+		router = routes.SetupProductionRouterWithConfig(taskStorage, eventBus, dispatcher, verifier, apiKeys, authorizer, limits, limitStore, allowedOrigins, cfg)
 	}
 
 	// Add metrics endpoint
-	routes.SetupMetricsEndpoint(router, memStorage)
+	routes.SetupMetricsEndpoint(router, taskStorage)
 
 	// Create HTTP server
 	server := &http.Server{
@@ -97,9 +250,13 @@ func NewApplication(cfg *config.Config) (*Application, error) {
 	}
 
 	return &Application{
-		server:  server,
-		storage: memStorage,
-		config:  cfg,
+		server:   server,
+		storage:  taskStorage,
+		events:   eventBus,
+		webhooks: dispatcher,
+		apiKeys:  apiKeys,
+		limits:   limitStore,
+		config:   cfg,
 	}, nil
 }
 
@@ -128,12 +285,38 @@ func (app *Application) Stop() error {
 		time.Duration(app.config.ShutdownTimeout)*time.Second)
 	defer cancel()
 
+	// End event streams, which would otherwise keep the shutdown waiting
+	app.events.Close()
+
 	// Attempt graceful shutdown
 	if err := app.server.Shutdown(ctx); err != nil {
 		log.Printf("Server forced to shutdown: %v", err)
 		return err
 	}
 
+	// Stop webhook deliveries; those waiting for a retry are dropped
+	app.webhooks.Close()
+
+	// Save the last-used times of the API keys
+	if err := app.apiKeys.Close(); err != nil {
+		log.Printf("Failed to save api keys: %v", err)
+	}
+
+	// Disconnect from the shared rate limit store
+	if closer, ok := app.limits.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			log.Printf("Failed to close rate limit store: %v", err)
+		}
+	}
+
+	// Flush and release persistent storage
+	if closer, ok := app.storage.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			log.Printf("Failed to close storage: %v", err)
+			return err
+		}
+	}
+
 	log.Println("Server stopped gracefully")
 	return nil
 }
@@ -163,9 +346,14 @@ func (app *Application) WaitForShutdown() {
 
 // HealthCheck performs application health check
 func (app *Application) HealthCheck() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
 	// Check storage health
-	if err := app.storage.HealthCheck(); err != nil {
-		return fmt.Errorf("storage health check failed: %w", err)
+	if healthChecker, ok := app.storage.(interfaces.HealthChecker); ok {
+		if err := healthChecker.HealthCheck(ctx); err != nil {
+			return fmt.Errorf("storage health check failed: %w", err)
+		}
 	}
 
 	// Add more health checks here as needed
@@ -182,7 +370,12 @@ func (app *Application) GetStats() map[string]interface{} {
 	stats := map[string]interface{}{
 		"server_addr": app.server.Addr,
 		"environment": app.config.Environment,
-		"storage":     app.storage.GetStats(),
+	}
+
+	if statsProvider, ok := app.storage.(interfaces.StatsProvider); ok {
+		if storageStats, err := statsProvider.GetStats(context.Background()); err == nil {
+			stats["storage"] = storageStats
+		}
 	}
 
 	return stats
@@ -228,6 +421,45 @@ func printStartupInfo(cfg *config.Config) {
 	log.Printf("Idle Timeout: %ds", cfg.IdleTimeout)
 	log.Printf("Shutdown Timeout: %ds", cfg.ShutdownTimeout)
 	log.Printf("Allowed Origins: %s", cfg.AllowedOrigins)
+	log.Printf("Trusted Proxies: %s (%s)", cfg.TrustedProxies, cfg.TrustedProxyHeader)
+	log.Printf("Storage Backend: %s", cfg.StorageBackend)
+	switch cfg.StorageBackend {
+	case "file":
+		log.Printf("Data Directory: %s", cfg.DataDir)
+	case "sqlite":
+		log.Printf("Database Path: %s", cfg.GetSQLitePath())
+	}
+	if path := cfg.GetWebhooksPath(); path != "" {
+		log.Printf("Webhooks File: %s", path)
+	}
+	if path := cfg.GetAPIKeysPath(); path != "" {
+		log.Printf("API Keys File: %s", path)
+	}
+	log.Printf("Workflow States: %s", strings.Join(models.CurrentWorkflow().Names(), ", "))
+	log.Printf("Default API Version: %d", cfg.APIVersion)
+	if cfg.GetAuthKeys().IsEmpty() {
+		log.Println("Authentication: disabled")
+	} else {
+		log.Println("Authentication: bearer tokens required")
+	}
+	if cfg.RBACPolicyFile != "" {
+		log.Printf("Authorization Policy: %s", cfg.RBACPolicyFile)
+	} else {
+		log.Println("Authorization Policy: built-in")
+	}
+	if cfg.RateLimitEnabled {
+		log.Printf("Rate Limit Algorithm: %s", cfg.RateLimitAlgorithm)
+		if cfg.RateLimitPolicyFile != "" {
+			log.Printf("Rate Limit Policies: %s", cfg.RateLimitPolicyFile)
+		} else {
+			log.Println("Rate Limit Policies: built-in")
+		}
+		if cfg.RateLimitRedisURL != "" {
+			log.Println("Rate Limit Store: redis (shared)")
+		} else {
+			log.Println("Rate Limit Store: memory (per instance)")
+		}
+	}
 	log.Println("=================================")
 
 	// Print available endpoints
@@ -241,6 +473,7 @@ func printStartupInfo(cfg *config.Config) {
 	if cfg.IsDevelopment() {
 		log.Printf("  Debug Routes: http://%s/debug/routes", cfg.GetServerAddress())
 		log.Printf("  Debug Echo: http://%s/debug/echo", cfg.GetServerAddress())
+		log.Printf("  Debug Authorization: http://%s/debug/authz", cfg.GetServerAddress())
 	}
 
 	log.Println("=================================")
@@ -23,38 +23,49 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/health": {
+        "/apikeys": {
             "get": {
-                "description": "Check if the service is healthy",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
                 ],
+                "description": "Get every issued API key, oldest first, revoked ones included. Keys themselves are never listed.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "health"
+                    "apikeys"
                 ],
-                "summary": "Health check",
+                "summary": "List API keys",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.HealthResponse"
+                            "$ref": "#/definitions/models.APIKeyListResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "501": {
+                        "description": "Not Implemented",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     }
                 }
-            }
-        },
-        "/stats": {
-            "get": {
-                "description": "Get statistics about the storage",
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "description": "Issue a key acting as \"subject\" (default: the caller) with the listed scopes: tasks:read,\ntasks:write or admin. Send it in the X-API-Key header. The response is the only one that\nincludes the key; only its hash is stored.",
                 "consumes": [
                     "application/json"
                 ],
@@ -62,14 +73,31 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "stats"
+                    "apikeys"
+                ],
+                "summary": "Issue an API key",
+                "parameters": [
+                    {
+                        "description": "API key data",
+                        "name": "apikey",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.IssueAPIKeyRequest"
+                        }
+                    }
                 ],
-                "summary": "Get storage statistics",
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIKeyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/storage.StorageStats"
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "500": {
@@ -77,70 +105,99 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
+                    },
+                    "501": {
+                        "description": "Not Implemented",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/tasks": {
+        "/apikeys/{id}": {
             "get": {
-                "description": "Get all tasks from the storage",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
                 ],
+                "description": "Get an API key by its ID, with its scopes, expiry and last use but without the key itself",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "tasks"
+                    "apikeys"
+                ],
+                "summary": "Get an API key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "API key ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Get all tasks",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.TaskListResponse"
+                            "$ref": "#/definitions/models.APIKeyResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "501": {
+                        "description": "Not Implemented",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     }
                 }
             },
-            "post": {
-                "description": "Create a new task with the provided data",
-                "consumes": [
-                    "application/json"
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
                 ],
+                "description": "Reject the key from now on. Revoked keys stay listed with their revocation time.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "tasks"
+                    "apikeys"
                 ],
-                "summary": "Create a new task",
+                "summary": "Revoke an API key",
                 "parameters": [
                     {
-                        "description": "Task data",
-                        "name": "task",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.CreateTaskRequest"
-                        }
+                        "type": "string",
+                        "description": "API key ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.TaskResponse"
+                            "$ref": "#/definitions/models.APIKeyResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -150,13 +207,19 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
+                    },
+                    "501": {
+                        "description": "Not Implemented",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/tasks/paginated": {
+        "/health": {
             "get": {
-                "description": "Get tasks with pagination support",
+                "description": "Check if the service is healthy",
                 "consumes": [
                     "application/json"
                 ],
@@ -164,34 +227,14 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "tasks"
-                ],
-                "summary": "Get tasks with pagination",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Offset for pagination (default: 0)",
-                        "name": "offset",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Limit for pagination (default: 10)",
-                        "name": "limit",
-                        "in": "query"
-                    }
+                    "health"
                 ],
+                "summary": "Health check",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.TaskListResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/models.ErrorResponse"
+                            "$ref": "#/definitions/models.HealthResponse"
                         }
                     },
                     "500": {
@@ -203,9 +246,17 @@ const docTemplate = `{
                 }
             }
         },
-        "/tasks/status/{status}": {
+        "/stats": {
             "get": {
-                "description": "Get all tasks with a specific status",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "description": "Get statistics about the storage",
                 "consumes": [
                     "application/json"
                 ],
@@ -213,29 +264,14 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "tasks"
-                ],
-                "summary": "Get tasks by status",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Task Status (0=incomplete, 1=completed)",
-                        "name": "status",
-                        "in": "path",
-                        "required": true
-                    }
+                    "stats"
                 ],
+                "summary": "Get storage statistics",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.TaskListResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/models.ErrorResponse"
+                            "$ref": "#/definitions/models.StorageStats"
                         }
                     },
                     "500": {
@@ -247,9 +283,17 @@ const docTemplate = `{
                 }
             }
         },
-        "/tasks/{id}": {
+        "/tasks": {
             "get": {
-                "description": "Get a specific task by its ID",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "description": "Get all tasks from the storage, optionally filtered, sorted and restricted to some fields.\nWithout a sort, tasks are listed in creation order.",
                 "consumes": [
                     "application/json"
                 ],
@@ -259,25 +303,82 @@ const docTemplate = `{
                 "tags": [
                     "tasks"
                 ],
-                "summary": "Get a task by ID",
+                "summary": "Get all tasks",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Task ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Filter by workflow state name or numeric status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by priority (low, medium, high, urgent or 0-3)",
+                        "name": "priority",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Filter by tag; repeat or comma-separate to require several",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only tasks whose name contains this text (case-insensitive)",
+                        "name": "name_contains",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only tasks due before this RFC 3339 time",
+                        "name": "due_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only tasks due after this RFC 3339 time",
+                        "name": "due_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only tasks created before this RFC 3339 time",
+                        "name": "created_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only tasks created after this RFC 3339 time",
+                        "name": "created_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated sort fields, '-' prefix for descending (e.g. -updated_at,name)",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated fields to return (e.g. id,name)",
+                        "name": "fields",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.TaskResponse"
+                            "$ref": "#/definitions/models.TaskListResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -290,8 +391,16 @@ const docTemplate = `{
                     }
                 }
             },
-            "put": {
-                "description": "Update an existing task with the provided data",
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "description": "Create a new task with the provided data",
                 "consumes": [
                     "application/json"
                 ],
@@ -301,30 +410,29 @@ const docTemplate = `{
                 "tags": [
                     "tasks"
                 ],
-                "summary": "Update a task",
+                "summary": "Create a new task",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Task ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Task update data",
+                        "description": "Task data",
                         "name": "task",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.UpdateTaskRequest"
+                            "$ref": "#/definitions/models.CreateTaskRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
                             "$ref": "#/definitions/models.TaskResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Strong entity tag of the task version"
+                            }
                         }
                     },
                     "400": {
@@ -333,8 +441,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "422": {
+                        "description": "Unprocessable Entity",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -344,11 +452,27 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
+                    },
+                    "507": {
+                        "description": "Insufficient Storage",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
                     }
                 }
-            },
-            "delete": {
-                "description": "Delete a task by its ID",
+            }
+        },
+        "/tasks/bulk": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "description": "Apply create, update and delete operations in order. In atomic mode (default) either every\noperation is applied or none is; in best_effort mode every operation that succeeds is applied.\nEach result carries the status the operation would have had on its own; operations skipped\nbecause another one failed report 424. The response is 200 when every operation was applied, 207 otherwise.",
                 "consumes": [
                     "application/json"
                 ],
@@ -358,12 +482,305 @@ const docTemplate = `{
                 "tags": [
                     "tasks"
                 ],
-                "summary": "Delete a task",
+                "summary": "Bulk task operations",
+                "parameters": [
+                    {
+                        "description": "Bulk operations",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.BulkRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.BulkResponse"
+                        }
+                    },
+                    "207": {
+                        "description": "Multi-Status",
+                        "schema": {
+                            "$ref": "#/definitions/models.BulkResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "501": {
+                        "description": "Not Implemented",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/events": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "description": "Keep the connection open and receive an event for every task created, updated or deleted,\nnamed after the change and carrying a models.TaskEvent as data. A reconnecting client sends the\nID of the last event it received (Last-Event-ID header or last_event_id parameter) and gets the\nevents it missed; when they are no longer buffered a \"reset\" event tells it to reload its tasks.\nIdle streams carry a comment line every 15 seconds. Clients that cannot keep up are disconnected.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Stream task changes",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Task ID",
-                        "name": "id",
+                        "description": "ID of the last event received, to resume after it",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Same as the Last-Event-ID header, for clients that cannot set it",
+                        "name": "last_event_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Stream of task events",
+                        "schema": {
+                            "$ref": "#/definitions/models.TaskEvent"
+                        }
+                    },
+                    "501": {
+                        "description": "Not Implemented",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/paginated": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "description": "Get tasks in creation order, a page at a time. Pass the next_cursor of a page as cursor to\nget the following one; cursors stay stable while tasks are added or removed. Offset paging\nis still supported but can skip or repeat tasks when the list changes between requests.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Get tasks with pagination",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Continue after this cursor (next_cursor of the previous page); cannot be combined with offset",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Offset for pagination (default: 0)",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit for pagination (default: 10)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by workflow state name or numeric status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by priority (low, medium, high, urgent or 0-3)",
+                        "name": "priority",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Filter by tag; repeat or comma-separate to require several",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only tasks whose name contains this text (case-insensitive)",
+                        "name": "name_contains",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only tasks due before this RFC 3339 time",
+                        "name": "due_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only tasks due after this RFC 3339 time",
+                        "name": "due_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only tasks created before this RFC 3339 time",
+                        "name": "created_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only tasks created after this RFC 3339 time",
+                        "name": "created_after",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.TaskListResponse"
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "RFC 8288 links to the first and next pages"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/search": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "description": "Find tasks whose name or description contains every word of the query. Each word also matches\nlonger words it is a prefix of (at a lower score). Results are ranked by relevance, name matches\nweighing more than description matches, and carry HTML-escaped snippets with the matches in \u003cmark\u003e tags.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Search tasks",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search query",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of results (default: 20, max: 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SearchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/status/{status}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "description": "Get all tasks with a specific status",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Get tasks by status",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Workflow state name (e.g. incomplete, completed) or numeric status",
+                        "name": "status",
                         "in": "path",
                         "required": true
                     }
@@ -372,7 +789,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.TaskResponse"
+                            "$ref": "#/definitions/models.TaskListResponse"
                         }
                     },
                     "400": {
@@ -381,121 +798,1976 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/models.ErrorResponse"
+                    }
+                }
+            }
+        },
+        "/tasks/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "description": "Get a specific task by its ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Get a task by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Return 304 if the task still has this ETag",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.TaskResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Strong entity tag of the task version"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Task unchanged"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "description": "API version 1 (default): partial update, only the fields present are changed.\nAPI version 2 (API-Version: 2): full replacement, omitted fields are reset to their defaults.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Update a task",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Task update data",
+                        "name": "task",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateTaskRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only update if the task still has this ETag",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "1 = partial update, 2 = full replacement",
+                        "name": "API-Version",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.TaskResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Strong entity tag of the new task version"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "description": "Delete a task by its ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Delete a task",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only delete if the task still has this ETag",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.TaskResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "description": "Apply an RFC 7396 JSON Merge Patch (application/merge-patch+json) or an RFC 6902 JSON Patch\n(application/json-patch+json, including test operations) to the task's JSON representation.\nThe patch is applied atomically: it is evaluated against one version of the task and only written if that version is still current.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Patch a task",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Merge patch object or JSON Patch operation array",
+                        "name": "patch",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only patch if the task still has this ETag",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.TaskResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Strong entity tag of the new task version"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}/history": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "description": "Get every stored revision of a task (who changed what and when), oldest first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "history"
+                ],
+                "summary": "Get task history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.TaskHistoryResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "501": {
+                        "description": "Not Implemented",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}/versions/{version}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "description": "Get the revision that produced the given version of a task, including the full task state",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "history"
+                ],
+                "summary": "Get a task version",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Task version",
+                        "name": "version",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.TaskRevisionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "501": {
+                        "description": "Not Implemented",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}/versions/{version}/revert": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "description": "Restore a task's fields from an earlier version; the result is recorded as a new version.\nThe status change must be allowed by the workflow.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "history"
+                ],
+                "summary": "Revert a task",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Version to restore",
+                        "name": "version",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.TaskResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Strong entity tag of the new task version"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "501": {
+                        "description": "Not Implemented",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "description": "Get every webhook subscription, oldest first. Secrets are never listed.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "List webhooks",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookListResponse"
+                        }
+                    },
+                    "501": {
+                        "description": "Not Implemented",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "description": "Register an endpoint to receive a signed POST for every task change of the listed event types\n(all types if none are listed). The response is the only one that includes the signing secret.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Create a webhook",
+                "parameters": [
+                    {
+                        "description": "Webhook data",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateWebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "501": {
+                        "description": "Not Implemented",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "description": "Get a webhook subscription by its ID, without its secret",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Get a webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "501": {
+                        "description": "Not Implemented",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "description": "Delete a webhook subscription and its delivery log; deliveries waiting for a retry are dropped",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Delete a webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "501": {
+                        "description": "Not Implemented",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "description": "Change the fields present in the request. Set \"active\" to pause or resume deliveries, and\n\"secret\" to rotate the signing secret (an empty string generates one); the response then includes it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Update a webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Webhook update data",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateWebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "501": {
+                        "description": "Not Implemented",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "description": "Get the most recent deliveries of a webhook, newest first, with every attempt made.\nDead deliveries failed every attempt and can be sent again with the retry endpoint.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "List webhook deliveries",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only deliveries in this state (pending, retrying, succeeded, dead)",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookDeliveryListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "501": {
+                        "description": "Not Implemented",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}/deliveries/{delivery_id}/retry": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "description": "Send a dead delivery once more. It goes back to pending; if the attempt fails it is dead again.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Retry a dead delivery",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Delivery ID",
+                        "name": "delivery_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookDeliveryResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "501": {
+                        "description": "Not Implemented",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/workflow": {
+            "get": {
+                "description": "Get the workflow states, their numeric status values (array index) and allowed transitions",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Get the task workflow",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Workflow"
+                        }
+                    }
+                }
+            }
+        },
+        "/ws": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "description": "Upgrade to a WebSocket connection carrying JSON messages. Send {\"type\":\"subscribe\",\"id\":\"mine\",\"filter\":\"status=completed\u0026tag=urgent\"}\nto receive {\"type\":\"event\",\"subscriptions\":[\"mine\"],\"event\":{...}} for every change to a task matching the filter before\nor after the change; the filter takes the list filter parameters as a query string. Send {\"type\":\"unsubscribe\",\"id\":\"mine\"}\nto stop and {\"type\":\"ping\"} to get a pong. The server sends ping frames on idle connections and closes connections\nthat cannot keep up with the events.",
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Subscribe to task changes",
+                "responses": {
+                    "101": {
+                        "description": "Switching Protocols"
+                    },
+                    "403": {
+                        "description": "Origin not allowed"
+                    },
+                    "501": {
+                        "description": "Not Implemented",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "models.APIKey": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "Issue timestamp",
+                    "type": "string"
+                },
+                "created_by": {
+                    "description": "Subject of the caller who issued the key",
+                    "type": "string"
+                },
+                "expires_at": {
+                    "description": "The key is rejected from then on (nil = never expires)",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Unique identifier",
+                    "type": "string"
+                },
+                "key": {
+                    "description": "The key (only returned when issued)",
+                    "type": "string"
+                },
+                "last_used_at": {
+                    "description": "Last request authenticated with the key",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "What the key is used for",
+                    "type": "string"
+                },
+                "prefix": {
+                    "description": "Leading characters of the key, to recognise it",
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "description": "The key is rejected since then",
+                    "type": "string"
+                },
+                "scopes": {
+                    "description": "Granted scopes",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "subject": {
+                    "description": "Owner the key acts as",
+                    "type": "string"
+                }
+            }
+        },
+        "models.APIKeyListResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "description": "Number of API keys",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "API keys, oldest first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.APIKey"
+                    }
+                },
+                "success": {
+                    "description": "Whether the operation was successful",
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.APIKeyResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "description": "API key data",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.APIKey"
+                        }
+                    ]
+                },
+                "message": {
+                    "description": "Response message",
+                    "type": "string"
+                },
+                "success": {
+                    "description": "Whether the operation was successful",
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.BulkMode": {
+            "type": "string",
+            "enum": [
+                "atomic",
+                "best_effort"
+            ],
+            "x-enum-comments": {
+                "BulkAtomic": "All operations are applied or none is",
+                "BulkBestEffort": "Every operation that succeeds is applied"
+            },
+            "x-enum-varnames": [
+                "BulkAtomic",
+                "BulkBestEffort"
+            ]
+        },
+        "models.BulkOperation": {
+            "type": "object",
+            "properties": {
+                "changes": {
+                    "description": "Fields to change (update)",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.UpdateTaskRequest"
+                        }
+                    ]
+                },
+                "id": {
+                    "description": "Target task ID (update and delete)",
+                    "type": "string"
+                },
+                "op": {
+                    "description": "Operation type",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.BulkOperationType"
+                        }
+                    ]
+                },
+                "task": {
+                    "description": "New task (create)",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.CreateTaskRequest"
+                        }
+                    ]
+                },
+                "version": {
+                    "description": "Only apply if the task is at this version (update and delete, optional)",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.BulkOperationResult": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "description": "Created or updated task",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Task"
+                        }
+                    ]
+                },
+                "error": {
+                    "description": "Detailed error information",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Task ID the operation applied to",
+                    "type": "string"
+                },
+                "index": {
+                    "description": "Position of the operation in the request",
+                    "type": "integer"
+                },
+                "op": {
+                    "description": "Operation type",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.BulkOperationType"
+                        }
+                    ]
+                },
+                "status": {
+                    "description": "HTTP status the operation would have had on its own",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.BulkOperationType": {
+            "type": "string",
+            "enum": [
+                "create",
+                "update",
+                "delete"
+            ],
+            "x-enum-comments": {
+                "BulkCreate": "Create a new task",
+                "BulkDelete": "Delete an existing task",
+                "BulkUpdate": "Partially update an existing task"
+            },
+            "x-enum-varnames": [
+                "BulkCreate",
+                "BulkUpdate",
+                "BulkDelete"
+            ]
+        },
+        "models.BulkRequest": {
+            "type": "object",
+            "required": [
+                "operations"
+            ],
+            "properties": {
+                "mode": {
+                    "description": "atomic (default) or best_effort",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.BulkMode"
+                        }
+                    ]
+                },
+                "operations": {
+                    "description": "Operations, applied in order",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BulkOperation"
+                    }
+                }
+            }
+        },
+        "models.BulkResponse": {
+            "type": "object",
+            "properties": {
+                "failed": {
+                    "description": "Number of operations not applied",
+                    "type": "integer"
+                },
+                "mode": {
+                    "description": "Mode the request was executed with",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.BulkMode"
+                        }
+                    ]
+                },
+                "results": {
+                    "description": "Per-operation results, in request order",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BulkOperationResult"
+                    }
+                },
+                "succeeded": {
+                    "description": "Number of applied operations",
+                    "type": "integer"
+                },
+                "success": {
+                    "description": "Whether every operation was applied",
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.CreateTaskRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "description": {
+                    "description": "Markdown description (optional)",
+                    "type": "string"
+                },
+                "due_date": {
+                    "description": "Due date (optional, RFC 3339)",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "Task name (required)",
+                    "type": "string"
+                },
+                "priority": {
+                    "description": "Task priority (optional, defaults to low)",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.TaskPriority"
+                        }
+                    ]
+                },
+                "status": {
+                    "description": "Task status (optional, defaults to incomplete)",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.TaskStatus"
+                        }
+                    ]
+                },
+                "tags": {
+                    "description": "Tags (optional)",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.CreateWebhookRequest": {
+            "type": "object",
+            "required": [
+                "url"
+            ],
+            "properties": {
+                "active": {
+                    "description": "Whether deliveries start right away (default true)",
+                    "type": "boolean"
+                },
+                "description": {
+                    "description": "Free-form note about the receiver",
+                    "type": "string"
+                },
+                "events": {
+                    "description": "Event types to deliver (empty = all)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TaskEventType"
+                    }
+                },
+                "secret": {
+                    "description": "Signing secret (generated if empty)",
+                    "type": "string"
+                },
+                "url": {
+                    "description": "Endpoint receiving the POST requests",
+                    "type": "string"
+                }
+            }
+        },
+        "models.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "Detailed error information",
+                    "type": "string"
+                },
+                "message": {
+                    "description": "Error message",
+                    "type": "string"
+                },
+                "success": {
+                    "description": "Always false",
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.FieldChange": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "description": "JSON name of the changed field",
+                    "type": "string"
+                },
+                "from": {
+                    "description": "Value before the change (absent if unset)"
+                },
+                "to": {
+                    "description": "Value after the change (absent if cleared)"
+                }
+            }
+        },
+        "models.HealthResponse": {
+            "type": "object",
+            "properties": {
+                "status": {
+                    "description": "Service status",
+                    "type": "string"
+                },
+                "timestamp": {
+                    "description": "Check timestamp",
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Service version",
+                    "type": "string"
+                }
+            }
+        },
+        "models.IssueAPIKeyRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "scopes"
+            ],
+            "properties": {
+                "expires_at": {
+                    "description": "Expiry (omit for a key that never expires)",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "What the key is used for",
+                    "type": "string"
+                },
+                "scopes": {
+                    "description": "Granted scopes",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "subject": {
+                    "description": "Owner the key acts as (default: the caller)",
+                    "type": "string"
+                }
+            }
+        },
+        "models.RevisionAction": {
+            "type": "string",
+            "enum": [
+                "create",
+                "update",
+                "revert"
+            ],
+            "x-enum-comments": {
+                "RevisionCreate": "Task was created",
+                "RevisionRevert": "Task was restored from an earlier revision",
+                "RevisionUpdate": "Task was updated"
+            },
+            "x-enum-varnames": [
+                "RevisionCreate",
+                "RevisionUpdate",
+                "RevisionRevert"
+            ]
+        },
+        "models.SearchResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "description": "Number of results",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "Results, best match first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SearchResult"
+                    }
+                },
+                "query": {
+                    "description": "Query as received",
+                    "type": "string"
+                },
+                "success": {
+                    "description": "Whether the operation was successful",
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.SearchResult": {
+            "type": "object",
+            "properties": {
+                "highlights": {
+                    "description": "Matching fields (name, description) with the query words marked",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "score": {
+                    "description": "Relevance; higher is better",
+                    "type": "number"
+                },
+                "task": {
+                    "description": "Matched task",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Task"
+                        }
+                    ]
+                }
+            }
+        },
+        "models.StorageStats": {
+            "type": "object",
+            "properties": {
+                "completed_tasks": {
+                    "description": "Number of completed tasks",
+                    "type": "integer"
+                },
+                "incomplete_tasks": {
+                    "description": "Number of incomplete tasks",
+                    "type": "integer"
+                },
+                "last_id": {
+                    "description": "Last generated ID",
+                    "type": "integer"
+                },
+                "storage_type": {
+                    "description": "Type of storage (sharded_memory, database, etc.)",
+                    "type": "string"
+                },
+                "total_tasks": {
+                    "description": "Total number of tasks",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Task": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "created_at": {
+                    "description": "Creation time",
+                    "type": "string"
+                },
+                "description": {
+                    "description": "Markdown description",
+                    "type": "string"
+                },
+                "due_date": {
+                    "description": "Optional due date",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Unique identifier",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "Task name (required)",
+                    "type": "string"
+                },
+                "owner_id": {
+                    "description": "Subject of the caller who created the task (empty when created without authentication)",
+                    "type": "string"
+                },
+                "priority": {
+                    "description": "Task priority",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.TaskPriority"
+                        }
+                    ]
+                },
+                "status": {
+                    "description": "Task status",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.TaskStatus"
+                        }
+                    ]
+                },
+                "tags": {
+                    "description": "Normalized, sorted tag set",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "updated_at": {
+                    "description": "Last update time",
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Revision number, starts at 1 and increases on every change",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.TaskEvent": {
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "description": "Who made the change",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Position in the change feed, used to resume after a disconnect",
+                    "type": "string"
+                },
+                "task": {
+                    "description": "New task state (absent for deletes)",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Task"
+                        }
+                    ]
+                },
+                "task_id": {
+                    "description": "ID of the changed task",
+                    "type": "string"
+                },
+                "time": {
+                    "description": "When the change was made",
+                    "type": "string"
+                },
+                "type": {
+                    "description": "Kind of change",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.TaskEventType"
+                        }
+                    ]
+                },
+                "version": {
+                    "description": "Task version after the change (last version for deletes)",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.TaskEventType": {
+            "type": "string",
+            "enum": [
+                "created",
+                "updated",
+                "deleted",
+                "reset"
+            ],
+            "x-enum-comments": {
+                "TaskCreated": "Task was created",
+                "TaskDeleted": "Task was deleted",
+                "TaskUpdated": "Task was updated or reverted"
+            },
+            "x-enum-varnames": [
+                "TaskCreated",
+                "TaskUpdated",
+                "TaskDeleted",
+                "TaskEventsReset"
+            ]
+        },
+        "models.TaskHistoryResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "description": "Number of revisions",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "Revisions, oldest first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TaskRevision"
+                    }
+                },
+                "success": {
+                    "description": "Whether the operation was successful",
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.TaskListResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "description": "Total number of tasks",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "Task list",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Task"
+                    }
+                },
+                "next_cursor": {
+                    "description": "Cursor of the next page (paginated listings with more tasks only)",
+                    "type": "string"
+                },
+                "success": {
+                    "description": "Whether the operation was successful",
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.TaskPriority": {
+            "type": "integer",
+            "enum": [
+                0,
+                1,
+                2,
+                3
+            ],
+            "x-enum-varnames": [
+                "PriorityLow",
+                "PriorityMedium",
+                "PriorityHigh",
+                "PriorityUrgent"
+            ]
+        },
+        "models.TaskResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "description": "Task data",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Task"
+                        }
+                    ]
+                },
+                "message": {
+                    "description": "Response message",
+                    "type": "string"
+                },
+                "success": {
+                    "description": "Whether the operation was successful",
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.TaskRevision": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "description": "What produced the revision",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.RevisionAction"
                         }
+                    ]
+                },
+                "actor": {
+                    "description": "Who made the change",
+                    "type": "string"
+                },
+                "changed_at": {
+                    "description": "When the change was made",
+                    "type": "string"
+                },
+                "changes": {
+                    "description": "Fields that differ from the previous version",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FieldChange"
                     }
+                },
+                "reverted_from": {
+                    "description": "Source version of a revert",
+                    "type": "integer"
+                },
+                "task": {
+                    "description": "Full task state at this version",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Task"
+                        }
+                    ]
+                },
+                "version": {
+                    "description": "Task version this revision produced",
+                    "type": "integer"
                 }
             }
-        }
-    },
-    "definitions": {
-        "models.CreateTaskRequest": {
+        },
+        "models.TaskRevisionResponse": {
             "type": "object",
-            "required": [
-                "name"
+            "properties": {
+                "data": {
+                    "description": "Revision data",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.TaskRevision"
+                        }
+                    ]
+                },
+                "success": {
+                    "description": "Whether the operation was successful",
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.TaskStatus": {
+            "type": "integer",
+            "enum": [
+                0,
+                1
             ],
+            "x-enum-varnames": [
+                "TaskIncomplete",
+                "TaskCompleted"
+            ]
+        },
+        "models.UpdateTaskRequest": {
+            "type": "object",
             "properties": {
+                "clear_due_date": {
+                    "description": "Remove the due date (optional)",
+                    "type": "boolean"
+                },
+                "description": {
+                    "description": "Markdown description (optional)",
+                    "type": "string"
+                },
+                "due_date": {
+                    "description": "Due date (optional)",
+                    "type": "string"
+                },
                 "name": {
-                    "description": "Task name (required)",
+                    "description": "Task name (optional)",
                     "type": "string"
                 },
+                "priority": {
+                    "description": "Task priority (optional)",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.TaskPriority"
+                        }
+                    ]
+                },
                 "status": {
-                    "description": "Task status (optional, defaults to incomplete)",
+                    "description": "Task status (optional)",
                     "allOf": [
                         {
                             "$ref": "#/definitions/models.TaskStatus"
                         }
                     ]
+                },
+                "tags": {
+                    "description": "Replacement tag set (optional)",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 }
             }
         },
-        "models.ErrorResponse": {
+        "models.UpdateWebhookRequest": {
             "type": "object",
             "properties": {
-                "error": {
-                    "description": "Detailed error information",
+                "active": {
+                    "description": "Pause or resume deliveries",
+                    "type": "boolean"
+                },
+                "description": {
+                    "description": "Free-form note about the receiver",
                     "type": "string"
                 },
-                "message": {
-                    "description": "Error message",
+                "events": {
+                    "description": "Replacement event types (empty = all)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TaskEventType"
+                    }
+                },
+                "secret": {
+                    "description": "Replacement signing secret (\"\" = generate one)",
                     "type": "string"
                 },
-                "success": {
-                    "description": "Always false",
-                    "type": "boolean"
+                "url": {
+                    "description": "Endpoint receiving the POST requests",
+                    "type": "string"
                 }
             }
         },
-        "models.HealthResponse": {
+        "models.Webhook": {
             "type": "object",
             "properties": {
-                "status": {
-                    "description": "Service status",
+                "active": {
+                    "description": "Inactive webhooks receive nothing",
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "description": "Creation timestamp",
                     "type": "string"
                 },
-                "timestamp": {
-                    "description": "Check timestamp",
+                "description": {
+                    "description": "Free-form note about the receiver",
                     "type": "string"
                 },
-                "version": {
-                    "description": "Service version",
+                "events": {
+                    "description": "Event types delivered (empty = all)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TaskEventType"
+                    }
+                },
+                "id": {
+                    "description": "Unique identifier",
+                    "type": "string"
+                },
+                "secret": {
+                    "description": "Signing secret (only returned when created or rotated)",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "description": "Last update timestamp",
+                    "type": "string"
+                },
+                "url": {
+                    "description": "Endpoint receiving the POST requests",
                     "type": "string"
                 }
             }
         },
-        "models.Task": {
+        "models.WebhookAttempt": {
+            "type": "object",
+            "properties": {
+                "duration_ms": {
+                    "description": "Time until the response or the failure",
+                    "type": "integer"
+                },
+                "error": {
+                    "description": "Why the attempt failed",
+                    "type": "string"
+                },
+                "status_code": {
+                    "description": "Response status (absent if no response was received)",
+                    "type": "integer"
+                },
+                "time": {
+                    "description": "When the request was sent",
+                    "type": "string"
+                }
+            }
+        },
+        "models.WebhookDelivery": {
             "type": "object",
-            "required": [
-                "name"
-            ],
             "properties": {
+                "attempts": {
+                    "description": "Requests sent so far, oldest first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.WebhookAttempt"
+                    }
+                },
                 "created_at": {
-                    "description": "Creation time",
+                    "description": "When the event was queued",
                     "type": "string"
                 },
+                "event": {
+                    "description": "Delivered event",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.TaskEvent"
+                        }
+                    ]
+                },
                 "id": {
-                    "description": "Unique identifier",
+                    "description": "Unique identifier, sent as X-Webhook-Delivery",
                     "type": "string"
                 },
-                "name": {
-                    "description": "Task name (required)",
+                "next_attempt_at": {
+                    "description": "When the next attempt is due (pending and retrying only)",
                     "type": "string"
                 },
                 "status": {
-                    "description": "Task status",
+                    "description": "Current state",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/models.TaskStatus"
+                            "$ref": "#/definitions/models.WebhookDeliveryStatus"
                         }
                     ]
                 },
-                "updated_at": {
-                    "description": "Last update time",
+                "webhook_id": {
+                    "description": "Receiving webhook",
                     "type": "string"
                 }
             }
         },
-        "models.TaskListResponse": {
+        "models.WebhookDeliveryListResponse": {
             "type": "object",
             "properties": {
                 "count": {
-                    "description": "Total number of tasks",
+                    "description": "Number of deliveries",
                     "type": "integer"
                 },
                 "data": {
-                    "description": "Task list",
+                    "description": "Deliveries, newest first",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.Task"
+                        "$ref": "#/definitions/models.WebhookDelivery"
                     }
                 },
                 "success": {
@@ -504,14 +2776,14 @@ const docTemplate = `{
                 }
             }
         },
-        "models.TaskResponse": {
+        "models.WebhookDeliveryResponse": {
             "type": "object",
             "properties": {
                 "data": {
-                    "description": "Task data",
+                    "description": "Delivery data",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/models.Task"
+                            "$ref": "#/definitions/models.WebhookDelivery"
                         }
                     ]
                 },
@@ -525,74 +2797,115 @@ const docTemplate = `{
                 }
             }
         },
-        "models.TaskStatus": {
-            "type": "integer",
+        "models.WebhookDeliveryStatus": {
+            "type": "string",
             "enum": [
-                0,
-                1
+                "pending",
+                "retrying",
+                "succeeded",
+                "dead"
             ],
+            "x-enum-comments": {
+                "DeliveryDead": "Every attempt failed; only a manual retry sends it again",
+                "DeliveryPending": "Waiting for its first attempt",
+                "DeliveryRetrying": "Failed at least once and will be attempted again",
+                "DeliverySucceeded": "Receiver answered with a 2xx status"
+            },
             "x-enum-varnames": [
-                "TaskIncomplete",
-                "TaskCompleted"
+                "DeliveryPending",
+                "DeliveryRetrying",
+                "DeliverySucceeded",
+                "DeliveryDead"
             ]
         },
-        "models.UpdateTaskRequest": {
+        "models.WebhookListResponse": {
             "type": "object",
             "properties": {
-                "name": {
-                    "description": "Task name (optional)",
-                    "type": "string"
+                "count": {
+                    "description": "Number of webhooks",
+                    "type": "integer"
                 },
-                "status": {
-                    "description": "Task status (optional)",
+                "data": {
+                    "description": "Webhooks, oldest first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Webhook"
+                    }
+                },
+                "success": {
+                    "description": "Whether the operation was successful",
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.WebhookResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "description": "Webhook data",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/models.TaskStatus"
+                            "$ref": "#/definitions/models.Webhook"
                         }
                     ]
+                },
+                "message": {
+                    "description": "Response message",
+                    "type": "string"
+                },
+                "success": {
+                    "description": "Whether the operation was successful",
+                    "type": "boolean"
                 }
             }
         },
-        "storage.StorageStats": {
+        "models.Workflow": {
             "type": "object",
             "properties": {
-                "completed_tasks": {
-                    "description": "Number of completed tasks",
-                    "type": "integer"
-                },
-                "incomplete_tasks": {
-                    "description": "Number of incomplete tasks",
-                    "type": "integer"
-                },
-                "last_id": {
-                    "description": "Last generated ID",
-                    "type": "integer"
-                },
-                "storage_type": {
-                    "description": "Type of storage (sharded_memory, database, etc.)",
+                "states": {
+                    "description": "Ordered state definitions",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.WorkflowState"
+                    }
+                }
+            }
+        },
+        "models.WorkflowState": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "description": "Unique state name used in URLs and filters",
                     "type": "string"
                 },
-                "total_tasks": {
-                    "description": "Total number of tasks",
-                    "type": "integer"
+                "terminal": {
+                    "description": "Work is finished in this state (counted as completed)",
+                    "type": "boolean"
+                },
+                "transitions": {
+                    "description": "States reachable from this one",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 }
             }
         }
     },
-    "tags": [
-        {
-            "description": "Task management operations",
-            "name": "tasks"
-        },
-        {
-            "description": "Health check and monitoring endpoints",
-            "name": "health"
-        },
-        {
-            "description": "Statistics and metrics endpoints",
-            "name": "stats"
+    "securityDefinitions": {
+        "APIKeyAuth": {
+            "description": "API key issued through /apikeys; accepted instead of a bearer token",
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
+        },
+        "BearerAuth": {
+            "description": "JWT as \"Bearer \u003ctoken\u003e\"; required on every endpoint but health and workflow when authentication is enabled",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
         }
-    ]
+    }
 }`
 
 // SwaggerInfo holds exported Swagger Info so clients can modify it
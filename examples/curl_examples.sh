@@ -3,6 +3,7 @@
 # Task API Examples using cURL
 # Make sure the server is running on http://localhost:${PORT:-8080}
 # Usage: PORT=3111 ./examples/curl_examples.sh
+# Statistics need an admin: API_KEY=tk_... ./examples/curl_examples.sh
 
 PORT=${PORT:-8080}
 BASE_URL="http://localhost:${PORT}/api/v1"
@@ -122,9 +123,9 @@ print_header "Test Pagination (Limit 2, Offset 0)"
 curl -s "$BASE_URL/tasks/paginated?limit=2&offset=0" \
   -H "Accept: application/json" | jq '.' 2>/dev/null || curl -s "$BASE_URL/tasks/paginated?limit=2&offset=0"
 
-# 13. Get storage statistics
+# 13. Get storage statistics (requires stats:read: set API_KEY to an admin-scoped key)
 print_header "Get Storage Statistics"
-curl -s "$BASE_URL/stats" | jq '.' 2>/dev/null || curl -s "$BASE_URL/stats"
+curl -s "$BASE_URL/stats" ${API_KEY:+-H "X-API-Key: $API_KEY"} | jq '.' 2>/dev/null || curl -s "$BASE_URL/stats" ${API_KEY:+-H "X-API-Key: $API_KEY"}
 
 # 14. Test error cases
 print_header "Test Error Cases"
@@ -174,7 +175,7 @@ curl -s "$BASE_URL/tasks" | jq '.' 2>/dev/null || curl -s "$BASE_URL/tasks"
 
 # 17. Final statistics
 print_header "Final Statistics"
-curl -s "$BASE_URL/stats" | jq '.' 2>/dev/null || curl -s "$BASE_URL/stats"
+curl -s "$BASE_URL/stats" ${API_KEY:+-H "X-API-Key: $API_KEY"} | jq '.' 2>/dev/null || curl -s "$BASE_URL/stats" ${API_KEY:+-H "X-API-Key: $API_KEY"}
 
 print_header "API Endpoints Summary"
 echo "Health Check:     GET  $HEALTH_URL"
@@ -120,6 +120,14 @@ export const useTasks = () => {
     loadStats();
   }, [loadStats]);
 
+  // Reload when tasks change, including changes made by other users
+  useEffect(() => {
+    return apiService.subscribeToTaskEvents(() => {
+      loadTasks();
+      loadStats();
+    });
+  }, [loadTasks, loadStats]);
+
   return {
     tasks,
     stats,
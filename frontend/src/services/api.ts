@@ -99,6 +99,14 @@ class ApiService {
     return this.apiRequest<TaskStats>('/stats');
   }
 
+  // Calls onChange whenever a task is created, updated or deleted; returns a function closing the stream
+  subscribeToTaskEvents(onChange: () => void): () => void {
+    // EventSource reconnects on its own and resumes from the last event it received
+    const source = new EventSource(`${ApiConfig.getBaseUrl()}/tasks/events`);
+    ['created', 'updated', 'deleted', 'reset'].forEach((type) => source.addEventListener(type, onChange));
+    return () => source.close();
+  }
+
   async testConnection(): Promise<{ success: boolean; message: string }> {
     try {
       const currentHostname = window.location.hostname;
@@ -0,0 +1,278 @@
+// Package apikeys issues and verifies the API keys scripts and services authenticate with
+// Keys are random 256-bit secrets; only their SHA-256 hash is kept, so a leaked registry file
+// does not leak usable keys.
+package apikeys
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"task-api/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Sentinel errors returned (wrapped) by the store
+var (
+	ErrNotFound   = errors.New("api key not found") // The requested key does not exist
+	ErrInvalidKey = errors.New("invalid api key")   // The presented key is unknown, revoked or expired
+)
+
+// storedKey is an API key as kept by the store, with the hash of the key
+type storedKey struct {
+	*models.APIKey
+	Hash string `json:"hash"` // Hex SHA-256 of the key
+}
+
+// storeFile is the on-disk format of the key registry
+type storeFile struct {
+	Keys []storedKey `json:"keys"`
+}
+
+// Store keeps the issued API keys
+// Keys are saved to a JSON file after every change when the store has a path. Last-used
+// times change on every request, so they are only written with the next change or by Close.
+type Store struct {
+	path   string                // Registry file ("" = not persisted)
+	keys   map[string]*storedKey // Issued keys by ID
+	hashes map[string]string     // Key IDs by hash of the key
+	dirty  bool                  // Last-used times changed since the last save
+	now    func() time.Time      // Current time source
+	mutex  sync.RWMutex          // Protects the maps and the file
+}
+
+// NewStore creates an API key store, loading the registry file at path if it exists (Factory Pattern)
+// An empty path keeps the keys in memory only.
+func NewStore(path string) (*Store, error) {
+	store := &Store{
+		path:   path,
+		keys:   make(map[string]*storedKey),
+		hashes: make(map[string]string),
+		now:    time.Now,
+	}
+	if path == "" {
+		return store, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read api keys: %w", err)
+	}
+
+	var file storeFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse api keys file %s: %w", path, err)
+	}
+	for i := range file.Keys {
+		key := file.Keys[i]
+		if key.APIKey == nil || key.Hash == "" {
+			return nil, fmt.Errorf("failed to parse api keys file %s: entry %d has no key or hash", path, i)
+		}
+		store.keys[key.ID] = &key
+		store.hashes[key.Hash] = key.ID
+	}
+	return store, nil
+}
+
+// List returns every key in issue order, revoked ones included
+func (s *Store) List() []*models.APIKey {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	keys := make([]*models.APIKey, 0, len(s.keys))
+	for _, key := range s.sorted() {
+		keys = append(keys, key.Redacted())
+	}
+	return keys
+}
+
+// Get returns a key by ID
+func (s *Store) Get(id string) (*models.APIKey, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	key, ok := s.keys[id]
+	if !ok {
+		return nil, notFoundError(id)
+	}
+	return key.Redacted(), nil
+}
+
+// Issue creates a key acting as req.Subject and returns it with the key itself, which is not kept
+func (s *Store) Issue(req *models.IssueAPIKeyRequest, createdBy string) (*models.APIKey, error) {
+	secret, err := generateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	key := &storedKey{
+		APIKey: &models.APIKey{
+			ID:        uuid.New().String(),
+			Name:      req.Name,
+			Prefix:    secret[:models.APIKeyDisplayLength],
+			Subject:   req.Subject,
+			Scopes:    append([]string(nil), req.Scopes...),
+			CreatedBy: createdBy,
+			CreatedAt: s.now().UTC(),
+		},
+		Hash: hashKey(secret),
+	}
+	if req.ExpiresAt != nil {
+		expiresAt := req.ExpiresAt.UTC()
+		key.ExpiresAt = &expiresAt
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.keys[key.ID] = key
+	s.hashes[key.Hash] = key.ID
+	if err := s.save(); err != nil {
+		delete(s.keys, key.ID)
+		delete(s.hashes, key.Hash)
+		return nil, err
+	}
+
+	issued := key.Clone()
+	issued.Key = secret
+	return issued, nil
+}
+
+// Revoke makes a key unusable; revoked keys stay listed with their revocation time
+// Revoking a revoked key keeps the first revocation time.
+func (s *Store) Revoke(id string) (*models.APIKey, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key, ok := s.keys[id]
+	if !ok {
+		return nil, notFoundError(id)
+	}
+	if key.RevokedAt != nil {
+		return key.Redacted(), nil
+	}
+
+	revokedAt := s.now().UTC()
+	key.RevokedAt = &revokedAt
+	if err := s.save(); err != nil {
+		key.RevokedAt = nil
+		return nil, err
+	}
+	return key.Redacted(), nil
+}
+
+// Authenticate returns the key matching secret and records its use
+// Unknown, revoked and expired keys fail with ErrInvalidKey.
+func (s *Store) Authenticate(secret string) (*models.APIKey, error) {
+	hash := hashKey(secret)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	id, ok := s.hashes[hash]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown key", ErrInvalidKey)
+	}
+	key := s.keys[id]
+
+	now := s.now().UTC()
+	if key.RevokedAt != nil {
+		return nil, fmt.Errorf("%w: key %s was revoked", ErrInvalidKey, key.Prefix)
+	}
+	if !key.Usable(now) {
+		return nil, fmt.Errorf("%w: key %s expired", ErrInvalidKey, key.Prefix)
+	}
+
+	key.LastUsedAt = &now
+	s.dirty = true
+	return key.Redacted(), nil
+}
+
+// Close saves the last-used times recorded since the last change
+func (s *Store) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.dirty {
+		return nil
+	}
+	return s.save()
+}
+
+// sorted returns the keys in issue order; must be called with the store lock held
+func (s *Store) sorted() []*storedKey {
+	keys := make([]*storedKey, 0, len(s.keys))
+	for _, key := range s.keys {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if !keys[i].CreatedAt.Equal(keys[j].CreatedAt) {
+			return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+		}
+		return keys[i].ID < keys[j].ID
+	})
+	return keys
+}
+
+// save writes the registry file atomically; must be called with the store lock held
+func (s *Store) save() error {
+	if s.path == "" {
+		s.dirty = false
+		return nil
+	}
+
+	file := storeFile{Keys: make([]storedKey, 0, len(s.keys))}
+	for _, key := range s.sorted() {
+		file.Keys = append(file.Keys, *key)
+	}
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode api keys: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create api keys directory: %w", err)
+	}
+	temp := s.path + ".tmp"
+	if err := os.WriteFile(temp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write api keys: %w", err)
+	}
+	if err := os.Rename(temp, s.path); err != nil {
+		return fmt.Errorf("failed to replace api keys file: %w", err)
+	}
+	s.dirty = false
+	return nil
+}
+
+// notFoundError reports a missing key
+func notFoundError(id string) error {
+	return fmt.Errorf("api key %s: %w", id, ErrNotFound)
+}
+
+// generateKey returns a new random key
+func generateKey() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+	return models.APIKeyPrefix + base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// hashKey returns the hex SHA-256 of a key
+// Keys carry 256 random bits, so a fast unsalted hash cannot be brute-forced.
+func hashKey(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
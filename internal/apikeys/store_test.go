@@ -0,0 +1,134 @@
+package apikeys
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"task-api/internal/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// issueRequest returns a request for a key acting as alice with the given scopes
+func issueRequest(scopes ...string) *models.IssueAPIKeyRequest {
+	return &models.IssueAPIKeyRequest{Name: "ci", Subject: "alice", Scopes: scopes}
+}
+
+func TestStore_IssueAndAuthenticate(t *testing.T) {
+	store, err := NewStore("")
+	require.NoError(t, err)
+
+	issued, err := store.Issue(issueRequest(models.ScopeTasksRead), "root")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(issued.Key, models.APIKeyPrefix))
+	assert.Equal(t, issued.Key[:models.APIKeyDisplayLength], issued.Prefix)
+	assert.Equal(t, "root", issued.CreatedBy)
+	assert.Nil(t, issued.LastUsedAt)
+
+	fetched, err := store.Get(issued.ID)
+	require.NoError(t, err)
+	assert.Empty(t, fetched.Key, "the key is only returned when issued")
+
+	key, err := store.Authenticate(issued.Key)
+	require.NoError(t, err)
+	assert.Equal(t, issued.ID, key.ID)
+	assert.Equal(t, "alice", key.Subject)
+	assert.Empty(t, key.Key)
+	assert.NotNil(t, key.LastUsedAt)
+
+	_, err = store.Authenticate(issued.Key + "x")
+	assert.ErrorIs(t, err, ErrInvalidKey)
+	_, err = store.Authenticate("")
+	assert.ErrorIs(t, err, ErrInvalidKey)
+
+	_, err = store.Get("missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestStore_Revoke(t *testing.T) {
+	store, err := NewStore("")
+	require.NoError(t, err)
+
+	issued, err := store.Issue(issueRequest(models.ScopeTasksWrite), "")
+	require.NoError(t, err)
+
+	revoked, err := store.Revoke(issued.ID)
+	require.NoError(t, err)
+	require.NotNil(t, revoked.RevokedAt)
+
+	_, err = store.Authenticate(issued.Key)
+	assert.ErrorIs(t, err, ErrInvalidKey)
+
+	again, err := store.Revoke(issued.ID)
+	require.NoError(t, err)
+	assert.Equal(t, revoked.RevokedAt, again.RevokedAt, "the first revocation time is kept")
+
+	assert.Len(t, store.List(), 1, "revoked keys stay listed")
+
+	_, err = store.Revoke("missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestStore_Expiry(t *testing.T) {
+	store, err := NewStore("")
+	require.NoError(t, err)
+
+	now := time.Now()
+	store.now = func() time.Time { return now }
+
+	req := issueRequest(models.ScopeTasksRead)
+	expiresAt := now.Add(time.Hour)
+	req.ExpiresAt = &expiresAt
+	issued, err := store.Issue(req, "")
+	require.NoError(t, err)
+
+	_, err = store.Authenticate(issued.Key)
+	require.NoError(t, err)
+
+	now = now.Add(time.Hour)
+	_, err = store.Authenticate(issued.Key)
+	assert.ErrorIs(t, err, ErrInvalidKey)
+}
+
+func TestStore_Persistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "apikeys.json")
+
+	store, err := NewStore(path)
+	require.NoError(t, err)
+	issued, err := store.Issue(issueRequest(models.ScopeAdmin), "root")
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), issued.Key, "only the hash of the key is written")
+	assert.Contains(t, string(data), hashKey(issued.Key))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	// Last-used times are written by Close
+	_, err = store.Authenticate(issued.Key)
+	require.NoError(t, err)
+	require.NoError(t, store.Close())
+
+	reloaded, err := NewStore(path)
+	require.NoError(t, err)
+	key, err := reloaded.Get(issued.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{models.ScopeAdmin}, key.Scopes)
+	assert.NotNil(t, key.LastUsedAt)
+
+	_, err = reloaded.Authenticate(issued.Key)
+	assert.NoError(t, err)
+
+	t.Run("corrupt file", func(t *testing.T) {
+		corrupt := filepath.Join(t.TempDir(), "apikeys.json")
+		require.NoError(t, os.WriteFile(corrupt, []byte(`{"keys":[{"id":"1"}]}`), 0o600))
+		_, err := NewStore(corrupt)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,284 @@
+// Package auth verifies the JSON Web Tokens (RFC 7519) that authenticate API callers
+// Tokens must be signed with HS256 or RS256 by one of the configured keys; the token's
+// algorithm has to match the key, so an RSA public key can never be used as an HMAC secret.
+package auth
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultLeeway is the clock skew tolerated when checking exp and nbf
+const DefaultLeeway = 30 * time.Second
+
+// DefaultAdminClaim names the boolean claim granting access to every task
+const DefaultAdminClaim = "admin"
+
+// DefaultRolesClaim names the claim listing the caller's roles
+const DefaultRolesClaim = "roles"
+
+// Sentinel errors returned (wrapped) by Verify
+var (
+	ErrInvalidToken = errors.New("invalid token") // The token is malformed, badly signed or fails a claim check
+	ErrExpiredToken = errors.New("token expired") // The token's exp lies in the past
+)
+
+// Claims are the verified claims of a token
+type Claims struct {
+	Subject   string                 // sub: the caller
+	Issuer    string                 // iss
+	Audience  []string               // aud
+	ExpiresAt time.Time              // exp
+	Admin     bool                   // Whether the admin claim is true
+	Roles     []string               // Roles listed by the roles claim
+	Raw       map[string]interface{} // Every claim as decoded from the payload
+}
+
+// VerifierConfig defines which tokens a verifier accepts
+type VerifierConfig struct {
+	Issuer     string        // Required iss ("" = any)
+	Audience   string        // Required entry of aud ("" = any)
+	AdminClaim string        // Boolean claim marking administrators ("" = DefaultAdminClaim)
+	RolesClaim string        // Claim listing roles, as an array or a space-separated string ("" = DefaultRolesClaim)
+	Leeway     time.Duration // Tolerated clock skew (0 = DefaultLeeway, negative = none)
+}
+
+// Verifier checks token signatures and claims
+type Verifier struct {
+	keys   *KeySet          // Keys tokens may be signed with
+	config VerifierConfig   // Accepted issuer and audience
+	now    func() time.Time // Current time source
+}
+
+// NewVerifier creates a verifier accepting tokens signed by keys (Factory Pattern)
+func NewVerifier(keys *KeySet, config VerifierConfig) *Verifier {
+	if config.AdminClaim == "" {
+		config.AdminClaim = DefaultAdminClaim
+	}
+	if config.RolesClaim == "" {
+		config.RolesClaim = DefaultRolesClaim
+	}
+	if config.Leeway == 0 {
+		config.Leeway = DefaultLeeway
+	}
+	if config.Leeway < 0 {
+		config.Leeway = 0
+	}
+
+	return &Verifier{keys: keys, config: config, now: time.Now}
+}
+
+// header is the JOSE header of a token
+type header struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid,omitempty"`
+	Type      string `json:"typ,omitempty"`
+}
+
+// Verify checks the token's signature and claims and returns the claims
+// Tokens must carry a subject and an expiry; nbf, iss and aud are checked when present or configured.
+func (v *Verifier) Verify(token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, invalidTokenError("malformed token")
+	}
+
+	var head header
+	if err := decodeSegment(parts[0], &head); err != nil {
+		return nil, invalidTokenError("malformed header")
+	}
+	if head.Algorithm != AlgHS256 && head.Algorithm != AlgRS256 {
+		return nil, invalidTokenError(fmt.Sprintf("unsupported algorithm %q", head.Algorithm))
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, invalidTokenError("malformed signature")
+	}
+	if !v.verifySignature(head, parts[0]+"."+parts[1], signature) {
+		return nil, invalidTokenError("signature verification failed")
+	}
+
+	raw := make(map[string]interface{})
+	if err := decodeSegment(parts[1], &raw); err != nil {
+		return nil, invalidTokenError("malformed claims")
+	}
+	return v.checkClaims(raw)
+}
+
+// verifySignature reports whether one of the candidate keys signed the input
+func (v *Verifier) verifySignature(head header, input string, signature []byte) bool {
+	digest := sha256.Sum256([]byte(input))
+	for _, key := range v.keys.candidates(head.Algorithm, head.KeyID) {
+		switch key.Algorithm {
+		case AlgHS256:
+			mac := hmac.New(sha256.New, key.secret)
+			mac.Write([]byte(input))
+			if hmac.Equal(mac.Sum(nil), signature) {
+				return true
+			}
+		case AlgRS256:
+			if rsa.VerifyPKCS1v15(key.publicKey, crypto.SHA256, digest[:], signature) == nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkClaims validates the registered claims and extracts the ones the API uses
+func (v *Verifier) checkClaims(raw map[string]interface{}) (*Claims, error) {
+	now := v.now()
+	claims := &Claims{Raw: raw}
+
+	subject, _ := raw["sub"].(string)
+	if strings.TrimSpace(subject) == "" {
+		return nil, invalidTokenError("missing sub claim")
+	}
+	claims.Subject = subject
+
+	expiresAt, ok := numericDate(raw["exp"])
+	if !ok {
+		return nil, invalidTokenError("missing or invalid exp claim")
+	}
+	if !now.Before(expiresAt.Add(v.config.Leeway)) {
+		return nil, fmt.Errorf("%w at %s", ErrExpiredToken, expiresAt.UTC().Format(time.RFC3339))
+	}
+	claims.ExpiresAt = expiresAt
+
+	if value, present := raw["nbf"]; present {
+		notBefore, ok := numericDate(value)
+		if !ok {
+			return nil, invalidTokenError("invalid nbf claim")
+		}
+		if now.Add(v.config.Leeway).Before(notBefore) {
+			return nil, invalidTokenError("token not valid yet")
+		}
+	}
+
+	claims.Issuer, _ = raw["iss"].(string)
+	if v.config.Issuer != "" && claims.Issuer != v.config.Issuer {
+		return nil, invalidTokenError("unexpected issuer")
+	}
+
+	switch aud := raw["aud"].(type) {
+	case string:
+		claims.Audience = []string{aud}
+	case []interface{}:
+		for _, entry := range aud {
+			if s, ok := entry.(string); ok {
+				claims.Audience = append(claims.Audience, s)
+			}
+		}
+	}
+	if v.config.Audience != "" && !contains(claims.Audience, v.config.Audience) {
+		return nil, invalidTokenError("unexpected audience")
+	}
+
+	claims.Admin, _ = raw[v.config.AdminClaim].(bool)
+	claims.Roles = stringList(raw[v.config.RolesClaim])
+	return claims, nil
+}
+
+// stringList reads a claim holding either an array of strings or a space-separated string
+// (the form of the OAuth scope claim); entries of other types are ignored.
+func stringList(value interface{}) []string {
+	switch value := value.(type) {
+	case string:
+		return strings.Fields(value)
+	case []interface{}:
+		var list []string
+		for _, entry := range value {
+			if s, ok := entry.(string); ok && s != "" {
+				list = append(list, s)
+			}
+		}
+		return list
+	}
+	return nil
+}
+
+// numericDate converts a NumericDate claim (seconds since the epoch) to a time
+func numericDate(value interface{}) (time.Time, bool) {
+	number, ok := value.(json.Number)
+	if !ok {
+		return time.Time{}, false
+	}
+	seconds, err := number.Float64()
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(0, int64(seconds*float64(time.Second))), true
+}
+
+// decodeSegment decodes a base64url JSON segment, keeping numbers exact
+func decodeSegment(segment string, target interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(target)
+}
+
+// contains reports whether values includes value
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// invalidTokenError reports why a token was rejected
+func invalidTokenError(reason string) error {
+	return fmt.Errorf("%w: %s", ErrInvalidToken, reason)
+}
+
+// SignHS256 issues a token signed with an HMAC secret, for tests and local tooling
+func SignHS256(claims map[string]interface{}, keyID string, secret []byte) (string, error) {
+	return sign(claims, header{Algorithm: AlgHS256, KeyID: keyID, Type: "JWT"}, func(input []byte) ([]byte, error) {
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(input)
+		return mac.Sum(nil), nil
+	})
+}
+
+// SignRS256 issues a token signed with an RSA private key, for tests and local tooling
+func SignRS256(claims map[string]interface{}, keyID string, privateKey *rsa.PrivateKey) (string, error) {
+	return sign(claims, header{Algorithm: AlgRS256, KeyID: keyID, Type: "JWT"}, func(input []byte) ([]byte, error) {
+		digest := sha256.Sum256(input)
+		return rsa.SignPKCS1v15(rand.Reader, privateKey, crypto.SHA256, digest[:])
+	})
+}
+
+// sign encodes the header and claims and appends the signature computed by signer
+func sign(claims map[string]interface{}, head header, signer func(input []byte) ([]byte, error)) (string, error) {
+	headJSON, err := json.Marshal(head)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode header: %w", err)
+	}
+	claimsJSON, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode claims: %w", err)
+	}
+
+	input := base64.RawURLEncoding.EncodeToString(headJSON) + "." + base64.RawURLEncoding.EncodeToString(claimsJSON)
+	signature, err := signer([]byte(input))
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %w", err)
+	}
+	return input + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
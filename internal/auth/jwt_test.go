@@ -0,0 +1,222 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testSecret signs the HS256 test tokens
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+var (
+	testRSAKey     *rsa.PrivateKey
+	testRSAKeyOnce sync.Once
+)
+
+// rsaKey returns an RSA key shared by the tests, generated on first use
+func rsaKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+
+	testRSAKeyOnce.Do(func() {
+		var err error
+		testRSAKey, err = rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+	})
+	return testRSAKey
+}
+
+// validClaims returns the claims of a token valid for the next hour
+func validClaims(subject string) map[string]interface{} {
+	return map[string]interface{}{
+		"sub": subject,
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+// hmacVerifier verifies tokens signed with testSecret
+func hmacVerifier(t *testing.T, config VerifierConfig) *Verifier {
+	t.Helper()
+
+	keys := NewKeySet()
+	require.NoError(t, keys.AddHMAC("", []byte(testSecret)))
+	return NewVerifier(keys, config)
+}
+
+func TestVerifier_HS256(t *testing.T) {
+	verifier := hmacVerifier(t, VerifierConfig{})
+
+	claims := validClaims("alice")
+	claims["admin"] = true
+	token, err := SignHS256(claims, "", []byte(testSecret))
+	require.NoError(t, err)
+
+	verified, err := verifier.Verify(token)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", verified.Subject)
+	assert.True(t, verified.Admin)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), verified.ExpiresAt, time.Minute)
+
+	t.Run("wrong secret", func(t *testing.T) {
+		token, err := SignHS256(validClaims("alice"), "", []byte("another secret of thirty-two bytes"))
+		require.NoError(t, err)
+		_, err = verifier.Verify(token)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("tampered claims", func(t *testing.T) {
+		parts := strings.Split(token, ".")
+		parts[1] = base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"mallory","exp":9999999999,"admin":true}`))
+		_, err := verifier.Verify(strings.Join(parts, "."))
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("non-boolean admin claim", func(t *testing.T) {
+		claims := validClaims("alice")
+		claims["admin"] = "true"
+		token, err := SignHS256(claims, "", []byte(testSecret))
+		require.NoError(t, err)
+		verified, err := verifier.Verify(token)
+		require.NoError(t, err)
+		assert.False(t, verified.Admin)
+	})
+}
+
+func TestVerifier_Roles(t *testing.T) {
+	tests := []struct {
+		name   string
+		config VerifierConfig
+		claim  string
+		value  interface{}
+		roles  []string
+	}{
+		{"array", VerifierConfig{}, "roles", []string{"viewer", "auditor"}, []string{"viewer", "auditor"}},
+		{"space-separated string", VerifierConfig{}, "roles", "viewer auditor", []string{"viewer", "auditor"}},
+		{"custom claim", VerifierConfig{RolesClaim: "groups"}, "groups", []string{"editor"}, []string{"editor"}},
+		{"other claim ignored", VerifierConfig{RolesClaim: "groups"}, "roles", []string{"editor"}, nil},
+		{"non-string entries ignored", VerifierConfig{}, "roles", []interface{}{"viewer", 7, ""}, []string{"viewer"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := validClaims("alice")
+			claims[tt.claim] = tt.value
+			token, err := SignHS256(claims, "", []byte(testSecret))
+			require.NoError(t, err)
+
+			verified, err := hmacVerifier(t, tt.config).Verify(token)
+			require.NoError(t, err)
+			assert.Equal(t, tt.roles, verified.Roles)
+		})
+	}
+}
+
+func TestVerifier_RS256(t *testing.T) {
+	key := rsaKey(t)
+	keys := NewKeySet()
+	require.NoError(t, keys.AddRSA("rsa-1", &key.PublicKey))
+	verifier := NewVerifier(keys, VerifierConfig{})
+
+	token, err := SignRS256(validClaims("bob"), "rsa-1", key)
+	require.NoError(t, err)
+	verified, err := verifier.Verify(token)
+	require.NoError(t, err)
+	assert.Equal(t, "bob", verified.Subject)
+	assert.False(t, verified.Admin)
+
+	t.Run("unknown key ID", func(t *testing.T) {
+		token, err := SignRS256(validClaims("bob"), "rsa-2", key)
+		require.NoError(t, err)
+		_, err = verifier.Verify(token)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("public key used as HMAC secret", func(t *testing.T) {
+		// A token claiming HS256 must not be checked against the RSA key
+		token, err := SignHS256(validClaims("mallory"), "rsa-1", key.PublicKey.N.Bytes())
+		require.NoError(t, err)
+		_, err = verifier.Verify(token)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+}
+
+func TestVerifier_Claims(t *testing.T) {
+	verifier := hmacVerifier(t, VerifierConfig{Issuer: "https://issuer.example.com", Audience: "task-api", Leeway: -1})
+
+	base := func() map[string]interface{} {
+		claims := validClaims("alice")
+		claims["iss"] = "https://issuer.example.com"
+		claims["aud"] = []string{"other", "task-api"}
+		return claims
+	}
+
+	token, err := SignHS256(base(), "", []byte(testSecret))
+	require.NoError(t, err)
+	verified, err := verifier.Verify(token)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"other", "task-api"}, verified.Audience)
+
+	tests := []struct {
+		name   string
+		change func(claims map[string]interface{})
+		err    error
+	}{
+		{"expired", func(c map[string]interface{}) { c["exp"] = time.Now().Add(-time.Minute).Unix() }, ErrExpiredToken},
+		{"missing exp", func(c map[string]interface{}) { delete(c, "exp") }, ErrInvalidToken},
+		{"string exp", func(c map[string]interface{}) { c["exp"] = "tomorrow" }, ErrInvalidToken},
+		{"not valid yet", func(c map[string]interface{}) { c["nbf"] = time.Now().Add(time.Minute).Unix() }, ErrInvalidToken},
+		{"missing sub", func(c map[string]interface{}) { delete(c, "sub") }, ErrInvalidToken},
+		{"blank sub", func(c map[string]interface{}) { c["sub"] = " " }, ErrInvalidToken},
+		{"other issuer", func(c map[string]interface{}) { c["iss"] = "https://evil.example.com" }, ErrInvalidToken},
+		{"other audience", func(c map[string]interface{}) { c["aud"] = "another-api" }, ErrInvalidToken},
+		{"missing audience", func(c map[string]interface{}) { delete(c, "aud") }, ErrInvalidToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := base()
+			tt.change(claims)
+			token, err := SignHS256(claims, "", []byte(testSecret))
+			require.NoError(t, err)
+
+			_, err = verifier.Verify(token)
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+
+	t.Run("leeway", func(t *testing.T) {
+		lenient := hmacVerifier(t, VerifierConfig{Leeway: time.Minute})
+		claims := validClaims("alice")
+		claims["exp"] = time.Now().Add(-30 * time.Second).Unix()
+		token, err := SignHS256(claims, "", []byte(testSecret))
+		require.NoError(t, err)
+
+		_, err = lenient.Verify(token)
+		assert.NoError(t, err)
+	})
+}
+
+func TestVerifier_MalformedTokens(t *testing.T) {
+	verifier := hmacVerifier(t, VerifierConfig{})
+	encode := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+
+	tokens := map[string]string{
+		"empty":            "",
+		"two segments":     "a.b",
+		"header not json":  encode("nope") + "." + encode("{}") + ".sig",
+		"alg none":         encode(`{"alg":"none"}`) + "." + encode(`{"sub":"alice","exp":9999999999}`) + ".",
+		"unsupported alg":  encode(`{"alg":"ES256"}`) + "." + encode(`{"sub":"alice","exp":9999999999}`) + ".c2ln",
+		"signature base64": encode(`{"alg":"HS256"}`) + "." + encode(`{}`) + ".***",
+	}
+	for name, token := range tokens {
+		t.Run(name, func(t *testing.T) {
+			_, err := verifier.Verify(token)
+			assert.ErrorIs(t, err, ErrInvalidToken)
+		})
+	}
+}
@@ -0,0 +1,236 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+)
+
+// Supported signing algorithms
+const (
+	AlgHS256 = "HS256" // HMAC with SHA-256, shared secret
+	AlgRS256 = "RS256" // RSASSA-PKCS1-v1_5 with SHA-256, RSA public key
+)
+
+// Key size floors; shorter keys are rejected when loaded
+const (
+	MinHMACSecretLength = 32   // Bytes, the SHA-256 output size (RFC 7518 section 3.2)
+	MinRSAKeyBits       = 2048 // Modulus size
+)
+
+// Key is one verification key
+type Key struct {
+	ID        string         // Key ID matched against the token's kid header ("" = any kid)
+	Algorithm string         // AlgHS256 or AlgRS256
+	secret    []byte         // HMAC secret (HS256)
+	publicKey *rsa.PublicKey // RSA public key (RS256)
+}
+
+// KeySet holds the keys tokens may be signed with
+type KeySet struct {
+	keys []*Key
+}
+
+// NewKeySet creates an empty key set (Factory Pattern)
+func NewKeySet() *KeySet {
+	return &KeySet{}
+}
+
+// Len returns the number of keys in the set
+func (ks *KeySet) Len() int {
+	return len(ks.keys)
+}
+
+// AddHMAC adds a shared HS256 secret
+func (ks *KeySet) AddHMAC(id string, secret []byte) error {
+	if len(secret) < MinHMACSecretLength {
+		return fmt.Errorf("HMAC secret must be at least %d bytes, got %d", MinHMACSecretLength, len(secret))
+	}
+	ks.keys = append(ks.keys, &Key{ID: id, Algorithm: AlgHS256, secret: append([]byte(nil), secret...)})
+	return nil
+}
+
+// AddRSA adds an RS256 public key
+func (ks *KeySet) AddRSA(id string, publicKey *rsa.PublicKey) error {
+	if publicKey.N.BitLen() < MinRSAKeyBits {
+		return fmt.Errorf("RSA key must be at least %d bits, got %d", MinRSAKeyBits, publicKey.N.BitLen())
+	}
+	ks.keys = append(ks.keys, &Key{ID: id, Algorithm: AlgRS256, publicKey: publicKey})
+	return nil
+}
+
+// candidates returns the keys that may have signed a token with the given header
+// A token naming a key ID only matches keys with that ID or without one.
+func (ks *KeySet) candidates(algorithm, id string) []*Key {
+	var keys []*Key
+	for _, key := range ks.keys {
+		if key.Algorithm != algorithm {
+			continue
+		}
+		if id != "" && key.ID != "" && key.ID != id {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// LoadPublicKeyFile adds the RSA public key of a PEM file
+// The file may hold a PKIX public key, a PKCS #1 public key or a certificate.
+func (ks *KeySet) LoadPublicKeyFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read public key: %w", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return fmt.Errorf("public key file %s is not PEM encoded", path)
+	}
+
+	var parsed interface{}
+	switch block.Type {
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		parsed, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "CERTIFICATE":
+		var cert *x509.Certificate
+		if cert, err = x509.ParseCertificate(block.Bytes); err == nil {
+			parsed = cert.PublicKey
+		}
+	default:
+		return fmt.Errorf("unsupported PEM block %q in %s", block.Type, path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to parse public key %s: %w", path, err)
+	}
+
+	publicKey, ok := parsed.(*rsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("public key %s is not an RSA key", path)
+	}
+	return ks.AddRSA("", publicKey)
+}
+
+// jsonWebKey is the subset of an RFC 7517 JSON Web Key the key set understands
+type jsonWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	Use       string `json:"use"`
+	N         string `json:"n"` // RSA modulus
+	E         string `json:"e"` // RSA public exponent
+	K         string `json:"k"` // Symmetric key
+}
+
+// LoadJWKSFile adds the signing keys of a local JSON Web Key Set file
+// RSA keys are used for RS256 and symmetric (oct) keys for HS256; encryption keys and keys
+// for other algorithms are skipped. The file must contain at least one usable key.
+func (ks *KeySet) LoadJWKSFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read JWKS: %w", err)
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return fmt.Errorf("failed to parse JWKS file %s: %w", path, err)
+	}
+
+	added := 0
+	for i, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		switch {
+		case jwk.KeyType == "RSA" && (jwk.Algorithm == "" || jwk.Algorithm == AlgRS256):
+			publicKey, err := jwk.rsaPublicKey()
+			if err != nil {
+				return fmt.Errorf("JWKS key %d: %w", i, err)
+			}
+			if err := ks.AddRSA(jwk.KeyID, publicKey); err != nil {
+				return fmt.Errorf("JWKS key %d: %w", i, err)
+			}
+		case jwk.KeyType == "oct" && (jwk.Algorithm == "" || jwk.Algorithm == AlgHS256):
+			secret, err := base64.RawURLEncoding.DecodeString(jwk.K)
+			if err != nil {
+				return fmt.Errorf("JWKS key %d: invalid k: %w", i, err)
+			}
+			if err := ks.AddHMAC(jwk.KeyID, secret); err != nil {
+				return fmt.Errorf("JWKS key %d: %w", i, err)
+			}
+		default:
+			continue
+		}
+		added++
+	}
+
+	if added == 0 {
+		return fmt.Errorf("JWKS file %s has no RS256 or HS256 signing keys", path)
+	}
+	return nil
+}
+
+// rsaPublicKey decodes the modulus and exponent of an RSA JSON Web Key
+func (jwk *jsonWebKey) rsaPublicKey() (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+	if err != nil || len(n) == 0 {
+		return nil, errors.New("invalid RSA modulus")
+	}
+	e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+	if err != nil || len(e) == 0 || len(e) > 4 {
+		return nil, errors.New("invalid RSA exponent")
+	}
+
+	exponent := 0
+	for _, b := range e {
+		exponent = exponent<<8 | int(b)
+	}
+	if exponent < 3 {
+		return nil, errors.New("invalid RSA exponent")
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: exponent}, nil
+}
+
+// KeyConfig names the sources of the verification keys
+type KeyConfig struct {
+	HMACSecret    string // Shared HS256 secret
+	PublicKeyFile string // PEM file with an RS256 public key
+	JWKSFile      string // Local JSON Web Key Set file
+}
+
+// IsEmpty reports whether no key source is configured, which leaves authentication off
+func (c KeyConfig) IsEmpty() bool {
+	return c.HMACSecret == "" && c.PublicKeyFile == "" && c.JWKSFile == ""
+}
+
+// LoadKeySet builds a key set from every configured source
+func LoadKeySet(config KeyConfig) (*KeySet, error) {
+	keys := NewKeySet()
+	if config.HMACSecret != "" {
+		if err := keys.AddHMAC("", []byte(config.HMACSecret)); err != nil {
+			return nil, err
+		}
+	}
+	if config.PublicKeyFile != "" {
+		if err := keys.LoadPublicKeyFile(config.PublicKeyFile); err != nil {
+			return nil, err
+		}
+	}
+	if config.JWKSFile != "" {
+		if err := keys.LoadJWKSFile(config.JWKSFile); err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
@@ -0,0 +1,120 @@
+package auth
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeFile writes a test file and returns its path
+func writeFile(t *testing.T, name string, data []byte) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	return path
+}
+
+func TestKeySet_Limits(t *testing.T) {
+	keys := NewKeySet()
+	assert.Error(t, keys.AddHMAC("", []byte("too short")))
+
+	key := rsaKey(t)
+	assert.NoError(t, keys.AddRSA("", &key.PublicKey))
+	assert.Equal(t, 1, keys.Len())
+}
+
+func TestKeySet_LoadPublicKeyFile(t *testing.T) {
+	key := rsaKey(t)
+	pkix, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+
+	blocks := map[string]*pem.Block{
+		"pkix":   {Type: "PUBLIC KEY", Bytes: pkix},
+		"pkcs1":  {Type: "RSA PUBLIC KEY", Bytes: x509.MarshalPKCS1PublicKey(&key.PublicKey)},
+		"secret": {Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)},
+	}
+
+	token, err := SignRS256(validClaims("alice"), "", key)
+	require.NoError(t, err)
+
+	for _, name := range []string{"pkix", "pkcs1"} {
+		t.Run(name, func(t *testing.T) {
+			keys := NewKeySet()
+			require.NoError(t, keys.LoadPublicKeyFile(writeFile(t, "key.pem", pem.EncodeToMemory(blocks[name]))))
+
+			_, err := NewVerifier(keys, VerifierConfig{}).Verify(token)
+			assert.NoError(t, err)
+		})
+	}
+
+	t.Run("private key", func(t *testing.T) {
+		err := NewKeySet().LoadPublicKeyFile(writeFile(t, "key.pem", pem.EncodeToMemory(blocks["secret"])))
+		assert.Error(t, err)
+	})
+
+	t.Run("not PEM", func(t *testing.T) {
+		assert.Error(t, NewKeySet().LoadPublicKeyFile(writeFile(t, "key.pem", []byte("garbage"))))
+	})
+}
+
+func TestKeySet_LoadJWKSFile(t *testing.T) {
+	key := rsaKey(t)
+	encode := base64.RawURLEncoding.EncodeToString
+
+	jwks := map[string]interface{}{
+		"keys": []map[string]string{
+			{"kty": "RSA", "kid": "rsa-1", "use": "sig", "alg": "RS256",
+				"n": encode(key.PublicKey.N.Bytes()), "e": encode(big.NewInt(int64(key.PublicKey.E)).Bytes())},
+			{"kty": "oct", "kid": "shared", "k": encode([]byte(testSecret))},
+			{"kty": "RSA", "kid": "encryption", "use": "enc", "n": "AQAB", "e": "AQAB"},
+			{"kty": "EC", "kid": "ec-1", "crv": "P-256"},
+		},
+	}
+	data, err := json.Marshal(jwks)
+	require.NoError(t, err)
+
+	keys := NewKeySet()
+	require.NoError(t, keys.LoadJWKSFile(writeFile(t, "jwks.json", data)))
+	assert.Equal(t, 2, keys.Len())
+	verifier := NewVerifier(keys, VerifierConfig{})
+
+	rsaToken, err := SignRS256(validClaims("alice"), "rsa-1", key)
+	require.NoError(t, err)
+	_, err = verifier.Verify(rsaToken)
+	assert.NoError(t, err)
+
+	hmacToken, err := SignHS256(validClaims("bob"), "shared", []byte(testSecret))
+	require.NoError(t, err)
+	_, err = verifier.Verify(hmacToken)
+	assert.NoError(t, err)
+
+	t.Run("no usable keys", func(t *testing.T) {
+		err := NewKeySet().LoadJWKSFile(writeFile(t, "jwks.json", []byte(`{"keys":[{"kty":"EC"}]}`)))
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid key", func(t *testing.T) {
+		err := NewKeySet().LoadJWKSFile(writeFile(t, "jwks.json", []byte(`{"keys":[{"kty":"RSA","n":"","e":"AQAB"}]}`)))
+		assert.Error(t, err)
+	})
+}
+
+func TestLoadKeySet(t *testing.T) {
+	assert.True(t, KeyConfig{}.IsEmpty())
+
+	keys, err := LoadKeySet(KeyConfig{HMACSecret: testSecret})
+	require.NoError(t, err)
+	assert.Equal(t, 1, keys.Len())
+
+	_, err = LoadKeySet(KeyConfig{JWKSFile: filepath.Join(t.TempDir(), "missing.json")})
+	assert.Error(t, err)
+}
@@ -3,7 +3,13 @@ package config
 import (
 	"log"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"task-api/internal/auth"
+	"task-api/internal/middleware"
+	"task-api/internal/rbac"
+	"time"
 )
 
 // Config holds application configuration
@@ -17,12 +23,62 @@ type Config struct {
 	IdleTimeout     int    `json:"idle_timeout"`
 	AllowedOrigins  string `json:"allowed_origins"`
 	MaxTasks        int    `json:"max_tasks"`
+	MaxRevisions    int    `json:"max_revisions"` // Revisions kept per task, oldest dropped first
+
+	// Storage configuration
+	StorageBackend   string `json:"storage_backend"`   // Storage backend: memory, file or sqlite
+	DataDir          string `json:"data_dir"`          // Directory for persistent storage files
+	SQLitePath       string `json:"sqlite_path"`       // Database file path (sqlite backend)
+	SnapshotInterval int    `json:"snapshot_interval"` // Log records between snapshots (file backend)
+	SyncWrites       bool   `json:"sync_writes"`       // fsync every write (file backend)
+
+	// Workflow configuration
+	WorkflowFile string `json:"workflow_file"` // JSON workflow definition (empty = incomplete/completed)
+
+	// API configuration
+	APIVersion int `json:"api_version"` // Default API version for requests without an API-Version header
+
+	// Change feed configuration
+	EventReplaySize       int `json:"event_replay_size"`       // Recent events kept for resuming clients
+	EventClientBuffer     int `json:"event_client_buffer"`     // Events a client may fall behind by before it is disconnected
+	EventHeartbeatSeconds int `json:"event_heartbeat_seconds"` // Seconds between keep-alive comments on idle streams
+
+	// Webhook configuration
+	WebhooksFile          string `json:"webhooks_file"`           // Webhook registry file (empty = inside DataDir for persistent backends)
+	WebhookMaxAttempts    int    `json:"webhook_max_attempts"`    // Attempts before a delivery is dead
+	WebhookTimeoutSeconds int    `json:"webhook_timeout_seconds"` // Seconds a receiver has to answer
+	WebhookLogSize        int    `json:"webhook_log_size"`        // Deliveries kept per webhook
+
+	// API key configuration
+	APIKeysFile string `json:"api_keys_file"` // API key registry file (empty = inside DataDir for persistent backends)
+
+	// Authentication configuration (disabled unless a key source is set)
+	JWTHMACSecret    string `json:"-"`                   // Shared HS256 secret
+	JWTPublicKeyFile string `json:"jwt_public_key_file"` // PEM file with the RS256 public key
+	JWTJWKSFile      string `json:"jwt_jwks_file"`       // Local JWKS file with RS256 and HS256 keys
+	JWTIssuer        string `json:"jwt_issuer"`          // Required iss claim (empty = any)
+	JWTAudience      string `json:"jwt_audience"`        // Required aud claim (empty = any)
+	JWTAdminClaim    string `json:"jwt_admin_claim"`     // Boolean claim granting access to every task
+	JWTRolesClaim    string `json:"jwt_roles_claim"`     // Claim listing the caller's roles
+	JWTLeewaySeconds int    `json:"jwt_leeway_seconds"`  // Tolerated clock skew when checking exp and nbf
+
+	// Authorization configuration
+	RBACPolicyFile string `json:"rbac_policy_file"` // JSON policy with roles and route permissions (empty = built-in policy)
+
+	// Reverse proxy configuration
+	TrustedProxies     string `json:"trusted_proxies"`      // Comma-separated CIDRs or addresses of the proxies in front of the server
+	TrustedProxyHeader string `json:"trusted_proxy_header"` // Header they report the client IP in: X-Forwarded-For, X-Real-IP or Forwarded
 
 	// Rate limiting configuration
-	RateLimitEnabled     bool `json:"rate_limit_enabled"`
-	RateLimitPerIP       int  `json:"rate_limit_per_ip"`       // Requests per minute per IP
-	RateLimitPerAPIKey   int  `json:"rate_limit_per_api_key"`  // Requests per minute per API key
-	RateLimitCleanupTime int  `json:"rate_limit_cleanup_time"` // Cleanup interval in minutes
+	RateLimitEnabled     bool   `json:"rate_limit_enabled"`
+	RateLimitPerIP       int    `json:"rate_limit_per_ip"`       // Requests per minute per IP
+	RateLimitPerAPIKey   int    `json:"rate_limit_per_api_key"`  // Requests per minute per API key
+	RateLimitCleanupTime int    `json:"rate_limit_cleanup_time"` // Cleanup interval in minutes
+	RateLimitAlgorithm   string `json:"rate_limit_algorithm"`    // fixed_window, token_bucket, sliding_log or sliding_window
+	RateLimitBurst       int    `json:"rate_limit_burst"`        // Token bucket: requests allowed at once on top of the limit
+	RateLimitPolicyFile  string `json:"rate_limit_policy_file"`  // JSON per-route rate limit policies (empty = built-in policies)
+	RateLimitRedisURL    string `json:"-"`                       // redis:// URL of the store shared by instances (empty = per-instance memory); may hold a password
+	RateLimitRedisPrefix string `json:"rate_limit_redis_prefix"` // Prefix of the rate limit keys kept in Redis
 }
 
 // LoadConfig loads configuration from environment variables with defaults
@@ -37,12 +93,62 @@ func LoadConfig() *Config {
 		IdleTimeout:     getEnvAsInt("IDLE_TIMEOUT", 120),
 		AllowedOrigins:  getEnv("ALLOWED_ORIGINS", "*"),
 		MaxTasks:        getEnvAsInt("MAX_TASKS", 10000),
+		MaxRevisions:    getEnvAsInt("MAX_TASK_REVISIONS", 100),
+
+		// Storage defaults
+		StorageBackend:   getEnv("STORAGE_BACKEND", "memory"),
+		DataDir:          getEnv("DATA_DIR", "./data"),
+		SQLitePath:       getEnv("SQLITE_PATH", ""),
+		SnapshotInterval: getEnvAsInt("SNAPSHOT_INTERVAL", 1000),
+		SyncWrites:       getEnvAsBool("SYNC_WRITES", true),
+
+		// Workflow defaults
+		WorkflowFile: getEnv("WORKFLOW_FILE", ""),
+
+		// API defaults
+		APIVersion: getEnvAsInt("API_VERSION", 1),
+
+		// Change feed defaults
+		EventReplaySize:       getEnvAsInt("EVENT_REPLAY_SIZE", 1000),
+		EventClientBuffer:     getEnvAsInt("EVENT_CLIENT_BUFFER", 256),
+		EventHeartbeatSeconds: getEnvAsInt("EVENT_HEARTBEAT_SECONDS", 15),
+
+		// Webhook defaults
+		WebhooksFile:          getEnv("WEBHOOKS_FILE", ""),
+		WebhookMaxAttempts:    getEnvAsInt("WEBHOOK_MAX_ATTEMPTS", 8),
+		WebhookTimeoutSeconds: getEnvAsInt("WEBHOOK_TIMEOUT_SECONDS", 10),
+		WebhookLogSize:        getEnvAsInt("WEBHOOK_LOG_SIZE", 100),
+
+		// API key defaults
+		APIKeysFile: getEnv("API_KEYS_FILE", ""),
+
+		// Authentication defaults
+		JWTHMACSecret:    getEnv("JWT_HS256_SECRET", ""),
+		JWTPublicKeyFile: getEnv("JWT_RS256_PUBLIC_KEY_FILE", ""),
+		JWTJWKSFile:      getEnv("JWT_JWKS_FILE", ""),
+		JWTIssuer:        getEnv("JWT_ISSUER", ""),
+		JWTAudience:      getEnv("JWT_AUDIENCE", ""),
+		JWTAdminClaim:    getEnv("JWT_ADMIN_CLAIM", auth.DefaultAdminClaim),
+		JWTRolesClaim:    getEnv("JWT_ROLES_CLAIM", auth.DefaultRolesClaim),
+		JWTLeewaySeconds: getEnvAsInt("JWT_LEEWAY_SECONDS", 30),
+
+		// Authorization defaults
+		RBACPolicyFile: getEnv("RBAC_POLICY_FILE", ""),
+
+		// Reverse proxy defaults
+		TrustedProxies:     getEnv("TRUSTED_PROXIES", "127.0.0.1,::1"),
+		TrustedProxyHeader: getEnv("TRUSTED_PROXY_HEADER", middleware.HeaderXForwardedFor),
 
 		// Rate limiting defaults
 		RateLimitEnabled:     getEnvAsBool("RATE_LIMIT_ENABLED", true),
 		RateLimitPerIP:       getEnvAsInt("RATE_LIMIT_PER_IP", 100),       // 100 requests per minute per IP
 		RateLimitPerAPIKey:   getEnvAsInt("RATE_LIMIT_PER_API_KEY", 1000), // 1000 requests per minute per API key
 		RateLimitCleanupTime: getEnvAsInt("RATE_LIMIT_CLEANUP_TIME", 5),   // Cleanup every 5 minutes
+		RateLimitAlgorithm:   getEnv("RATE_LIMIT_ALGORITHM", "fixed_window"),
+		RateLimitBurst:       getEnvAsInt("RATE_LIMIT_BURST", 0),
+		RateLimitPolicyFile:  getEnv("RATE_LIMIT_POLICY_FILE", ""),
+		RateLimitRedisURL:    getEnv("RATE_LIMIT_REDIS_URL", ""),
+		RateLimitRedisPrefix: getEnv("RATE_LIMIT_REDIS_PREFIX", middleware.DefaultRedisKeyPrefix),
 	}
 
 	return config
@@ -93,6 +199,82 @@ func (c *Config) GetServerAddress() string {
 	return c.Host + ":" + c.Port
 }
 
+// GetSQLitePath returns the sqlite database path, defaulting to a file inside DataDir
+func (c *Config) GetSQLitePath() string {
+	if c.SQLitePath != "" {
+		return c.SQLitePath
+	}
+	return filepath.Join(c.DataDir, "tasks.db")
+}
+
+// GetWebhooksPath returns the webhook registry file, or "" to keep webhooks in memory
+// Persistent storage backends keep it next to their data unless WebhooksFile is set.
+func (c *Config) GetWebhooksPath() string {
+	if c.WebhooksFile != "" {
+		return c.WebhooksFile
+	}
+	if c.StorageBackend == "" || c.StorageBackend == "memory" {
+		return ""
+	}
+	return filepath.Join(c.DataDir, "webhooks.json")
+}
+
+// GetAPIKeysPath returns the API key registry file, or "" to keep API keys in memory
+// Persistent storage backends keep it next to their data unless APIKeysFile is set.
+func (c *Config) GetAPIKeysPath() string {
+	if c.APIKeysFile != "" {
+		return c.APIKeysFile
+	}
+	if c.StorageBackend == "" || c.StorageBackend == "memory" {
+		return ""
+	}
+	return filepath.Join(c.DataDir, "apikeys.json")
+}
+
+// GetAuthKeys returns the sources of the keys tokens may be signed with
+// Authentication is disabled when none is set.
+func (c *Config) GetAuthKeys() auth.KeyConfig {
+	return auth.KeyConfig{
+		HMACSecret:    c.JWTHMACSecret,
+		PublicKeyFile: c.JWTPublicKeyFile,
+		JWKSFile:      c.JWTJWKSFile,
+	}
+}
+
+// GetAuthVerifierConfig returns the claims tokens are checked against
+func (c *Config) GetAuthVerifierConfig() auth.VerifierConfig {
+	leeway := time.Duration(c.JWTLeewaySeconds) * time.Second
+	if c.JWTLeewaySeconds == 0 {
+		leeway = -1 // Zero seconds configured means no leeway, not the default
+	}
+	return auth.VerifierConfig{
+		Issuer:     c.JWTIssuer,
+		Audience:   c.JWTAudience,
+		AdminClaim: c.JWTAdminClaim,
+		RolesClaim: c.JWTRolesClaim,
+		Leeway:     leeway,
+	}
+}
+
+// GetRBACPolicy returns the authorization policy: the policy file, or the built-in policy when none is set
+func (c *Config) GetRBACPolicy() (*rbac.Policy, error) {
+	if c.RBACPolicyFile == "" {
+		return rbac.DefaultPolicy(), nil
+	}
+	return rbac.LoadPolicy(c.RBACPolicyFile)
+}
+
+// GetClientIPConfig returns the proxies believed about the client IP
+func (c *Config) GetClientIPConfig() middleware.ClientIPConfig {
+	var proxies []string
+	for _, proxy := range strings.Split(c.TrustedProxies, ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return middleware.ClientIPConfig{TrustedProxies: proxies, Header: c.TrustedProxyHeader}
+}
+
 // GetRateLimitEnabled returns whether rate limiting is enabled
 func (c *Config) GetRateLimitEnabled() bool {
 	return c.RateLimitEnabled
@@ -112,3 +294,36 @@ func (c *Config) GetRateLimitPerAPIKey() int {
 func (c *Config) GetRateLimitCleanupTime() int {
 	return c.RateLimitCleanupTime
 }
+
+// GetRateLimitAlgorithm returns the name of the rate limiting algorithm
+func (c *Config) GetRateLimitAlgorithm() string {
+	return c.RateLimitAlgorithm
+}
+
+// GetRateLimitBurst returns the token bucket burst
+func (c *Config) GetRateLimitBurst() int {
+	return c.RateLimitBurst
+}
+
+// GetRateLimitPolicies returns the rate limit policies of the policy file, or nil for the built-in policies
+func (c *Config) GetRateLimitPolicies() (*middleware.RateLimitPolicies, error) {
+	if c.RateLimitPolicyFile == "" {
+		return nil, nil
+	}
+	return middleware.LoadRateLimitPolicies(c.RateLimitPolicyFile)
+}
+
+// GetWriteTimeout returns the server write timeout in seconds
+func (c *Config) GetWriteTimeout() int {
+	return c.WriteTimeout
+}
+
+// GetAPIVersion returns the default API version
+func (c *Config) GetAPIVersion() int {
+	return c.APIVersion
+}
+
+// GetEventHeartbeat returns the time between keep-alive comments on idle event streams in seconds
+func (c *Config) GetEventHeartbeat() int {
+	return c.EventHeartbeatSeconds
+}
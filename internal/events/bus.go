@@ -0,0 +1,213 @@
+// Package events provides the in-process bus that carries task change events from the
+// storage to the clients of the change feed
+package events
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"task-api/internal/interfaces"
+	"task-api/internal/models"
+	"time"
+)
+
+// Default bus limits
+const (
+	DefaultReplaySize       = 1000 // Recent events kept for resuming subscribers
+	DefaultSubscriberBuffer = 256  // Events queued per subscriber before it is dropped
+)
+
+// BusConfig defines the limits of an event bus
+type BusConfig struct {
+	ReplaySize       int // Number of recent events kept for resuming subscribers
+	SubscriberBuffer int // Number of undelivered events a subscriber may fall behind by
+}
+
+// Bus fans task change events out to subscribers
+// Every published event gets an ID of the form "<epoch>-<sequence>", where the epoch is
+// unique to the bus. The most recent events are kept in a bounded ring buffer so a
+// subscriber that reconnects with the ID of the last event it saw receives what it missed.
+// Publishing never blocks: a subscriber whose buffer is full is dropped instead.
+type Bus struct {
+	epoch       string                     // Prefix of the IDs of this bus's events
+	sequence    uint64                     // Sequence number of the last published event
+	replay      []models.TaskEvent         // Ring buffer of the most recent events
+	head        int                        // Index of the oldest event in replay once it is full
+	bufferSize  int                        // Channel capacity of each subscription
+	subscribers map[*Subscription]struct{} // Active subscriptions
+	closed      bool                       // Whether Close has been called
+	mutex       sync.Mutex                 // Protects every field above
+}
+
+// Ensure Bus implements required interfaces at compile time
+var (
+	_ interfaces.EventPublisher = (*Bus)(nil)
+)
+
+// NewBus creates an event bus (Factory Pattern)
+func NewBus(config BusConfig) *Bus {
+	if config.ReplaySize <= 0 {
+		config.ReplaySize = DefaultReplaySize
+	}
+	if config.SubscriberBuffer <= 0 {
+		config.SubscriberBuffer = DefaultSubscriberBuffer
+	}
+
+	return &Bus{
+		epoch:       strconv.FormatInt(time.Now().UnixNano(), 36),
+		replay:      make([]models.TaskEvent, 0, config.ReplaySize),
+		bufferSize:  config.SubscriberBuffer,
+		subscribers: make(map[*Subscription]struct{}),
+	}
+}
+
+// Publish assigns the event its ID, remembers it for replay and delivers it to every subscriber
+func (b *Bus) Publish(event models.TaskEvent) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.closed {
+		return
+	}
+
+	b.sequence++
+	event.ID = b.lastID()
+
+	if len(b.replay) < cap(b.replay) {
+		b.replay = append(b.replay, event)
+	} else {
+		b.replay[b.head] = event
+		b.head = (b.head + 1) % len(b.replay)
+	}
+
+	for sub := range b.subscribers {
+		select {
+		case sub.events <- event:
+		default:
+			// The subscriber fell too far behind; it can resume from its last event ID
+			b.unsubscribe(sub)
+		}
+	}
+}
+
+// Subscribe registers a subscriber resuming after lastEventID ("" = only new events)
+// It returns the buffered events published after lastEventID, followed on the subscription by
+// every later event. complete is false when lastEventID is unknown to the bus or older than
+// the replay buffer, in which case events were missed and nothing is replayed.
+func (b *Bus) Subscribe(lastEventID string) (sub *Subscription, replay []models.TaskEvent, complete bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	sub = &Subscription{
+		bus:    b,
+		start:  b.lastID(),
+		events: make(chan models.TaskEvent, b.bufferSize),
+		done:   make(chan struct{}),
+	}
+	if b.closed {
+		close(sub.done)
+		return sub, nil, true
+	}
+	b.subscribers[sub] = struct{}{}
+
+	if lastEventID == "" {
+		return sub, nil, true
+	}
+
+	sequence, ok := b.parseID(lastEventID)
+	oldest := b.sequence - uint64(len(b.replay)) + 1
+	if !ok || sequence > b.sequence || sequence+1 < oldest {
+		return sub, nil, false
+	}
+
+	// Events after the last one seen, oldest first
+	missed := int(b.sequence - sequence)
+	replay = make([]models.TaskEvent, 0, missed)
+	for i := len(b.replay) - missed; i < len(b.replay); i++ {
+		replay = append(replay, b.replay[(b.head+i)%len(b.replay)])
+	}
+	return sub, replay, true
+}
+
+// lastID returns the ID of the last published event, or "" if there is none
+// Must be called with the bus lock held.
+func (b *Bus) lastID() string {
+	if b.sequence == 0 {
+		return ""
+	}
+	return b.epoch + "-" + strconv.FormatUint(b.sequence, 10)
+}
+
+// parseID returns the sequence number of an event ID issued by this bus
+func (b *Bus) parseID(id string) (uint64, bool) {
+	epoch, sequence, found := strings.Cut(id, "-")
+	if !found || epoch != b.epoch {
+		return 0, false
+	}
+
+	value, err := strconv.ParseUint(sequence, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return value, true
+}
+
+// Subscribers returns the number of active subscriptions
+func (b *Bus) Subscribers() int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return len(b.subscribers)
+}
+
+// Close ends every subscription; events published afterwards are discarded
+func (b *Bus) Close() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.closed = true
+	for sub := range b.subscribers {
+		b.unsubscribe(sub)
+	}
+}
+
+// unsubscribe ends a subscription; must be called with the bus lock held
+func (b *Bus) unsubscribe(sub *Subscription) {
+	if _, ok := b.subscribers[sub]; ok {
+		delete(b.subscribers, sub)
+		close(sub.done)
+	}
+}
+
+// Subscription is one subscriber's view of the bus
+type Subscription struct {
+	bus    *Bus                  // Bus the subscription belongs to
+	start  string                // ID of the last event published before the subscription started
+	events chan models.TaskEvent // Events published since the subscription started
+	done   chan struct{}         // Closed when the subscription ends
+}
+
+// Start returns the ID of the last event published before the subscription started
+// ("" if there was none); a client that resumes from it misses nothing delivered here.
+func (s *Subscription) Start() string {
+	return s.start
+}
+
+// Events returns the channel delivering published events
+func (s *Subscription) Events() <-chan models.TaskEvent {
+	return s.events
+}
+
+// Done returns a channel closed when the subscription ends, because it was closed, the
+// subscriber fell behind or the bus was closed
+func (s *Subscription) Done() <-chan struct{} {
+	return s.done
+}
+
+// Close ends the subscription
+func (s *Subscription) Close() {
+	s.bus.mutex.Lock()
+	defer s.bus.mutex.Unlock()
+
+	s.bus.unsubscribe(s)
+}
@@ -0,0 +1,149 @@
+package events
+
+import (
+	"task-api/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// publishTasks publishes an update event for each task ID
+func publishTasks(bus *Bus, ids ...string) {
+	for _, id := range ids {
+		bus.Publish(models.NewTaskEvent(&models.Task{ID: id, Version: 1}, &models.Task{ID: id, Version: 2}, "tester"))
+	}
+}
+
+// taskIDs lists the task IDs of events, in order
+func taskIDs(events []models.TaskEvent) []string {
+	ids := make([]string, len(events))
+	for i, event := range events {
+		ids[i] = event.TaskID
+	}
+	return ids
+}
+
+// receive reads the events already delivered to a subscription
+func receive(sub *Subscription) []models.TaskEvent {
+	var events []models.TaskEvent
+	for {
+		select {
+		case event := <-sub.Events():
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
+func TestBus_PublishSubscribe(t *testing.T) {
+	bus := NewBus(BusConfig{})
+	publishTasks(bus, "before")
+
+	sub, replay, complete := bus.Subscribe("")
+	defer sub.Close()
+	assert.True(t, complete)
+	assert.Empty(t, replay)
+
+	publishTasks(bus, "a", "b")
+	events := receive(sub)
+	assert.Equal(t, []string{"a", "b"}, taskIDs(events))
+	assert.NotEqual(t, events[0].ID, events[1].ID)
+	assert.Equal(t, sub.Start(), bus.epoch+"-1")
+
+	sub.Close()
+	assert.Equal(t, 0, bus.Subscribers())
+	sub.Close()
+}
+
+func TestBus_Resume(t *testing.T) {
+	bus := NewBus(BusConfig{ReplaySize: 3})
+
+	first, _, _ := bus.Subscribe("")
+	publishTasks(bus, "1", "2", "3", "4")
+	events := receive(first)
+	first.Close()
+
+	t.Run("replays events after the last one seen", func(t *testing.T) {
+		sub, replay, complete := bus.Subscribe(events[1].ID)
+		defer sub.Close()
+		assert.True(t, complete)
+		assert.Equal(t, []string{"3", "4"}, taskIDs(replay))
+		assert.Equal(t, events[2:], replay)
+	})
+
+	t.Run("up to date", func(t *testing.T) {
+		sub, replay, complete := bus.Subscribe(events[3].ID)
+		defer sub.Close()
+		assert.True(t, complete)
+		assert.Empty(t, replay)
+	})
+
+	t.Run("oldest buffered event is still complete", func(t *testing.T) {
+		sub, replay, complete := bus.Subscribe(events[0].ID)
+		defer sub.Close()
+		assert.True(t, complete)
+		assert.Equal(t, []string{"2", "3", "4"}, taskIDs(replay))
+	})
+
+	incomplete := []struct {
+		name string
+		id   string
+	}{
+		{"evicted event", bus.epoch + "-0"},
+		{"earlier process", "zzz-2"},
+		{"future event", bus.epoch + "-99"},
+		{"malformed ID", "not-an-id"},
+		{"no sequence", bus.epoch},
+	}
+	for _, tt := range incomplete {
+		t.Run("incomplete after "+tt.name, func(t *testing.T) {
+			sub, replay, complete := bus.Subscribe(tt.id)
+			defer sub.Close()
+			assert.False(t, complete)
+			assert.Empty(t, replay)
+			assert.Equal(t, events[3].ID, sub.Start())
+		})
+	}
+}
+
+func TestBus_DropsSlowSubscribers(t *testing.T) {
+	bus := NewBus(BusConfig{SubscriberBuffer: 2})
+
+	slow, _, _ := bus.Subscribe("")
+	fast, _, _ := bus.Subscribe("")
+	defer fast.Close()
+
+	publishTasks(bus, "1", "2")
+	assert.Len(t, receive(fast), 2)
+
+	// The third event does not fit the slow subscriber's buffer
+	publishTasks(bus, "3")
+	select {
+	case <-slow.Done():
+	default:
+		t.Fatal("slow subscriber should have been dropped")
+	}
+	assert.Equal(t, []string{"1", "2"}, taskIDs(receive(slow)))
+	assert.Equal(t, []string{"3"}, taskIDs(receive(fast)))
+	assert.Equal(t, 1, bus.Subscribers())
+	slow.Close()
+}
+
+func TestBus_Close(t *testing.T) {
+	bus := NewBus(BusConfig{})
+	sub, _, _ := bus.Subscribe("")
+
+	bus.Close()
+	<-sub.Done()
+	assert.Equal(t, 0, bus.Subscribers())
+
+	publishTasks(bus, "ignored")
+	assert.Empty(t, receive(sub))
+
+	late, _, complete := bus.Subscribe("")
+	require.True(t, complete)
+	<-late.Done()
+	late.Close()
+}
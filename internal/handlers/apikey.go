@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"task-api/internal/apikeys"
+	"task-api/internal/middleware"
+	"task-api/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyHandler handles HTTP requests for API key management
+type APIKeyHandler struct {
+	keys *apikeys.Store // Issued keys (nil = API keys not configured)
+}
+
+// NewAPIKeyHandler creates a new APIKeyHandler instance (Factory Pattern)
+// A nil store makes every API key endpoint respond 501 Not Implemented.
+func NewAPIKeyHandler(keys *apikeys.Store) *APIKeyHandler {
+	return &APIKeyHandler{
+		keys: keys,
+	}
+}
+
+// store returns the key registry, answering 501 when API keys are not configured
+func (h *APIKeyHandler) store(c *gin.Context) (*apikeys.Store, bool) {
+	if h.keys == nil {
+		respondError(c, http.StatusNotImplemented, "API keys are not configured", nil)
+		return nil, false
+	}
+	return h.keys, true
+}
+
+// ListAPIKeys handles GET /apikeys - list API keys
+// @Summary List API keys
+// @Description Get every issued API key, oldest first, revoked ones included. Keys themselves are never listed.
+// @Tags apikeys
+// @Produce json
+// @Success 200 {object} models.APIKeyListResponse
+// @Failure 501 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /apikeys [get]
+func (h *APIKeyHandler) ListAPIKeys(c *gin.Context) {
+	store, ok := h.store(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewAPIKeyListResponse(store.List()))
+}
+
+// IssueAPIKey handles POST /apikeys - issue an API key
+// @Summary Issue an API key
+// @Description Issue a key acting as "subject" (default: the caller) with the listed scopes: tasks:read,
+// @Description tasks:write or admin. Send it in the X-API-Key header. The response is the only one that
+// @Description includes the key; only its hash is stored.
+// @Tags apikeys
+// @Accept json
+// @Produce json
+// @Param apikey body models.IssueAPIKeyRequest true "API key data"
+// @Success 201 {object} models.APIKeyResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Failure 501 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /apikeys [post]
+func (h *APIKeyHandler) IssueAPIKey(c *gin.Context) {
+	store, ok := h.store(c)
+	if !ok {
+		return
+	}
+
+	var req models.IssueAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request data", err)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		respondError(c, http.StatusBadRequest, "Validation failed", err)
+		return
+	}
+
+	// Keys act as the caller unless an owner is named
+	caller := middleware.GetSubject(c)
+	if req.Subject == "" {
+		req.Subject = caller
+	}
+	if req.Subject == "" {
+		respondError(c, http.StatusBadRequest, "Validation failed", errors.New("subject is required when the caller is not authenticated"))
+		return
+	}
+
+	key, err := store.Issue(&req, caller)
+	if err != nil {
+		respondStorageError(c, err, "Failed to issue API key")
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.NewAPIKeyResponse(key, "API key issued successfully"))
+}
+
+// GetAPIKey handles GET /apikeys/:id - get an API key
+// @Summary Get an API key
+// @Description Get an API key by its ID, with its scopes, expiry and last use but without the key itself
+// @Tags apikeys
+// @Produce json
+// @Param id path string true "API key ID"
+// @Success 200 {object} models.APIKeyResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 501 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /apikeys/{id} [get]
+func (h *APIKeyHandler) GetAPIKey(c *gin.Context) {
+	store, ok := h.store(c)
+	if !ok {
+		return
+	}
+
+	key, err := store.Get(c.Param("id"))
+	if err != nil {
+		respondStorageError(c, err, "Failed to retrieve API key")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewAPIKeyResponse(key, "API key retrieved successfully"))
+}
+
+// RevokeAPIKey handles DELETE /apikeys/:id - revoke an API key
+// @Summary Revoke an API key
+// @Description Reject the key from now on. Revoked keys stay listed with their revocation time.
+// @Tags apikeys
+// @Produce json
+// @Param id path string true "API key ID"
+// @Success 200 {object} models.APIKeyResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Failure 501 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /apikeys/{id} [delete]
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	store, ok := h.store(c)
+	if !ok {
+		return
+	}
+
+	key, err := store.Revoke(c.Param("id"))
+	if err != nil {
+		respondStorageError(c, err, "Failed to revoke API key")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewAPIKeyResponse(key, "API key revoked successfully"))
+}
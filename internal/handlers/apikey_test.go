@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"task-api/internal/apikeys"
+	"task-api/internal/middleware"
+	"task-api/internal/models"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupAPIKeyRouter serves the API key endpoints and a task list authenticated by the issued keys
+// It also returns the headers of an admin key acting as root.
+func setupAPIKeyRouter(t *testing.T) (*gin.Engine, *apikeys.Store, map[string]string) {
+	t.Helper()
+
+	store, err := apikeys.NewStore("")
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.APIKeyAuth(store))
+	registerAPIKeyRoutes(router, NewAPIKeyHandler(store))
+
+	admin, err := store.Issue(&models.IssueAPIKeyRequest{Name: "root", Subject: "root", Scopes: []string{models.ScopeAdmin}}, "")
+	require.NoError(t, err)
+	return router, store, map[string]string{middleware.APIKeyHeader: admin.Key}
+}
+
+// registerAPIKeyRoutes mounts the API key endpoints as the API does
+func registerAPIKeyRoutes(router *gin.Engine, handler *APIKeyHandler) {
+	keys := router.Group("/apikeys", middleware.RequireAdmin())
+	keys.GET("", handler.ListAPIKeys)
+	keys.POST("", handler.IssueAPIKey)
+	keys.GET("/:id", handler.GetAPIKey)
+	keys.DELETE("/:id", handler.RevokeAPIKey)
+}
+
+// decodeAPIKeyResponse decodes a single API key response body
+func decodeAPIKeyResponse(t *testing.T, body []byte) *models.APIKey {
+	t.Helper()
+
+	var response models.APIKeyResponse
+	require.NoError(t, json.Unmarshal(body, &response))
+	require.NotNil(t, response.Data)
+	return response.Data
+}
+
+func TestAPIKeyHandler_Lifecycle(t *testing.T) {
+	router, _, root := setupAPIKeyRouter(t)
+
+	expiresAt := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+	w := sendWithHeaders(router, "POST", "/apikeys", models.IssueAPIKeyRequest{
+		Name:      "nightly export",
+		Subject:   "alice",
+		Scopes:    []string{models.ScopeTasksRead},
+		ExpiresAt: &expiresAt,
+	}, root)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	issued := decodeAPIKeyResponse(t, w.Body.Bytes())
+	assert.NotEmpty(t, issued.Key)
+	assert.Equal(t, "alice", issued.Subject)
+	assert.True(t, expiresAt.Equal(*issued.ExpiresAt))
+
+	t.Run("the key is never returned again", func(t *testing.T) {
+		w := sendWithHeaders(router, "GET", "/apikeys/"+issued.ID, nil, root)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), issued.Key)
+
+		w = sendWithHeaders(router, "GET", "/apikeys", nil, root)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), issued.Key)
+		assert.Contains(t, w.Body.String(), issued.Prefix)
+	})
+
+	t.Run("non-admin keys cannot manage keys", func(t *testing.T) {
+		w := sendWithHeaders(router, "GET", "/apikeys", nil, map[string]string{middleware.APIKeyHeader: issued.Key})
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("anonymous callers cannot manage keys", func(t *testing.T) {
+		w := sendWithHeaders(router, "POST", "/apikeys", models.IssueAPIKeyRequest{
+			Name: "x", Subject: "eve", Scopes: []string{models.ScopeAdmin},
+		}, nil)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("last use is tracked", func(t *testing.T) {
+		w := sendWithHeaders(router, "GET", "/apikeys/"+issued.ID, nil, root)
+		assert.NotNil(t, decodeAPIKeyResponse(t, w.Body.Bytes()).LastUsedAt)
+	})
+
+	t.Run("revoke", func(t *testing.T) {
+		w := sendWithHeaders(router, "DELETE", "/apikeys/"+issued.ID, nil, root)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.NotNil(t, decodeAPIKeyResponse(t, w.Body.Bytes()).RevokedAt)
+
+		w = sendWithHeaders(router, "GET", "/apikeys", nil, map[string]string{middleware.APIKeyHeader: issued.Key})
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+		w = sendWithHeaders(router, "DELETE", "/apikeys/missing", nil, root)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestAPIKeyHandler_Issue(t *testing.T) {
+	router, _, root := setupAPIKeyRouter(t)
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name    string
+		request models.IssueAPIKeyRequest
+		headers map[string]string
+		status  int
+		subject string
+	}{
+		{"defaults to the caller", models.IssueAPIKeyRequest{Name: "ci", Scopes: []string{models.ScopeTasksWrite}},
+			root, http.StatusCreated, "root"},
+		{"anonymous callers are rejected", models.IssueAPIKeyRequest{Name: "ci", Subject: "alice", Scopes: []string{models.ScopeTasksWrite}},
+			nil, http.StatusUnauthorized, ""},
+		{"unknown scope", models.IssueAPIKeyRequest{Name: "ci", Subject: "alice", Scopes: []string{"tasks:delete"}},
+			root, http.StatusBadRequest, ""},
+		{"no scopes", models.IssueAPIKeyRequest{Name: "ci", Subject: "alice", Scopes: []string{}},
+			root, http.StatusBadRequest, ""},
+		{"expiry in the past", models.IssueAPIKeyRequest{Name: "ci", Subject: "alice", Scopes: []string{models.ScopeTasksRead}, ExpiresAt: &past},
+			root, http.StatusBadRequest, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := sendWithHeaders(router, "POST", "/apikeys", tt.request, tt.headers)
+			require.Equal(t, tt.status, w.Code, w.Body.String())
+			if tt.status == http.StatusCreated {
+				issued := decodeAPIKeyResponse(t, w.Body.Bytes())
+				assert.Equal(t, tt.subject, issued.Subject)
+				assert.Equal(t, "root", issued.CreatedBy)
+			}
+		})
+	}
+}
+
+func TestAPIKeyHandler_NotConfigured(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/apikeys", NewAPIKeyHandler(nil).ListAPIKeys)
+
+	w := sendWithHeaders(router, "GET", "/apikeys", nil, nil)
+	assert.Equal(t, http.StatusNotImplemented, w.Code)
+}
@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"task-api/internal/interfaces"
+	"task-api/internal/models"
+	"task-api/internal/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BulkTasks handles POST /tasks/bulk - apply many create, update and delete operations at once
+// @Summary Bulk task operations
+// @Description Apply create, update and delete operations in order. In atomic mode (default) either every
+// @Description operation is applied or none is; in best_effort mode every operation that succeeds is applied.
+// @Description Each result carries the status the operation would have had on its own; operations skipped
+// @Description because another one failed report 424. The response is 200 when every operation was applied, 207 otherwise.
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Param request body models.BulkRequest true "Bulk operations"
+// @Success 200 {object} models.BulkResponse
+// @Success 207 {object} models.BulkResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Failure 501 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /tasks/bulk [post]
+func (h *TaskHandler) BulkTasks(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req models.BulkRequest
+
+	// Bind JSON request to struct with validation
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request data", err)
+		return
+	}
+
+	// Check the shape of every operation; their contents are validated per operation
+	if err := req.Validate(); err != nil {
+		respondError(c, http.StatusBadRequest, "Validation failed", err)
+		return
+	}
+
+	if _, ok := h.storage.(interfaces.BatchWriter); !ok && req.Mode == models.BulkAtomic {
+		respondError(c, http.StatusNotImplemented, "Atomic bulk operations are not supported by this storage", nil)
+		return
+	}
+
+	// Operations on tasks of other owners fail like operations on missing tasks
+	denied, err := h.authorizeBatch(ctx, req.Operations)
+	if err != nil {
+		respondStorageError(c, err, "Failed to apply bulk operations")
+		return
+	}
+
+	results, err := h.applyPermitted(ctx, req.Operations, req.Mode, denied)
+	if err != nil {
+		respondStorageError(c, err, "Failed to apply bulk operations")
+		return
+	}
+
+	response := models.NewBulkResponse(req.Mode, bulkOperationResults(req.Operations, results))
+	status := http.StatusOK
+	if !response.Success {
+		status = http.StatusMultiStatus
+	}
+	c.JSON(status, response)
+}
+
+// applyBatch applies operations through the storage's batch support, or one by one for
+// storages without it (best-effort mode only)
+func (h *TaskHandler) applyBatch(ctx context.Context, ops []models.BulkOperation, mode models.BulkMode) ([]models.BatchResult, error) {
+	if writer, ok := h.storage.(interfaces.BatchWriter); ok {
+		return writer.ApplyBatch(ctx, ops, mode)
+	}
+	return h.applyOneByOne(ctx, ops)
+}
+
+// applyPermitted applies the operations the caller may run; denied operations fail with their error
+// In atomic mode a denied operation aborts the batch exactly like an operation the storage rejects.
+func (h *TaskHandler) applyPermitted(ctx context.Context, ops []models.BulkOperation, mode models.BulkMode, denied map[int]error) ([]models.BatchResult, error) {
+	if len(denied) == 0 {
+		return h.applyBatch(ctx, ops, mode)
+	}
+
+	results := make([]models.BatchResult, len(ops))
+	if mode == models.BulkAtomic {
+		first := len(ops)
+		for i := range denied {
+			if i < first {
+				first = i
+			}
+		}
+		for i := range results {
+			results[i].Err = fmt.Errorf("%w: operation %d failed", storage.ErrBatchAborted, first)
+		}
+		results[first].Err = denied[first]
+		return results, nil
+	}
+
+	permitted := make([]models.BulkOperation, 0, len(ops)-len(denied))
+	indexes := make([]int, 0, len(ops)-len(denied))
+	for i, op := range ops {
+		if err, ok := denied[i]; ok {
+			results[i].Err = err
+			continue
+		}
+		permitted = append(permitted, op)
+		indexes = append(indexes, i)
+	}
+	if len(permitted) == 0 {
+		return results, nil
+	}
+
+	applied, err := h.applyBatch(ctx, permitted, mode)
+	if err != nil {
+		return nil, err
+	}
+	for j, result := range applied {
+		results[indexes[j]] = result
+	}
+	return results, nil
+}
+
+// applyOneByOne applies best-effort operations through the plain storage methods
+// Used for storages without batch support; a cancelled request stops before the next operation.
+func (h *TaskHandler) applyOneByOne(ctx context.Context, ops []models.BulkOperation) ([]models.BatchResult, error) {
+	results := make([]models.BatchResult, len(ops))
+	for i, op := range ops {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		switch op.Op {
+		case models.BulkCreate:
+			results[i].Task, results[i].Err = h.storage.Create(ctx, op.Task)
+		case models.BulkUpdate:
+			results[i].Task, results[i].Err = h.updateTask(ctx, op.ID, op.Version, op.Changes)
+		case models.BulkDelete:
+			results[i].Err = h.deleteTask(ctx, op.ID, op.Version)
+		}
+	}
+	return results, nil
+}
+
+// bulkOperationResults turns storage results into response entries with per-operation statuses
+func bulkOperationResults(ops []models.BulkOperation, results []models.BatchResult) []models.BulkOperationResult {
+	entries := make([]models.BulkOperationResult, len(ops))
+	for i, op := range ops {
+		entry := models.BulkOperationResult{Index: i, Op: op.Op, ID: op.ID}
+		result := results[i]
+
+		switch {
+		case result.Err != nil:
+			entry.Status, _ = errorStatus(result.Err)
+			entry.Error = result.Err.Error()
+		case op.Op == models.BulkCreate:
+			entry.Status = http.StatusCreated
+		case op.Op == models.BulkDelete:
+			entry.Status = http.StatusNoContent
+		default:
+			entry.Status = http.StatusOK
+		}
+
+		if result.Task != nil {
+			entry.ID = result.Task.ID
+			entry.Data = result.Task
+		}
+		entries[i] = entry
+	}
+	return entries
+}
@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"task-api/internal/models"
+	"task-api/internal/storage"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// decodeBulkResponse decodes a bulk response body
+func decodeBulkResponse(t *testing.T, body []byte) models.BulkResponse {
+	t.Helper()
+
+	var response models.BulkResponse
+	require.NoError(t, json.Unmarshal(body, &response))
+	return response
+}
+
+// resultStatuses lists the per-operation statuses of a bulk response
+func resultStatuses(response models.BulkResponse) []int {
+	statuses := make([]int, len(response.Results))
+	for i, result := range response.Results {
+		statuses[i] = result.Status
+	}
+	return statuses
+}
+
+func TestTaskHandler_BulkTasks(t *testing.T) {
+	handler, router := setupTestHandler()
+	existing := createTestTask(t, handler, "Existing", models.TaskIncomplete)
+	doomed := createTestTask(t, handler, "Doomed", models.TaskIncomplete)
+
+	t.Run("all operations succeed", func(t *testing.T) {
+		w := sendWithHeaders(router, "POST", "/api/v1/tasks/bulk", models.BulkRequest{
+			Operations: []models.BulkOperation{
+				{Op: models.BulkCreate, Task: &models.CreateTaskRequest{Name: "Imported"}},
+				{Op: models.BulkUpdate, ID: existing.ID, Changes: &models.UpdateTaskRequest{Name: stringPtr("Renamed")}},
+				{Op: models.BulkDelete, ID: doomed.ID},
+			},
+		}, nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		response := decodeBulkResponse(t, w.Body.Bytes())
+		assert.True(t, response.Success)
+		assert.Equal(t, models.BulkAtomic, response.Mode)
+		assert.Equal(t, 3, response.Succeeded)
+		assert.Equal(t, []int{http.StatusCreated, http.StatusOK, http.StatusNoContent}, resultStatuses(response))
+		require.NotNil(t, response.Results[0].Data)
+		assert.Equal(t, response.Results[0].Data.ID, response.Results[0].ID)
+		assert.Equal(t, "Renamed", response.Results[1].Data.Name)
+		assert.Equal(t, doomed.ID, response.Results[2].ID)
+	})
+
+	t.Run("atomic failure applies nothing", func(t *testing.T) {
+		w := sendWithHeaders(router, "POST", "/api/v1/tasks/bulk", models.BulkRequest{
+			Mode: models.BulkAtomic,
+			Operations: []models.BulkOperation{
+				{Op: models.BulkCreate, Task: &models.CreateTaskRequest{Name: "Never stored"}},
+				{Op: models.BulkUpdate, ID: existing.ID, Version: 1, Changes: &models.UpdateTaskRequest{Name: stringPtr("Stale")}},
+			},
+		}, nil)
+		require.Equal(t, http.StatusMultiStatus, w.Code, w.Body.String())
+
+		response := decodeBulkResponse(t, w.Body.Bytes())
+		assert.False(t, response.Success)
+		assert.Equal(t, 2, response.Failed)
+		assert.Equal(t, []int{http.StatusFailedDependency, http.StatusPreconditionFailed}, resultStatuses(response))
+		assert.Nil(t, response.Results[0].Data)
+
+		count, err := handler.storage.Count(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+	})
+
+	t.Run("best effort applies what it can", func(t *testing.T) {
+		w := sendWithHeaders(router, "POST", "/api/v1/tasks/bulk", models.BulkRequest{
+			Mode: models.BulkBestEffort,
+			Operations: []models.BulkOperation{
+				{Op: models.BulkCreate, Task: &models.CreateTaskRequest{Name: "Kept"}},
+				{Op: models.BulkDelete, ID: "non-existent-id"},
+				{Op: models.BulkUpdate, ID: existing.ID, Changes: &models.UpdateTaskRequest{}},
+			},
+		}, nil)
+		require.Equal(t, http.StatusMultiStatus, w.Code, w.Body.String())
+
+		response := decodeBulkResponse(t, w.Body.Bytes())
+		assert.Equal(t, 1, response.Succeeded)
+		assert.Equal(t, 2, response.Failed)
+		assert.Equal(t, []int{http.StatusCreated, http.StatusNotFound, http.StatusUnprocessableEntity}, resultStatuses(response))
+		assert.NotEmpty(t, response.Results[1].Error)
+	})
+
+	t.Run("invalid tasks fail like single requests", func(t *testing.T) {
+		invalidTask := &models.CreateTaskRequest{Name: "Tagged", Tags: []string{"has space"}}
+		single := sendWithHeaders(router, "POST", "/api/v1/tasks", invalidTask, nil)
+		assert.Equal(t, http.StatusUnprocessableEntity, single.Code)
+
+		w := sendWithHeaders(router, "POST", "/api/v1/tasks/bulk", models.BulkRequest{
+			Mode:       models.BulkBestEffort,
+			Operations: []models.BulkOperation{{Op: models.BulkCreate, Task: invalidTask}},
+		}, nil)
+		require.Equal(t, http.StatusMultiStatus, w.Code, w.Body.String())
+		assert.Equal(t, []int{single.Code}, resultStatuses(decodeBulkResponse(t, w.Body.Bytes())))
+	})
+
+	invalid := []struct {
+		name string
+		body interface{}
+	}{
+		{"no operations", models.BulkRequest{Operations: []models.BulkOperation{}}},
+		{"unknown mode", models.BulkRequest{Mode: "eventually", Operations: []models.BulkOperation{{Op: models.BulkDelete, ID: "x"}}}},
+		{"unknown operation", models.BulkRequest{Operations: []models.BulkOperation{{Op: "upsert", ID: "x"}}}},
+		{"create without task", models.BulkRequest{Operations: []models.BulkOperation{{Op: models.BulkCreate}}}},
+		{"update without changes", models.BulkRequest{Operations: []models.BulkOperation{{Op: models.BulkUpdate, ID: "x"}}}},
+		{"malformed body", "not an object"},
+	}
+	for _, tt := range invalid {
+		t.Run("rejects "+tt.name, func(t *testing.T) {
+			w := sendWithHeaders(router, "POST", "/api/v1/tasks/bulk", tt.body, nil)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+
+	t.Run("rejects too many operations", func(t *testing.T) {
+		ops := make([]models.BulkOperation, models.MaxBulkOperations+1)
+		for i := range ops {
+			ops[i] = models.BulkOperation{Op: models.BulkDelete, ID: "x"}
+		}
+		w := sendWithHeaders(router, "POST", "/api/v1/tasks/bulk", models.BulkRequest{Operations: ops}, nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestTaskHandler_BulkTasksFallback(t *testing.T) {
+	handler := NewTaskHandler(bareStorage{storage.NewMemoryStorage(100)})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/tasks/bulk", handler.BulkTasks)
+
+	task := createTestTask(t, handler, "Plain", models.TaskIncomplete)
+	ops := []models.BulkOperation{
+		{Op: models.BulkCreate, Task: &models.CreateTaskRequest{Name: "Created"}},
+		{Op: models.BulkUpdate, ID: task.ID, Version: 2, Changes: &models.UpdateTaskRequest{Name: stringPtr("Stale")}},
+		{Op: models.BulkDelete, ID: task.ID},
+	}
+
+	w := sendWithHeaders(router, "POST", "/tasks/bulk", models.BulkRequest{Operations: ops}, nil)
+	assert.Equal(t, http.StatusNotImplemented, w.Code)
+
+	w = sendWithHeaders(router, "POST", "/tasks/bulk", models.BulkRequest{Mode: models.BulkBestEffort, Operations: ops}, nil)
+	require.Equal(t, http.StatusMultiStatus, w.Code, w.Body.String())
+	response := decodeBulkResponse(t, w.Body.Bytes())
+	assert.Equal(t, []int{http.StatusCreated, http.StatusPreconditionFailed, http.StatusNoContent}, resultStatuses(response))
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"task-api/internal/apikeys"
+	"task-api/internal/models"
+	"task-api/internal/storage"
+	"task-api/internal/webhooks"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StatusClientClosedRequest is the non-standard status logged when the client disconnects
+// before the response is written (nginx convention)
+const StatusClientClosedRequest = 499
+
+// errorMapping maps a sentinel error to the status and message returned to the client
+type errorMapping struct {
+	target  error  // Sentinel matched with errors.Is
+	status  int    // HTTP status code
+	message string // Client-facing message
+}
+
+// errorMappings is the central table translating storage, webhook, API key and context errors to HTTP responses
+var errorMappings = []errorMapping{
+	{storage.ErrNotFound, http.StatusNotFound, "Task not found"},
+	{models.ErrInvalidTransition, http.StatusConflict, "Status transition not allowed by workflow"},
+	{models.ErrPatchTestFailed, http.StatusConflict, "Patch test failed"},
+	{models.ErrInvalidPatch, http.StatusUnprocessableEntity, "Patch cannot be applied"},
+	{storage.ErrValidation, http.StatusUnprocessableEntity, "Validation failed"},
+	{storage.ErrQuotaExceeded, http.StatusInsufficientStorage, "Task limit reached"},
+	{storage.ErrConflict, http.StatusConflict, "Conflict with current task state"},
+	{storage.ErrPreconditionFailed, http.StatusPreconditionFailed, "Task has been modified"},
+	{storage.ErrBatchAborted, http.StatusFailedDependency, "Not applied because another operation failed"},
+	{webhooks.ErrNotFound, http.StatusNotFound, "Webhook not found"},
+	{webhooks.ErrDeliveryNotFound, http.StatusNotFound, "Delivery not found"},
+	{webhooks.ErrNotRetryable, http.StatusConflict, "Only dead deliveries can be retried"},
+	{webhooks.ErrClosed, http.StatusServiceUnavailable, "Webhook deliveries are shutting down"},
+	{apikeys.ErrNotFound, http.StatusNotFound, "API key not found"},
+	{context.DeadlineExceeded, http.StatusGatewayTimeout, "Request timed out"},
+	{context.Canceled, StatusClientClosedRequest, "Request cancelled"},
+}
+
+// respondStorageError writes the mapped response for err, or a 500 with message when err is unknown
+func respondStorageError(c *gin.Context, err error, message string) {
+	status, mapped := errorStatus(err)
+	if mapped != "" {
+		message = mapped
+	}
+	respondError(c, status, message, err)
+}
+
+// respondValidationError writes the response of a task request the models rejected, the same
+// as when the storage rejects it (single or bulk)
+func respondValidationError(c *gin.Context, err error) {
+	respondStorageError(c, fmt.Errorf("%w: %w", storage.ErrValidation, err), "Validation failed")
+}
+
+// errorStatus returns the mapped status and message for err, or a 500 and no message when err is unknown
+func errorStatus(err error) (int, string) {
+	for _, mapping := range errorMappings {
+		if errors.Is(err, mapping.target) {
+			return mapping.status, mapping.message
+		}
+	}
+	return http.StatusInternalServerError, ""
+}
+
+// respondError writes an error response, using problem+json when the client asks for it
+func respondError(c *gin.Context, status int, message string, err error) {
+	if acceptsProblemJSON(c.Request) {
+		// Set before rendering so gin keeps it instead of application/json
+		c.Header("Content-Type", models.ProblemContentType)
+		c.JSON(status, models.NewProblemDetails(status, message, err, c.Request.URL.RequestURI()))
+		return
+	}
+
+	c.JSON(status, models.NewErrorResponse(message, err))
+}
+
+// acceptsProblemJSON reports whether the Accept header lists application/problem+json
+func acceptsProblemJSON(req *http.Request) bool {
+	for _, accept := range req.Header.Values("Accept") {
+		for _, mediaRange := range strings.Split(accept, ",") {
+			mediaType, _, _ := strings.Cut(mediaRange, ";")
+			if strings.EqualFold(strings.TrimSpace(mediaType), models.ProblemContentType) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
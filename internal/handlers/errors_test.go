@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"task-api/internal/models"
+	"task-api/internal/storage"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRespondStorageError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name            string
+		err             error
+		expectedStatus  int
+		expectedMessage string
+	}{
+		{"not found", fmt.Errorf("task with ID x %w", storage.ErrNotFound), http.StatusNotFound, "Task not found"},
+		{"validation", fmt.Errorf("%w: name is required", storage.ErrValidation), http.StatusUnprocessableEntity, "Validation failed"},
+		{"quota exceeded", fmt.Errorf("%w (10)", storage.ErrQuotaExceeded), http.StatusInsufficientStorage, "Task limit reached"},
+		{"conflict", fmt.Errorf("%w: duplicate", storage.ErrConflict), http.StatusConflict, "Conflict with current task state"},
+		{"deadline", fmt.Errorf("failed to query tasks: %w", context.DeadlineExceeded), http.StatusGatewayTimeout, "Request timed out"},
+		{"cancelled", context.Canceled, StatusClientClosedRequest, "Request cancelled"},
+		{"unknown", errors.New("disk on fire"), http.StatusInternalServerError, "Failed to do thing"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/api/v1/tasks", nil)
+
+			respondStorageError(c, tt.err, "Failed to do thing")
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+
+			var response models.ErrorResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.False(t, response.Success)
+			assert.Equal(t, tt.expectedMessage, response.Message)
+			assert.Equal(t, tt.err.Error(), response.Error)
+		})
+	}
+}
+
+func TestRespondError_ProblemJSON(t *testing.T) {
+	handler, router := setupTestHandler()
+	createTestTask(t, handler, "Task 1", models.TaskIncomplete)
+
+	tests := []struct {
+		name    string
+		accept  string
+		problem bool
+	}{
+		{"no accept header", "", false},
+		{"plain json", "application/json", false},
+		{"problem json", "application/problem+json", true},
+		{"problem json among others", "application/json;q=0.9, Application/Problem+JSON", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/api/v1/tasks/missing?verbose=1", nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusNotFound, w.Code)
+
+			if !tt.problem {
+				var response models.ErrorResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, "Task not found", response.Message)
+				return
+			}
+
+			assert.Equal(t, models.ProblemContentType, w.Header().Get("Content-Type"))
+
+			var problem models.ProblemDetails
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+			assert.Equal(t, "about:blank", problem.Type)
+			assert.Equal(t, "Task not found", problem.Title)
+			assert.Equal(t, http.StatusNotFound, problem.Status)
+			assert.Contains(t, problem.Detail, "not found")
+			assert.Equal(t, "/api/v1/tasks/missing?verbose=1", problem.Instance)
+		})
+	}
+}
+
+func TestTaskHandler_QuotaExceeded(t *testing.T) {
+	handler := NewTaskHandler(storage.NewMemoryStorage(1))
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/api/v1/tasks", handler.CreateTask)
+
+	createTestTask(t, handler, "Only task", models.TaskIncomplete)
+
+	body, _ := json.Marshal(models.CreateTaskRequest{Name: "One too many", Status: models.TaskIncomplete})
+	req, _ := http.NewRequest("POST", "/api/v1/tasks", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInsufficientStorage, w.Code)
+
+	var response models.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "Task limit reached", response.Message)
+	assert.Contains(t, response.Error, "maximum tasks limit reached")
+}
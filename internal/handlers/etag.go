@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"task-api/internal/interfaces"
+	"task-api/internal/models"
+	"task-api/internal/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// taskETag returns the strong entity tag of a task, derived from its version
+// Every change increments the version, so equal tags always mean identical task state.
+func taskETag(task *models.Task) string {
+	return `"` + strconv.Itoa(task.Version) + `"`
+}
+
+// setTaskETag sets the ETag response header for a task
+func setTaskETag(c *gin.Context, task *models.Task) {
+	c.Header("ETag", taskETag(task))
+}
+
+// entityTag is one entry of an If-Match or If-None-Match header
+type entityTag struct {
+	opaque string // Quoted tag value, e.g. "3"
+	weak   bool   // Whether the tag carried the W/ prefix
+}
+
+// parseEntityTags splits a conditional header into its entity tags
+// any is true when the header is "*". Malformed entries are skipped: they can never match.
+func parseEntityTags(header string) (tags []entityTag, any bool) {
+	for _, part := range strings.Split(header, ",") {
+		part = strings.TrimSpace(part)
+		if part == "*" {
+			return nil, true
+		}
+
+		tag := entityTag{}
+		if strings.HasPrefix(part, "W/") {
+			tag.weak = true
+			part = part[2:]
+		}
+		if len(part) < 2 || part[0] != '"' || part[len(part)-1] != '"' {
+			continue
+		}
+		tag.opaque = part
+		tags = append(tags, tag)
+	}
+	return tags, false
+}
+
+// ifMatchVersions returns the task versions a strong If-Match comparison can match
+// Weak tags never match under strong comparison (RFC 9110 section 13.1.1).
+func ifMatchVersions(tags []entityTag) []int {
+	var versions []int
+	for _, tag := range tags {
+		if tag.weak {
+			continue
+		}
+		version, err := strconv.Atoi(strings.Trim(tag.opaque, `"`))
+		if err == nil && version > 0 {
+			versions = append(versions, version)
+		}
+	}
+	return versions
+}
+
+// notModified reports whether If-None-Match matches the task, using weak comparison
+func notModified(c *gin.Context, task *models.Task) bool {
+	header := c.GetHeader("If-None-Match")
+	if header == "" {
+		return false
+	}
+
+	tags, any := parseEntityTags(header)
+	if any {
+		return true
+	}
+
+	current := taskETag(task)
+	for _, tag := range tags {
+		if tag.opaque == current {
+			return true
+		}
+	}
+	return false
+}
+
+// expectedVersion resolves the If-Match header into the version a conditional write must find
+// Returns 0 when the write is unconditional ("*" or no header; the write itself checks
+// existence). A list naming several versions is resolved against the current task, and the
+// write stays conditional on the resolved version so a concurrent change still fails it.
+func (h *TaskHandler) expectedVersion(ctx context.Context, c *gin.Context, id string) (int, error) {
+	header := c.GetHeader("If-Match")
+	if header == "" {
+		return 0, nil
+	}
+
+	tags, any := parseEntityTags(header)
+	if any {
+		return 0, nil
+	}
+
+	versions := ifMatchVersions(tags)
+	if len(versions) == 1 {
+		return versions[0], nil
+	}
+
+	task, err := h.storage.GetByID(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+	for _, version := range versions {
+		if version == task.Version {
+			return version, nil
+		}
+	}
+
+	return 0, fmt.Errorf("%w: If-Match does not match current ETag %s", storage.ErrPreconditionFailed, taskETag(task))
+}
+
+// updateTask applies an update, conditional on the task version when version is positive
+// Storages without compare-and-swap fall back to a check-then-write that is not atomic.
+func (h *TaskHandler) updateTask(ctx context.Context, id string, version int, req *models.UpdateTaskRequest) (*models.Task, error) {
+	if version == 0 {
+		return h.storage.Update(ctx, id, req)
+	}
+
+	if writer, ok := h.storage.(interfaces.ConditionalWriter); ok {
+		return writer.UpdateIfVersion(ctx, id, version, req)
+	}
+
+	if err := h.checkVersion(ctx, id, version); err != nil {
+		return nil, err
+	}
+	return h.storage.Update(ctx, id, req)
+}
+
+// deleteTask removes a task, conditional on the task version when version is positive
+// Storages without compare-and-swap fall back to a check-then-delete that is not atomic.
+func (h *TaskHandler) deleteTask(ctx context.Context, id string, version int) error {
+	if version == 0 {
+		return h.storage.Delete(ctx, id)
+	}
+
+	if writer, ok := h.storage.(interfaces.ConditionalWriter); ok {
+		return writer.DeleteIfVersion(ctx, id, version)
+	}
+
+	if err := h.checkVersion(ctx, id, version); err != nil {
+		return err
+	}
+	return h.storage.Delete(ctx, id)
+}
+
+// checkVersion fails with ErrPreconditionFailed unless the task is at the given version
+func (h *TaskHandler) checkVersion(ctx context.Context, id string, version int) error {
+	task, err := h.storage.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if task.Version != version {
+		return fmt.Errorf("%w: If-Match does not match current ETag %s", storage.ErrPreconditionFailed, taskETag(task))
+	}
+	return nil
+}
+
+// respondNotModified writes a 304 carrying the current ETag
+func respondNotModified(c *gin.Context, task *models.Task) {
+	setTaskETag(c, task)
+	c.Status(http.StatusNotModified)
+}
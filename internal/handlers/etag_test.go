@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"task-api/internal/models"
+	"task-api/internal/storage"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sendWithHeaders performs a request with optional JSON body and extra headers
+func sendWithHeaders(router *gin.Engine, method, path string, body interface{}, headers map[string]string) *httptest.ResponseRecorder {
+	var reader *bytes.Buffer
+	if body != nil {
+		data, _ := json.Marshal(body)
+		reader = bytes.NewBuffer(data)
+	} else {
+		reader = bytes.NewBuffer(nil)
+	}
+
+	req, _ := http.NewRequest(method, path, reader)
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestTaskHandler_ETag(t *testing.T) {
+	handler, router := setupTestHandler()
+	task := createTestTask(t, handler, "Tagged", models.TaskIncomplete)
+	path := "/api/v1/tasks/" + task.ID
+
+	w := sendWithHeaders(router, "GET", path, nil, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	etag := w.Header().Get("ETag")
+	assert.Equal(t, `"1"`, etag)
+
+	tests := []struct {
+		name           string
+		ifNoneMatch    string
+		expectedStatus int
+	}{
+		{"matching tag", etag, http.StatusNotModified},
+		{"weak form of matching tag", "W/" + etag, http.StatusNotModified},
+		{"tag in list", `"7", ` + etag, http.StatusNotModified},
+		{"wildcard", "*", http.StatusNotModified},
+		{"stale tag", `"7"`, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run("If-None-Match "+tt.name, func(t *testing.T) {
+			w := sendWithHeaders(router, "GET", path, nil, map[string]string{"If-None-Match": tt.ifNoneMatch})
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, etag, w.Header().Get("ETag"))
+			if tt.expectedStatus == http.StatusNotModified {
+				assert.Empty(t, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestTaskHandler_IfMatch(t *testing.T) {
+	handler, router := setupTestHandler()
+	task := createTestTask(t, handler, "Guarded", models.TaskIncomplete)
+	path := "/api/v1/tasks/" + task.ID
+	update := models.UpdateTaskRequest{Name: stringPtr("Changed")}
+
+	t.Run("stale If-Match on PUT", func(t *testing.T) {
+		w := sendWithHeaders(router, "PUT", path, update, map[string]string{"If-Match": `"5"`})
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+
+		current, err := handler.storage.GetByID(context.Background(), task.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Guarded", current.Name)
+	})
+
+	t.Run("weak If-Match never matches", func(t *testing.T) {
+		w := sendWithHeaders(router, "PUT", path, update, map[string]string{"If-Match": `W/"1"`})
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	})
+
+	t.Run("current If-Match on PUT", func(t *testing.T) {
+		w := sendWithHeaders(router, "PUT", path, update, map[string]string{"If-Match": `"1"`})
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+	})
+
+	t.Run("lost update is rejected", func(t *testing.T) {
+		// A second client still holding version 1 must not overwrite version 2
+		w := sendWithHeaders(router, "PUT", path, models.UpdateTaskRequest{Name: stringPtr("Clobber")},
+			map[string]string{"If-Match": `"1"`})
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	})
+
+	t.Run("list with current tag", func(t *testing.T) {
+		w := sendWithHeaders(router, "PUT", path, models.UpdateTaskRequest{Name: stringPtr("Again")},
+			map[string]string{"If-Match": `"1", "2"`})
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+	})
+
+	t.Run("wildcard on missing task", func(t *testing.T) {
+		w := sendWithHeaders(router, "PUT", "/api/v1/tasks/missing", update, map[string]string{"If-Match": "*"})
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("stale If-Match on DELETE", func(t *testing.T) {
+		w := sendWithHeaders(router, "DELETE", path, nil, map[string]string{"If-Match": `"1"`})
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	})
+
+	t.Run("current If-Match on DELETE", func(t *testing.T) {
+		w := sendWithHeaders(router, "DELETE", path, nil, map[string]string{"If-Match": `"3"`})
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
+
+func TestTaskHandler_IfMatchFallback(t *testing.T) {
+	memStorage := storage.NewMemoryStorage(100)
+	handler := NewTaskHandler(bareStorage{memStorage})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.PUT("/tasks/:id", handler.UpdateTask)
+
+	task := createTestTask(t, handler, "Plain", models.TaskIncomplete)
+	update := models.UpdateTaskRequest{Name: stringPtr("Changed")}
+
+	w := sendWithHeaders(router, "PUT", "/tasks/"+task.ID, update, map[string]string{"If-Match": `"2"`})
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+
+	w = sendWithHeaders(router, "PUT", "/tasks/"+task.ID, update, map[string]string{"If-Match": `"1"`})
+	assert.Equal(t, http.StatusOK, w.Code)
+}
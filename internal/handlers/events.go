@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"task-api/internal/events"
+	"task-api/internal/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultHeartbeatInterval is the time between keep-alive comments on an idle event stream
+const DefaultHeartbeatInterval = 15 * time.Second
+
+// EventHandler streams task change events to clients as Server-Sent Events
+type EventHandler struct {
+	bus       *events.Bus   // Source of change events (nil if the storage does not report changes)
+	heartbeat time.Duration // Time between keep-alive comments
+}
+
+// NewEventHandler creates a new EventHandler instance (Factory Pattern)
+// A nil bus makes the stream endpoint respond 501 Not Implemented.
+func NewEventHandler(bus *events.Bus, heartbeat time.Duration) *EventHandler {
+	if heartbeat <= 0 {
+		heartbeat = DefaultHeartbeatInterval
+	}
+
+	return &EventHandler{
+		bus:       bus,
+		heartbeat: heartbeat,
+	}
+}
+
+// StreamEvents handles GET /tasks/events - stream task changes as Server-Sent Events
+// @Summary Stream task changes
+// @Description Keep the connection open and receive an event for every task created, updated or deleted,
+// @Description named after the change and carrying a models.TaskEvent as data. A reconnecting client sends the
+// @Description ID of the last event it received (Last-Event-ID header or last_event_id parameter) and gets the
+// @Description events it missed; when they are no longer buffered a "reset" event tells it to reload its tasks.
+// @Description Idle streams carry a comment line every 15 seconds. Clients that cannot keep up are disconnected.
+// @Tags tasks
+// @Produce text/event-stream
+// @Param Last-Event-ID header string false "ID of the last event received, to resume after it"
+// @Param last_event_id query string false "Same as the Last-Event-ID header, for clients that cannot set it"
+// @Success 200 {object} models.TaskEvent "Stream of task events"
+// @Failure 501 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /tasks/events [get]
+func (h *EventHandler) StreamEvents(c *gin.Context) {
+	if h.bus == nil {
+		respondError(c, http.StatusNotImplemented, "Change feed not supported by the storage backend", nil)
+		return
+	}
+
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+
+	// Callers restricted to their own tasks only hear about those
+	owned := &models.TaskFilter{OwnerID: ownerScope(c.Request.Context())}
+
+	sub, replay, complete := h.bus.Subscribe(lastEventID)
+	defer sub.Close()
+
+	// The stream outlives the server's write timeout; writers without deadlines are fine too
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+
+	header := c.Writer.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("X-Accel-Buffering", "no") // Keep reverse proxies from buffering the stream
+	c.Status(http.StatusOK)
+
+	if !complete {
+		// Resuming from the subscription start after a reload misses nothing
+		reset := models.TaskEvent{ID: sub.Start(), Type: models.TaskEventsReset, Time: time.Now().UTC()}
+		if err := writeEvent(c.Writer, reset); err != nil {
+			return
+		}
+	}
+	for _, event := range replay {
+		if !event.Concerns(owned) {
+			continue
+		}
+		if err := writeEvent(c.Writer, event); err != nil {
+			return
+		}
+	}
+	c.Writer.Flush()
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+
+	ctx := c.Request.Context()
+	for {
+		var err error
+		select {
+		case <-ctx.Done():
+			return
+		case <-sub.Done():
+			// Closed by the bus: the client fell behind or the server is shutting down
+			return
+		case event := <-sub.Events():
+			if !event.Concerns(owned) {
+				continue
+			}
+			err = writeEvent(c.Writer, event)
+		case <-ticker.C:
+			_, err = io.WriteString(c.Writer, ": heartbeat\n\n")
+		}
+		if err != nil {
+			return
+		}
+		c.Writer.Flush()
+	}
+}
+
+// writeEvent writes one event in the text/event-stream format
+func writeEvent(w io.Writer, event models.TaskEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	return err
+}
@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"task-api/internal/events"
+	"task-api/internal/models"
+	"task-api/internal/storage"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sseFrame is one frame of an event stream
+type sseFrame struct {
+	id      string
+	event   string
+	data    string
+	comment string
+}
+
+// setupEventServer starts a server streaming the changes of a memory storage
+func setupEventServer(t *testing.T, heartbeat time.Duration) (*storage.MemoryStorage, *events.Bus, *httptest.Server) {
+	t.Helper()
+
+	bus := events.NewBus(events.BusConfig{})
+	memory := storage.NewMemoryStorage(100)
+	memory.SetEventPublisher(bus)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/tasks/events", NewEventHandler(bus, heartbeat).StreamEvents)
+
+	server := httptest.NewServer(router)
+	t.Cleanup(func() {
+		bus.Close()
+		server.Close()
+	})
+	return memory, bus, server
+}
+
+// openStream connects to the event stream, resuming after lastEventID if set
+func openStream(t *testing.T, server *httptest.Server, lastEventID string) *bufio.Reader {
+	t.Helper()
+
+	req, err := http.NewRequest("GET", server.URL+"/tasks/events", nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept", "text/event-stream")
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "no-cache", resp.Header.Get("Cache-Control"))
+	return bufio.NewReader(resp.Body)
+}
+
+// readFrame reads the next frame of an event stream
+func readFrame(t *testing.T, reader *bufio.Reader) sseFrame {
+	t.Helper()
+
+	var frame sseFrame
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			return frame
+		}
+
+		field, value, _ := strings.Cut(line, ": ")
+		switch field {
+		case "id":
+			frame.id = value
+		case "event":
+			frame.event = value
+		case "data":
+			frame.data = value
+		case "":
+			frame.comment = value
+		}
+	}
+}
+
+// readEvent reads the next frame of an event stream and decodes its event
+func readEvent(t *testing.T, reader *bufio.Reader) (sseFrame, models.TaskEvent) {
+	t.Helper()
+
+	frame := readFrame(t, reader)
+	var event models.TaskEvent
+	require.NoError(t, json.Unmarshal([]byte(frame.data), &event))
+	assert.Equal(t, frame.id, event.ID)
+	assert.Equal(t, frame.event, string(event.Type))
+	return frame, event
+}
+
+// waitForSubscribers waits until the bus has the given number of subscribers
+func waitForSubscribers(t *testing.T, bus *events.Bus, count int) {
+	t.Helper()
+
+	require.Eventually(t, func() bool { return bus.Subscribers() == count }, time.Second, 5*time.Millisecond)
+}
+
+func TestEventHandler_StreamEvents(t *testing.T) {
+	memory, bus, server := setupEventServer(t, time.Hour)
+	ctx := context.Background()
+
+	stream := openStream(t, server, "")
+	waitForSubscribers(t, bus, 1)
+
+	task, err := memory.Create(ctx, &models.CreateTaskRequest{Name: "Live"})
+	require.NoError(t, err)
+	_, err = memory.Update(ctx, task.ID, &models.UpdateTaskRequest{Name: stringPtr("Renamed")})
+	require.NoError(t, err)
+	require.NoError(t, memory.Delete(ctx, task.ID))
+
+	created, createdEvent := readEvent(t, stream)
+	assert.Equal(t, "created", created.event)
+	assert.Equal(t, "Live", createdEvent.Task.Name)
+
+	updated, updatedEvent := readEvent(t, stream)
+	assert.Equal(t, "updated", updated.event)
+	assert.Equal(t, 2, updatedEvent.Version)
+
+	deleted, deletedEvent := readEvent(t, stream)
+	assert.Equal(t, "deleted", deleted.event)
+	assert.Equal(t, task.ID, deletedEvent.TaskID)
+	assert.Nil(t, deletedEvent.Task)
+
+	t.Run("resumes after the last event", func(t *testing.T) {
+		resumed := openStream(t, server, created.id)
+		frame, _ := readEvent(t, resumed)
+		assert.Equal(t, updated.id, frame.id)
+		frame, _ = readEvent(t, resumed)
+		assert.Equal(t, deleted.id, frame.id)
+	})
+
+	t.Run("resets unknown positions", func(t *testing.T) {
+		reset, event := readEvent(t, openStream(t, server, "unknown-1"))
+		assert.Equal(t, "reset", reset.event)
+		assert.Equal(t, deleted.id, event.ID)
+	})
+}
+
+func TestEventHandler_Heartbeat(t *testing.T) {
+	_, _, server := setupEventServer(t, 10*time.Millisecond)
+
+	frame := readFrame(t, openStream(t, server, ""))
+	assert.Equal(t, "heartbeat", frame.comment)
+	assert.Empty(t, frame.data)
+}
+
+func TestEventHandler_Disconnects(t *testing.T) {
+	_, bus, server := setupEventServer(t, time.Hour)
+
+	stream := openStream(t, server, "")
+	waitForSubscribers(t, bus, 1)
+
+	// Closing the bus ends the stream and releases the subscription
+	bus.Close()
+	_, err := stream.ReadString('\n')
+	assert.Error(t, err)
+	waitForSubscribers(t, bus, 0)
+}
+
+func TestEventHandler_NotSupported(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/tasks/events", NewEventHandler(nil, 0).StreamEvents)
+
+	w := sendWithHeaders(router, "GET", "/tasks/events", nil, nil)
+	assert.Equal(t, http.StatusNotImplemented, w.Code)
+}
+
+func TestEventHandler_Ownership(t *testing.T) {
+	bus := events.NewBus(events.BusConfig{})
+	memory := storage.NewMemoryStorage(100)
+	memory.SetEventPublisher(bus)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		principal := &models.Principal{Subject: "alice"}
+		c.Request = c.Request.WithContext(models.WithPrincipal(c.Request.Context(), principal))
+	})
+	router.GET("/tasks/events", NewEventHandler(bus, time.Hour).StreamEvents)
+
+	server := httptest.NewServer(router)
+	t.Cleanup(func() {
+		bus.Close()
+		server.Close()
+	})
+	ctx := context.Background()
+
+	stream := openStream(t, server, "")
+	waitForSubscribers(t, bus, 1)
+
+	_, err := memory.Create(ctx, &models.CreateTaskRequest{Name: "Anchor", OwnerID: "alice"})
+	require.NoError(t, err)
+	_, err = memory.Create(ctx, &models.CreateTaskRequest{Name: "Theirs", OwnerID: "bob"})
+	require.NoError(t, err)
+	_, err = memory.Create(ctx, &models.CreateTaskRequest{Name: "Mine", OwnerID: "alice"})
+	require.NoError(t, err)
+
+	anchor, _ := readEvent(t, stream)
+	_, event := readEvent(t, stream)
+	assert.Equal(t, "Mine", event.Task.Name)
+
+	t.Run("replay skips other owners", func(t *testing.T) {
+		_, event := readEvent(t, openStream(t, server, anchor.id))
+		assert.Equal(t, "Mine", event.Task.Name)
+	})
+}
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"task-api/internal/interfaces"
+	"task-api/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// historyProvider returns the storage's history capability, answering 501 when it has none
+func (h *TaskHandler) historyProvider(c *gin.Context) (interfaces.HistoryProvider, bool) {
+	historian, ok := h.storage.(interfaces.HistoryProvider)
+	if !ok {
+		respondError(c, http.StatusNotImplemented, "Task history is not supported by this storage", nil)
+	}
+	return historian, ok
+}
+
+// parseVersionParam reads the :version path parameter as a positive integer
+func parseVersionParam(c *gin.Context) (int, error) {
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil || version <= 0 {
+		return 0, fmt.Errorf("version must be a positive integer, got %q", c.Param("version"))
+	}
+	return version, nil
+}
+
+// GetTaskHistory handles GET /tasks/:id/history - list every revision of a task
+// @Summary Get task history
+// @Description Get every stored revision of a task (who changed what and when), oldest first
+// @Tags history
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Success 200 {object} models.TaskHistoryResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Failure 501 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /tasks/{id}/history [get]
+func (h *TaskHandler) GetTaskHistory(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	historian, ok := h.historyProvider(c)
+	if !ok {
+		return
+	}
+
+	if err := h.checkAccess(ctx, c.Param("id")); err != nil {
+		respondStorageError(c, err, "Failed to retrieve task history")
+		return
+	}
+
+	revisions, err := historian.GetHistory(ctx, c.Param("id"))
+	if err != nil {
+		respondStorageError(c, err, "Failed to retrieve task history")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewTaskHistoryResponse(revisions))
+}
+
+// GetTaskVersion handles GET /tasks/:id/versions/:version - get a task as of one version
+// @Summary Get a task version
+// @Description Get the revision that produced the given version of a task, including the full task state
+// @Tags history
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param version path int true "Task version"
+// @Success 200 {object} models.TaskRevisionResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Failure 501 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /tasks/{id}/versions/{version} [get]
+func (h *TaskHandler) GetTaskVersion(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	version, err := parseVersionParam(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid version parameter", err)
+		return
+	}
+
+	historian, ok := h.historyProvider(c)
+	if !ok {
+		return
+	}
+
+	if err := h.checkAccess(ctx, c.Param("id")); err != nil {
+		respondStorageError(c, err, "Failed to retrieve task version")
+		return
+	}
+
+	revision, err := historian.GetVersion(ctx, c.Param("id"), version)
+	if err != nil {
+		respondStorageError(c, err, "Failed to retrieve task version")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewTaskRevisionResponse(revision))
+}
+
+// RevertTask handles POST /tasks/:id/versions/:version/revert - restore a task from an earlier version
+// @Summary Revert a task
+// @Description Restore a task's fields from an earlier version; the result is recorded as a new version.
+// @Description The status change must be allowed by the workflow.
+// @Tags history
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param version path int true "Version to restore"
+// @Success 200 {object} models.TaskResponse
+// @Header 200 {string} ETag "Strong entity tag of the new task version"
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Failure 501 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /tasks/{id}/versions/{version}/revert [post]
+func (h *TaskHandler) RevertTask(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	version, err := parseVersionParam(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid version parameter", err)
+		return
+	}
+
+	historian, ok := h.historyProvider(c)
+	if !ok {
+		return
+	}
+
+	if err := h.checkAccess(ctx, c.Param("id")); err != nil {
+		respondStorageError(c, err, "Failed to revert task")
+		return
+	}
+
+	task, err := historian.Revert(ctx, c.Param("id"), version)
+	if err != nil {
+		respondStorageError(c, err, "Failed to revert task")
+		return
+	}
+
+	setTaskETag(c, task)
+	response := models.NewTaskResponse(task, fmt.Sprintf("Task reverted to version %d", version))
+	c.JSON(http.StatusOK, response)
+}
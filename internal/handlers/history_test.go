@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"task-api/internal/interfaces"
+	"task-api/internal/middleware"
+	"task-api/internal/models"
+	"task-api/internal/storage"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskHandler_History(t *testing.T) {
+	handler, router := setupTestHandler()
+	task := createTestTask(t, handler, "Original", models.TaskIncomplete)
+
+	body, _ := json.Marshal(models.UpdateTaskRequest{Name: stringPtr("Renamed")})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/api/v1/tasks/"+task.ID, bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	t.Run("list history", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/tasks/"+task.ID+"/history", nil)
+		router.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var response models.TaskHistoryResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, 2, response.Count)
+		assert.Equal(t, models.RevisionUpdate, response.Data[1].Action)
+		assert.Equal(t, "name", response.Data[1].Changes[0].Field)
+		assert.Equal(t, "Original", response.Data[1].Changes[0].From)
+		assert.Equal(t, "Renamed", response.Data[1].Changes[0].To)
+	})
+
+	t.Run("get version", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/tasks/"+task.ID+"/versions/1", nil)
+		router.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var response models.TaskRevisionResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, 1, response.Data.Version)
+		assert.Equal(t, "Original", response.Data.Task.Name)
+	})
+
+	t.Run("revert", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/tasks/"+task.ID+"/versions/1/revert", nil)
+		router.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var response models.TaskResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "Original", response.Data.Name)
+		assert.Equal(t, 3, response.Data.Version)
+	})
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		expectedStatus int
+	}{
+		{"unknown task history", "GET", "/api/v1/tasks/missing/history", http.StatusNotFound},
+		{"unknown version", "GET", "/api/v1/tasks/" + task.ID + "/versions/42", http.StatusNotFound},
+		{"invalid version", "GET", "/api/v1/tasks/" + task.ID + "/versions/abc", http.StatusBadRequest},
+		{"zero version", "GET", "/api/v1/tasks/" + task.ID + "/versions/0", http.StatusBadRequest},
+		{"revert unknown version", "POST", "/api/v1/tasks/" + task.ID + "/versions/42/revert", http.StatusNotFound},
+		{"revert unknown task", "POST", "/api/v1/tasks/missing/versions/1/revert", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(tt.method, tt.path, nil)
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestTaskHandler_HistoryActor(t *testing.T) {
+	handler := NewTaskHandler(storage.NewMemoryStorage(100))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.Actor())
+	router.POST("/tasks", handler.CreateTask)
+	router.GET("/tasks/:id/history", handler.GetTaskHistory)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/tasks", bytes.NewBufferString(`{"name":"Audited"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(middleware.ActorHeader, "alice")
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var created models.TaskResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/tasks/"+created.Data.ID+"/history", nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var history models.TaskHistoryResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &history))
+	require.Len(t, history.Data, 1)
+	assert.Equal(t, "alice", history.Data[0].Actor)
+}
+
+// bareStorage hides every optional capability of the wrapped storage
+type bareStorage struct {
+	interfaces.TaskStorage
+}
+
+func TestTaskHandler_HistoryNotSupported(t *testing.T) {
+	handler := NewTaskHandler(bareStorage{storage.NewMemoryStorage(100)})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/tasks/:id/history", handler.GetTaskHistory)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/tasks/any/history", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotImplemented, w.Code)
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"task-api/internal/models"
+	"task-api/internal/storage"
+)
+
+// ownerScope returns the owner the caller's task listings are restricted to, or "" when the
+// caller sees every task (authentication disabled, the admin claim or the admin role)
+func ownerScope(ctx context.Context) string {
+	return models.PrincipalFromContext(ctx).OwnerScope()
+}
+
+// hiddenTaskError reports a task of another owner exactly like a missing one, so callers
+// cannot probe which task IDs exist
+func hiddenTaskError(id string) error {
+	return fmt.Errorf("task with ID %s %w", id, storage.ErrNotFound)
+}
+
+// accessibleTask retrieves a task the caller may see
+func (h *TaskHandler) accessibleTask(ctx context.Context, id string) (*models.Task, error) {
+	task, err := h.storage.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !models.PrincipalFromContext(ctx).CanAccess(task) {
+		return nil, hiddenTaskError(id)
+	}
+	return task, nil
+}
+
+// checkAccess fails with ErrNotFound unless the caller may change the task
+// Owners never change, so the check stays valid for the write that follows it. Callers
+// that see every task skip the lookup and leave missing tasks to the write itself.
+func (h *TaskHandler) checkAccess(ctx context.Context, id string) error {
+	if ownerScope(ctx) == "" {
+		return nil
+	}
+	_, err := h.accessibleTask(ctx, id)
+	return err
+}
+
+// authorizeBatch records the caller as the owner of the tasks a batch creates and checks
+// that its updates and deletes only target the caller's tasks
+// Returns the error of every operation on a task of another owner, by operation index.
+func (h *TaskHandler) authorizeBatch(ctx context.Context, ops []models.BulkOperation) (map[int]error, error) {
+	principal := models.PrincipalFromContext(ctx)
+	if principal == nil {
+		return nil, nil
+	}
+
+	denied := make(map[int]error)
+	for i := range ops {
+		op := &ops[i]
+		if op.Op == models.BulkCreate {
+			op.Task.OwnerID = principal.Subject
+			continue
+		}
+		if principal.Admin {
+			continue
+		}
+
+		// Missing tasks are reported by the storage when the operation is applied
+		task, err := h.storage.GetByID(ctx, op.ID)
+		if err != nil && !errors.Is(err, storage.ErrNotFound) {
+			return nil, err
+		}
+		if task != nil && !principal.CanAccess(task) {
+			denied[i] = hiddenTaskError(op.ID)
+		}
+	}
+	return denied, nil
+}
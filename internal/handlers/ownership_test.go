@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"task-api/internal/auth"
+	"task-api/internal/middleware"
+	"task-api/internal/models"
+	"task-api/internal/storage"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ownershipSecret signs the test tokens
+const ownershipSecret = "0123456789abcdef0123456789abcdef"
+
+// setupOwnershipHandler creates a handler whose routes require a bearer token
+func setupOwnershipHandler(t *testing.T) (*TaskHandler, *gin.Engine) {
+	t.Helper()
+
+	keys := auth.NewKeySet()
+	require.NoError(t, keys.AddHMAC("", []byte(ownershipSecret)))
+
+	handler := NewTaskHandler(storage.NewMemoryStorage(100))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	api := router.Group("/api/v1", middleware.Authenticate(auth.NewVerifier(keys, auth.VerifierConfig{})))
+	{
+		api.GET("/stats", handler.GetStorageStats)
+		api.GET("/tasks", handler.GetAllTasks)
+		api.GET("/tasks/:id", handler.GetTaskByID)
+		api.POST("/tasks", handler.CreateTask)
+		api.PUT("/tasks/:id", handler.UpdateTask)
+		api.PATCH("/tasks/:id", handler.PatchTask)
+		api.DELETE("/tasks/:id", handler.DeleteTask)
+		api.GET("/tasks/status/:status", handler.GetTasksByStatus)
+		api.GET("/tasks/paginated", handler.GetTasksPaginated)
+		api.GET("/tasks/search", handler.SearchTasks)
+		api.POST("/tasks/bulk", handler.BulkTasks)
+		api.GET("/tasks/:id/history", handler.GetTaskHistory)
+		api.POST("/tasks/:id/versions/:version/revert", handler.RevertTask)
+	}
+	return handler, router
+}
+
+// bearer returns the Authorization header of a token for subject
+func bearer(t *testing.T, subject string, admin bool) map[string]string {
+	t.Helper()
+
+	token, err := auth.SignHS256(map[string]interface{}{
+		"sub":   subject,
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"admin": admin,
+	}, "", []byte(ownershipSecret))
+	require.NoError(t, err)
+	return map[string]string{"Authorization": "Bearer " + token}
+}
+
+// createOwnedTask creates a task through the API as the caller identified by headers
+func createOwnedTask(t *testing.T, router *gin.Engine, headers map[string]string, name string) *models.Task {
+	t.Helper()
+
+	w := sendWithHeaders(router, "POST", "/api/v1/tasks", models.CreateTaskRequest{Name: name}, headers)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	var response models.TaskResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	return response.Data
+}
+
+// listedNames returns the names of the tasks in a list response
+func listedNames(t *testing.T, body []byte) []string {
+	t.Helper()
+
+	var response models.TaskListResponse
+	require.NoError(t, json.Unmarshal(body, &response))
+	names := make([]string, len(response.Data))
+	for i, task := range response.Data {
+		names[i] = task.Name
+	}
+	return names
+}
+
+func TestTaskHandler_Ownership(t *testing.T) {
+	handler, router := setupOwnershipHandler(t)
+	alice := bearer(t, "alice", false)
+	bob := bearer(t, "bob", false)
+	admin := bearer(t, "root", true)
+
+	mine := createOwnedTask(t, router, alice, "Alice's task")
+	theirs := createOwnedTask(t, router, bob, "Bob's task")
+	assert.Equal(t, "alice", mine.OwnerID)
+	assert.Equal(t, "bob", theirs.OwnerID)
+
+	t.Run("requires a token", func(t *testing.T) {
+		w := sendWithHeaders(router, "GET", "/api/v1/tasks", nil, nil)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("owner cannot be set by the client", func(t *testing.T) {
+		w := sendWithHeaders(router, "POST", "/api/v1/tasks", map[string]interface{}{"name": "Forged", "owner_id": "bob"}, alice)
+		require.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), `"owner_id":"alice"`)
+
+		var response models.TaskResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.Equal(t, http.StatusOK, sendWithHeaders(router, "DELETE", "/api/v1/tasks/"+response.Data.ID, nil, alice).Code)
+	})
+
+	t.Run("listings only show the caller's tasks", func(t *testing.T) {
+		for _, path := range []string{
+			"/api/v1/tasks",
+			"/api/v1/tasks/status/incomplete",
+			"/api/v1/tasks/paginated",
+			"/api/v1/tasks/paginated?page=1&page_size=10",
+		} {
+			w := sendWithHeaders(router, "GET", path, nil, alice)
+			require.Equal(t, http.StatusOK, w.Code, path)
+			assert.Equal(t, []string{"Alice's task"}, listedNames(t, w.Body.Bytes()), path)
+		}
+
+		w := sendWithHeaders(router, "GET", "/api/v1/tasks/search?q=task", nil, alice)
+		require.Equal(t, http.StatusOK, w.Code)
+		response := decodeSearchResponse(t, w.Body.Bytes())
+		require.Len(t, response.Data, 1)
+		assert.Equal(t, mine.ID, response.Data[0].Task.ID)
+	})
+
+	t.Run("other owners' tasks look missing", func(t *testing.T) {
+		requests := []struct {
+			method string
+			path   string
+			body   interface{}
+		}{
+			{"GET", "/api/v1/tasks/" + theirs.ID, nil},
+			{"PUT", "/api/v1/tasks/" + theirs.ID, models.UpdateTaskRequest{Name: stringPtr("Stolen")}},
+			{"PATCH", "/api/v1/tasks/" + theirs.ID, map[string]interface{}{"name": "Stolen"}},
+			{"DELETE", "/api/v1/tasks/" + theirs.ID, nil},
+			{"GET", "/api/v1/tasks/" + theirs.ID + "/history", nil},
+			{"POST", "/api/v1/tasks/" + theirs.ID + "/versions/1/revert", nil},
+		}
+		for _, req := range requests {
+			headers := map[string]string{"Authorization": alice["Authorization"]}
+			if req.method == "PATCH" {
+				headers["Content-Type"] = models.MergePatchContentType
+			}
+			w := sendWithHeaders(router, req.method, req.path, req.body, headers)
+			assert.Equal(t, http.StatusNotFound, w.Code, "%s %s", req.method, req.path)
+		}
+
+		task, err := handler.storage.GetByID(t.Context(), theirs.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Bob's task", task.Name)
+		assert.Equal(t, 1, task.Version)
+	})
+
+	t.Run("owners change their own tasks", func(t *testing.T) {
+		w := sendWithHeaders(router, "PUT", "/api/v1/tasks/"+mine.ID, models.UpdateTaskRequest{Name: stringPtr("Renamed")}, alice)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Contains(t, w.Body.String(), `"owner_id":"alice"`)
+	})
+
+	t.Run("stats only count the caller's tasks", func(t *testing.T) {
+		w := sendWithHeaders(router, "GET", "/api/v1/stats", nil, alice)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"success": true, "data": {"total_tasks": 1, "completed_tasks": 0, "incomplete_tasks": 1}}`, w.Body.String())
+
+		w = sendWithHeaders(router, "GET", "/api/v1/stats", nil, admin)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"total_tasks":2`)
+	})
+
+	t.Run("admins see every task", func(t *testing.T) {
+		w := sendWithHeaders(router, "GET", "/api/v1/tasks?sort=name", nil, admin)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, []string{"Bob's task", "Renamed"}, listedNames(t, w.Body.Bytes()))
+
+		w = sendWithHeaders(router, "GET", "/api/v1/tasks/"+theirs.ID, nil, admin)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
+
+func TestTaskHandler_BulkOwnership(t *testing.T) {
+	_, router := setupOwnershipHandler(t)
+	alice := bearer(t, "alice", false)
+	bob := bearer(t, "bob", false)
+
+	mine := createOwnedTask(t, router, alice, "Alice's task")
+	theirs := createOwnedTask(t, router, bob, "Bob's task")
+
+	operations := []models.BulkOperation{
+		{Op: models.BulkCreate, Task: &models.CreateTaskRequest{Name: "Imported"}},
+		{Op: models.BulkUpdate, ID: mine.ID, Changes: &models.UpdateTaskRequest{Name: stringPtr("Renamed")}},
+		{Op: models.BulkDelete, ID: theirs.ID},
+	}
+
+	t.Run("atomic batch touching another owner's task is aborted", func(t *testing.T) {
+		w := sendWithHeaders(router, "POST", "/api/v1/tasks/bulk", models.BulkRequest{Operations: operations}, alice)
+		require.Equal(t, http.StatusMultiStatus, w.Code, w.Body.String())
+		response := decodeBulkResponse(t, w.Body.Bytes())
+		assert.Equal(t, []int{http.StatusFailedDependency, http.StatusFailedDependency, http.StatusNotFound}, resultStatuses(response))
+
+		w = sendWithHeaders(router, "GET", "/api/v1/tasks", nil, alice)
+		assert.Equal(t, []string{"Alice's task"}, listedNames(t, w.Body.Bytes()))
+	})
+
+	t.Run("best effort applies the caller's operations", func(t *testing.T) {
+		w := sendWithHeaders(router, "POST", "/api/v1/tasks/bulk", models.BulkRequest{Mode: models.BulkBestEffort, Operations: operations}, alice)
+		require.Equal(t, http.StatusMultiStatus, w.Code, w.Body.String())
+		response := decodeBulkResponse(t, w.Body.Bytes())
+		assert.Equal(t, []int{http.StatusCreated, http.StatusOK, http.StatusNotFound}, resultStatuses(response))
+		assert.Equal(t, "alice", response.Results[0].Data.OwnerID)
+
+		w = sendWithHeaders(router, "GET", "/api/v1/tasks/"+theirs.ID, nil, bob)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
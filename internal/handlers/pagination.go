@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"task-api/internal/interfaces"
+	"task-api/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// tasksAfter returns up to limit tasks matching filter that come after the cursor in creation order,
+// and whether more follow. Storages without cursor support are paged from a full, sorted listing.
+func (h *TaskHandler) tasksAfter(ctx context.Context, filter *models.TaskFilter, after *models.TaskCursor, limit int) ([]*models.Task, bool, error) {
+	if paginator, ok := h.storage.(interfaces.CursorPaginator); ok {
+		return paginator.GetTasksAfter(ctx, filter, after, limit)
+	}
+
+	allTasks, err := h.storage.GetAll(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	allTasks = filter.Apply(allTasks)
+	models.SortTasks(allTasks, nil)
+	remaining := models.TasksAfter(allTasks, after)
+	if len(remaining) > limit {
+		return remaining[:limit], true, nil
+	}
+	return remaining, false, nil
+}
+
+// respondPage writes a page of tasks with its next cursor and RFC 8288 Link header
+// The first link restarts the listing; the next link, present when more tasks follow,
+// continues after the last task of the page.
+func respondPage(c *gin.Context, tasks []*models.Task, more bool, limit int) {
+	response := models.NewTaskListResponse(tasks)
+	links := []string{pageLink(c, "first", "", limit)}
+	if more && len(tasks) > 0 {
+		response.NextCursor = models.CursorOf(tasks[len(tasks)-1]).Encode()
+		links = append(links, pageLink(c, "next", response.NextCursor, limit))
+	}
+
+	c.Header("Link", strings.Join(links, ", "))
+	c.Header("X-Limit", strconv.Itoa(limit))
+	c.JSON(http.StatusOK, response)
+}
+
+// pageLink builds one Link header entry for the current listing with the given cursor
+// The query parameters of the request (filters) are kept; offset is replaced by the cursor.
+func pageLink(c *gin.Context, rel, cursor string, limit int) string {
+	query := c.Request.URL.Query()
+	query.Del("offset")
+	query.Del("cursor")
+	if cursor != "" {
+		query.Set("cursor", cursor)
+	}
+	query.Set("limit", strconv.Itoa(limit))
+
+	return fmt.Sprintf("<%s?%s>; rel=%q", c.Request.URL.Path, query.Encode(), rel)
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"task-api/internal/models"
+	"task-api/internal/storage"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// nextLinkPattern extracts the target of the rel="next" entry of a Link header
+var nextLinkPattern = regexp.MustCompile(`<([^>]*)>; rel="next"`)
+
+// walkPages follows rel="next" links from path and returns the task names of every page
+func walkPages(t *testing.T, router *gin.Engine, path string) [][]string {
+	t.Helper()
+
+	var pages [][]string
+	for path != "" {
+		require.Less(t, len(pages), 10, "pagination does not terminate")
+
+		w := sendWithHeaders(router, "GET", path, nil, nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var response models.TaskListResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		names := make([]string, 0, len(response.Data))
+		for _, task := range response.Data {
+			names = append(names, task.Name)
+		}
+		pages = append(pages, names)
+
+		path = ""
+		if match := nextLinkPattern.FindStringSubmatch(w.Header().Get("Link")); match != nil {
+			path = match[1]
+			next, err := url.Parse(path)
+			require.NoError(t, err)
+			assert.Equal(t, response.NextCursor, next.Query().Get("cursor"))
+		} else {
+			assert.Empty(t, response.NextCursor)
+		}
+	}
+	return pages
+}
+
+func TestTaskHandler_GetTasksPaginatedCursor(t *testing.T) {
+	handler, router := setupTestHandler()
+	for i := 1; i <= 7; i++ {
+		createTestTask(t, handler, fmt.Sprintf("Task %d", i), models.TaskStatus((i+1)%2))
+	}
+
+	t.Run("cursor pages in creation order", func(t *testing.T) {
+		pages := walkPages(t, router, "/api/v1/tasks/paginated?cursor=&limit=3")
+		assert.Equal(t, [][]string{
+			{"Task 1", "Task 2", "Task 3"},
+			{"Task 4", "Task 5", "Task 6"},
+			{"Task 7"},
+		}, pages)
+	})
+
+	t.Run("offset page links to the next cursor page", func(t *testing.T) {
+		pages := walkPages(t, router, "/api/v1/tasks/paginated?offset=2&limit=4")
+		assert.Equal(t, [][]string{
+			{"Task 3", "Task 4", "Task 5", "Task 6"},
+			{"Task 7"},
+		}, pages)
+	})
+
+	t.Run("links keep the filter", func(t *testing.T) {
+		pages := walkPages(t, router, "/api/v1/tasks/paginated?cursor=&limit=2&status=incomplete")
+		assert.Equal(t, [][]string{
+			{"Task 1", "Task 3"},
+			{"Task 5", "Task 7"},
+		}, pages)
+	})
+
+	t.Run("first link and headers", func(t *testing.T) {
+		w := sendWithHeaders(router, "GET", "/api/v1/tasks/paginated?cursor=&limit=3", nil, nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Header().Get("Link"), `</api/v1/tasks/paginated?limit=3>; rel="first"`)
+		assert.Equal(t, "3", w.Header().Get("X-Limit"))
+	})
+
+	t.Run("pages are stable while tasks change", func(t *testing.T) {
+		w := sendWithHeaders(router, "GET", "/api/v1/tasks/paginated?cursor=&limit=3", nil, nil)
+		var first models.TaskListResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &first))
+
+		// Deleting a task of the first page does not shift the second one
+		w = sendWithHeaders(router, "DELETE", "/api/v1/tasks/"+first.Data[0].ID, nil, nil)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		pages := walkPages(t, router, "/api/v1/tasks/paginated?limit=3&cursor="+first.NextCursor)
+		assert.Equal(t, [][]string{{"Task 4", "Task 5", "Task 6"}, {"Task 7"}}, pages)
+	})
+
+	invalid := []struct {
+		name  string
+		query string
+	}{
+		{"cursor with offset", "?cursor=&offset=0"},
+		{"malformed cursor", "?cursor=not-a-cursor"},
+		{"cursor without position", "?cursor=e30"},
+	}
+	for _, tt := range invalid {
+		t.Run("rejects "+tt.name, func(t *testing.T) {
+			w := sendWithHeaders(router, "GET", "/api/v1/tasks/paginated"+tt.query, nil, nil)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
+
+func TestTaskHandler_GetTasksPaginatedCursorFallback(t *testing.T) {
+	handler := NewTaskHandler(bareStorage{storage.NewMemoryStorage(100)})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/tasks/paginated", handler.GetTasksPaginated)
+
+	for i := 1; i <= 5; i++ {
+		createTestTask(t, handler, fmt.Sprintf("Task %d", i), models.TaskIncomplete)
+	}
+
+	pages := walkPages(t, router, "/tasks/paginated?cursor=&limit=2")
+	assert.Equal(t, [][]string{{"Task 1", "Task 2"}, {"Task 3", "Task 4"}, {"Task 5"}}, pages)
+
+	pages = walkPages(t, router, "/tasks/paginated?limit=4")
+	assert.Equal(t, [][]string{{"Task 1", "Task 2", "Task 3", "Task 4"}, {"Task 5"}}, pages)
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"task-api/internal/models"
+	"task-api/internal/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxPatchAttempts bounds how often a patch is re-applied after losing a race with another writer
+const maxPatchAttempts = 3
+
+// PatchTask handles PATCH /tasks/:id - apply a JSON Merge Patch or JSON Patch to a task
+// @Summary Patch a task
+// @Description Apply an RFC 7396 JSON Merge Patch (application/merge-patch+json) or an RFC 6902 JSON Patch
+// @Description (application/json-patch+json, including test operations) to the task's JSON representation.
+// @Description The patch is applied atomically: it is evaluated against one version of the task and only written if that version is still current.
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param patch body object true "Merge patch object or JSON Patch operation array"
+// @Param If-Match header string false "Only patch if the task still has this ETag"
+// @Success 200 {object} models.TaskResponse
+// @Header 200 {string} ETag "Strong entity tag of the new task version"
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 412 {object} models.ErrorResponse
+// @Failure 415 {object} models.ErrorResponse
+// @Failure 422 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /tasks/{id} [patch]
+func (h *TaskHandler) PatchTask(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id := c.Param("id")
+	if id == "" {
+		respondError(c, http.StatusBadRequest, "Task ID is required", nil)
+		return
+	}
+
+	body, err := c.GetRawData()
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request data", err)
+		return
+	}
+
+	var patch models.TaskPatch
+	switch contentType := c.ContentType(); contentType {
+	case models.MergePatchContentType:
+		patch, err = models.ParseMergePatch(body)
+	case models.JSONPatchContentType:
+		patch, err = models.ParseJSONPatch(body)
+	default:
+		respondError(c, http.StatusUnsupportedMediaType, "Unsupported patch format",
+			fmt.Errorf("content type must be %s or %s, got %q",
+				models.MergePatchContentType, models.JSONPatchContentType, contentType))
+		return
+	}
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid patch document", err)
+		return
+	}
+
+	if err := h.checkAccess(ctx, id); err != nil {
+		respondStorageError(c, err, "Failed to patch task")
+		return
+	}
+
+	version, err := h.expectedVersion(ctx, c, id)
+	if err != nil {
+		respondStorageError(c, err, "Failed to patch task")
+		return
+	}
+
+	task, err := h.patchTask(ctx, id, version, patch)
+	if err != nil {
+		respondStorageError(c, err, "Failed to patch task")
+		return
+	}
+
+	setTaskETag(c, task)
+	response := models.NewTaskResponse(task, "Task patched successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+// patchTask applies the patch to the current task and writes the result conditionally on
+// the version it was computed from, so a concurrent write is never overwritten
+// Without If-Match (version 0) a lost race re-applies the patch to the newer task; with it,
+// the patch only ever applies to the requested version.
+func (h *TaskHandler) patchTask(ctx context.Context, id string, version int, patch models.TaskPatch) (*models.Task, error) {
+	for attempt := 1; ; attempt++ {
+		current, err := h.storage.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if version > 0 && current.Version != version {
+			return nil, fmt.Errorf("%w: If-Match does not match current ETag %s", storage.ErrPreconditionFailed, taskETag(current))
+		}
+
+		patched, err := patch.Apply(current)
+		if err != nil {
+			return nil, err
+		}
+
+		// A patch that changes nothing (e.g. only test operations) does not create a new version
+		if len(models.DiffTasks(current, patched)) == 0 {
+			return current, nil
+		}
+
+		task, err := h.updateTask(ctx, id, current.Version, patched.ReplaceRequest())
+		if errors.Is(err, storage.ErrPreconditionFailed) && version == 0 && attempt < maxPatchAttempts {
+			continue
+		}
+		return task, err
+	}
+}
@@ -0,0 +1,285 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"task-api/internal/middleware"
+	"task-api/internal/models"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createPatchTestTask creates a task with every optional field set
+func createPatchTestTask(t *testing.T, handler *TaskHandler) *models.Task {
+	t.Helper()
+
+	due := time.Date(2030, 1, 2, 15, 0, 0, 0, time.UTC)
+	task, err := handler.storage.Create(context.Background(), &models.CreateTaskRequest{
+		Name:        "Patch me",
+		Description: "Original description",
+		Status:      models.TaskIncomplete,
+		DueDate:     &due,
+		Tags:        []string{"work"},
+	})
+	require.NoError(t, err)
+
+	return task
+}
+
+func TestTaskHandler_PatchTask(t *testing.T) {
+	handler, router := setupTestHandler()
+
+	tests := []struct {
+		name           string
+		contentType    string
+		body           string
+		expectedStatus int
+		check          func(t *testing.T, task *models.Task)
+	}{
+		{
+			name:           "merge patch sets fields",
+			contentType:    models.MergePatchContentType,
+			body:           `{"name":"Merged","priority":2,"tags":["a","b"]}`,
+			expectedStatus: http.StatusOK,
+			check: func(t *testing.T, task *models.Task) {
+				assert.Equal(t, "Merged", task.Name)
+				assert.Equal(t, models.PriorityHigh, task.Priority)
+				assert.Equal(t, []string{"a", "b"}, task.Tags)
+				assert.Equal(t, "Original description", task.Description)
+				assert.Equal(t, 2, task.Version)
+			},
+		},
+		{
+			name:           "merge patch null removes a field",
+			contentType:    models.MergePatchContentType,
+			body:           `{"due_date":null,"description":null}`,
+			expectedStatus: http.StatusOK,
+			check: func(t *testing.T, task *models.Task) {
+				assert.Nil(t, task.DueDate)
+				assert.Empty(t, task.Description)
+			},
+		},
+		{
+			name:           "merge patch on read-only field",
+			contentType:    models.MergePatchContentType,
+			body:           `{"version":9}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "merge patch with unknown field",
+			contentType:    models.MergePatchContentType,
+			body:           `{"colour":"red"}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "merge patch that is not an object",
+			contentType:    models.MergePatchContentType,
+			body:           `[1,2]`,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:        "json patch add replace remove",
+			contentType: models.JSONPatchContentType,
+			body: `[{"op":"add","path":"/tags/-","value":"added"},
+				{"op":"replace","path":"/name","value":"Replaced"},
+				{"op":"remove","path":"/due_date"}]`,
+			expectedStatus: http.StatusOK,
+			check: func(t *testing.T, task *models.Task) {
+				assert.Equal(t, "Replaced", task.Name)
+				assert.Equal(t, []string{"added", "work"}, task.Tags)
+				assert.Nil(t, task.DueDate)
+			},
+		},
+		{
+			name:        "json patch move and copy",
+			contentType: models.JSONPatchContentType,
+			body: `[{"op":"copy","from":"/name","path":"/description"},
+				{"op":"move","from":"/tags/0","path":"/tags/-"}]`,
+			expectedStatus: http.StatusOK,
+			check: func(t *testing.T, task *models.Task) {
+				assert.Equal(t, "Patch me", task.Description)
+				assert.Equal(t, []string{"work"}, task.Tags)
+			},
+		},
+		{
+			name:        "json patch test passes",
+			contentType: models.JSONPatchContentType,
+			body: `[{"op":"test","path":"/status","value":0},
+				{"op":"replace","path":"/status","value":1}]`,
+			expectedStatus: http.StatusOK,
+			check: func(t *testing.T, task *models.Task) {
+				assert.Equal(t, models.TaskCompleted, task.Status)
+			},
+		},
+		{
+			name:        "json patch test fails",
+			contentType: models.JSONPatchContentType,
+			body: `[{"op":"test","path":"/status","value":1},
+				{"op":"replace","path":"/name","value":"Never"}]`,
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "json patch path does not exist",
+			contentType:    models.JSONPatchContentType,
+			body:           `[{"op":"remove","path":"/tags/5"}]`,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "json patch unknown operation",
+			contentType:    models.JSONPatchContentType,
+			body:           `[{"op":"merge","path":"/name","value":"x"}]`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "json patch missing value",
+			contentType:    models.JSONPatchContentType,
+			body:           `[{"op":"replace","path":"/name"}]`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "json patch fails validation",
+			contentType:    models.JSONPatchContentType,
+			body:           `[{"op":"replace","path":"/name","value":""}]`,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "plain json is not a patch format",
+			contentType:    "application/json",
+			body:           `{"name":"x"}`,
+			expectedStatus: http.StatusUnsupportedMediaType,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := createPatchTestTask(t, handler)
+
+			w := sendWithHeaders(router, "PATCH", "/api/v1/tasks/"+task.ID, json.RawMessage(tt.body),
+				map[string]string{"Content-Type": tt.contentType})
+			require.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+
+			stored, err := handler.storage.GetByID(context.Background(), task.ID)
+			require.NoError(t, err)
+
+			if tt.check == nil {
+				assert.Equal(t, 1, stored.Version, "failed patch must not write")
+				return
+			}
+
+			var response models.TaskResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, taskETag(response.Data), w.Header().Get("ETag"))
+			tt.check(t, response.Data)
+			assert.Equal(t, response.Data.Version, stored.Version)
+		})
+	}
+}
+
+func TestTaskHandler_PatchTaskConditional(t *testing.T) {
+	handler, router := setupTestHandler()
+	task := createPatchTestTask(t, handler)
+	path := "/api/v1/tasks/" + task.ID
+	patch := json.RawMessage(`{"name":"Changed"}`)
+
+	t.Run("stale If-Match", func(t *testing.T) {
+		w := sendWithHeaders(router, "PATCH", path, patch, map[string]string{
+			"Content-Type": models.MergePatchContentType,
+			"If-Match":     `"4"`,
+		})
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	})
+
+	t.Run("current If-Match", func(t *testing.T) {
+		w := sendWithHeaders(router, "PATCH", path, patch, map[string]string{
+			"Content-Type": models.MergePatchContentType,
+			"If-Match":     `"1"`,
+		})
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+	})
+
+	t.Run("no-op patch keeps the version", func(t *testing.T) {
+		w := sendWithHeaders(router, "PATCH", path, json.RawMessage(`[{"op":"test","path":"/name","value":"Changed"}]`),
+			map[string]string{"Content-Type": models.JSONPatchContentType})
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+	})
+
+	t.Run("missing task", func(t *testing.T) {
+		w := sendWithHeaders(router, "PATCH", "/api/v1/tasks/missing", patch,
+			map[string]string{"Content-Type": models.MergePatchContentType})
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestTaskHandler_UpdateTaskAPIVersion(t *testing.T) {
+	handler, _ := setupTestHandler()
+	router := gin.New()
+	router.Use(middleware.APIVersion(middleware.APIVersion1))
+	router.PUT("/api/v1/tasks/:id", handler.UpdateTask)
+
+	tests := []struct {
+		name           string
+		apiVersion     string
+		expectedStatus int
+		check          func(t *testing.T, task *models.Task)
+	}{
+		{
+			name:           "version 1 keeps omitted fields",
+			apiVersion:     "1",
+			expectedStatus: http.StatusOK,
+			check: func(t *testing.T, task *models.Task) {
+				assert.Equal(t, "Renamed", task.Name)
+				assert.Equal(t, "Original description", task.Description)
+				assert.Equal(t, []string{"work"}, task.Tags)
+				assert.NotNil(t, task.DueDate)
+			},
+		},
+		{
+			name:           "version 2 resets omitted fields",
+			apiVersion:     "2",
+			expectedStatus: http.StatusOK,
+			check: func(t *testing.T, task *models.Task) {
+				assert.Equal(t, "Renamed", task.Name)
+				assert.Empty(t, task.Description)
+				assert.Empty(t, task.Tags)
+				assert.Nil(t, task.DueDate)
+				assert.Equal(t, models.TaskIncomplete, task.Status)
+			},
+		},
+		{
+			name:           "unsupported version",
+			apiVersion:     "3",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "malformed version",
+			apiVersion:     "v2",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := createPatchTestTask(t, handler)
+
+			w := sendWithHeaders(router, "PUT", "/api/v1/tasks/"+task.ID,
+				models.UpdateTaskRequest{Name: stringPtr("Renamed")},
+				map[string]string{middleware.APIVersionHeader: tt.apiVersion})
+			require.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+			if tt.check == nil {
+				return
+			}
+
+			assert.Equal(t, tt.apiVersion, w.Header().Get(middleware.APIVersionHeader))
+			var response models.TaskResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			tt.check(t, response.Data)
+		})
+	}
+}
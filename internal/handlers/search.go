@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"task-api/internal/interfaces"
+	"task-api/internal/models"
+	"task-api/internal/search"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SearchTasks handles GET /tasks/search - full-text search over task names and descriptions
+// @Summary Search tasks
+// @Description Find tasks whose name or description contains every word of the query. Each word also matches
+// @Description longer words it is a prefix of (at a lower score). Results are ranked by relevance, name matches
+// @Description weighing more than description matches, and carry HTML-escaped snippets with the matches in <mark> tags.
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Param q query string true "Search query"
+// @Param limit query int false "Maximum number of results (default: 20, max: 100)"
+// @Success 200 {object} models.SearchResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /tasks/search [get]
+func (h *TaskHandler) SearchTasks(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	query := c.Query("q")
+	terms := search.QueryTerms(query)
+	if len(terms) == 0 {
+		respondError(c, http.StatusBadRequest, "Invalid search query", fmt.Errorf("q must contain at least one word"))
+		return
+	}
+	if len(query) > models.MaxSearchQueryLength {
+		respondError(c, http.StatusBadRequest, "Invalid search query",
+			fmt.Errorf("q cannot exceed %d characters", models.MaxSearchQueryLength))
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(models.DefaultSearchLimit)))
+	if err != nil || limit <= 0 || limit > models.MaxSearchLimit {
+		respondError(c, http.StatusBadRequest,
+			fmt.Sprintf("Invalid limit parameter (must be between 1 and %d)", models.MaxSearchLimit), err)
+		return
+	}
+
+	results, err := h.searchTasks(ctx, query, limit)
+	if err != nil {
+		respondStorageError(c, err, "Failed to search tasks")
+		return
+	}
+
+	for i := range results {
+		results[i].Highlights = highlights(results[i].Task, terms)
+	}
+
+	c.JSON(http.StatusOK, models.NewSearchResponse(query, results))
+}
+
+// searchTasks runs the search on the storage's index, or on a temporary index of all tasks
+// for storages without one
+// Callers restricted to their own tasks always search a temporary index of those, so the
+// limit is not used up by matches they cannot see.
+func (h *TaskHandler) searchTasks(ctx context.Context, query string, limit int) ([]models.SearchResult, error) {
+	owner := ownerScope(ctx)
+	if searcher, ok := h.storage.(interfaces.TaskSearcher); ok && owner == "" {
+		return searcher.SearchTasks(ctx, query, limit)
+	}
+
+	tasks, err := h.queryTasks(ctx, &models.TaskQuery{Filter: &models.TaskFilter{OwnerID: owner}})
+	if err != nil {
+		return nil, err
+	}
+
+	index := search.NewIndex()
+	byID := make(map[string]*models.Task, len(tasks))
+	for _, task := range tasks {
+		index.Add(task)
+		byID[task.ID] = task
+	}
+
+	hits := index.Search(query, limit)
+	results := make([]models.SearchResult, len(hits))
+	for i, hit := range hits {
+		results[i] = models.SearchResult{Task: byID[hit.ID], Score: hit.Score}
+	}
+	return results, nil
+}
+
+// highlights returns the snippets of the task fields matching the query terms
+func highlights(task *models.Task, terms []string) map[string]string {
+	fields := make(map[string]string)
+	if snippet := search.Highlight(task.Name, terms); snippet != "" {
+		fields["name"] = snippet
+	}
+	if snippet := search.Highlight(task.Description, terms); snippet != "" {
+		fields["description"] = snippet
+	}
+	return fields
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"task-api/internal/models"
+	"task-api/internal/storage"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// decodeSearchResponse decodes a search response body
+func decodeSearchResponse(t *testing.T, body []byte) models.SearchResponse {
+	t.Helper()
+
+	var response models.SearchResponse
+	require.NoError(t, json.Unmarshal(body, &response))
+	return response
+}
+
+// createSearchTestTasks stores tasks with descriptions for the search tests
+func createSearchTestTasks(t *testing.T, handler *TaskHandler) {
+	t.Helper()
+
+	for _, req := range []*models.CreateTaskRequest{
+		{Name: "Quarterly report", Description: "Numbers for the board"},
+		{Name: "Board meeting", Description: "Present the report & budget"},
+		{Name: "Buy milk"},
+	} {
+		_, err := handler.storage.Create(context.Background(), req)
+		require.NoError(t, err)
+	}
+}
+
+func TestTaskHandler_SearchTasks(t *testing.T) {
+	handler, router := setupTestHandler()
+	createSearchTestTasks(t, handler)
+
+	t.Run("ranked results with highlights", func(t *testing.T) {
+		w := sendWithHeaders(router, "GET", "/api/v1/tasks/search?q=report", nil, nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		response := decodeSearchResponse(t, w.Body.Bytes())
+		assert.True(t, response.Success)
+		assert.Equal(t, "report", response.Query)
+		require.Equal(t, 2, response.Count)
+
+		assert.Equal(t, "Quarterly report", response.Data[0].Task.Name)
+		assert.Greater(t, response.Data[0].Score, response.Data[1].Score)
+		assert.Equal(t, map[string]string{"name": "Quarterly <mark>report</mark>"}, response.Data[0].Highlights)
+		assert.Equal(t, map[string]string{"description": "Present the <mark>report</mark> &amp; budget"}, response.Data[1].Highlights)
+	})
+
+	t.Run("prefix and multiple words", func(t *testing.T) {
+		w := sendWithHeaders(router, "GET", "/api/v1/tasks/search?q=boa+num", nil, nil)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		response := decodeSearchResponse(t, w.Body.Bytes())
+		require.Equal(t, 1, response.Count)
+		assert.Equal(t, "<mark>Numbers</mark> for the <mark>board</mark>", response.Data[0].Highlights["description"])
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		w := sendWithHeaders(router, "GET", "/api/v1/tasks/search?q=board&limit=1", nil, nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, 1, decodeSearchResponse(t, w.Body.Bytes()).Count)
+	})
+
+	t.Run("no results", func(t *testing.T) {
+		w := sendWithHeaders(router, "GET", "/api/v1/tasks/search?q=taxes", nil, nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"data":[]`)
+	})
+
+	invalid := []struct {
+		name  string
+		query string
+	}{
+		{"missing query", ""},
+		{"query without words", "?q=%21%3F"},
+		{"query too long", "?q=" + strings.Repeat("a", models.MaxSearchQueryLength+1)},
+		{"invalid limit", "?q=report&limit=0"},
+		{"limit too large", "?q=report&limit=101"},
+	}
+	for _, tt := range invalid {
+		t.Run("rejects "+tt.name, func(t *testing.T) {
+			w := sendWithHeaders(router, "GET", "/api/v1/tasks/search"+tt.query, nil, nil)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
+
+func TestTaskHandler_SearchTasksFallback(t *testing.T) {
+	handler := NewTaskHandler(bareStorage{storage.NewMemoryStorage(100)})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/tasks/search", handler.SearchTasks)
+
+	createSearchTestTasks(t, handler)
+
+	w := sendWithHeaders(router, "GET", "/tasks/search?q=report", nil, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	response := decodeSearchResponse(t, w.Body.Bytes())
+	require.Equal(t, 2, response.Count)
+	assert.Equal(t, "Quarterly report", response.Data[0].Task.Name)
+	assert.NotEmpty(t, response.Data[1].Highlights["description"])
+}
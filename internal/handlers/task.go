@@ -1,12 +1,13 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"strconv"
-	"strings"
 	"task-api/internal/interfaces"
+	"task-api/internal/middleware"
 	"task-api/internal/models"
-	"task-api/internal/storage"
 
 	"github.com/gin-gonic/gin"
 )
@@ -26,20 +27,45 @@ func NewTaskHandler(storage interfaces.TaskStorage) *TaskHandler {
 
 // GetAllTasks handles GET /tasks - retrieve all tasks
 // @Summary Get all tasks
-// @Description Get all tasks from the storage
+// @Description Get all tasks from the storage, optionally filtered, sorted and restricted to some fields.
+// @Description Without a sort, tasks are listed in creation order.
 // @Tags tasks
 // @Accept json
 // @Produce json
+// @Param status query string false "Filter by workflow state name or numeric status"
+// @Param priority query string false "Filter by priority (low, medium, high, urgent or 0-3)"
+// @Param tag query []string false "Filter by tag; repeat or comma-separate to require several" collectionFormat(multi)
+// @Param name_contains query string false "Only tasks whose name contains this text (case-insensitive)"
+// @Param due_before query string false "Only tasks due before this RFC 3339 time"
+// @Param due_after query string false "Only tasks due after this RFC 3339 time"
+// @Param created_before query string false "Only tasks created before this RFC 3339 time"
+// @Param created_after query string false "Only tasks created after this RFC 3339 time"
+// @Param sort query string false "Comma-separated sort fields, '-' prefix for descending (e.g. -updated_at,name)"
+// @Param fields query string false "Comma-separated fields to return (e.g. id,name)"
 // @Success 200 {object} models.TaskListResponse
+// @Failure 400 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
 // @Router /tasks [get]
 func (h *TaskHandler) GetAllTasks(c *gin.Context) {
-	tasks, err := h.storage.GetAll()
+	ctx := c.Request.Context()
+
+	query, err := models.ParseTaskQuery(c.Request.URL.Query())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
-			"Failed to retrieve tasks",
-			err,
-		))
+		respondError(c, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+	query.Filter.OwnerID = ownerScope(ctx)
+
+	tasks, err := h.queryTasks(ctx, query)
+	if err != nil {
+		respondStorageError(c, err, "Failed to retrieve tasks")
+		return
+	}
+
+	if len(query.Fields) > 0 {
+		c.JSON(http.StatusOK, models.NewTaskFieldsListResponse(tasks, query.Fields))
 		return
 	}
 
@@ -47,6 +73,23 @@ func (h *TaskHandler) GetAllTasks(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// queryTasks returns the tasks selected by the query, in its order
+// Filtering and sorting are pushed down to storages that support it.
+func (h *TaskHandler) queryTasks(ctx context.Context, query *models.TaskQuery) ([]*models.Task, error) {
+	if querier, ok := h.storage.(interfaces.TaskQuerier); ok {
+		return querier.QueryTasks(ctx, query.Filter, query.Sort)
+	}
+
+	tasks, err := h.storage.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks = query.Filter.Apply(tasks)
+	models.SortTasks(tasks, query.Sort)
+	return tasks, nil
+}
+
 // GetTaskByID handles GET /tasks/:id - retrieve a specific task
 // @Summary Get a task by ID
 // @Description Get a specific task by its ID
@@ -54,38 +97,36 @@ func (h *TaskHandler) GetAllTasks(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path string true "Task ID"
+// @Param If-None-Match header string false "Return 304 if the task still has this ETag"
 // @Success 200 {object} models.TaskResponse
+// @Header 200 {string} ETag "Strong entity tag of the task version"
+// @Success 304 "Task unchanged"
 // @Failure 404 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
 // @Router /tasks/{id} [get]
 func (h *TaskHandler) GetTaskByID(c *gin.Context) {
+	ctx := c.Request.Context()
+
 	id := c.Param("id")
 	if id == "" {
-		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
-			"Task ID is required",
-			nil,
-		))
+		respondError(c, http.StatusBadRequest, "Task ID is required", nil)
 		return
 	}
 
-	task, err := h.storage.GetByID(id)
+	task, err := h.accessibleTask(ctx, id)
 	if err != nil {
-		// Check if it's a "not found" error
-		if strings.Contains(err.Error(), "not found") {
-			c.JSON(http.StatusNotFound, models.NewErrorResponse(
-				"Task not found",
-				err,
-			))
-			return
-		}
+		respondStorageError(c, err, "Failed to retrieve task")
+		return
+	}
 
-		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
-			"Failed to retrieve task",
-			err,
-		))
+	if notModified(c, task) {
+		respondNotModified(c, task)
 		return
 	}
 
+	setTaskETag(c, task)
 	response := models.NewTaskResponse(task, "Task retrieved successfully")
 	c.JSON(http.StatusOK, response)
 }
@@ -98,117 +139,151 @@ func (h *TaskHandler) GetTaskByID(c *gin.Context) {
 // @Produce json
 // @Param task body models.CreateTaskRequest true "Task data"
 // @Success 201 {object} models.TaskResponse
+// @Header 201 {string} ETag "Strong entity tag of the task version"
 // @Failure 400 {object} models.ErrorResponse
+// @Failure 422 {object} models.ErrorResponse
+// @Failure 507 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
 // @Router /tasks [post]
 func (h *TaskHandler) CreateTask(c *gin.Context) {
+	ctx := c.Request.Context()
+
 	var req models.CreateTaskRequest
 
 	// Bind JSON request to struct with validation
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
-			"Invalid request data",
-			err,
-		))
+		respondError(c, http.StatusBadRequest, "Invalid request data", err)
 		return
 	}
 
 	// Additional validation (business logic)
 	if err := req.Validate(); err != nil {
-		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
-			"Validation failed",
-			err,
-		))
+		respondValidationError(c, err)
 		return
 	}
 
-	// Create the task
-	task, err := h.storage.Create(&req)
+	// Create the task, owned by the authenticated caller
+	req.OwnerID = middleware.GetSubject(c)
+	task, err := h.storage.Create(ctx, &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
-			"Failed to create task",
-			err,
-		))
+		respondStorageError(c, err, "Failed to create task")
 		return
 	}
 
+	setTaskETag(c, task)
 	response := models.NewTaskResponse(task, "Task created successfully")
 	c.JSON(http.StatusCreated, response)
 }
 
 // UpdateTask handles PUT /tasks/:id - update an existing task
 // @Summary Update a task
-// @Description Update an existing task with the provided data
+// @Description API version 1 (default): partial update, only the fields present are changed.
+// @Description API version 2 (API-Version: 2): full replacement, omitted fields are reset to their defaults.
 // @Tags tasks
 // @Accept json
 // @Produce json
 // @Param id path string true "Task ID"
 // @Param task body models.UpdateTaskRequest true "Task update data"
+// @Param If-Match header string false "Only update if the task still has this ETag"
+// @Param API-Version header int false "1 = partial update, 2 = full replacement"
 // @Success 200 {object} models.TaskResponse
+// @Header 200 {string} ETag "Strong entity tag of the new task version"
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 412 {object} models.ErrorResponse
+// @Failure 422 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
 // @Router /tasks/{id} [put]
 func (h *TaskHandler) UpdateTask(c *gin.Context) {
+	ctx := c.Request.Context()
+
 	id := c.Param("id")
 	if id == "" {
-		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
-			"Task ID is required",
-			nil,
-		))
+		respondError(c, http.StatusBadRequest, "Task ID is required", nil)
+		return
+	}
+
+	// API version 2 turns PUT into a full replacement
+	bind := bindUpdateRequest
+	if middleware.GetAPIVersion(c) >= middleware.APIVersion2 {
+		bind = bindReplaceRequest
+	}
+	req, ok := bind(c)
+	if !ok {
+		return
+	}
+
+	if err := h.checkAccess(ctx, id); err != nil {
+		respondStorageError(c, err, "Failed to update task")
 		return
 	}
 
+	version, err := h.expectedVersion(ctx, c, id)
+	if err != nil {
+		respondStorageError(c, err, "Failed to update task")
+		return
+	}
+
+	// Update the task (atomically conditional on the version when If-Match is sent)
+	task, err := h.updateTask(ctx, id, version, req)
+	if err != nil {
+		respondStorageError(c, err, "Failed to update task")
+		return
+	}
+
+	setTaskETag(c, task)
+	response := models.NewTaskResponse(task, "Task updated successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+// bindUpdateRequest reads a partial update body; only the fields present are changed
+func bindUpdateRequest(c *gin.Context) (*models.UpdateTaskRequest, bool) {
 	var req models.UpdateTaskRequest
 
 	// Bind JSON request to struct
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
-			"Invalid request data",
-			err,
-		))
-		return
+		respondError(c, http.StatusBadRequest, "Invalid request data", err)
+		return nil, false
 	}
 
 	// Additional validation (business logic)
 	if err := req.Validate(); err != nil {
-		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
-			"Validation failed",
-			err,
-		))
-		return
+		respondValidationError(c, err)
+		return nil, false
 	}
 
 	// Check if there are any updates
 	if !req.HasUpdates() {
-		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
-			"No updates provided",
-			nil,
-		))
-		return
+		respondValidationError(c, errors.New("no updates provided"))
+		return nil, false
 	}
 
-	// Update the task
-	task, err := h.storage.Update(id, &req)
-	if err != nil {
-		// Check if it's a "not found" error
-		if strings.Contains(err.Error(), "not found") {
-			c.JSON(http.StatusNotFound, models.NewErrorResponse(
-				"Task not found",
-				err,
-			))
-			return
-		}
+	return &req, true
+}
 
-		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
-			"Failed to update task",
-			err,
-		))
-		return
+// bindReplaceRequest reads a full task representation and turns it into an update that
+// sets every field; omitted fields take the same defaults as on creation
+func bindReplaceRequest(c *gin.Context) (*models.UpdateTaskRequest, bool) {
+	var replacement models.CreateTaskRequest
+
+	// Bind JSON request to struct with validation
+	if err := c.ShouldBindJSON(&replacement); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request data", err)
+		return nil, false
 	}
 
-	response := models.NewTaskResponse(task, "Task updated successfully")
-	c.JSON(http.StatusOK, response)
+	// Additional validation (business logic)
+	if err := replacement.Validate(); err != nil {
+		respondValidationError(c, err)
+		return nil, false
+	}
+
+	return replacement.ToTask().ReplaceRequest(), true
 }
 
 // DeleteTask handles DELETE /tasks/:id - delete a task
@@ -218,47 +293,38 @@ func (h *TaskHandler) UpdateTask(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path string true "Task ID"
+// @Param If-Match header string false "Only delete if the task still has this ETag"
 // @Success 200 {object} models.TaskResponse
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 404 {object} models.ErrorResponse
+// @Failure 412 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
 // @Router /tasks/{id} [delete]
 func (h *TaskHandler) DeleteTask(c *gin.Context) {
+	ctx := c.Request.Context()
+
 	id := c.Param("id")
 	if id == "" {
-		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
-			"Task ID is required",
-			nil,
-		))
+		respondError(c, http.StatusBadRequest, "Task ID is required", nil)
 		return
 	}
 
-	// Check if task exists before deletion
-	_, err := h.storage.GetByID(id)
-	if err != nil {
-		// Check if it's a "not found" error
-		if strings.Contains(err.Error(), "not found") {
-			c.JSON(http.StatusNotFound, models.NewErrorResponse(
-				"Task not found",
-				err,
-			))
-			return
-		}
-
-		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
-			"Failed to retrieve task",
-			err,
-		))
+	if err := h.checkAccess(ctx, id); err != nil {
+		respondStorageError(c, err, "Failed to delete task")
 		return
 	}
 
-	// Delete the task
-	err = h.storage.Delete(id)
+	version, err := h.expectedVersion(ctx, c, id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
-			"Failed to delete task",
-			err,
-		))
+		respondStorageError(c, err, "Failed to delete task")
+		return
+	}
+
+	// Delete the task (storage reports ErrNotFound for missing tasks)
+	if err := h.deleteTask(ctx, id, version); err != nil {
+		respondStorageError(c, err, "Failed to delete task")
 		return
 	}
 
@@ -276,70 +342,56 @@ func (h *TaskHandler) DeleteTask(c *gin.Context) {
 // @Tags tasks
 // @Accept json
 // @Produce json
-// @Param status path int true "Task Status (0=incomplete, 1=completed)"
+// @Param status path string true "Workflow state name (e.g. incomplete, completed) or numeric status"
 // @Success 200 {object} models.TaskListResponse
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
 // @Router /tasks/status/{status} [get]
 func (h *TaskHandler) GetTasksByStatus(c *gin.Context) {
+	ctx := c.Request.Context()
+
 	statusStr := c.Param("status")
 	if statusStr == "" {
-		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
-			"Status is required",
-			nil,
-		))
+		respondError(c, http.StatusBadRequest, "Status is required", nil)
 		return
 	}
 
-	// Parse status
-	statusInt, err := strconv.Atoi(statusStr)
+	// Parse status (workflow state name or numeric value)
+	status, err := models.ParseTaskStatus(statusStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
-			"Invalid status format",
-			err,
-		))
+		respondError(c, http.StatusBadRequest, "Invalid status value", err)
 		return
 	}
 
-	status := models.TaskStatus(statusInt)
-	if !status.IsValid() {
-		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
-			"Invalid status value. Must be 0 (incomplete) or 1 (completed)",
-			nil,
-		))
-		return
-	}
+	// Only the caller's tasks, unless it sees every task
+	owned := &models.TaskFilter{OwnerID: ownerScope(ctx)}
 
 	// Get tasks by status (if storage supports it)
-	if memStorage, ok := h.storage.(*storage.MemoryStorage); ok {
-		tasks, err := memStorage.GetTasksByStatus(status)
+	if querier, ok := h.storage.(interfaces.StatusQuerier); ok {
+		tasks, err := querier.GetTasksByStatus(ctx, status)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
-				"Failed to retrieve tasks by status",
-				err,
-			))
+			respondStorageError(c, err, "Failed to retrieve tasks by status")
 			return
 		}
 
-		response := models.NewTaskListResponse(tasks)
+		response := models.NewTaskListResponse(owned.Apply(tasks))
 		c.JSON(http.StatusOK, response)
 		return
 	}
 
 	// Fallback: get all tasks and filter
-	allTasks, err := h.storage.GetAll()
+	allTasks, err := h.storage.GetAll(ctx)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
-			"Failed to retrieve tasks",
-			err,
-		))
+		respondStorageError(c, err, "Failed to retrieve tasks")
 		return
 	}
 
 	// Filter tasks by status
 	var filteredTasks []*models.Task
 	for _, task := range allTasks {
-		if task.Status == status {
+		if task.Status == status && owned.Matches(task) {
 			filteredTasks = append(filteredTasks, task)
 		}
 	}
@@ -350,96 +402,133 @@ func (h *TaskHandler) GetTasksByStatus(c *gin.Context) {
 
 // GetTasksPaginated handles GET /tasks/paginated - get tasks with pagination
 // @Summary Get tasks with pagination
-// @Description Get tasks with pagination support
+// @Description Get tasks in creation order, a page at a time. Pass the next_cursor of a page as cursor to
+// @Description get the following one; cursors stay stable while tasks are added or removed. Offset paging
+// @Description is still supported but can skip or repeat tasks when the list changes between requests.
 // @Tags tasks
 // @Accept json
 // @Produce json
+// @Param cursor query string false "Continue after this cursor (next_cursor of the previous page); cannot be combined with offset"
 // @Param offset query int false "Offset for pagination (default: 0)"
 // @Param limit query int false "Limit for pagination (default: 10)"
+// @Param status query string false "Filter by workflow state name or numeric status"
+// @Param priority query string false "Filter by priority (low, medium, high, urgent or 0-3)"
+// @Param tag query []string false "Filter by tag; repeat or comma-separate to require several" collectionFormat(multi)
+// @Param name_contains query string false "Only tasks whose name contains this text (case-insensitive)"
+// @Param due_before query string false "Only tasks due before this RFC 3339 time"
+// @Param due_after query string false "Only tasks due after this RFC 3339 time"
+// @Param created_before query string false "Only tasks created before this RFC 3339 time"
+// @Param created_after query string false "Only tasks created after this RFC 3339 time"
 // @Success 200 {object} models.TaskListResponse
+// @Header 200 {string} Link "RFC 8288 links to the first and next pages"
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
 // @Router /tasks/paginated [get]
 func (h *TaskHandler) GetTasksPaginated(c *gin.Context) {
+	ctx := c.Request.Context()
+
 	// Parse query parameters
-	offsetStr := c.DefaultQuery("offset", "0")
 	limitStr := c.DefaultQuery("limit", "10")
 
-	offset, err := strconv.Atoi(offsetStr)
-	if err != nil || offset < 0 {
-		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
-			"Invalid offset parameter",
-			err,
-		))
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit <= 0 || limit > 100 {
+		respondError(c, http.StatusBadRequest, "Invalid limit parameter (must be between 1 and 100)", err)
 		return
 	}
 
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil || limit <= 0 || limit > 100 {
-		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
-			"Invalid limit parameter (must be between 1 and 100)",
-			err,
-		))
+	filter, err := models.ParseTaskFilter(c.Request.URL.Query())
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid filter parameters", err)
 		return
 	}
+	filter.OwnerID = ownerScope(ctx)
 
-	// Get paginated tasks (if storage supports it)
-	if memStorage, ok := h.storage.(*storage.MemoryStorage); ok {
-		tasks, total, err := memStorage.GetTasksPaginated(offset, limit)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
-				"Failed to retrieve paginated tasks",
-				err,
-			))
+	// Cursor paging: an empty cursor starts at the first task
+	if cursorStr, ok := c.GetQuery("cursor"); ok {
+		if _, ok := c.GetQuery("offset"); ok {
+			respondError(c, http.StatusBadRequest, "Invalid pagination parameters", errors.New("cursor and offset cannot be combined"))
 			return
 		}
 
-		response := models.NewTaskListResponse(tasks)
-		// Add pagination metadata to response headers
-		c.Header("X-Total-Count", strconv.Itoa(total))
-		c.Header("X-Offset", strconv.Itoa(offset))
-		c.Header("X-Limit", strconv.Itoa(limit))
+		var after *models.TaskCursor
+		if cursorStr != "" {
+			if after, err = models.DecodeTaskCursor(cursorStr); err != nil {
+				respondError(c, http.StatusBadRequest, "Invalid cursor parameter", err)
+				return
+			}
+		}
 
-		c.JSON(http.StatusOK, response)
-		return
-	}
+		tasks, more, err := h.tasksAfter(ctx, filter, after, limit)
+		if err != nil {
+			respondStorageError(c, err, "Failed to retrieve paginated tasks")
+			return
+		}
 
-	// Fallback: get all tasks and slice
-	allTasks, err := h.storage.GetAll()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
-			"Failed to retrieve tasks",
-			err,
-		))
+		respondPage(c, tasks, more, limit)
 		return
 	}
 
-	total := len(allTasks)
-
-	// Handle pagination manually
-	if offset >= total {
-		response := models.NewTaskListResponse([]*models.Task{})
-		c.Header("X-Total-Count", strconv.Itoa(total))
-		c.Header("X-Offset", strconv.Itoa(offset))
-		c.Header("X-Limit", strconv.Itoa(limit))
-		c.JSON(http.StatusOK, response)
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		respondError(c, http.StatusBadRequest, "Invalid offset parameter", err)
 		return
 	}
 
-	end := offset + limit
-	if end > total {
-		end = total
-	}
+	// Get paginated tasks (if storage supports it and no filter has to be applied first)
+	var (
+		tasks []*models.Task
+		total int
+	)
+	if paginator, ok := h.storage.(interfaces.Paginator); ok && filter.IsEmpty() {
+		tasks, total, err = paginator.GetTasksPaginated(ctx, offset, limit)
+		if err != nil {
+			respondStorageError(c, err, "Failed to retrieve paginated tasks")
+			return
+		}
+	} else {
+		// Fallback: get all tasks, filter, sort and slice
+		allTasks, err := h.storage.GetAll(ctx)
+		if err != nil {
+			respondStorageError(c, err, "Failed to retrieve tasks")
+			return
+		}
 
-	paginatedTasks := allTasks[offset:end]
-	response := models.NewTaskListResponse(paginatedTasks)
+		allTasks = filter.Apply(allTasks)
+		models.SortTasks(allTasks, nil)
+		total = len(allTasks)
+
+		tasks = []*models.Task{}
+		if offset < total {
+			end := offset + limit
+			if end > total {
+				end = total
+			}
+			tasks = allTasks[offset:end]
+		}
+	}
 
 	// Add pagination metadata to response headers
 	c.Header("X-Total-Count", strconv.Itoa(total))
 	c.Header("X-Offset", strconv.Itoa(offset))
-	c.Header("X-Limit", strconv.Itoa(limit))
 
-	c.JSON(http.StatusOK, response)
+	respondPage(c, tasks, offset+len(tasks) < total, limit)
+}
+
+// GetWorkflow handles GET /workflow - describe the task status workflow
+// @Summary Get the task workflow
+// @Description Get the workflow states, their numeric status values (array index) and allowed transitions
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Success 200 {object} models.Workflow
+// @Router /workflow [get]
+func (h *TaskHandler) GetWorkflow(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    models.CurrentWorkflow(),
+	})
 }
 
 // HealthCheck handles GET /health - health check endpoint
@@ -452,13 +541,12 @@ func (h *TaskHandler) GetTasksPaginated(c *gin.Context) {
 // @Failure 500 {object} models.ErrorResponse
 // @Router /health [get]
 func (h *TaskHandler) HealthCheck(c *gin.Context) {
+	ctx := c.Request.Context()
+
 	// Check storage health if it implements HealthChecker
 	if healthChecker, ok := h.storage.(interfaces.HealthChecker); ok {
-		if err := healthChecker.HealthCheck(); err != nil {
-			c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
-				"Storage health check failed",
-				err,
-			))
+		if err := healthChecker.HealthCheck(ctx); err != nil {
+			respondStorageError(c, err, "Storage health check failed")
 			return
 		}
 	}
@@ -469,17 +557,41 @@ func (h *TaskHandler) HealthCheck(c *gin.Context) {
 
 // GetStorageStats handles GET /stats - get storage statistics
 // @Summary Get storage statistics
-// @Description Get statistics about the storage
+// @Description Get statistics about the storage; callers who only see their own tasks get the counts of those
 // @Tags stats
 // @Accept json
 // @Produce json
-// @Success 200 {object} storage.StorageStats
+// @Success 200 {object} models.StorageStats
 // @Failure 500 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
 // @Router /stats [get]
 func (h *TaskHandler) GetStorageStats(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// Callers restricted to their own tasks only count those
+	if owner := ownerScope(ctx); owner != "" {
+		stats, err := h.ownerStats(ctx, owner)
+		if err != nil {
+			respondStorageError(c, err, "Failed to get storage stats")
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"data":    stats,
+		})
+		return
+	}
+
 	// Check if storage supports stats
-	if memStorage, ok := h.storage.(*storage.MemoryStorage); ok {
-		stats := memStorage.GetStats()
+	if statsProvider, ok := h.storage.(interfaces.StatsProvider); ok {
+		stats, err := statsProvider.GetStats(ctx)
+		if err != nil {
+			respondStorageError(c, err, "Failed to get storage stats")
+			return
+		}
+
 		c.JSON(http.StatusOK, gin.H{
 			"success": true,
 			"data":    stats,
@@ -488,12 +600,9 @@ func (h *TaskHandler) GetStorageStats(c *gin.Context) {
 	}
 
 	// Fallback: basic stats
-	count, err := h.storage.Count()
+	count, err := h.storage.Count(ctx)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
-			"Failed to get task count",
-			err,
-		))
+		respondStorageError(c, err, "Failed to get task count")
 		return
 	}
 
@@ -507,3 +616,26 @@ func (h *TaskHandler) GetStorageStats(c *gin.Context) {
 		"data":    stats,
 	})
 }
+
+// ownerStats counts the tasks of owner like StatsProvider counts every task, without the
+// details of the storage
+func (h *TaskHandler) ownerStats(ctx context.Context, owner string) (map[string]interface{}, error) {
+	tasks, err := h.queryTasks(ctx, &models.TaskQuery{Filter: &models.TaskFilter{OwnerID: owner}})
+	if err != nil {
+		return nil, err
+	}
+
+	completed := 0
+	workflow := models.CurrentWorkflow()
+	for _, task := range tasks {
+		if workflow.IsTerminal(task.Status) {
+			completed++
+		}
+	}
+
+	return map[string]interface{}{
+		"total_tasks":      len(tasks),
+		"completed_tasks":  completed,
+		"incomplete_tasks": len(tasks) - completed,
+	}, nil
+}
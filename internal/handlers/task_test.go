@@ -2,14 +2,17 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
+	"task-api/internal/interfaces"
 	"task-api/internal/models"
 	"task-api/internal/storage"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -35,9 +38,15 @@ func setupTestHandler() (*TaskHandler, *gin.Engine) {
 		api.GET("/tasks/:id", handler.GetTaskByID)
 		api.POST("/tasks", handler.CreateTask)
 		api.PUT("/tasks/:id", handler.UpdateTask)
+		api.PATCH("/tasks/:id", handler.PatchTask)
 		api.DELETE("/tasks/:id", handler.DeleteTask)
 		api.GET("/tasks/status/:status", handler.GetTasksByStatus)
 		api.GET("/tasks/paginated", handler.GetTasksPaginated)
+		api.GET("/tasks/search", handler.SearchTasks)
+		api.POST("/tasks/bulk", handler.BulkTasks)
+		api.GET("/tasks/:id/history", handler.GetTaskHistory)
+		api.GET("/tasks/:id/versions/:version", handler.GetTaskVersion)
+		api.POST("/tasks/:id/versions/:version/revert", handler.RevertTask)
 		api.GET("/health", handler.HealthCheck)
 		api.GET("/stats", handler.GetStorageStats)
 	}
@@ -81,7 +90,7 @@ func createTestTask(tb testing.TB, handler *TaskHandler, name string, status mod
 		Status: status,
 	}
 
-	task, err := handler.storage.Create(req)
+	task, err := handler.storage.Create(context.Background(), req)
 	require.NoError(tb, err)
 
 	return task
@@ -125,7 +134,7 @@ func TestTaskHandler_GetAllTasks(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			// Clear storage and setup test data
 			// Clear storage for test setup (ignore error in test context)
-			_ = handler.storage.Clear()
+			_ = handler.storage.Clear(context.Background())
 			tt.setupTasks()
 
 			// Make request
@@ -254,6 +263,30 @@ func TestTaskHandler_CreateTask(t *testing.T) {
 				Name:   "Test Task",
 				Status: models.TaskStatus(99),
 			},
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedError:  true,
+		},
+		{
+			name:           "rich task",
+			request:        `{"name": "Rich", "description": "**bold**", "priority": 2, "due_date": "2030-01-02T15:04:05Z", "tags": ["a", "b"]}`,
+			expectedStatus: http.StatusCreated,
+			expectedError:  false,
+		},
+		{
+			name:           "invalid priority",
+			request:        `{"name": "Test", "priority": 7}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedError:  true,
+		},
+		{
+			name:           "invalid tag",
+			request:        `{"name": "Test", "tags": ["has space"]}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedError:  true,
+		},
+		{
+			name:           "invalid due date",
+			request:        `{"name": "Test", "due_date": "tomorrow"}`,
 			expectedStatus: http.StatusBadRequest,
 			expectedError:  true,
 		},
@@ -311,6 +344,213 @@ func TestTaskHandler_CreateTask(t *testing.T) {
 	}
 }
 
+func TestTaskHandler_Workflow(t *testing.T) {
+	workflow, err := models.NewWorkflow([]models.WorkflowState{
+		{Name: "todo", Transitions: []string{"in-progress"}},
+		{Name: "in-progress", Transitions: []string{"todo", "review"}},
+		{Name: "review", Transitions: []string{"in-progress", "done"}},
+		{Name: "done", Terminal: true},
+	})
+	require.NoError(t, err)
+	previous := models.CurrentWorkflow()
+	models.SetWorkflow(workflow)
+	defer models.SetWorkflow(previous)
+
+	_, router := setupTestHandler()
+	router.GET("/api/v1/workflow", (&TaskHandler{}).GetWorkflow)
+
+	send := func(method, url, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, url, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// Statuses can be sent by state name
+	w := send("POST", "/api/v1/tasks", `{"name": "Ship it", "status": "in-progress"}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var created models.TaskResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, "in-progress", created.Data.Status.String())
+
+	w = send("POST", "/api/v1/tasks", `{"name": "Bad", "status": "blocked"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Illegal transitions are conflicts
+	w = send("PUT", "/api/v1/tasks/"+created.Data.ID, `{"status": "done"}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	var errorResponse models.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errorResponse))
+	assert.Equal(t, "Status transition not allowed by workflow", errorResponse.Message)
+	assert.Contains(t, errorResponse.Error, `from "in-progress" to "done"`)
+
+	w = send("PUT", "/api/v1/tasks/"+created.Data.ID, `{"status": "review"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// The status endpoint accepts state names
+	w = send("GET", "/api/v1/tasks/status/review", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var list models.TaskListResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	assert.Equal(t, 1, list.Count)
+
+	w = send("GET", "/api/v1/tasks/status/blocked", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = send("GET", "/api/v1/workflow", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var described struct {
+		Data models.Workflow `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &described))
+	assert.Equal(t, []string{"todo", "in-progress", "review", "done"}, described.Data.Names())
+	assert.True(t, described.Data.States[3].Terminal)
+}
+
+func TestTaskHandler_ListFilters(t *testing.T) {
+	handler, router := setupTestHandler()
+	ctx := context.Background()
+
+	soon := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	later := time.Date(2031, 1, 1, 0, 0, 0, 0, time.UTC)
+	requests := []*models.CreateTaskRequest{
+		{Name: "Report", Priority: models.PriorityHigh, DueDate: &soon, Tags: []string{"work", "q1"}},
+		{Name: "Taxes", Priority: models.PriorityUrgent, DueDate: &later, Tags: []string{"home"}},
+		{Name: "Groceries", Status: models.TaskCompleted, Tags: []string{"home"}},
+		{Name: "Review", Priority: models.PriorityHigh, Tags: []string{"work"}},
+	}
+	for _, req := range requests {
+		_, err := handler.storage.Create(ctx, req)
+		require.NoError(t, err)
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedNames  []string
+	}{
+		{"no filter", "", http.StatusOK, []string{"Groceries", "Report", "Review", "Taxes"}},
+		{"priority by name", "?priority=high", http.StatusOK, []string{"Report", "Review"}},
+		{"priority by number", "?priority=3", http.StatusOK, []string{"Taxes"}},
+		{"single tag", "?tag=home", http.StatusOK, []string{"Groceries", "Taxes"}},
+		{"all tags must match", "?tag=work&tag=Q1", http.StatusOK, []string{"Report"}},
+		{"comma separated tags", "?tag=work,q1", http.StatusOK, []string{"Report"}},
+		{"due before", "?due_before=2030-06-01T00:00:00Z", http.StatusOK, []string{"Report"}},
+		{"due after", "?due_after=2030-06-01T00:00:00Z", http.StatusOK, []string{"Taxes"}},
+		{"combined", "?status=0&tag=home", http.StatusOK, []string{"Taxes"}},
+		{"name contains", "?name_contains=R", http.StatusOK, []string{"Report", "Review", "Groceries"}},
+		{"empty name contains", "?name_contains=", http.StatusBadRequest, nil},
+		{"created range inverted", "?created_after=2030-01-01T00:00:00Z&created_before=2029-01-01T00:00:00Z", http.StatusBadRequest, nil},
+		{"invalid priority", "?priority=whenever", http.StatusBadRequest, nil},
+		{"invalid due date", "?due_before=soon", http.StatusBadRequest, nil},
+		{"invalid status", "?status=9", http.StatusBadRequest, nil},
+	}
+
+	for _, tt := range tests {
+		for _, path := range []string{"/api/v1/tasks", "/api/v1/tasks/paginated"} {
+			t.Run(tt.name+" "+path, func(t *testing.T) {
+				req, _ := http.NewRequest("GET", path+tt.query, nil)
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+
+				assert.Equal(t, tt.expectedStatus, w.Code)
+				if tt.expectedStatus != http.StatusOK {
+					return
+				}
+
+				var response models.TaskListResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+				names := make([]string, 0, len(response.Data))
+				for _, task := range response.Data {
+					names = append(names, task.Name)
+				}
+				assert.ElementsMatch(t, tt.expectedNames, names)
+			})
+		}
+	}
+}
+
+func TestTaskHandler_ListQuery(t *testing.T) {
+	handler, router := setupTestHandler()
+	for _, name := range []string{"Bravo", "alpha", "Charlie"} {
+		createTestTask(t, handler, name, models.TaskIncomplete)
+	}
+
+	get := func(t *testing.T, query string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", "/api/v1/tasks"+query, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	sorts := []struct {
+		name          string
+		query         string
+		expectedNames []string
+	}{
+		{"creation order by default", "", []string{"Bravo", "alpha", "Charlie"}},
+		{"ascending name", "?sort=name", []string{"Bravo", "Charlie", "alpha"}},
+		{"descending name", "?sort=-name", []string{"alpha", "Charlie", "Bravo"}},
+		{"newest first", "?sort=-created_at", []string{"Charlie", "alpha", "Bravo"}},
+		{"sort with filter", "?sort=-name&name_contains=a", []string{"alpha", "Charlie", "Bravo"}},
+	}
+	for _, tt := range sorts {
+		t.Run(tt.name, func(t *testing.T) {
+			w := get(t, tt.query)
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+			var response models.TaskListResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			names := make([]string, 0, len(response.Data))
+			for _, task := range response.Data {
+				names = append(names, task.Name)
+			}
+			assert.Equal(t, tt.expectedNames, names)
+		})
+	}
+
+	t.Run("sparse fieldset", func(t *testing.T) {
+		w := get(t, "?fields=id,name,due_date&sort=name")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var response struct {
+			Data  []map[string]interface{} `json:"data"`
+			Count int                      `json:"count"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.Len(t, response.Data, 3)
+		assert.Equal(t, 3, response.Count)
+		for _, data := range response.Data {
+			assert.Len(t, data, 3)
+			assert.Contains(t, data, "id")
+			assert.Contains(t, data, "due_date")
+		}
+		assert.Equal(t, "Bravo", response.Data[0]["name"])
+		assert.Nil(t, response.Data[0]["due_date"])
+	})
+
+	invalid := []struct {
+		name  string
+		query string
+	}{
+		{"unknown sort field", "?sort=owner"},
+		{"empty sort field", "?sort=name,"},
+		{"duplicate sort field", "?sort=name,-name"},
+		{"unknown field", "?fields=id,secret"},
+		{"empty fieldset", "?fields="},
+	}
+	for _, tt := range invalid {
+		t.Run("rejects "+tt.name, func(t *testing.T) {
+			w := get(t, tt.query)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Contains(t, w.Body.String(), "Invalid query parameters")
+		})
+	}
+}
+
 func TestTaskHandler_UpdateTask(t *testing.T) {
 	handler, router := setupTestHandler()
 
@@ -365,7 +605,7 @@ func TestTaskHandler_UpdateTask(t *testing.T) {
 			name:           "empty update",
 			taskID:         task.ID,
 			request:        models.UpdateTaskRequest{},
-			expectedStatus: http.StatusBadRequest,
+			expectedStatus: http.StatusUnprocessableEntity,
 			expectedError:  true,
 		},
 		{
@@ -374,7 +614,7 @@ func TestTaskHandler_UpdateTask(t *testing.T) {
 			request: models.UpdateTaskRequest{
 				Name: stringPtr(""),
 			},
-			expectedStatus: http.StatusBadRequest,
+			expectedStatus: http.StatusUnprocessableEntity,
 			expectedError:  true,
 		},
 		{
@@ -383,7 +623,7 @@ func TestTaskHandler_UpdateTask(t *testing.T) {
 			request: models.UpdateTaskRequest{
 				Status: taskStatusPtr(models.TaskStatus(99)),
 			},
-			expectedStatus: http.StatusBadRequest,
+			expectedStatus: http.StatusUnprocessableEntity,
 			expectedError:  true,
 		},
 	}
@@ -472,7 +712,7 @@ func TestTaskHandler_DeleteTask(t *testing.T) {
 				assert.Nil(t, response.Data)
 
 				// Verify task is actually deleted
-				_, err = handler.storage.GetByID(tt.taskID)
+				_, err = handler.storage.GetByID(context.Background(), tt.taskID)
 				assert.Error(t, err)
 			}
 		})
@@ -728,6 +968,156 @@ func TestTaskHandler_GetStorageStats(t *testing.T) {
 	assert.Equal(t, "sharded_memory", data["storage_type"])
 }
 
+// basicStorage exposes only the TaskStorage contract, hiding every optional capability
+type basicStorage struct {
+	interfaces.TaskStorage
+}
+
+func TestTaskHandler_CapabilityFallback(t *testing.T) {
+	handler := NewTaskHandler(basicStorage{storage.NewMemoryStorage(1000)})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/api/v1/tasks/status/:status", handler.GetTasksByStatus)
+	router.GET("/api/v1/tasks/paginated", handler.GetTasksPaginated)
+	router.GET("/api/v1/stats", handler.GetStorageStats)
+
+	createTestTask(t, handler, "Task 1", models.TaskIncomplete)
+	createTestTask(t, handler, "Task 2", models.TaskCompleted)
+	createTestTask(t, handler, "Task 3", models.TaskCompleted)
+
+	t.Run("status falls back to filtering all tasks", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/v1/tasks/status/1", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response models.TaskListResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, 2, response.Count)
+	})
+
+	t.Run("pagination falls back to slicing all tasks", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/v1/tasks/paginated?offset=1&limit=5", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "3", w.Header().Get("X-Total-Count"))
+
+		var response models.TaskListResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, 2, response.Count)
+	})
+
+	t.Run("stats fall back to basic counts", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/v1/stats", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		data := response["data"].(map[string]interface{})
+		assert.Equal(t, float64(3), data["total_tasks"])
+		assert.Equal(t, "unknown", data["storage_type"])
+	})
+}
+
+func TestTaskHandler_CapabilityDiscovery(t *testing.T) {
+	// Any backend implementing the capability interfaces gets the fast paths,
+	// not just *storage.MemoryStorage
+	fileStorage, err := storage.NewFileStorage(storage.FileStorageConfig{DataDir: t.TempDir()})
+	require.NoError(t, err)
+	defer fileStorage.Close()
+
+	handler := NewTaskHandler(fileStorage)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/api/v1/stats", handler.GetStorageStats)
+
+	createTestTask(t, handler, "Task 1", models.TaskCompleted)
+
+	req, _ := http.NewRequest("GET", "/api/v1/stats", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	data := response["data"].(map[string]interface{})
+	assert.Equal(t, "file", data["storage_type"])
+	assert.Equal(t, float64(1), data["completed_tasks"])
+}
+
+func TestTaskHandler_ContextErrors(t *testing.T) {
+	handler, router := setupTestHandler()
+	task := createTestTask(t, handler, "Task 1", models.TaskIncomplete)
+
+	tests := []struct {
+		name           string
+		ctx            func() (context.Context, context.CancelFunc)
+		method         string
+		url            string
+		expectedStatus int
+	}{
+		{
+			name: "deadline exceeded returns gateway timeout",
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+			},
+			method:         "GET",
+			url:            "/api/v1/tasks",
+			expectedStatus: http.StatusGatewayTimeout,
+		},
+		{
+			name: "client disconnect returns client closed request",
+			ctx: func() (context.Context, context.CancelFunc) {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				return ctx, cancel
+			},
+			method:         "GET",
+			url:            "/api/v1/tasks/" + task.ID,
+			expectedStatus: StatusClientClosedRequest,
+		},
+		{
+			name: "cancelled delete leaves the task in place",
+			ctx: func() (context.Context, context.CancelFunc) {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				return ctx, cancel
+			},
+			method:         "DELETE",
+			url:            "/api/v1/tasks/" + task.ID,
+			expectedStatus: StatusClientClosedRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := tt.ctx()
+			defer cancel()
+
+			req, _ := http.NewRequestWithContext(ctx, tt.method, tt.url, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			var response models.ErrorResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.False(t, response.Success)
+		})
+	}
+
+	_, err := handler.storage.GetByID(context.Background(), task.ID)
+	assert.NoError(t, err)
+}
+
 // Helper functions for tests
 func stringPtr(s string) *string {
 	return &s
@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"task-api/internal/models"
+	"task-api/internal/webhooks"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WebhookHandler handles HTTP requests for webhook subscriptions and their delivery logs
+type WebhookHandler struct {
+	dispatcher *webhooks.Dispatcher // Webhook registry and delivery (nil = webhooks not configured)
+}
+
+// NewWebhookHandler creates a new WebhookHandler instance (Factory Pattern)
+// A nil dispatcher makes every webhook endpoint respond 501 Not Implemented.
+func NewWebhookHandler(dispatcher *webhooks.Dispatcher) *WebhookHandler {
+	return &WebhookHandler{
+		dispatcher: dispatcher,
+	}
+}
+
+// store returns the webhook registry, answering 501 when webhooks are not configured
+func (h *WebhookHandler) store(c *gin.Context) (*webhooks.Store, bool) {
+	if h.dispatcher == nil {
+		respondError(c, http.StatusNotImplemented, "Webhooks are not configured", nil)
+		return nil, false
+	}
+	return h.dispatcher.Store(), true
+}
+
+// ListWebhooks handles GET /webhooks - list webhook subscriptions
+// @Summary List webhooks
+// @Description Get every webhook subscription, oldest first. Secrets are never listed.
+// @Tags webhooks
+// @Produce json
+// @Success 200 {object} models.WebhookListResponse
+// @Failure 501 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /webhooks [get]
+func (h *WebhookHandler) ListWebhooks(c *gin.Context) {
+	store, ok := h.store(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewWebhookListResponse(store.List()))
+}
+
+// CreateWebhook handles POST /webhooks - register a webhook
+// @Summary Create a webhook
+// @Description Register an endpoint to receive a signed POST for every task change of the listed event types
+// @Description (all types if none are listed). The response is the only one that includes the signing secret.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param webhook body models.CreateWebhookRequest true "Webhook data"
+// @Success 201 {object} models.WebhookResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Failure 501 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /webhooks [post]
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	store, ok := h.store(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request data", err)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		respondError(c, http.StatusBadRequest, "Validation failed", err)
+		return
+	}
+
+	webhook, err := store.Create(&req)
+	if err != nil {
+		respondStorageError(c, err, "Failed to create webhook")
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.NewWebhookResponse(webhook, "Webhook created successfully"))
+}
+
+// GetWebhook handles GET /webhooks/:id - get a webhook
+// @Summary Get a webhook
+// @Description Get a webhook subscription by its ID, without its secret
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Success 200 {object} models.WebhookResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 501 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /webhooks/{id} [get]
+func (h *WebhookHandler) GetWebhook(c *gin.Context) {
+	store, ok := h.store(c)
+	if !ok {
+		return
+	}
+
+	webhook, err := store.Get(c.Param("id"))
+	if err != nil {
+		respondStorageError(c, err, "Failed to retrieve webhook")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewWebhookResponse(webhook, "Webhook retrieved successfully"))
+}
+
+// UpdateWebhook handles PATCH /webhooks/:id - update a webhook
+// @Summary Update a webhook
+// @Description Change the fields present in the request. Set "active" to pause or resume deliveries, and
+// @Description "secret" to rotate the signing secret (an empty string generates one); the response then includes it.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Param webhook body models.UpdateWebhookRequest true "Webhook update data"
+// @Success 200 {object} models.WebhookResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Failure 501 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /webhooks/{id} [patch]
+func (h *WebhookHandler) UpdateWebhook(c *gin.Context) {
+	store, ok := h.store(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request data", err)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		respondError(c, http.StatusBadRequest, "Validation failed", err)
+		return
+	}
+
+	webhook, err := store.Update(c.Param("id"), &req)
+	if err != nil {
+		respondStorageError(c, err, "Failed to update webhook")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewWebhookResponse(webhook, "Webhook updated successfully"))
+}
+
+// DeleteWebhook handles DELETE /webhooks/:id - delete a webhook
+// @Summary Delete a webhook
+// @Description Delete a webhook subscription and its delivery log; deliveries waiting for a retry are dropped
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Success 200 {object} models.WebhookResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Failure 501 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /webhooks/{id} [delete]
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	store, ok := h.store(c)
+	if !ok {
+		return
+	}
+
+	if err := store.Delete(c.Param("id")); err != nil {
+		respondStorageError(c, err, "Failed to delete webhook")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewWebhookResponse(nil, "Webhook deleted successfully"))
+}
+
+// ListDeliveries handles GET /webhooks/:id/deliveries - get a webhook's delivery log
+// @Summary List webhook deliveries
+// @Description Get the most recent deliveries of a webhook, newest first, with every attempt made.
+// @Description Dead deliveries failed every attempt and can be sent again with the retry endpoint.
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Param status query string false "Only deliveries in this state (pending, retrying, succeeded, dead)"
+// @Success 200 {object} models.WebhookDeliveryListResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 501 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	store, ok := h.store(c)
+	if !ok {
+		return
+	}
+
+	status := models.WebhookDeliveryStatus(c.Query("status"))
+	switch status {
+	case "", models.DeliveryPending, models.DeliveryRetrying, models.DeliverySucceeded, models.DeliveryDead:
+	default:
+		respondError(c, http.StatusBadRequest, "Invalid query parameters", fmt.Errorf("unknown delivery status %q", status))
+		return
+	}
+
+	deliveries, err := store.Deliveries(c.Param("id"), status)
+	if err != nil {
+		respondStorageError(c, err, "Failed to retrieve deliveries")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewWebhookDeliveryListResponse(deliveries))
+}
+
+// RetryDelivery handles POST /webhooks/:id/deliveries/:delivery_id/retry - send a dead delivery again
+// @Summary Retry a dead delivery
+// @Description Send a dead delivery once more. It goes back to pending; if the attempt fails it is dead again.
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Param delivery_id path string true "Delivery ID"
+// @Success 202 {object} models.WebhookDeliveryResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 501 {object} models.ErrorResponse
+// @Failure 503 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /webhooks/{id}/deliveries/{delivery_id}/retry [post]
+func (h *WebhookHandler) RetryDelivery(c *gin.Context) {
+	if _, ok := h.store(c); !ok {
+		return
+	}
+
+	delivery, err := h.dispatcher.Redeliver(c.Param("id"), c.Param("delivery_id"))
+	if err != nil {
+		respondStorageError(c, err, "Failed to retry delivery")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, models.NewWebhookDeliveryResponse(delivery, "Delivery queued"))
+}
@@ -0,0 +1,273 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"task-api/internal/events"
+	"task-api/internal/models"
+	"task-api/internal/storage"
+	"task-api/internal/webhooks"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupWebhookRouter serves the webhook endpoints of a dispatcher fed by a memory storage's changes
+func setupWebhookRouter(t *testing.T) (*gin.Engine, *storage.MemoryStorage) {
+	t.Helper()
+
+	store, err := webhooks.NewStore("", 0)
+	require.NoError(t, err)
+
+	bus := events.NewBus(events.BusConfig{})
+	memory := storage.NewMemoryStorage(100)
+	memory.SetEventPublisher(bus)
+
+	dispatcher := webhooks.NewDispatcher(store, webhooks.DispatcherConfig{
+		MaxAttempts:    2,
+		InitialBackoff: 10 * time.Millisecond,
+	})
+	dispatcher.Start(bus)
+	t.Cleanup(func() {
+		bus.Close()
+		dispatcher.Close()
+	})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	registerWebhookRoutes(router, NewWebhookHandler(dispatcher))
+	return router, memory
+}
+
+// registerWebhookRoutes mounts the webhook endpoints as the API does
+func registerWebhookRoutes(router *gin.Engine, handler *WebhookHandler) {
+	router.GET("/webhooks", handler.ListWebhooks)
+	router.POST("/webhooks", handler.CreateWebhook)
+	router.GET("/webhooks/:id", handler.GetWebhook)
+	router.PATCH("/webhooks/:id", handler.UpdateWebhook)
+	router.DELETE("/webhooks/:id", handler.DeleteWebhook)
+	router.GET("/webhooks/:id/deliveries", handler.ListDeliveries)
+	router.POST("/webhooks/:id/deliveries/:delivery_id/retry", handler.RetryDelivery)
+}
+
+// createWebhook registers a webhook through the API
+func createWebhook(t *testing.T, router *gin.Engine, req models.CreateWebhookRequest) *models.Webhook {
+	t.Helper()
+
+	w := sendWithHeaders(router, "POST", "/webhooks", req, nil)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	var response models.WebhookResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	return response.Data
+}
+
+// listDeliveries fetches a webhook's delivery log through the API
+func listDeliveries(t *testing.T, router *gin.Engine, webhookID, status string) []*models.WebhookDelivery {
+	t.Helper()
+
+	path := "/webhooks/" + webhookID + "/deliveries"
+	if status != "" {
+		path += "?status=" + status
+	}
+	w := sendWithHeaders(router, "GET", path, nil, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var response models.WebhookDeliveryListResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	return response.Data
+}
+
+func TestWebhookHandler_CRUD(t *testing.T) {
+	router, _ := setupWebhookRouter(t)
+
+	created := createWebhook(t, router, models.CreateWebhookRequest{
+		URL:         "https://hooks.example.com/tasks",
+		Events:      []models.TaskEventType{models.TaskCreated, models.TaskDeleted},
+		Description: "Chat notifications",
+	})
+	assert.NotEmpty(t, created.ID)
+	assert.NotEmpty(t, created.Secret, "the secret is returned on creation")
+	assert.True(t, created.Active)
+
+	t.Run("get hides the secret", func(t *testing.T) {
+		w := sendWithHeaders(router, "GET", "/webhooks/"+created.ID, nil, nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), created.Secret)
+		assert.Contains(t, w.Body.String(), "Chat notifications")
+	})
+
+	t.Run("list", func(t *testing.T) {
+		w := sendWithHeaders(router, "GET", "/webhooks", nil, nil)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response models.WebhookListResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, 1, response.Count)
+		assert.Empty(t, response.Data[0].Secret)
+	})
+
+	t.Run("update", func(t *testing.T) {
+		w := sendWithHeaders(router, "PATCH", "/webhooks/"+created.ID, map[string]interface{}{"active": false}, nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var response models.WebhookResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.False(t, response.Data.Active)
+		assert.Equal(t, created.URL, response.Data.URL)
+		assert.Empty(t, response.Data.Secret)
+	})
+
+	t.Run("rotate secret", func(t *testing.T) {
+		w := sendWithHeaders(router, "PATCH", "/webhooks/"+created.ID, map[string]interface{}{"secret": ""}, nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var response models.WebhookResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.NotEmpty(t, response.Data.Secret)
+		assert.NotEqual(t, created.Secret, response.Data.Secret)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		w := sendWithHeaders(router, "DELETE", "/webhooks/"+created.ID, nil, nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		for _, path := range []string{"/webhooks/" + created.ID, "/webhooks/" + created.ID + "/deliveries"} {
+			w = sendWithHeaders(router, "GET", path, nil, nil)
+			assert.Equal(t, http.StatusNotFound, w.Code, path)
+			assert.Contains(t, w.Body.String(), "Webhook not found")
+		}
+		w = sendWithHeaders(router, "DELETE", "/webhooks/"+created.ID, nil, nil)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestWebhookHandler_Validation(t *testing.T) {
+	router, _ := setupWebhookRouter(t)
+
+	invalid := []struct {
+		name string
+		body map[string]interface{}
+	}{
+		{"missing URL", map[string]interface{}{}},
+		{"relative URL", map[string]interface{}{"url": "/hooks"}},
+		{"unsupported scheme", map[string]interface{}{"url": "ftp://hooks.example.com"}},
+		{"unknown event type", map[string]interface{}{"url": "https://hooks.example.com", "events": []string{"archived"}}},
+		{"repeated event type", map[string]interface{}{"url": "https://hooks.example.com", "events": []string{"created", "created"}}},
+		{"short secret", map[string]interface{}{"url": "https://hooks.example.com", "secret": "hunter2"}},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			w := sendWithHeaders(router, "POST", "/webhooks", tt.body, nil)
+			assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+		})
+	}
+
+	created := createWebhook(t, router, models.CreateWebhookRequest{URL: "https://hooks.example.com"})
+
+	t.Run("empty update", func(t *testing.T) {
+		w := sendWithHeaders(router, "PATCH", "/webhooks/"+created.ID, map[string]interface{}{}, nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("unknown delivery status", func(t *testing.T) {
+		w := sendWithHeaders(router, "GET", "/webhooks/"+created.ID+"/deliveries?status=lost", nil, nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestWebhookHandler_Deliveries(t *testing.T) {
+	router, memory := setupWebhookRouter(t)
+	ctx := context.Background()
+
+	// The receiver fails until it is told to recover
+	var healthy atomic.Bool
+	var received atomic.Int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		received.Add(1)
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	webhook := createWebhook(t, router, models.CreateWebhookRequest{URL: receiver.URL})
+	_, err := memory.Create(ctx, &models.CreateTaskRequest{Name: "Notify me"})
+	require.NoError(t, err)
+
+	// Two failed attempts make the delivery dead
+	var dead []*models.WebhookDelivery
+	require.Eventually(t, func() bool {
+		dead = listDeliveries(t, router, webhook.ID, "dead")
+		return len(dead) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Len(t, dead[0].Attempts, 2)
+	assert.Equal(t, http.StatusBadGateway, dead[0].Attempts[1].StatusCode)
+	assert.Equal(t, models.TaskCreated, dead[0].Event.Type)
+	assert.Equal(t, "Notify me", dead[0].Event.Task.Name)
+	assert.EqualValues(t, 2, received.Load())
+
+	retryPath := "/webhooks/" + webhook.ID + "/deliveries/" + dead[0].ID + "/retry"
+
+	t.Run("retry a dead delivery", func(t *testing.T) {
+		healthy.Store(true)
+		w := sendWithHeaders(router, "POST", retryPath, nil, nil)
+		require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+
+		require.Eventually(t, func() bool {
+			return len(listDeliveries(t, router, webhook.ID, "succeeded")) == 1
+		}, 5*time.Second, 10*time.Millisecond)
+
+		all := listDeliveries(t, router, webhook.ID, "")
+		require.Len(t, all, 1)
+		assert.Len(t, all[0].Attempts, 3)
+		assert.Equal(t, http.StatusNoContent, all[0].Attempts[2].StatusCode)
+	})
+
+	t.Run("delivered deliveries cannot be retried", func(t *testing.T) {
+		w := sendWithHeaders(router, "POST", retryPath, nil, nil)
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("unknown delivery", func(t *testing.T) {
+		w := sendWithHeaders(router, "POST", "/webhooks/"+webhook.ID+"/deliveries/unknown/retry", nil, nil)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Contains(t, w.Body.String(), "Delivery not found")
+	})
+
+	t.Run("newest first", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			_, err := memory.Create(ctx, &models.CreateTaskRequest{Name: "Task " + strconv.Itoa(i)})
+			require.NoError(t, err)
+		}
+		require.Eventually(t, func() bool {
+			return len(listDeliveries(t, router, webhook.ID, "succeeded")) == 3
+		}, 5*time.Second, 10*time.Millisecond)
+
+		deliveries := listDeliveries(t, router, webhook.ID, "")
+		assert.Equal(t, "Task 1", deliveries[0].Event.Task.Name)
+		assert.Equal(t, "Notify me", deliveries[2].Event.Task.Name)
+	})
+}
+
+func TestWebhookHandler_NotConfigured(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	registerWebhookRoutes(router, NewWebhookHandler(nil))
+
+	for _, path := range []string{"/webhooks", "/webhooks/x", "/webhooks/x/deliveries"} {
+		w := sendWithHeaders(router, "GET", path, nil, nil)
+		assert.Equal(t, http.StatusNotImplemented, w.Code, path)
+	}
+	w := sendWithHeaders(router, "POST", "/webhooks/x/deliveries/y/retry", nil, nil)
+	assert.Equal(t, http.StatusNotImplemented, w.Code)
+}
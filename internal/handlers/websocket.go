@@ -0,0 +1,241 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"task-api/internal/events"
+	"task-api/internal/middleware"
+	"task-api/internal/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+)
+
+// WebSocket connection limits
+const (
+	maxSocketMessageBytes = 8 << 10          // Largest client message accepted
+	socketWriteTimeout    = 10 * time.Second // Deadline for each write to the client
+)
+
+// pingCodec sends empty ping control frames; clients answer them without involving the application
+var pingCodec = websocket.Codec{
+	Marshal: func(interface{}) ([]byte, byte, error) { return nil, websocket.PingFrame, nil },
+}
+
+// WebSocketHandler serves filtered task change subscriptions over WebSocket connections
+type WebSocketHandler struct {
+	bus            *events.Bus   // Source of change events (nil if the storage does not report changes)
+	pingInterval   time.Duration // Time between ping frames
+	allowedOrigins []string      // Origins browsers may connect from (empty = any)
+}
+
+// NewWebSocketHandler creates a new WebSocketHandler instance (Factory Pattern)
+// A nil bus makes the endpoint respond 501 Not Implemented.
+func NewWebSocketHandler(bus *events.Bus, pingInterval time.Duration, allowedOrigins []string) *WebSocketHandler {
+	if pingInterval <= 0 {
+		pingInterval = DefaultHeartbeatInterval
+	}
+
+	return &WebSocketHandler{
+		bus:            bus,
+		pingInterval:   pingInterval,
+		allowedOrigins: allowedOrigins,
+	}
+}
+
+// Connect handles GET /ws - subscribe to task changes over a WebSocket connection
+// @Summary Subscribe to task changes
+// @Description Upgrade to a WebSocket connection carrying JSON messages. Send {"type":"subscribe","id":"mine","filter":"status=completed&tag=urgent"}
+// @Description to receive {"type":"event","subscriptions":["mine"],"event":{...}} for every change to a task matching the filter before
+// @Description or after the change; the filter takes the list filter parameters as a query string. Send {"type":"unsubscribe","id":"mine"}
+// @Description to stop and {"type":"ping"} to get a pong. The server sends ping frames on idle connections and closes connections
+// @Description that cannot keep up with the events.
+// @Tags tasks
+// @Success 101 "Switching Protocols"
+// @Failure 403 "Origin not allowed"
+// @Failure 501 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /ws [get]
+func (h *WebSocketHandler) Connect(c *gin.Context) {
+	if h.bus == nil {
+		respondError(c, http.StatusNotImplemented, "Change feed not supported by the storage backend", nil)
+		return
+	}
+
+	server := websocket.Server{Handshake: h.checkOrigin, Handler: h.serve}
+	server.ServeHTTP(c.Writer, c.Request)
+}
+
+// checkOrigin accepts clients without an Origin header (non-browser) and browsers on an allowed origin
+func (h *WebSocketHandler) checkOrigin(_ *websocket.Config, req *http.Request) error {
+	origin := req.Header.Get("Origin")
+	if origin == "" || len(h.allowedOrigins) == 0 || middleware.IsOriginAllowed(origin, h.allowedOrigins) {
+		return nil
+	}
+	return fmt.Errorf("origin %s not allowed", origin)
+}
+
+// socketInput is one message read from the client, or the error reading it
+type socketInput struct {
+	request models.SocketRequest
+	err     error
+}
+
+// socketSession holds the state of one WebSocket connection
+// Only the connection's main loop touches it, so it needs no locking.
+type socketSession struct {
+	conn    *websocket.Conn               // Client connection
+	owner   string                        // Owner every subscription is restricted to ("" = none)
+	filters map[string]*models.TaskFilter // Filters of the active subscriptions by ID
+}
+
+// serve runs one WebSocket connection until the client leaves, falls behind or a write fails
+func (h *WebSocketHandler) serve(conn *websocket.Conn) {
+	defer conn.Close()
+
+	conn.MaxPayloadBytes = maxSocketMessageBytes
+	// The hijacked connection keeps the server's read and write timeouts
+	_ = conn.SetDeadline(time.Time{})
+
+	sub, _, _ := h.bus.Subscribe("")
+	defer sub.Close()
+
+	session := &socketSession{
+		conn:    conn,
+		owner:   ownerScope(conn.Request().Context()),
+		filters: make(map[string]*models.TaskFilter),
+	}
+
+	inputs := make(chan socketInput)
+	done := make(chan struct{})
+	defer close(done)
+	go readSocket(conn, inputs, done)
+
+	ticker := time.NewTicker(h.pingInterval)
+	defer ticker.Stop()
+
+	for {
+		var err error
+		select {
+		case input, ok := <-inputs:
+			if !ok {
+				return
+			}
+			err = session.send(session.handle(input))
+		case event := <-sub.Events():
+			if ids := session.matching(&event); len(ids) > 0 {
+				err = session.send(models.SocketMessage{Type: models.SocketEvent, Subscriptions: ids, Event: &event})
+			}
+		case <-sub.Done():
+			// Best effort: the client may be the reason the subscription ended
+			_ = session.send(models.SocketMessage{Type: models.SocketError, Error: "event stream ended, reconnect and subscribe again"})
+			return
+		case <-ticker.C:
+			err = session.write(pingCodec, nil)
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// readSocket decodes client messages into inputs until the connection fails or done is closed
+func readSocket(conn *websocket.Conn, inputs chan<- socketInput, done <-chan struct{}) {
+	defer close(inputs)
+
+	for {
+		var input socketInput
+		var data []byte
+		if err := websocket.Message.Receive(conn, &data); errors.Is(err, websocket.ErrFrameTooLarge) {
+			input.err = fmt.Errorf("message exceeds %d bytes", maxSocketMessageBytes)
+		} else if err != nil {
+			return
+		} else if err := json.Unmarshal(data, &input.request); err != nil {
+			input.err = fmt.Errorf("invalid message: %w", err)
+		}
+
+		select {
+		case inputs <- input:
+		case <-done:
+			return
+		}
+	}
+}
+
+// handle applies one client message and returns the reply
+func (s *socketSession) handle(input socketInput) models.SocketMessage {
+	if input.err != nil {
+		return socketError("", input.err)
+	}
+
+	req := input.request
+	switch req.Type {
+	case models.SocketSubscribe:
+		if req.ID == "" || len(req.ID) > models.MaxSubscriptionIDLength {
+			return socketError(req.ID, fmt.Errorf("id must be 1 to %d characters", models.MaxSubscriptionIDLength))
+		}
+		if len(req.Filter) > models.MaxFilterExpressionBytes {
+			return socketError(req.ID, fmt.Errorf("filter cannot exceed %d characters", models.MaxFilterExpressionBytes))
+		}
+		if _, exists := s.filters[req.ID]; !exists && len(s.filters) >= models.MaxSocketSubscriptions {
+			return socketError(req.ID, fmt.Errorf("at most %d subscriptions per connection", models.MaxSocketSubscriptions))
+		}
+
+		filter, err := models.ParseFilterExpression(req.Filter)
+		if err != nil {
+			return socketError(req.ID, err)
+		}
+		// Subscribing again with the same ID replaces the filter
+		filter.OwnerID = s.owner
+		s.filters[req.ID] = filter
+		return models.SocketMessage{Type: models.SocketSubscribed, ID: req.ID}
+
+	case models.SocketUnsubscribe:
+		if _, exists := s.filters[req.ID]; !exists {
+			return socketError(req.ID, fmt.Errorf("unknown subscription %q", req.ID))
+		}
+		delete(s.filters, req.ID)
+		return models.SocketMessage{Type: models.SocketUnsubscribed, ID: req.ID}
+
+	case models.SocketPing:
+		return models.SocketMessage{Type: models.SocketPong}
+
+	default:
+		return socketError(req.ID, fmt.Errorf("unknown message type %q", req.Type))
+	}
+}
+
+// matching returns the sorted IDs of the subscriptions the event concerns
+func (s *socketSession) matching(event *models.TaskEvent) []string {
+	var ids []string
+	for id, filter := range s.filters {
+		if event.Concerns(filter) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// send writes a JSON message to the client
+func (s *socketSession) send(message models.SocketMessage) error {
+	return s.write(websocket.JSON, message)
+}
+
+// write sends a frame with the given codec, giving up after socketWriteTimeout
+func (s *socketSession) write(codec websocket.Codec, v interface{}) error {
+	if err := s.conn.SetWriteDeadline(time.Now().Add(socketWriteTimeout)); err != nil {
+		return err
+	}
+	return codec.Send(s.conn, v)
+}
+
+// socketError builds an error reply
+func socketError(id string, err error) models.SocketMessage {
+	return models.SocketMessage{Type: models.SocketError, ID: id, Error: err.Error()}
+}
@@ -0,0 +1,288 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"task-api/internal/events"
+	"task-api/internal/models"
+	"task-api/internal/storage"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+)
+
+// setupSocketServer starts a server carrying the changes of a memory storage over WebSockets
+func setupSocketServer(t *testing.T, pingInterval time.Duration, allowedOrigins []string) (*storage.MemoryStorage, *events.Bus, *httptest.Server) {
+	t.Helper()
+
+	bus := events.NewBus(events.BusConfig{})
+	memory := storage.NewMemoryStorage(100)
+	memory.SetEventPublisher(bus)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/ws", NewWebSocketHandler(bus, pingInterval, allowedOrigins).Connect)
+
+	server := httptest.NewServer(router)
+	t.Cleanup(func() {
+		bus.Close()
+		server.Close()
+	})
+	return memory, bus, server
+}
+
+// dialSocket opens a WebSocket connection to the server from the given origin
+func dialSocket(t *testing.T, server *httptest.Server, origin string) (*websocket.Conn, error) {
+	t.Helper()
+
+	conn, err := websocket.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws", "", origin)
+	if err == nil {
+		t.Cleanup(func() { conn.Close() })
+	}
+	return conn, err
+}
+
+// openSocket opens a WebSocket connection and waits for its subscription to the bus
+func openSocket(t *testing.T, server *httptest.Server, bus *events.Bus) *websocket.Conn {
+	t.Helper()
+
+	subscribers := bus.Subscribers()
+	conn, err := dialSocket(t, server, server.URL)
+	require.NoError(t, err)
+	waitForSubscribers(t, bus, subscribers+1)
+	return conn
+}
+
+// exchange sends a request and reads the reply
+func exchange(t *testing.T, conn *websocket.Conn, request models.SocketRequest) models.SocketMessage {
+	t.Helper()
+
+	require.NoError(t, websocket.JSON.Send(conn, request))
+	return receiveMessage(t, conn)
+}
+
+// receiveMessage reads the next message from the server
+func receiveMessage(t *testing.T, conn *websocket.Conn) models.SocketMessage {
+	t.Helper()
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	var message models.SocketMessage
+	require.NoError(t, websocket.JSON.Receive(conn, &message))
+	return message
+}
+
+func TestWebSocketHandler_Subscriptions(t *testing.T) {
+	memory, bus, server := setupSocketServer(t, time.Hour, nil)
+	conn := openSocket(t, server, bus)
+	ctx := context.Background()
+
+	reply := exchange(t, conn, models.SocketRequest{Type: models.SocketSubscribe, ID: "done", Filter: "status=completed"})
+	assert.Equal(t, models.SocketMessage{Type: models.SocketSubscribed, ID: "done"}, reply)
+	reply = exchange(t, conn, models.SocketRequest{Type: models.SocketSubscribe, ID: "all"})
+	assert.Equal(t, models.SocketSubscribed, reply.Type)
+
+	completed := models.TaskCompleted
+	task, err := memory.Create(ctx, &models.CreateTaskRequest{Name: "Watched"})
+	require.NoError(t, err)
+	_, err = memory.Update(ctx, task.ID, &models.UpdateTaskRequest{Status: &completed})
+	require.NoError(t, err)
+
+	created := receiveMessage(t, conn)
+	assert.Equal(t, models.SocketEvent, created.Type)
+	assert.Equal(t, []string{"all"}, created.Subscriptions)
+	require.NotNil(t, created.Event)
+	assert.Equal(t, models.TaskCreated, created.Event.Type)
+	assert.Equal(t, "Watched", created.Event.Task.Name)
+
+	updated := receiveMessage(t, conn)
+	assert.Equal(t, []string{"all", "done"}, updated.Subscriptions)
+	assert.Equal(t, models.TaskUpdated, updated.Event.Type)
+	assert.Equal(t, 2, updated.Event.Version)
+
+	t.Run("unsubscribe", func(t *testing.T) {
+		reply := exchange(t, conn, models.SocketRequest{Type: models.SocketUnsubscribe, ID: "all"})
+		assert.Equal(t, models.SocketMessage{Type: models.SocketUnsubscribed, ID: "all"}, reply)
+
+		reply = exchange(t, conn, models.SocketRequest{Type: models.SocketUnsubscribe, ID: "all"})
+		assert.Equal(t, models.SocketError, reply.Type)
+		assert.Contains(t, reply.Error, "unknown subscription")
+	})
+
+	t.Run("tasks leaving the filter and deletes are delivered", func(t *testing.T) {
+		other, err := memory.Create(ctx, &models.CreateTaskRequest{Name: "Ignored"})
+		require.NoError(t, err)
+
+		incomplete := models.TaskIncomplete
+		_, err = memory.Update(ctx, task.ID, &models.UpdateTaskRequest{Status: &incomplete})
+		require.NoError(t, err)
+		left := receiveMessage(t, conn)
+		assert.Equal(t, []string{"done"}, left.Subscriptions)
+		assert.Equal(t, models.TaskIncomplete, left.Event.Task.Status)
+
+		_, err = memory.Update(ctx, task.ID, &models.UpdateTaskRequest{Status: &completed})
+		require.NoError(t, err)
+		require.NoError(t, memory.Delete(ctx, other.ID))
+		require.NoError(t, memory.Delete(ctx, task.ID))
+
+		assert.Equal(t, models.TaskUpdated, receiveMessage(t, conn).Event.Type)
+		deleted := receiveMessage(t, conn)
+		assert.Equal(t, models.TaskDeleted, deleted.Event.Type)
+		assert.Equal(t, task.ID, deleted.Event.TaskID)
+		assert.Nil(t, deleted.Event.Task)
+	})
+
+	t.Run("resubscribing replaces the filter", func(t *testing.T) {
+		reply := exchange(t, conn, models.SocketRequest{Type: models.SocketSubscribe, ID: "done", Filter: "priority=high"})
+		assert.Equal(t, models.SocketSubscribed, reply.Type)
+
+		_, err := memory.Create(ctx, &models.CreateTaskRequest{Name: "Low", Priority: models.PriorityLow})
+		require.NoError(t, err)
+		_, err = memory.Create(ctx, &models.CreateTaskRequest{Name: "High", Priority: models.PriorityHigh})
+		require.NoError(t, err)
+
+		message := receiveMessage(t, conn)
+		assert.Equal(t, []string{"done"}, message.Subscriptions)
+		assert.Equal(t, "High", message.Event.Task.Name)
+	})
+}
+
+func TestWebSocketHandler_Ping(t *testing.T) {
+	_, bus, server := setupSocketServer(t, time.Hour, nil)
+	conn := openSocket(t, server, bus)
+
+	reply := exchange(t, conn, models.SocketRequest{Type: models.SocketPing})
+	assert.Equal(t, models.SocketMessage{Type: models.SocketPong}, reply)
+}
+
+func TestWebSocketHandler_PingFrames(t *testing.T) {
+	_, bus, server := setupSocketServer(t, 10*time.Millisecond, nil)
+	conn := openSocket(t, server, bus)
+
+	// The client answers ping frames by itself; the connection stays usable
+	time.Sleep(50 * time.Millisecond)
+	reply := exchange(t, conn, models.SocketRequest{Type: models.SocketPing})
+	assert.Equal(t, models.SocketPong, reply.Type)
+}
+
+func TestWebSocketHandler_InvalidRequests(t *testing.T) {
+	_, bus, server := setupSocketServer(t, time.Hour, nil)
+	conn := openSocket(t, server, bus)
+
+	tests := []struct {
+		name    string
+		request models.SocketRequest
+		error   string
+	}{
+		{"unknown type", models.SocketRequest{Type: "listen", ID: "x"}, `unknown message type "listen"`},
+		{"missing ID", models.SocketRequest{Type: models.SocketSubscribe}, "id must be"},
+		{"long ID", models.SocketRequest{Type: models.SocketSubscribe, ID: strings.Repeat("x", models.MaxSubscriptionIDLength+1)}, "id must be"},
+		{"unknown filter key", models.SocketRequest{Type: models.SocketSubscribe, ID: "x", Filter: "owner=me"}, `unknown filter key "owner"`},
+		{"invalid filter value", models.SocketRequest{Type: models.SocketSubscribe, ID: "x", Filter: "status=unknown"}, "status"},
+		{"long filter", models.SocketRequest{Type: models.SocketSubscribe, ID: "x", Filter: strings.Repeat("a", models.MaxFilterExpressionBytes+1)}, "filter cannot exceed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reply := exchange(t, conn, tt.request)
+			assert.Equal(t, models.SocketError, reply.Type)
+			assert.Contains(t, reply.Error, tt.error)
+		})
+	}
+
+	t.Run("malformed JSON", func(t *testing.T) {
+		require.NoError(t, websocket.Message.Send(conn, "{not json"))
+		reply := receiveMessage(t, conn)
+		assert.Equal(t, models.SocketError, reply.Type)
+		assert.Contains(t, reply.Error, "invalid message")
+	})
+
+	t.Run("oversized message", func(t *testing.T) {
+		require.NoError(t, websocket.Message.Send(conn, strings.Repeat("x", maxSocketMessageBytes+1)))
+		reply := receiveMessage(t, conn)
+		assert.Equal(t, models.SocketError, reply.Type)
+		assert.Contains(t, reply.Error, "exceeds")
+	})
+
+	// Errors leave the connection open
+	reply := exchange(t, conn, models.SocketRequest{Type: models.SocketPing})
+	assert.Equal(t, models.SocketPong, reply.Type)
+}
+
+func TestWebSocketHandler_SubscriptionLimit(t *testing.T) {
+	_, bus, server := setupSocketServer(t, time.Hour, nil)
+	conn := openSocket(t, server, bus)
+
+	for i := 0; i < models.MaxSocketSubscriptions; i++ {
+		reply := exchange(t, conn, models.SocketRequest{Type: models.SocketSubscribe, ID: fmt.Sprint(i)})
+		require.Equal(t, models.SocketSubscribed, reply.Type)
+	}
+
+	reply := exchange(t, conn, models.SocketRequest{Type: models.SocketSubscribe, ID: "extra"})
+	assert.Equal(t, models.SocketError, reply.Type)
+	assert.Contains(t, reply.Error, "at most")
+
+	// Existing subscriptions can still change their filter
+	reply = exchange(t, conn, models.SocketRequest{Type: models.SocketSubscribe, ID: "0", Filter: "tag=urgent"})
+	assert.Equal(t, models.SocketSubscribed, reply.Type)
+}
+
+func TestWebSocketHandler_Disconnects(t *testing.T) {
+	_, bus, server := setupSocketServer(t, time.Hour, nil)
+
+	t.Run("client leaves", func(t *testing.T) {
+		conn := openSocket(t, server, bus)
+		conn.Close()
+		waitForSubscribers(t, bus, 0)
+	})
+
+	t.Run("bus closes", func(t *testing.T) {
+		conn := openSocket(t, server, bus)
+		bus.Close()
+
+		reply := receiveMessage(t, conn)
+		assert.Equal(t, models.SocketError, reply.Type)
+		assert.Contains(t, reply.Error, "event stream ended")
+
+		var message models.SocketMessage
+		assert.Error(t, websocket.JSON.Receive(conn, &message))
+	})
+}
+
+func TestWebSocketHandler_Origins(t *testing.T) {
+	_, _, server := setupSocketServer(t, time.Hour, []string{"https://app.example.com"})
+
+	_, err := dialSocket(t, server, "https://app.example.com")
+	assert.NoError(t, err)
+
+	_, err = dialSocket(t, server, "https://evil.example.com")
+	assert.Error(t, err)
+
+	t.Run("non-browser clients send no origin", func(t *testing.T) {
+		req, err := http.NewRequest("GET", server.URL+"/ws", nil)
+		require.NoError(t, err)
+		req.Header.Set("Connection", "Upgrade")
+		req.Header.Set("Upgrade", "websocket")
+		req.Header.Set("Sec-WebSocket-Version", "13")
+		req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+	})
+}
+
+func TestWebSocketHandler_NotSupported(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/ws", NewWebSocketHandler(nil, 0, nil).Connect)
+
+	w := sendWithHeaders(router, "GET", "/ws", nil, map[string]string{"Connection": "Upgrade", "Upgrade": "websocket"})
+	assert.Equal(t, http.StatusNotImplemented, w.Code)
+}
@@ -1,38 +1,43 @@
 package interfaces
 
-import "task-api/internal/models"
+import (
+	"context"
+	"task-api/internal/models"
+)
 
 // TaskStorage defines the interface for task storage operations
 // This interface implements the Repository Pattern, allowing for different storage implementations
+// Every method takes a context first so slow backends can stop work once the caller has
+// gone away or a deadline has passed; implementations return ctx.Err() in that case.
 type TaskStorage interface {
 	// GetAll retrieves all tasks from storage
 	// Returns a slice of all tasks and any error that occurred
-	GetAll() ([]*models.Task, error)
+	GetAll(ctx context.Context) ([]*models.Task, error)
 
 	// GetByID retrieves a specific task by its ID
 	// Returns the task if found, nil if not found, and any error that occurred
-	GetByID(id string) (*models.Task, error)
+	GetByID(ctx context.Context, id string) (*models.Task, error)
 
 	// Create creates a new task in storage
 	// Takes a CreateTaskRequest and returns the created task with generated ID and timestamps
-	Create(req *models.CreateTaskRequest) (*models.Task, error)
+	Create(ctx context.Context, req *models.CreateTaskRequest) (*models.Task, error)
 
 	// Update updates an existing task in storage
 	// Takes the task ID and UpdateTaskRequest, returns the updated task
 	// Returns error if task not found or update fails
-	Update(id string, req *models.UpdateTaskRequest) (*models.Task, error)
+	Update(ctx context.Context, id string, req *models.UpdateTaskRequest) (*models.Task, error)
 
 	// Delete removes a task from storage by its ID
 	// Returns error if task not found or deletion fails
-	Delete(id string) error
+	Delete(ctx context.Context, id string) error
 
 	// Count returns the total number of tasks in storage
 	// Useful for pagination and statistics
-	Count() (int, error)
+	Count(ctx context.Context) (int, error)
 
 	// Clear removes all tasks from storage
 	// Primarily used for testing purposes
-	Clear() error
+	Clear(ctx context.Context) error
 }
 
 // HealthChecker defines the interface for health checking storage connections
@@ -40,5 +45,109 @@ type TaskStorage interface {
 type HealthChecker interface {
 	// HealthCheck verifies if the storage is accessible and functioning
 	// Returns error if storage is not healthy
-	HealthCheck() error
+	HealthCheck(ctx context.Context) error
+}
+
+// Optional capability interfaces
+//
+// Storage backends may implement any of the interfaces below to provide fast paths for
+// operations that can otherwise be derived from TaskStorage. Callers discover them with a
+// type assertion and fall back to the generic TaskStorage methods when absent, so a new
+// backend gains fast paths simply by implementing the matching method set.
+
+// StatusQuerier is implemented by storages that can filter tasks by status natively
+type StatusQuerier interface {
+	// GetTasksByStatus returns all tasks with the specified status
+	GetTasksByStatus(ctx context.Context, status models.TaskStatus) ([]*models.Task, error)
+}
+
+// TaskQuerier is implemented by storages that can filter and sort a task listing natively
+type TaskQuerier interface {
+	// QueryTasks returns the tasks matching filter, ordered by the sort keys (creation order if empty)
+	// Ties are broken by creation time and then ID, as in models.SortTasks.
+	QueryTasks(ctx context.Context, filter *models.TaskFilter, order []models.SortField) ([]*models.Task, error)
+}
+
+// Paginator is implemented by storages that can return a page of tasks natively
+type Paginator interface {
+	// GetTasksPaginated returns up to limit tasks starting at offset, plus the total task count
+	GetTasksPaginated(ctx context.Context, offset, limit int) ([]*models.Task, int, error)
+}
+
+// CursorPaginator is implemented by storages that can page through tasks in creation order natively
+type CursorPaginator interface {
+	// GetTasksAfter returns up to limit tasks matching filter that come after the cursor
+	// (nil = from the first task) in creation order, and whether more matching tasks follow
+	GetTasksAfter(ctx context.Context, filter *models.TaskFilter, after *models.TaskCursor, limit int) ([]*models.Task, bool, error)
+}
+
+// TaskSearcher is implemented by storages that maintain a full-text index of their tasks
+type TaskSearcher interface {
+	// SearchTasks returns up to limit tasks whose name or description contains every word of
+	// the query (as a word or a word prefix), best match first; highlights are left to the caller
+	SearchTasks(ctx context.Context, query string, limit int) ([]models.SearchResult, error)
+}
+
+// StatsProvider is implemented by storages that can report aggregate task statistics
+type StatsProvider interface {
+	// GetStats returns statistics about the stored tasks
+	GetStats(ctx context.Context) (models.StorageStats, error)
+}
+
+// UsageReporter is implemented by storages that can report capacity and usage details
+type UsageReporter interface {
+	// GetUsage returns backend-specific usage information (current tasks, limits, etc.)
+	GetUsage() map[string]interface{}
+
+	// GetMaxTasks returns the maximum number of tasks allowed
+	GetMaxTasks() int
+}
+
+// ConditionalWriter is implemented by storages that can update or delete a task only if it
+// is still at an expected version, checked atomically with the write (compare-and-swap)
+// A version mismatch fails with an error wrapping storage.ErrPreconditionFailed.
+type ConditionalWriter interface {
+	// UpdateIfVersion applies the update only if the task is currently at the given version
+	UpdateIfVersion(ctx context.Context, id string, version int, req *models.UpdateTaskRequest) (*models.Task, error)
+
+	// DeleteIfVersion removes the task only if it is currently at the given version
+	DeleteIfVersion(ctx context.Context, id string, version int) error
+}
+
+// BatchWriter is implemented by storages that can apply many writes in one call
+// In atomic mode either every operation is applied or none is; operations skipped because
+// another one failed report an error wrapping storage.ErrBatchAborted.
+type BatchWriter interface {
+	// ApplyBatch applies the operations in order and returns one result per operation
+	// The error is reserved for failures of the batch as a whole, in which case nothing was applied.
+	ApplyBatch(ctx context.Context, ops []models.BulkOperation, mode models.BulkMode) ([]models.BatchResult, error)
+}
+
+// HistoryProvider is implemented by storages that keep a revision for every task version
+// Revisions are removed together with their task; beyond a per-task limit the oldest are dropped.
+type HistoryProvider interface {
+	// GetHistory returns every stored revision of a task, oldest first
+	GetHistory(ctx context.Context, id string) ([]*models.TaskRevision, error)
+
+	// GetVersion returns the revision that produced the given task version
+	GetVersion(ctx context.Context, id string, version int) (*models.TaskRevision, error)
+
+	// Revert restores the task's fields from an earlier version as a new revision
+	// The status change is subject to the workflow like any other update.
+	Revert(ctx context.Context, id string, version int) (*models.Task, error)
+}
+
+// EventPublisher receives the change events of a storage
+// Publish is called while the storage still holds the locks of the change, so events of one
+// task arrive in version order; it must not block or call back into the storage.
+type EventPublisher interface {
+	// Publish delivers an event to the subscribers of the change feed
+	Publish(event models.TaskEvent)
+}
+
+// ChangeNotifier is implemented by storages that report every committed change to a publisher
+type ChangeNotifier interface {
+	// SetEventPublisher sets the publisher changes are reported to
+	// It must be called before the storage is used concurrently.
+	SetEventPublisher(publisher EventPublisher)
 }
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"strings"
+	"task-api/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ActorHeader names the caller recorded as the author of task changes
+const ActorHeader = "X-Actor"
+
+// maxActorLength bounds the actor name stored with every revision
+const maxActorLength = 100
+
+// Actor attributes storage changes made during the request to the caller named in the
+// X-Actor header; requests without it are recorded as anonymous
+// The header is advisory and should only be trusted behind an authenticating proxy.
+func Actor() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		actor := strings.TrimSpace(c.GetHeader(ActorHeader))
+		if len(actor) > maxActorLength {
+			actor = actor[:maxActorLength]
+		}
+
+		if actor != "" {
+			c.Request = c.Request.WithContext(models.WithActor(c.Request.Context(), actor))
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,136 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"task-api/internal/apikeys"
+	"task-api/internal/auth"
+	"task-api/internal/models"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupAPIKeyRouter serves task-like endpoints behind APIKeyAuth, Authenticate and RequireTaskScope
+func setupAPIKeyRouter(t *testing.T) (*gin.Engine, *apikeys.Store) {
+	t.Helper()
+
+	store, err := apikeys.NewStore("")
+	require.NoError(t, err)
+	keys := auth.NewKeySet()
+	require.NoError(t, keys.AddHMAC("", []byte(testSecret)))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(APIKeyAuth(store))
+	tasks := router.Group("/tasks", Authenticate(auth.NewVerifier(keys, auth.VerifierConfig{})), RequireTaskScope())
+	tasks.GET("", func(c *gin.Context) {
+		principal := GetPrincipal(c)
+		c.JSON(http.StatusOK, gin.H{"subject": principal.Subject, "admin": principal.Admin, "key": principal.APIKeyID})
+	})
+	tasks.POST("", func(c *gin.Context) { c.Status(http.StatusCreated) })
+	router.GET("/admin", RequireAdmin(), func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	return router, store
+}
+
+// issueKey issues a key acting as alice with the given scopes
+func issueKey(t *testing.T, store *apikeys.Store, scopes ...string) *models.APIKey {
+	t.Helper()
+
+	key, err := store.Issue(&models.IssueAPIKeyRequest{Name: "test", Subject: "alice", Scopes: scopes}, "")
+	require.NoError(t, err)
+	return key
+}
+
+// send performs a request with the given headers
+func send(router *gin.Engine, method, path string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestAPIKeyAuth(t *testing.T) {
+	router, store := setupAPIKeyRouter(t)
+	reader := issueKey(t, store, models.ScopeTasksRead)
+
+	t.Run("valid key", func(t *testing.T) {
+		w := send(router, http.MethodGet, "/tasks", map[string]string{APIKeyHeader: reader.Key})
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.JSONEq(t, `{"subject":"alice","admin":false,"key":"`+reader.ID+`"}`, w.Body.String())
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		w := send(router, http.MethodGet, "/tasks", map[string]string{APIKeyHeader: "tk_guessed"})
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), "Invalid API key")
+	})
+
+	t.Run("revoked key", func(t *testing.T) {
+		revoked := issueKey(t, store, models.ScopeTasksRead)
+		_, err := store.Revoke(revoked.ID)
+		require.NoError(t, err)
+
+		w := send(router, http.MethodGet, "/tasks", map[string]string{APIKeyHeader: revoked.Key})
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("without a key the bearer token is required", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, send(router, http.MethodGet, "/tasks", nil).Code)
+	})
+
+	t.Run("unknown keys are rejected before rate limiting", func(t *testing.T) {
+		limited := gin.New()
+		limited.Use(APIKeyAuth(store), SmartRateLimit(RateLimitConfig{
+			Enabled: true, PerIP: 100, PerAPIKey: 1, CleanupInterval: time.Minute, WindowSize: time.Minute,
+		}))
+		limited.GET("/tasks", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+		for i := 0; i < 3; i++ {
+			w := send(limited, http.MethodGet, "/tasks", map[string]string{APIKeyHeader: "tk_guessed"})
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+		}
+		assert.Equal(t, http.StatusOK, send(limited, http.MethodGet, "/tasks", map[string]string{APIKeyHeader: reader.Key}).Code)
+	})
+}
+
+func TestRequireTaskScope(t *testing.T) {
+	router, store := setupAPIKeyRouter(t)
+	reader := issueKey(t, store, models.ScopeTasksRead)
+	writer := issueKey(t, store, models.ScopeTasksWrite)
+	admin := issueKey(t, store, models.ScopeAdmin)
+
+	tests := []struct {
+		name   string
+		key    *models.APIKey
+		method string
+		path   string
+		status int
+	}{
+		{"read scope reads", reader, http.MethodGet, "/tasks", http.StatusOK},
+		{"read scope cannot write", reader, http.MethodPost, "/tasks", http.StatusForbidden},
+		{"write scope writes", writer, http.MethodPost, "/tasks", http.StatusCreated},
+		{"write scope cannot read", writer, http.MethodGet, "/tasks", http.StatusForbidden},
+		{"admin scope reads", admin, http.MethodGet, "/tasks", http.StatusOK},
+		{"admin scope writes", admin, http.MethodPost, "/tasks", http.StatusCreated},
+		{"admin endpoint needs the admin scope", writer, http.MethodGet, "/admin", http.StatusForbidden},
+		{"admin scope reaches admin endpoints", admin, http.MethodGet, "/admin", http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := send(router, tt.method, tt.path, map[string]string{APIKeyHeader: tt.key.Key})
+			assert.Equal(t, tt.status, w.Code, w.Body.String())
+		})
+	}
+
+	t.Run("bearer tokens are not restricted by scopes", func(t *testing.T) {
+		w := send(router, http.MethodPost, "/tasks", map[string]string{"Authorization": "Bearer " + signToken(t, "bob", false)})
+		assert.Equal(t, http.StatusCreated, w.Code)
+	})
+}
@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"task-api/internal/auth"
+	"task-api/internal/models"
+	"task-api/internal/rbac"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Gin context keys set by Authenticate
+const (
+	SubjectKey   = "auth_subject"   // Token subject of the caller (string)
+	PrincipalKey = "auth_principal" // Authenticated caller (*models.Principal)
+)
+
+// AccessTokenParam carries the token of event streams and WebSocket connections, which
+// browsers open without letting scripts set the Authorization header
+const AccessTokenParam = "access_token"
+
+// Authenticate rejects requests without a valid bearer token and identifies the caller
+// The token's subject, admin claim and roles are stored in the gin context (SubjectKey, PrincipalKey)
+// and the request context (models.PrincipalFromContext); the subject also becomes the actor
+// recorded with task changes, replacing the advisory X-Actor header. Requests already
+// authenticated by APIKeyAuth pass.
+func Authenticate(verifier *auth.Verifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if GetPrincipal(c) != nil {
+			c.Next()
+			return
+		}
+
+		token := bearerToken(c)
+		if token == "" {
+			abortUnauthorized(c, "Authentication required", errors.New("missing bearer token or API key"))
+			return
+		}
+
+		claims, err := verifier.Verify(token)
+		if err != nil {
+			abortUnauthorized(c, "Invalid token", err)
+			return
+		}
+
+		// The admin role and the admin claim are interchangeable
+		roles := principalRoles(claims.Roles, claims.Admin)
+		setPrincipal(c, &models.Principal{
+			Subject: claims.Subject,
+			Admin:   slices.Contains(roles, rbac.AdminRole),
+			Roles:   roles,
+		})
+		c.Next()
+	}
+}
+
+// RequireAdmin rejects callers without the admin claim or an admin-scoped API key
+// Requests without a principal are rejected too, including on a server without authentication.
+func RequireAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal := GetPrincipal(c)
+		if principal == nil {
+			abortUnauthorized(c, "Authentication required", errors.New("missing bearer token or API key"))
+			return
+		}
+		if !principal.Admin {
+			c.AbortWithStatusJSON(http.StatusForbidden, models.NewErrorResponse(
+				"Administrator access required",
+				errors.New("the token does not carry the admin claim"),
+			))
+			return
+		}
+		c.Next()
+	}
+}
+
+// GetSubject returns the token subject of the caller, or "" if the request is not authenticated
+func GetSubject(c *gin.Context) string {
+	return c.GetString(SubjectKey)
+}
+
+// GetPrincipal returns the authenticated caller, or nil if the request is not authenticated
+func GetPrincipal(c *gin.Context) *models.Principal {
+	principal, _ := c.Get(PrincipalKey)
+	p, _ := principal.(*models.Principal)
+	return p
+}
+
+// bearerToken reads the token from the Authorization header, or from the access_token query
+// parameter for event streams and WebSocket connections
+func bearerToken(c *gin.Context) string {
+	if header := c.GetHeader("Authorization"); header != "" {
+		scheme, token, ok := strings.Cut(header, " ")
+		if ok && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+		return ""
+	}
+
+	if isLongLived(c) {
+		return c.Query(AccessTokenParam)
+	}
+	return ""
+}
+
+// isLongLived reports whether the request opens an event stream or a WebSocket connection
+func isLongLived(c *gin.Context) bool {
+	return strings.Contains(c.GetHeader("Accept"), "text/event-stream") ||
+		strings.EqualFold(c.GetHeader("Upgrade"), "websocket")
+}
+
+// abortUnauthorized answers 401 with the Bearer challenge of RFC 6750
+// Rejected tokens are flagged as invalid_token; a request without one gets the bare challenge.
+func abortUnauthorized(c *gin.Context, message string, err error) {
+	challenge := `Bearer realm="task-api"`
+	if errors.Is(err, auth.ErrInvalidToken) || errors.Is(err, auth.ErrExpiredToken) {
+		challenge += `, error="invalid_token"`
+	}
+	c.Header("WWW-Authenticate", challenge)
+	c.AbortWithStatusJSON(http.StatusUnauthorized, models.NewErrorResponse(message, err))
+}
@@ -0,0 +1,127 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"task-api/internal/auth"
+	"task-api/internal/models"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testSecret signs the test tokens
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+// signToken issues a token for subject valid for the next hour
+func signToken(t *testing.T, subject string, admin bool) string {
+	t.Helper()
+
+	token, err := auth.SignHS256(map[string]interface{}{
+		"sub":   subject,
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"admin": admin,
+	}, "", []byte(testSecret))
+	require.NoError(t, err)
+	return token
+}
+
+// setupAuthRouter serves an endpoint echoing the caller behind Authenticate
+func setupAuthRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+
+	keys := auth.NewKeySet()
+	require.NoError(t, keys.AddHMAC("", []byte(testSecret)))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Actor())
+	authenticated := router.Group("", Authenticate(auth.NewVerifier(keys, auth.VerifierConfig{})))
+	authenticated.GET("/whoami", func(c *gin.Context) {
+		principal := models.PrincipalFromContext(c.Request.Context())
+		c.JSON(http.StatusOK, gin.H{
+			"subject": GetSubject(c),
+			"admin":   principal.Admin,
+			"actor":   models.ActorFromContext(c.Request.Context()),
+		})
+	})
+	authenticated.GET("/admin", RequireAdmin(), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	return router
+}
+
+// get sends a GET request with the given headers
+func get(router *gin.Engine, path string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestAuthenticate(t *testing.T) {
+	router := setupAuthRouter(t)
+	alice := signToken(t, "alice", false)
+
+	t.Run("valid token", func(t *testing.T) {
+		w := get(router, "/whoami", map[string]string{"Authorization": "Bearer " + alice, ActorHeader: "mallory"})
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.JSONEq(t, `{"subject":"alice","admin":false,"actor":"alice"}`, w.Body.String())
+	})
+
+	t.Run("scheme is case-insensitive", func(t *testing.T) {
+		w := get(router, "/whoami", map[string]string{"Authorization": "bearer " + alice})
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("missing token", func(t *testing.T) {
+		w := get(router, "/whoami", nil)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Equal(t, `Bearer realm="task-api"`, w.Header().Get("WWW-Authenticate"))
+		assert.Contains(t, w.Body.String(), "Authentication required")
+	})
+
+	t.Run("other scheme", func(t *testing.T) {
+		w := get(router, "/whoami", map[string]string{"Authorization": "Basic YWxpY2U6c2VjcmV0"})
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		w := get(router, "/whoami", map[string]string{"Authorization": "Bearer " + alice + "x"})
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Header().Get("WWW-Authenticate"), `error="invalid_token"`)
+		assert.Contains(t, w.Body.String(), "Invalid token")
+	})
+
+	t.Run("query token only for streams", func(t *testing.T) {
+		w := get(router, "/whoami?access_token="+alice, nil)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+		w = get(router, "/whoami?access_token="+alice, map[string]string{"Accept": "text/event-stream"})
+		assert.Equal(t, http.StatusOK, w.Code)
+		w = get(router, "/whoami?access_token="+alice, map[string]string{"Upgrade": "websocket"})
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
+
+func TestRequireAdmin(t *testing.T) {
+	router := setupAuthRouter(t)
+
+	w := get(router, "/admin", map[string]string{"Authorization": "Bearer " + signToken(t, "alice", false)})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = get(router, "/admin", map[string]string{"Authorization": "Bearer " + signToken(t, "root", true)})
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	t.Run("without authentication", func(t *testing.T) {
+		open := gin.New()
+		open.GET("/admin", RequireAdmin(), func(c *gin.Context) { c.Status(http.StatusNoContent) })
+		assert.Equal(t, http.StatusUnauthorized, get(open, "/admin", nil).Code)
+	})
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"task-api/internal/models"
+	"task-api/internal/rbac"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Authorize rejects callers whose roles lack a permission the route requires
+// The route is looked up by method and registered pattern, so it must run on a matched route;
+// routes without a mapping are denied. Requests without a principal, such as those on a server
+// without authentication, hold no roles and so get the policy's default roles; when these fall
+// short the request answers 401 rather than 403.
+func Authorize(authorizer *rbac.Authorizer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal := GetPrincipal(c)
+		var roles []string
+		if principal != nil {
+			roles = principal.Roles
+		}
+
+		decision := authorizer.Authorize(roles, c.Request.Method, c.FullPath())
+		if !decision.Allowed && principal == nil {
+			abortUnauthorized(c, "Authentication required", errors.New(decision.Reason))
+			return
+		}
+		if !decision.Allowed {
+			c.AbortWithStatusJSON(http.StatusForbidden, models.NewErrorResponse(
+				"Permission denied",
+				errors.New(decision.Reason),
+			))
+			return
+		}
+		c.Next()
+	}
+}
+
+// principalRoles returns the roles of a caller, adding the admin role for administrators
+func principalRoles(roles []string, admin bool) []string {
+	if !admin || slices.Contains(roles, rbac.AdminRole) {
+		return roles
+	}
+	return append(slices.Clone(roles), rbac.AdminRole)
+}
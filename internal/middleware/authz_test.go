@@ -0,0 +1,130 @@
+package middleware
+
+import (
+	"net/http"
+	"task-api/internal/apikeys"
+	"task-api/internal/auth"
+	"task-api/internal/models"
+	"task-api/internal/rbac"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// signRoleToken issues a token for subject holding roles, valid for the next hour
+func signRoleToken(t *testing.T, subject string, roles ...string) string {
+	t.Helper()
+
+	token, err := auth.SignHS256(map[string]interface{}{
+		"sub":   subject,
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"roles": roles,
+	}, "", []byte(testSecret))
+	require.NoError(t, err)
+	return token
+}
+
+// setupVerifier accepts tokens signed with testSecret
+func setupVerifier(t *testing.T) *auth.Verifier {
+	t.Helper()
+
+	keys := auth.NewKeySet()
+	require.NoError(t, keys.AddHMAC("", []byte(testSecret)))
+	return auth.NewVerifier(keys, auth.VerifierConfig{})
+}
+
+// setupAuthzRouter serves task and stats endpoints behind APIKeyAuth, Authenticate and Authorize
+func setupAuthzRouter(t *testing.T) (*gin.Engine, *apikeys.Store) {
+	t.Helper()
+
+	authorizer, err := rbac.NewAuthorizer(rbac.DefaultPolicy(), []rbac.RouteRule{
+		{Method: "GET", Path: "/tasks/:id", Permissions: []rbac.Permission{rbac.PermTasksRead}},
+		{Method: "DELETE", Path: "/tasks/:id", Permissions: []rbac.Permission{rbac.PermTasksDelete}},
+		{Method: "GET", Path: "/stats", Permissions: []rbac.Permission{rbac.PermStatsRead}},
+	})
+	require.NoError(t, err)
+	store, err := apikeys.NewStore("")
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(APIKeyAuth(store))
+	api := router.Group("", Authenticate(setupVerifier(t)), Authorize(authorizer))
+	api.GET("/tasks/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+	api.DELETE("/tasks/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+	api.GET("/stats", func(c *gin.Context) { c.Status(http.StatusOK) })
+	api.GET("/unmapped", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return router, store
+}
+
+func TestAuthorize(t *testing.T) {
+	router, store := setupAuthzRouter(t)
+	bearerHeader := func(token string) map[string]string {
+		return map[string]string{"Authorization": "Bearer " + token}
+	}
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		method  string
+		path    string
+		status  int
+	}{
+		{"viewer reads", bearerHeader(signRoleToken(t, "alice", "viewer")), http.MethodGet, "/tasks/1", http.StatusOK},
+		{"viewer cannot delete", bearerHeader(signRoleToken(t, "alice", "viewer")), http.MethodDelete, "/tasks/1", http.StatusForbidden},
+		{"callers without roles get the default roles", bearerHeader(signRoleToken(t, "alice")), http.MethodDelete, "/tasks/1", http.StatusOK},
+		{"default roles cannot read stats", bearerHeader(signRoleToken(t, "alice")), http.MethodGet, "/stats", http.StatusForbidden},
+		{"admin role reads stats", bearerHeader(signRoleToken(t, "alice", rbac.AdminRole)), http.MethodGet, "/stats", http.StatusOK},
+		{"admin claim grants the admin role", bearerHeader(signToken(t, "root", true)), http.MethodGet, "/stats", http.StatusOK},
+		{"unmapped routes are denied", bearerHeader(signToken(t, "root", true)), http.MethodGet, "/unmapped", http.StatusForbidden},
+		{"admin-scoped keys hold the admin role", map[string]string{APIKeyHeader: issueKey(t, store, models.ScopeAdmin).Key}, http.MethodGet, "/stats", http.StatusOK},
+		{"other keys hold the default roles", map[string]string{APIKeyHeader: issueKey(t, store, models.ScopeTasksWrite).Key}, http.MethodGet, "/stats", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := send(router, tt.method, tt.path, tt.headers)
+			assert.Equal(t, tt.status, w.Code, w.Body.String())
+		})
+	}
+
+	t.Run("the admin role sees every task like the admin claim", func(t *testing.T) {
+		whoami := gin.New()
+		whoami.GET("/whoami", Authenticate(setupVerifier(t)), func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{"admin": GetPrincipal(c).Admin, "roles": GetPrincipal(c).Roles})
+		})
+		w := send(whoami, http.MethodGet, "/whoami", bearerHeader(signRoleToken(t, "alice", "viewer", rbac.AdminRole)))
+		assert.JSONEq(t, `{"admin":true,"roles":["viewer","admin"]}`, w.Body.String())
+
+		w = send(whoami, http.MethodGet, "/whoami", bearerHeader(signToken(t, "root", true)))
+		assert.JSONEq(t, `{"admin":true,"roles":["admin"]}`, w.Body.String())
+	})
+
+	t.Run("the missing permission is reported", func(t *testing.T) {
+		w := send(router, http.MethodDelete, "/tasks/1", bearerHeader(signRoleToken(t, "alice", "viewer")))
+		require.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Body.String(), "Permission denied")
+		assert.Contains(t, w.Body.String(), "missing permission tasks:delete")
+	})
+
+	t.Run("requests without a principal hold the default roles", func(t *testing.T) {
+		authorizer, err := rbac.NewAuthorizer(rbac.DefaultPolicy(), []rbac.RouteRule{
+			{Method: "GET", Path: "/tasks/:id", Permissions: []rbac.Permission{rbac.PermTasksRead}},
+			{Method: "GET", Path: "/stats", Permissions: []rbac.Permission{rbac.PermStatsRead}},
+		})
+		require.NoError(t, err)
+		open := gin.New()
+		open.Use(Authorize(authorizer))
+		open.GET("/tasks/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+		open.GET("/stats", func(c *gin.Context) { c.Status(http.StatusOK) })
+		open.GET("/unmapped", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+		assert.Equal(t, http.StatusOK, send(open, http.MethodGet, "/tasks/1", nil).Code)
+		w := send(open, http.MethodGet, "/stats", nil)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), "missing permission stats:read")
+		assert.Equal(t, http.StatusUnauthorized, send(open, http.MethodGet, "/unmapped", nil).Code)
+	})
+}
@@ -0,0 +1,158 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ClientIPKey is the context key of the client IP resolved by ClientIP (string)
+const ClientIPKey = "client_ip"
+
+// Headers listing the addresses a request was forwarded for
+const (
+	HeaderXForwardedFor = "X-Forwarded-For" // Comma-separated addresses, each proxy appending its client's
+	HeaderXRealIP       = "X-Real-IP"       // The single address of the proxy's client
+	HeaderForwarded     = "Forwarded"       // RFC 7239 elements, whose for= parameters hold the addresses
+)
+
+// ClientIPConfig defines which proxies are believed about the client IP
+type ClientIPConfig struct {
+	TrustedProxies []string `json:"trusted_proxies"` // CIDRs or addresses of the proxies whose header is believed
+	Header         string   `json:"header"`          // Header the trusted proxies set (default X-Forwarded-For)
+}
+
+// ClientIPResolver finds the IP of the client a request comes from
+// Addresses in the forwarding header are read right to left, the order proxies append them in,
+// and only while they come from trusted proxies: the first untrusted address is the client,
+// so clients cannot pick their IP by sending the header themselves. A nil resolver trusts no
+// proxy and resolves the connection's remote address.
+type ClientIPResolver struct {
+	trusted []netip.Prefix // Trusted proxy ranges
+	header  string         // Forwarding header
+}
+
+// NewClientIPResolver creates a resolver believing the header of config's trusted proxies (Factory Pattern)
+func NewClientIPResolver(config ClientIPConfig) (*ClientIPResolver, error) {
+	header := HeaderXForwardedFor
+	if config.Header != "" {
+		header = ""
+		for _, supported := range []string{HeaderXForwardedFor, HeaderXRealIP, HeaderForwarded} {
+			if strings.EqualFold(strings.TrimSpace(config.Header), supported) {
+				header = supported
+			}
+		}
+		if header == "" {
+			return nil, fmt.Errorf("unsupported proxy header %q", config.Header)
+		}
+	}
+
+	resolver := &ClientIPResolver{header: header}
+	for _, proxy := range config.TrustedProxies {
+		prefix, ok := parseCIDR(strings.TrimSpace(proxy))
+		if !ok {
+			return nil, fmt.Errorf("invalid trusted proxy %q", proxy)
+		}
+		resolver.trusted = append(resolver.trusted, prefix)
+	}
+	return resolver, nil
+}
+
+// Resolve returns the IP of the client req comes from
+func (r *ClientIPResolver) Resolve(req *http.Request) string {
+	remote := req.RemoteAddr
+	if host, _, err := net.SplitHostPort(remote); err == nil {
+		remote = host
+	}
+	client, err := netip.ParseAddr(remote)
+	if err != nil || !r.trusts(client) {
+		return remote
+	}
+
+	// Walk back through the proxies until one forwards for an untrusted address
+	hops := r.forwardedFor(req)
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop, ok := parseHop(hops[i])
+		if !ok {
+			// Unknown or obfuscated addresses end the chain at the last proxy known
+			break
+		}
+		client = hop
+		if !r.trusts(hop) {
+			break
+		}
+	}
+	return client.String()
+}
+
+// trusts reports whether addr belongs to a trusted proxy
+func (r *ClientIPResolver) trusts(addr netip.Addr) bool {
+	if r == nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range r.trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// forwardedFor returns the addresses listed in the resolver's header, first proxy first
+func (r *ClientIPResolver) forwardedFor(req *http.Request) []string {
+	var hops []string
+	for _, value := range req.Header.Values(r.header) {
+		for _, element := range strings.Split(value, ",") {
+			if r.header == HeaderForwarded {
+				element = forwardedForParam(element)
+			}
+			hops = append(hops, strings.TrimSpace(element))
+		}
+	}
+	return hops
+}
+
+// forwardedForParam returns the for= parameter of a Forwarded element, unquoted
+func forwardedForParam(element string) string {
+	for _, pair := range strings.Split(element, ";") {
+		key, value, found := strings.Cut(strings.TrimSpace(pair), "=")
+		if found && strings.EqualFold(key, "for") {
+			return strings.Trim(value, `"`)
+		}
+	}
+	return ""
+}
+
+// parseHop parses a forwarded address, which may carry a port and, for IPv6, brackets
+func parseHop(hop string) (netip.Addr, bool) {
+	if addr, err := netip.ParseAddr(strings.Trim(hop, "[]")); err == nil {
+		return addr.Unmap(), true
+	}
+	if addrPort, err := netip.ParseAddrPort(hop); err == nil {
+		return addrPort.Addr().Unmap(), true
+	}
+	return netip.Addr{}, false
+}
+
+// ClientIP resolves the client IP of every request with resolver, for GetClientIP
+// A nil resolver trusts no proxy.
+func ClientIP(resolver *ClientIPResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(ClientIPKey, resolver.Resolve(c.Request))
+		c.Next()
+	}
+}
+
+// GetClientIP returns the client IP resolved by the ClientIP middleware, or the connection's
+// remote address when the middleware did not run
+func GetClientIP(c *gin.Context) string {
+	if clientIP := c.GetString(ClientIPKey); clientIP != "" {
+		return clientIP
+	}
+	return (*ClientIPResolver)(nil).Resolve(c.Request)
+}
@@ -0,0 +1,180 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testClientIP resolves client IPs from X-Forwarded-For, trusting httptest's remote address
+func testClientIP(t *testing.T) gin.HandlerFunc {
+	t.Helper()
+
+	resolver, err := NewClientIPResolver(ClientIPConfig{TrustedProxies: []string{"192.0.2.1"}})
+	require.NoError(t, err)
+	return ClientIP(resolver)
+}
+
+func TestNewClientIPResolver(t *testing.T) {
+	_, err := NewClientIPResolver(ClientIPConfig{TrustedProxies: []string{"10.0.0.0/8", " 127.0.0.1", "::1"}, Header: "forwarded"})
+	assert.NoError(t, err)
+
+	_, err = NewClientIPResolver(ClientIPConfig{TrustedProxies: []string{"10.0.0.0/33"}})
+	assert.EqualError(t, err, `invalid trusted proxy "10.0.0.0/33"`)
+
+	_, err = NewClientIPResolver(ClientIPConfig{Header: "X-Client-IP"})
+	assert.EqualError(t, err, `unsupported proxy header "X-Client-IP"`)
+}
+
+func TestClientIPResolver_Resolve(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		remote  string
+		headers map[string][]string
+		want    string
+	}{
+		{
+			name:   "direct client",
+			remote: "203.0.113.7:51000",
+			want:   "203.0.113.7",
+		},
+		{
+			name:    "untrusted client forging the header",
+			remote:  "203.0.113.7:51000",
+			headers: map[string][]string{"X-Forwarded-For": {"198.51.100.1"}},
+			want:    "203.0.113.7",
+		},
+		{
+			name:    "trusted proxy",
+			remote:  "10.0.0.1:40000",
+			headers: map[string][]string{"X-Forwarded-For": {"203.0.113.7"}},
+			want:    "203.0.113.7",
+		},
+		{
+			name:    "forged addresses left of the client",
+			remote:  "10.0.0.1:40000",
+			headers: map[string][]string{"X-Forwarded-For": {"198.51.100.1, 203.0.113.7, 10.0.0.2"}},
+			want:    "203.0.113.7",
+		},
+		{
+			name:    "header lines in order",
+			remote:  "10.0.0.1:40000",
+			headers: map[string][]string{"X-Forwarded-For": {"198.51.100.1", "203.0.113.7:5000"}},
+			want:    "203.0.113.7",
+		},
+		{
+			name:    "only trusted proxies",
+			remote:  "10.0.0.1:40000",
+			headers: map[string][]string{"X-Forwarded-For": {"10.0.0.3, 10.0.0.2"}},
+			want:    "10.0.0.3",
+		},
+		{
+			name:    "unknown address ends the chain",
+			remote:  "10.0.0.1:40000",
+			headers: map[string][]string{"X-Forwarded-For": {"198.51.100.1, unknown, 10.0.0.2"}},
+			want:    "10.0.0.2",
+		},
+		{
+			name:    "trusted proxy without header",
+			remote:  "[::1]:40000",
+			headers: map[string][]string{"X-Real-IP": {"203.0.113.7"}},
+			want:    "::1",
+		},
+		{
+			name:    "X-Real-IP",
+			header:  HeaderXRealIP,
+			remote:  "10.0.0.1:40000",
+			headers: map[string][]string{"X-Real-IP": {"203.0.113.7"}, "X-Forwarded-For": {"198.51.100.1"}},
+			want:    "203.0.113.7",
+		},
+		{
+			name:   "Forwarded",
+			header: HeaderForwarded,
+			remote: "10.0.0.1:40000",
+			headers: map[string][]string{
+				"Forwarded": {`for=198.51.100.1, for="[2001:db8:cafe::17]:4711";proto=https, For=10.0.0.2;by=10.0.0.1`},
+			},
+			want: "2001:db8:cafe::17",
+		},
+		{
+			name:    "Forwarded obfuscated identifier",
+			header:  HeaderForwarded,
+			remote:  "10.0.0.1:40000",
+			headers: map[string][]string{"Forwarded": {"for=_hidden, for=10.0.0.2"}},
+			want:    "10.0.0.2",
+		},
+		{
+			name:    "Forwarded ignored when X-Forwarded-For is used",
+			remote:  "10.0.0.1:40000",
+			headers: map[string][]string{"Forwarded": {"for=198.51.100.1"}, "X-Forwarded-For": {"203.0.113.7"}},
+			want:    "203.0.113.7",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver, err := NewClientIPResolver(ClientIPConfig{TrustedProxies: []string{"10.0.0.0/8", "::1"}, Header: tt.header})
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remote
+			for key, values := range tt.headers {
+				for _, value := range values {
+					req.Header.Add(key, value)
+				}
+			}
+			assert.Equal(t, tt.want, resolver.Resolve(req))
+		})
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	forged := map[string]string{"X-Forwarded-For": "198.51.100.1"}
+
+	t.Run("shared by the rate limiter and the logger", func(t *testing.T) {
+		var logs bytes.Buffer
+		router := gin.New()
+		router.Use(ClientIP(nil))
+		router.Use(LoggerWithConfig(LoggerConfig{Output: &logs, TimeFormat: time.RFC3339}))
+		router.Use(SmartRateLimit(RateLimitConfig{
+			Enabled: true, PerIP: 3, PerAPIKey: 100, CleanupInterval: time.Hour, WindowSize: time.Minute,
+		}))
+		router.GET("/test", func(c *gin.Context) { c.String(http.StatusOK, GetClientIP(c)) })
+
+		// Forging a new address for every request does not escape the limit
+		allowed := 0
+		for i := 0; i < 10; i++ {
+			w := send(router, http.MethodGet, "/test", map[string]string{"X-Forwarded-For": "198.51.100." + strconv.Itoa(i+1)})
+			if w.Code == http.StatusOK {
+				allowed++
+				assert.Equal(t, "192.0.2.1", w.Body.String())
+			}
+		}
+		assert.Equal(t, 3, allowed)
+		assert.Contains(t, logs.String(), "192.0.2.1")
+		assert.NotContains(t, logs.String(), "198.51.100")
+	})
+
+	t.Run("trusted proxies", func(t *testing.T) {
+		router := gin.New()
+		router.Use(testClientIP(t))
+		router.GET("/test", func(c *gin.Context) { c.String(http.StatusOK, GetClientIP(c)) })
+
+		assert.Equal(t, "198.51.100.1", send(router, http.MethodGet, "/test", forged).Body.String())
+	})
+
+	t.Run("remote address without the middleware", func(t *testing.T) {
+		router := gin.New()
+		router.GET("/test", func(c *gin.Context) { c.String(http.StatusOK, GetClientIP(c)) })
+
+		assert.Equal(t, "192.0.2.1", send(router, http.MethodGet, "/test", forged).Body.String())
+	})
+}
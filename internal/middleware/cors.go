@@ -44,12 +44,24 @@ func DefaultCORSConfig() CORSConfig {
 			"Host",
 			"Referer",
 			"User-Agent",
+			"If-Match",
+			"If-None-Match",
+			"X-Actor",
+			"API-Version",
 		},
 		ExposeHeaders: []string{
 			"Content-Length",
+			"ETag",
+			"API-Version",
 			"X-Total-Count",
 			"X-Offset",
 			"X-Limit",
+			"X-RateLimit-Limit",
+			"X-RateLimit-Remaining",
+			"X-RateLimit-Reset",
+			"RateLimit-Policy",
+			"RateLimit",
+			"Retry-After",
 		},
 		AllowCredentials: false,
 		MaxAge:           86400, // 24 hours
@@ -69,7 +81,7 @@ func CORSWithConfig(config CORSConfig) gin.HandlerFunc {
 		// Set Access-Control-Allow-Origin
 		if len(config.AllowOrigins) == 1 && config.AllowOrigins[0] == "*" {
 			c.Header("Access-Control-Allow-Origin", "*")
-		} else if isOriginAllowed(origin, config.AllowOrigins) {
+		} else if IsOriginAllowed(origin, config.AllowOrigins) {
 			c.Header("Access-Control-Allow-Origin", origin)
 		}
 
@@ -117,6 +129,7 @@ func RestrictiveCORS(allowedOrigins []string) gin.HandlerFunc {
 			http.MethodGet,
 			http.MethodPost,
 			http.MethodPut,
+			http.MethodPatch,
 			http.MethodDelete,
 			http.MethodOptions,
 		},
@@ -125,10 +138,22 @@ func RestrictiveCORS(allowedOrigins []string) gin.HandlerFunc {
 			"Content-Type",
 			"Authorization",
 			"Accept",
+			"If-Match",
+			"If-None-Match",
+			"X-Actor",
+			"API-Version",
 		},
 		ExposeHeaders: []string{
 			"Content-Length",
+			"ETag",
+			"API-Version",
 			"X-Total-Count",
+			"X-RateLimit-Limit",
+			"X-RateLimit-Remaining",
+			"X-RateLimit-Reset",
+			"RateLimit-Policy",
+			"RateLimit",
+			"Retry-After",
 		},
 		AllowCredentials: true,
 		MaxAge:           3600, // 1 hour
@@ -154,8 +179,8 @@ func DevelopmentCORS() gin.HandlerFunc {
 
 // Helper functions
 
-// isOriginAllowed checks if the origin is in the allowed list
-func isOriginAllowed(origin string, allowedOrigins []string) bool {
+// IsOriginAllowed checks if the origin is in the allowed list
+func IsOriginAllowed(origin string, allowedOrigins []string) bool {
 	for _, allowed := range allowedOrigins {
 		if allowed == "*" || allowed == origin {
 			return true
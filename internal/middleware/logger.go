@@ -72,7 +72,7 @@ func LoggerWithConfig(config LoggerConfig) gin.HandlerFunc {
 		// Get request information
 		statusCode := c.Writer.Status()
 		method := c.Request.Method
-		clientIP := c.ClientIP()
+		clientIP := GetClientIP(c)
 		userAgent := c.Request.UserAgent()
 
 		// Determine log level based on status code
@@ -263,7 +263,7 @@ func logError(c *gin.Context, err *gin.Error) {
 	timestamp := time.Now().Format(time.RFC3339)
 	method := c.Request.Method
 	path := c.Request.URL.Path
-	clientIP := c.ClientIP()
+	clientIP := GetClientIP(c)
 	requestID := c.GetString("request_id")
 
 	errorMsg := fmt.Sprintf("[ERROR] %s | %s | %s %s | Request-ID: %s | Error: %s",
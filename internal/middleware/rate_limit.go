@@ -1,9 +1,11 @@
 package middleware
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
-	"strings"
-	"sync"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -11,11 +13,16 @@ import (
 
 // RateLimitConfig defines rate limiting configuration
 type RateLimitConfig struct {
-	Enabled         bool          // Enable rate limiting
-	PerIP           int           // Requests per minute per IP
-	PerAPIKey       int           // Requests per minute per API key
-	CleanupInterval time.Duration // Interval for cleaning up expired records
-	WindowSize      time.Duration // Time window size
+	Enabled         bool               // Enable rate limiting
+	PerIP           int                // Requests per minute per IP
+	PerAPIKey       int                // Requests per minute per API key
+	CleanupInterval time.Duration      // Interval for cleaning up expired records
+	WindowSize      time.Duration      // Time window size
+	Algorithm       RateLimitAlgorithm // Counting algorithm ("" = AlgorithmFixedWindow)
+	Burst           int                // Token bucket: requests a rested client may send on top of the limit
+	Clock           func() time.Time   // Time source (nil = time.Now)
+	Policies        *RateLimitPolicies // Per-client quotas by request (nil = PerIP for every request)
+	Store           RateLimitStore     // Keeps the request records (nil = a new MemoryRateLimitStore)
 }
 
 // DefaultRateLimitConfig returns default rate limiting configuration
@@ -26,32 +33,86 @@ func DefaultRateLimitConfig() RateLimitConfig {
 		PerAPIKey:       1000,            // 1000 requests per minute per API key
 		CleanupInterval: 5 * time.Minute, // Cleanup every 5 minutes
 		WindowSize:      1 * time.Minute, // 1 minute time window
+		Algorithm:       AlgorithmFixedWindow,
 	}
 }
 
 // RequestRecord tracks request information
+// Each algorithm uses the fields it needs: the window counters, the bucket or the request log.
 type RequestRecord struct {
-	Count     int       // Request count
-	FirstSeen time.Time // First request time
-	LastSeen  time.Time // Last request time
+	Count     int         // Request count of the current window
+	FirstSeen time.Time   // Start of the current window
+	LastSeen  time.Time   // Last request time
+	PrevCount int         // Request count of the previous window (sliding window)
+	Tokens    float64     // Tokens left in the bucket (token bucket)
+	Hits      []time.Time // Request times within the last window (sliding log)
+}
+
+// PolicyAPIKey is the quota of API keys reported in the RateLimit-Policy and RateLimit headers;
+// per-client quotas are reported under the name of their policy
+const PolicyAPIKey = "api_key"
+
+// RateLimitResult describes the quota a request was counted against
+// When several quotas apply, it describes the one that rejected the request, or else the one
+// with the fewest requests left.
+type RateLimitResult struct {
+	Allowed    bool          // Whether the request fits the quota
+	Policy     string        // Quota evaluated: the client's policy or PolicyAPIKey
+	Limit      int           // Requests allowed per window
+	Window     time.Duration // Window the limit applies to
+	Remaining  int           // Requests the client may still send right away
+	Reset      time.Duration // Time until the whole quota is available again
+	RetryAfter time.Duration // Time until the next request fits, when rejected
 }
 
 // RateLimiter implements rate limiting functionality
 type RateLimiter struct {
-	config     RateLimitConfig
-	ipRecords  map[string]*RequestRecord // IP request records
-	keyRecords map[string]*RequestRecord // API key request records
-	mu         sync.RWMutex              // Read-write mutex
-	stopChan   chan struct{}             // Channel to stop cleanup routine
+	config        RateLimitConfig
+	policies      []*ratePolicy    // Per-client policies, in evaluation order
+	defaultPolicy *ratePolicy      // Policy of requests no other policy matches
+	keyPolicy     *ratePolicy      // Quota of API keys
+	store         RateLimitStore   // Request records of clients and API keys
+	now           func() time.Time // Current time source
+	stopChan      chan struct{}    // Channel to stop cleanup routine
 }
 
 // NewRateLimiter creates a new rate limiter instance
 func NewRateLimiter(config RateLimitConfig) *RateLimiter {
+	if config.Algorithm == "" {
+		config.Algorithm = AlgorithmFixedWindow
+	}
+	now := config.Clock
+	if now == nil {
+		now = time.Now
+	}
+
+	policies := config.Policies
+	if policies == nil {
+		policies = &RateLimitPolicies{}
+	}
+	defaultPolicy := policies.Default
+	if defaultPolicy.Name == "" {
+		defaultPolicy.Name = DefaultPolicyName
+	}
+	if defaultPolicy.Limit == 0 {
+		defaultPolicy.Limit = config.PerIP
+	}
+
+	store := config.Store
+	if store == nil {
+		store = NewMemoryRateLimitStore()
+	}
+
 	limiter := &RateLimiter{
-		config:     config,
-		ipRecords:  make(map[string]*RequestRecord),
-		keyRecords: make(map[string]*RequestRecord),
-		stopChan:   make(chan struct{}),
+		config:        config,
+		defaultPolicy: newRatePolicy(defaultPolicy, config),
+		keyPolicy:     newRatePolicy(RateLimitPolicy{Name: PolicyAPIKey, Limit: config.PerAPIKey}, config),
+		store:         store,
+		now:           now,
+		stopChan:      make(chan struct{}),
+	}
+	for _, policy := range policies.Policies {
+		limiter.policies = append(limiter.policies, newRatePolicy(policy, config))
 	}
 
 	// Start cleanup routine
@@ -72,7 +133,9 @@ func RateLimit(config RateLimitConfig) gin.HandlerFunc {
 
 	return func(c *gin.Context) {
 		// Check rate limit
-		if !limiter.Allow(c) {
+		result := limiter.Check(c)
+		setRateLimitHeaders(c, result)
+		if !result.Allowed {
 			c.JSON(http.StatusTooManyRequests, gin.H{
 				"error":   "Rate limit exceeded",
 				"message": "Too many requests. Please try again later.",
@@ -88,62 +151,84 @@ func RateLimit(config RateLimitConfig) gin.HandlerFunc {
 
 // Allow checks if the request is allowed
 func (rl *RateLimiter) Allow(c *gin.Context) bool {
-	clientIP := getClientIP(c)
-	apiKey := c.GetHeader("X-API-Key")
+	return rl.Check(c).Allowed
+}
 
-	now := time.Now()
+// Check counts the request against the quota of the client IP's policy and, when an API key
+// is present, the key's quota, and describes the most restrictive of them
+// A request rejected for its IP is not counted against its key. When the store fails, the
+// request is allowed and the error recorded on c.
+func (rl *RateLimiter) Check(c *gin.Context) RateLimitResult {
+	clientIP := GetClientIP(c)
+	apiKey := c.GetHeader("X-API-Key")
+	policy := rl.policyFor(c, clientIP)
 
-	rl.mu.Lock()
-	defer rl.mu.Unlock()
+	now := rl.now()
+	ctx := c.Request.Context()
 
-	// Check IP limit
-	if !rl.checkLimit(clientIP, rl.config.PerIP, now, rl.ipRecords) {
-		return false
+	// Check IP limit; every policy counts its own requests
+	result, err := rl.take(ctx, policy, ipRecordKey(policy.name, clientIP), now)
+	if err != nil {
+		return rl.failOpen(c, policy, err)
+	}
+	if !result.Allowed {
+		return result
 	}
 
 	// Check API key limit if present
 	if apiKey != "" {
-		if !rl.checkLimit(apiKey, rl.config.PerAPIKey, now, rl.keyRecords) {
-			return false
+		keyResult, err := rl.take(ctx, rl.keyPolicy, apiKeyRecordKey(apiKey), now)
+		if err != nil {
+			return rl.failOpen(c, rl.keyPolicy, err)
+		}
+		if !keyResult.Allowed || keyResult.Remaining < result.Remaining {
+			return keyResult
 		}
 	}
 
-	return true
+	return result
 }
 
-// checkLimit checks the limit for a specific identifier
-func (rl *RateLimiter) checkLimit(identifier string, limit int, now time.Time, records map[string]*RequestRecord) bool {
-	record, exists := records[identifier]
-
-	if !exists {
-		// First request
-		records[identifier] = &RequestRecord{
-			Count:     1,
-			FirstSeen: now,
-			LastSeen:  now,
+// policyFor returns the first policy matching the request, or the default policy
+func (rl *RateLimiter) policyFor(c *gin.Context, clientIP string) *ratePolicy {
+	for _, policy := range rl.policies {
+		if policy.matches(c, clientIP) {
+			return policy
 		}
-		return true
-	}
-
-	// Check time window
-	if now.Sub(record.FirstSeen) > rl.config.WindowSize {
-		// Reset counter
-		record.Count = 1
-		record.FirstSeen = now
-		record.LastSeen = now
-		return true
 	}
+	return rl.defaultPolicy
+}
 
-	// Check if limit exceeded
-	if record.Count >= limit {
-		return false
+// take counts a request of the record key against policy
+func (rl *RateLimiter) take(ctx context.Context, policy *ratePolicy, key string, now time.Time) (RateLimitResult, error) {
+	counted, err := rl.store.Take(ctx, key, policy, now)
+	if err != nil {
+		return RateLimitResult{}, err
 	}
 
-	// Increment counter
-	record.Count++
-	record.LastSeen = now
+	return RateLimitResult{
+		Allowed:    counted.allowed,
+		Policy:     policy.name,
+		Limit:      policy.requestLimit(),
+		Window:     policy.window,
+		Remaining:  counted.remaining,
+		Reset:      counted.reset,
+		RetryAfter: counted.retryAfter,
+	}, nil
+}
 
-	return true
+// failOpen allows a request the store could not count, recording the error on c
+// An unavailable store must not take the API down with it.
+func (rl *RateLimiter) failOpen(c *gin.Context, policy *ratePolicy, err error) RateLimitResult {
+	_ = c.Error(fmt.Errorf("rate limit store unavailable, request allowed: %w", err))
+
+	return RateLimitResult{
+		Allowed:   true,
+		Policy:    policy.name,
+		Limit:     policy.requestLimit(),
+		Window:    policy.window,
+		Remaining: policy.requestLimit(),
+	}
 }
 
 // startCleanupRoutine starts the routine to clean up expired records
@@ -163,25 +248,7 @@ func (rl *RateLimiter) startCleanupRoutine() {
 
 // cleanup removes expired records
 func (rl *RateLimiter) cleanup() {
-	rl.mu.Lock()
-	defer rl.mu.Unlock()
-
-	now := time.Now()
-	expiry := rl.config.WindowSize * 2 // Keep records for two time windows
-
-	// Clean up IP records
-	for ip, record := range rl.ipRecords {
-		if now.Sub(record.LastSeen) > expiry {
-			delete(rl.ipRecords, ip)
-		}
-	}
-
-	// Clean up API key records
-	for key, record := range rl.keyRecords {
-		if now.Sub(record.LastSeen) > expiry {
-			delete(rl.keyRecords, key)
-		}
-	}
+	rl.store.Cleanup(rl.now())
 }
 
 // Stop stops the rate limiter
@@ -191,8 +258,22 @@ func (rl *RateLimiter) Stop() {
 
 // GetStats returns rate limiter statistics
 func (rl *RateLimiter) GetStats() map[string]interface{} {
-	rl.mu.RLock()
-	defer rl.mu.RUnlock()
+	policies := make([]map[string]interface{}, 0, len(rl.policies)+1)
+	for _, policy := range rl.policies {
+		policies = append(policies, policy.stats())
+	}
+	policies = append(policies, rl.defaultPolicy.stats())
+
+	ctx := context.Background()
+	trackedIPs, ipErr := rl.store.Count(ctx, ipRecordPrefix)
+	trackedKeys, keyErr := rl.store.Count(ctx, keyRecordPrefix)
+	statistics := map[string]interface{}{
+		"tracked_ips":      trackedIPs,
+		"tracked_api_keys": trackedKeys,
+	}
+	if err := errors.Join(ipErr, keyErr); err != nil {
+		statistics["error"] = err.Error()
+	}
 
 	return map[string]interface{}{
 		"config": map[string]interface{}{
@@ -201,11 +282,11 @@ func (rl *RateLimiter) GetStats() map[string]interface{} {
 			"per_api_key":      rl.config.PerAPIKey,
 			"cleanup_interval": rl.config.CleanupInterval.String(),
 			"window_size":      rl.config.WindowSize.String(),
+			"algorithm":        string(rl.config.Algorithm),
+			"burst":            rl.config.Burst,
+			"policies":         policies,
 		},
-		"statistics": map[string]interface{}{
-			"tracked_ips":      len(rl.ipRecords),
-			"tracked_api_keys": len(rl.keyRecords),
-		},
+		"statistics": statistics,
 	}
 }
 
@@ -214,7 +295,9 @@ func RateLimitWithConfig(config RateLimitConfig) gin.HandlerFunc {
 	return RateLimit(config)
 }
 
-// SmartRateLimit creates intelligent rate limiting middleware with different limits for different endpoints
+// SmartRateLimit creates rate limiting middleware applying different limits to different requests
+// config.Policies declares the limits; without them, health checks get five times the per-IP
+// limit and writes half of it (see DefaultRateLimitPolicies).
 func SmartRateLimit(config RateLimitConfig) gin.HandlerFunc {
 	if !config.Enabled {
 		return func(c *gin.Context) {
@@ -222,30 +305,25 @@ func SmartRateLimit(config RateLimitConfig) gin.HandlerFunc {
 		}
 	}
 
+	if config.Policies == nil {
+		config.Policies = DefaultRateLimitPolicies(config)
+	}
 	limiter := NewRateLimiter(config)
 
 	return func(c *gin.Context) {
-		path := c.Request.URL.Path
-		method := c.Request.Method
-
-		// Set different limit strategies for different endpoints
-		customLimit := getCustomLimit(path, method, config)
-
 		// Check rate limit
-		if !limiter.allowWithCustomLimit(c, customLimit) {
-			// Set appropriate response headers
-			c.Header("X-RateLimit-Limit", "100") // Can be set dynamically based on actual limit
-			c.Header("X-RateLimit-Remaining", "0")
-			c.Header("X-RateLimit-Reset", "60") // Reset time in seconds
-
+		result := limiter.Check(c)
+		setRateLimitHeaders(c, result)
+		if !result.Allowed {
 			c.JSON(http.StatusTooManyRequests, gin.H{
 				"error":   "Rate limit exceeded",
 				"message": "Too many requests. Please try again later.",
 				"code":    "RATE_LIMIT_EXCEEDED",
 				"details": map[string]interface{}{
-					"path":   path,
-					"method": method,
-					"limit":  customLimit,
+					"path":   c.Request.URL.Path,
+					"method": c.Request.Method,
+					"policy": result.Policy,
+					"limit":  result.Limit,
 				},
 			})
 			c.Abort()
@@ -256,67 +334,24 @@ func SmartRateLimit(config RateLimitConfig) gin.HandlerFunc {
 	}
 }
 
-// getCustomLimit returns custom limit based on path and method
-func getCustomLimit(path, method string, baseConfig RateLimitConfig) int {
-	// Health check endpoints allow more requests
-	if path == "/health" || path == "/api/v1/health" {
-		return baseConfig.PerIP * 5
-	}
+// setRateLimitHeaders describes the evaluated quota with the legacy X-RateLimit-* headers and
+// the IETF RateLimit-Policy and RateLimit headers; rejections also get Retry-After
+// Times are whole seconds, rounded up so clients never retry too early.
+func setRateLimitHeaders(c *gin.Context, result RateLimitResult) {
+	reset := ceilSeconds(result.Reset)
 
-	// Read operations allow more requests
-	if method == "GET" {
-		return baseConfig.PerIP
-	}
+	c.Header("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+	c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	c.Header("X-RateLimit-Reset", strconv.Itoa(reset))
+	c.Header("RateLimit-Policy", fmt.Sprintf("%q;q=%d;w=%d", result.Policy, result.Limit, ceilSeconds(result.Window)))
+	c.Header("RateLimit", fmt.Sprintf("%q;r=%d;t=%d", result.Policy, result.Remaining, reset))
 
-	// Write operations have stricter limits
-	if method == "POST" || method == "PUT" || method == "DELETE" {
-		return baseConfig.PerIP / 2
+	if !result.Allowed {
+		c.Header("Retry-After", strconv.Itoa(max(ceilSeconds(result.RetryAfter), 1)))
 	}
-
-	return baseConfig.PerIP
 }
 
-// allowWithCustomLimit checks requests with custom limits
-func (rl *RateLimiter) allowWithCustomLimit(c *gin.Context, customLimit int) bool {
-	clientIP := getClientIP(c)
-	apiKey := c.GetHeader("X-API-Key")
-
-	now := time.Now()
-
-	rl.mu.Lock()
-	defer rl.mu.Unlock()
-
-	// Check IP limit (with custom limit)
-	if !rl.checkLimit(clientIP, customLimit, now, rl.ipRecords) {
-		return false
-	}
-
-	// Check API key limit if present
-	if apiKey != "" {
-		if !rl.checkLimit(apiKey, rl.config.PerAPIKey, now, rl.keyRecords) {
-			return false
-		}
-	}
-
-	return true
-}
-
-// getClientIP extracts the client IP from various sources
-func getClientIP(c *gin.Context) string {
-	// Check X-Forwarded-For header first (for proxy/load balancer scenarios)
-	if forwarded := c.GetHeader("X-Forwarded-For"); forwarded != "" {
-		// X-Forwarded-For can contain multiple IPs, take the first one
-		if idx := strings.Index(forwarded, ","); idx != -1 {
-			return strings.TrimSpace(forwarded[:idx])
-		}
-		return strings.TrimSpace(forwarded)
-	}
-
-	// Check X-Real-IP header
-	if realIP := c.GetHeader("X-Real-IP"); realIP != "" {
-		return strings.TrimSpace(realIP)
-	}
-
-	// Fall back to Gin's ClientIP method
-	return c.ClientIP()
+// ceilSeconds returns d in whole seconds, rounded up
+func ceilSeconds(d time.Duration) int {
+	return int((d + time.Second - 1) / time.Second)
 }
@@ -0,0 +1,201 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// RateLimitAlgorithm names the way requests are counted against a limit
+type RateLimitAlgorithm string
+
+// Rate limiting algorithms
+const (
+	// AlgorithmFixedWindow counts requests in windows starting at a client's first request;
+	// a client may send twice the limit around the end of a window
+	AlgorithmFixedWindow RateLimitAlgorithm = "fixed_window"
+	// AlgorithmTokenBucket refills limit tokens per window into a bucket holding limit+Burst
+	AlgorithmTokenBucket RateLimitAlgorithm = "token_bucket"
+	// AlgorithmSlidingLog remembers every request of the last window; exact, but keeps up to
+	// limit timestamps per client
+	AlgorithmSlidingLog RateLimitAlgorithm = "sliding_log"
+	// AlgorithmSlidingWindow weighs the previous window's count by its overlap with the last
+	// window; close to exact with two counters per client
+	AlgorithmSlidingWindow RateLimitAlgorithm = "sliding_window"
+)
+
+// ParseRateLimitAlgorithm returns the algorithm named name ("" = AlgorithmFixedWindow)
+func ParseRateLimitAlgorithm(name string) (RateLimitAlgorithm, error) {
+	switch algorithm := RateLimitAlgorithm(name); algorithm {
+	case "":
+		return AlgorithmFixedWindow, nil
+	case AlgorithmFixedWindow, AlgorithmTokenBucket, AlgorithmSlidingLog, AlgorithmSlidingWindow:
+		return algorithm, nil
+	default:
+		return "", fmt.Errorf("unknown rate limit algorithm: %s", name)
+	}
+}
+
+// limitResult is the outcome of counting one request
+type limitResult struct {
+	allowed    bool          // Whether the request fits the limit
+	remaining  int           // Requests the client may still send right away
+	reset      time.Duration // Time until the whole limit is available again
+	retryAfter time.Duration // Time until the next request fits, when denied
+}
+
+// limitAlgorithm counts a request against a client's record
+// A record is new when its LastSeen is zero; the store sets LastSeen after take returns.
+type limitAlgorithm interface {
+	take(record *RequestRecord, limit int, now time.Time) limitResult
+}
+
+// newLimitAlgorithm creates algorithm counting requests per window (Factory Pattern)
+// Unknown algorithms fall back to the fixed window; ParseRateLimitAlgorithm rejects them earlier.
+func newLimitAlgorithm(algorithm RateLimitAlgorithm, window time.Duration, burst int) limitAlgorithm {
+	switch algorithm {
+	case AlgorithmTokenBucket:
+		return tokenBucket{window: window, burst: burst}
+	case AlgorithmSlidingLog:
+		return slidingLog{window: window}
+	case AlgorithmSlidingWindow:
+		return slidingWindow{window: window}
+	default:
+		return fixedWindow{window: window}
+	}
+}
+
+// fixedWindow allows limit requests per window, the window starting at the first request
+type fixedWindow struct {
+	window time.Duration
+}
+
+func (a fixedWindow) take(record *RequestRecord, limit int, now time.Time) limitResult {
+	if record.LastSeen.IsZero() || now.Sub(record.FirstSeen) > a.window {
+		record.Count = 0
+		record.FirstSeen = now
+	}
+
+	reset := record.FirstSeen.Add(a.window).Sub(now)
+	if record.Count >= limit {
+		return limitResult{reset: reset, retryAfter: reset}
+	}
+	record.Count++
+	return limitResult{allowed: true, remaining: limit - record.Count, reset: reset}
+}
+
+// tokenBucket refills limit tokens per window, up to limit+burst, and spends one per request
+type tokenBucket struct {
+	window time.Duration
+	burst  int
+}
+
+func (a tokenBucket) take(record *RequestRecord, limit int, now time.Time) limitResult {
+	capacity := float64(limit + a.burst)
+	perToken := a.window / time.Duration(limit) // Refill time of one token
+
+	if record.LastSeen.IsZero() {
+		record.Tokens = capacity
+	} else if elapsed := now.Sub(record.LastSeen); elapsed > 0 {
+		record.Tokens = math.Min(capacity, record.Tokens+float64(elapsed)/float64(perToken))
+	}
+
+	if record.Tokens < 1 {
+		return limitResult{
+			reset:      a.refillTime(capacity-record.Tokens, perToken),
+			retryAfter: a.refillTime(1-record.Tokens, perToken),
+		}
+	}
+	record.Tokens--
+	return limitResult{allowed: true, remaining: int(record.Tokens), reset: a.refillTime(capacity-record.Tokens, perToken)}
+}
+
+// refillTime returns the time needed to refill tokens
+func (a tokenBucket) refillTime(tokens float64, perToken time.Duration) time.Duration {
+	return time.Duration(tokens * float64(perToken))
+}
+
+// slidingLog allows limit requests in any window-long period
+type slidingLog struct {
+	window time.Duration
+}
+
+func (a slidingLog) take(record *RequestRecord, limit int, now time.Time) limitResult {
+	// Forget requests that left the window
+	kept := record.Hits[:0]
+	for _, hit := range record.Hits {
+		if now.Sub(hit) < a.window {
+			kept = append(kept, hit)
+		}
+	}
+	record.Hits = kept
+
+	if len(record.Hits) >= limit {
+		// The request fits once enough of the oldest requests have left the window
+		oldest := record.Hits[len(record.Hits)-limit]
+		return limitResult{reset: a.reset(record, now), retryAfter: oldest.Add(a.window).Sub(now)}
+	}
+	record.Hits = append(record.Hits, now)
+	return limitResult{allowed: true, remaining: limit - len(record.Hits), reset: a.reset(record, now)}
+}
+
+// reset returns the time until the newest request leaves the window
+func (a slidingLog) reset(record *RequestRecord, now time.Time) time.Duration {
+	return record.Hits[len(record.Hits)-1].Add(a.window).Sub(now)
+}
+
+// slidingWindow estimates the requests of the last window from the counts of the current and
+// previous fixed windows, assuming the previous window's requests were evenly spread
+type slidingWindow struct {
+	window time.Duration
+}
+
+func (a slidingWindow) take(record *RequestRecord, limit int, now time.Time) limitResult {
+	if record.LastSeen.IsZero() {
+		record.Count = 0
+		record.PrevCount = 0
+		record.FirstSeen = now
+	} else if elapsed := now.Sub(record.FirstSeen); elapsed >= a.window {
+		// Move to the window containing now; after an idle window nothing carries over
+		windows := elapsed / a.window
+		record.PrevCount = 0
+		if windows == 1 {
+			record.PrevCount = record.Count
+		}
+		record.Count = 0
+		record.FirstSeen = record.FirstSeen.Add(windows * a.window)
+	}
+
+	elapsed := now.Sub(record.FirstSeen)
+	overlap := 1 - float64(elapsed)/float64(a.window) // Share of the previous window still inside the last window
+	estimate := float64(record.PrevCount)*overlap + float64(record.Count)
+
+	if estimate >= float64(limit) {
+		return limitResult{reset: a.reset(record, elapsed), retryAfter: a.retryAfter(record, limit, elapsed)}
+	}
+	record.Count++
+	remaining := int(float64(limit) - (estimate + 1))
+	return limitResult{allowed: true, remaining: max(remaining, 0), reset: a.reset(record, elapsed)}
+}
+
+// reset returns the time until no request weighs on the estimate: the end of this window, or
+// the end of the next one when this window has requests
+func (a slidingWindow) reset(record *RequestRecord, elapsed time.Duration) time.Duration {
+	if record.Count > 0 {
+		return 2*a.window - elapsed
+	}
+	return a.window - elapsed
+}
+
+// retryAfter returns the time until the estimate drops below limit
+func (a slidingWindow) retryAfter(record *RequestRecord, limit int, elapsed time.Duration) time.Duration {
+	window := float64(a.window)
+	if record.Count < limit {
+		// Within this window, once the previous window's weight has decreased enough
+		at := window * (1 - float64(limit-record.Count)/float64(record.PrevCount))
+		return time.Duration(at) - elapsed
+	}
+	// In the next window, where this window's count becomes the weighed one
+	at := window * (1 - float64(limit)/float64(record.Count))
+	return a.window - elapsed + time.Duration(at)
+}
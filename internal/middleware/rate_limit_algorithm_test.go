@@ -0,0 +1,233 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock is a time source the tests move by hand
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// newFakeClock returns a clock stopped at a fixed instant
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2025, 6, 9, 22, 0, 0, 0, time.UTC)}
+}
+
+// Now returns the clock's time
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance moves the clock forward by d
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// newClockedLimiter creates a limiter for algorithm driven by a fake clock, and a function
+// counting one request of a single client against limit
+func newClockedLimiter(t *testing.T, algorithm RateLimitAlgorithm, limit, burst int) (func() limitResult, *fakeClock) {
+	t.Helper()
+
+	clock := newFakeClock()
+	limiter := NewRateLimiter(RateLimitConfig{
+		Enabled:         true,
+		PerIP:           limit,
+		CleanupInterval: time.Hour,
+		WindowSize:      time.Minute,
+		Algorithm:       algorithm,
+		Burst:           burst,
+		Clock:           clock.Now,
+	})
+	t.Cleanup(limiter.Stop)
+
+	take := func() limitResult {
+		result, err := limiter.take(context.Background(), limiter.defaultPolicy, "192.168.1.50", limiter.now())
+		require.NoError(t, err)
+		return limitResult{allowed: result.Allowed, remaining: result.Remaining, reset: result.Reset, retryAfter: result.RetryAfter}
+	}
+	return take, clock
+}
+
+// takeN counts n requests and returns how many were allowed
+func takeN(take func() limitResult, n int) int {
+	allowed := 0
+	for i := 0; i < n; i++ {
+		if take().allowed {
+			allowed++
+		}
+	}
+	return allowed
+}
+
+func TestParseRateLimitAlgorithm(t *testing.T) {
+	for _, name := range []string{"fixed_window", "token_bucket", "sliding_log", "sliding_window"} {
+		algorithm, err := ParseRateLimitAlgorithm(name)
+		require.NoError(t, err)
+		assert.Equal(t, RateLimitAlgorithm(name), algorithm)
+	}
+
+	algorithm, err := ParseRateLimitAlgorithm("")
+	require.NoError(t, err)
+	assert.Equal(t, AlgorithmFixedWindow, algorithm)
+
+	_, err = ParseRateLimitAlgorithm("leaky_bucket")
+	assert.EqualError(t, err, "unknown rate limit algorithm: leaky_bucket")
+}
+
+func TestRateLimitAlgorithms_WindowBoundary(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// One request, then four just before the window ends: how many more fit right after it?
+	tests := []struct {
+		algorithm RateLimitAlgorithm
+		allowed   int
+	}{
+		{AlgorithmFixedWindow, 5}, // A fresh window: 9 requests within 2 seconds
+		{AlgorithmTokenBucket, 1},
+		{AlgorithmSlidingLog, 1},
+		{AlgorithmSlidingWindow, 1},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.algorithm), func(t *testing.T) {
+			clock := newFakeClock()
+			router := gin.New()
+			router.Use(testClientIP(t))
+			router.Use(RateLimit(RateLimitConfig{
+				Enabled:         true,
+				PerIP:           5,
+				PerAPIKey:       5,
+				CleanupInterval: time.Hour,
+				WindowSize:      time.Minute,
+				Algorithm:       tt.algorithm,
+				Clock:           clock.Now,
+			}))
+			router.GET("/test", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			sendN := func(n int) int {
+				allowed := 0
+				for i := 0; i < n; i++ {
+					req := httptest.NewRequest(http.MethodGet, "/test", nil)
+					req.Header.Set("X-Forwarded-For", "192.168.1.60")
+					w := httptest.NewRecorder()
+					router.ServeHTTP(w, req)
+					if w.Code == http.StatusOK {
+						allowed++
+					}
+				}
+				return allowed
+			}
+
+			require.Equal(t, 1, sendN(1))
+			clock.Advance(59 * time.Second)
+			require.Equal(t, 4, sendN(4))
+
+			clock.Advance(2 * time.Second)
+			assert.Equal(t, tt.allowed, sendN(10))
+		})
+	}
+}
+
+func TestRateLimitAlgorithm_FixedWindow(t *testing.T) {
+	take, clock := newClockedLimiter(t, AlgorithmFixedWindow, 3, 0)
+
+	assert.Equal(t, limitResult{allowed: true, remaining: 2, reset: time.Minute}, take())
+	assert.Equal(t, 2, takeN(take, 5))
+
+	clock.Advance(20 * time.Second)
+	assert.Equal(t, limitResult{reset: 40 * time.Second, retryAfter: 40 * time.Second}, take())
+
+	clock.Advance(41 * time.Second)
+	assert.Equal(t, 3, takeN(take, 5))
+}
+
+func TestRateLimitAlgorithm_TokenBucket(t *testing.T) {
+	// 4 tokens per minute (one every 15 seconds) in a bucket holding 6
+	take, clock := newClockedLimiter(t, AlgorithmTokenBucket, 4, 2)
+
+	assert.Equal(t, limitResult{allowed: true, remaining: 5, reset: 15 * time.Second}, take())
+	assert.Equal(t, 5, takeN(take, 5))
+	assert.Equal(t, limitResult{reset: 90 * time.Second, retryAfter: 15 * time.Second}, take())
+
+	t.Run("refills one token per limit-th of the window", func(t *testing.T) {
+		clock.Advance(15 * time.Second)
+		assert.Equal(t, limitResult{allowed: true, remaining: 0, reset: 90 * time.Second}, take())
+
+		clock.Advance(7500 * time.Millisecond)
+		assert.Equal(t, limitResult{reset: 82500 * time.Millisecond, retryAfter: 7500 * time.Millisecond}, take())
+
+		clock.Advance(7500 * time.Millisecond)
+		assert.True(t, take().allowed)
+	})
+
+	t.Run("never holds more than limit plus burst", func(t *testing.T) {
+		clock.Advance(time.Hour)
+		assert.Equal(t, 6, takeN(take, 10))
+	})
+}
+
+func TestRateLimitAlgorithm_SlidingLog(t *testing.T) {
+	take, clock := newClockedLimiter(t, AlgorithmSlidingLog, 3, 0)
+
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, limitResult{allowed: true, remaining: 2 - i, reset: time.Minute}, take())
+		clock.Advance(10 * time.Second)
+	}
+	assert.Equal(t, limitResult{reset: 50 * time.Second, retryAfter: 30 * time.Second}, take())
+
+	// The first request leaves the window a minute after it was sent
+	clock.Advance(30 * time.Second)
+	assert.Equal(t, limitResult{allowed: true, remaining: 0, reset: time.Minute}, take())
+
+	clock.Advance(time.Second)
+	assert.Equal(t, limitResult{reset: 59 * time.Second, retryAfter: 9 * time.Second}, take())
+}
+
+func TestRateLimitAlgorithm_SlidingWindow(t *testing.T) {
+	take, clock := newClockedLimiter(t, AlgorithmSlidingWindow, 10, 0)
+
+	assert.Equal(t, 10, takeN(take, 10))
+	assert.Equal(t, limitResult{reset: 2 * time.Minute, retryAfter: time.Minute}, take())
+
+	t.Run("the previous window counts for its overlap", func(t *testing.T) {
+		// 15 seconds into the next window, 3/4 of the previous one's 10 requests still count
+		clock.Advance(75 * time.Second)
+		assert.Equal(t, limitResult{allowed: true, remaining: 1, reset: 105 * time.Second}, take())
+		assert.Equal(t, 2, takeN(take, 2))
+		assert.Equal(t, limitResult{reset: 105 * time.Second, retryAfter: 3 * time.Second}, take())
+
+		clock.Advance(4 * time.Second)
+		assert.True(t, take().allowed)
+	})
+
+	t.Run("nothing carries over an idle window", func(t *testing.T) {
+		clock.Advance(2 * time.Minute)
+		assert.Equal(t, 10, takeN(take, 20))
+	})
+}
+
+func TestRateLimiter_MinimumLimit(t *testing.T) {
+	for _, algorithm := range []RateLimitAlgorithm{AlgorithmFixedWindow, AlgorithmTokenBucket, AlgorithmSlidingLog, AlgorithmSlidingWindow} {
+		t.Run(string(algorithm), func(t *testing.T) {
+			take, clock := newClockedLimiter(t, algorithm, 0, 0)
+
+			assert.Equal(t, 1, takeN(take, 3))
+			clock.Advance(61 * time.Second)
+			assert.Equal(t, 1, takeN(take, 3))
+		})
+	}
+}
@@ -0,0 +1,318 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultPolicyName names the default policy when the policy file leaves it unnamed
+const DefaultPolicyName = "default"
+
+// RateLimitPolicy is a per-client quota applied to the requests it matches
+// Every condition that is set must match; a condition listing several values matches any of them.
+// Zero limits (default policy only), windows, algorithms and bursts fall back to RateLimitConfig.
+type RateLimitPolicy struct {
+	Name      string             `json:"name"`                // Reported in the RateLimit headers and 429 responses
+	Methods   []string           `json:"methods,omitempty"`   // HTTP methods (empty = any)
+	Paths     []string           `json:"paths,omitempty"`     // Path patterns; ":name" and "*" match a segment, a final "**" the rest
+	Scopes    []string           `json:"scopes,omitempty"`    // Scopes, one of which the caller's API key must hold (empty = any caller)
+	CIDRs     []string           `json:"cidrs,omitempty"`     // Client IP ranges or addresses (empty = any client)
+	Limit     int                `json:"limit"`               // Requests per window and client IP
+	Window    string             `json:"window,omitempty"`    // Window length, such as "30s" or "1h"
+	Algorithm RateLimitAlgorithm `json:"algorithm,omitempty"` // Counting algorithm
+	Burst     int                `json:"burst,omitempty"`     // Token bucket: requests allowed at once on top of the limit
+}
+
+// hasConditions reports whether the policy restricts the requests it matches
+func (p RateLimitPolicy) hasConditions() bool {
+	return len(p.Methods) > 0 || len(p.Paths) > 0 || len(p.Scopes) > 0 || len(p.CIDRs) > 0
+}
+
+// RateLimitPolicies are the per-client quotas of a rate limiter
+type RateLimitPolicies struct {
+	Policies []RateLimitPolicy `json:"policies"` // Evaluated in order; the first matching policy applies
+	Default  RateLimitPolicy   `json:"default"`  // Applies to requests no policy matches
+}
+
+// DefaultRateLimitPolicies returns the built-in policies of SmartRateLimit
+// Health checks get five times the per-IP limit and writes half of it.
+func DefaultRateLimitPolicies(config RateLimitConfig) *RateLimitPolicies {
+	return &RateLimitPolicies{
+		Policies: []RateLimitPolicy{
+			{Name: "health", Paths: []string{"/health", "/api/v1/health"}, Limit: config.PerIP * 5},
+			{Name: "writes", Methods: []string{"POST", "PUT", "DELETE"}, Limit: config.PerIP / 2},
+		},
+		Default: RateLimitPolicy{Name: DefaultPolicyName},
+	}
+}
+
+// LoadRateLimitPolicies reads rate limit policies from a JSON file
+func LoadRateLimitPolicies(path string) (*RateLimitPolicies, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rate limit policy file: %w", err)
+	}
+
+	var policies RateLimitPolicies
+	if err := json.Unmarshal(data, &policies); err != nil {
+		return nil, fmt.Errorf("failed to decode rate limit policy file: %w", err)
+	}
+	if err := policies.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid rate limit policy file %s: %w", path, err)
+	}
+	return &policies, nil
+}
+
+// Validate checks the policies
+// Names must be unique, limits positive, and windows, algorithms, paths and CIDRs well-formed;
+// the default policy cannot have match conditions.
+func (p *RateLimitPolicies) Validate() error {
+	names := make(map[string]bool, len(p.Policies)+1)
+	for i, policy := range p.Policies {
+		if strings.TrimSpace(policy.Name) == "" {
+			return fmt.Errorf("policy %d has no name", i+1)
+		}
+		if names[policy.Name] {
+			return fmt.Errorf("policy %q is defined twice", policy.Name)
+		}
+		names[policy.Name] = true
+
+		if policy.Limit <= 0 {
+			return fmt.Errorf("policy %q: limit must be positive", policy.Name)
+		}
+		if err := validateRateLimitPolicy(policy); err != nil {
+			return fmt.Errorf("policy %q: %w", policy.Name, err)
+		}
+	}
+
+	name := p.Default.Name
+	if name == "" {
+		name = DefaultPolicyName
+	}
+	if names[name] {
+		return fmt.Errorf("policy %q is defined twice", name)
+	}
+	if p.Default.hasConditions() {
+		return fmt.Errorf("default policy cannot have match conditions")
+	}
+	if p.Default.Limit < 0 {
+		return fmt.Errorf("default policy: limit cannot be negative")
+	}
+	if err := validateRateLimitPolicy(p.Default); err != nil {
+		return fmt.Errorf("default policy: %w", err)
+	}
+	return nil
+}
+
+// validateRateLimitPolicy checks the conditions and counting settings of a policy
+func validateRateLimitPolicy(policy RateLimitPolicy) error {
+	for _, method := range policy.Methods {
+		if strings.TrimSpace(method) == "" {
+			return fmt.Errorf("methods cannot be empty")
+		}
+	}
+	for _, pattern := range policy.Paths {
+		if !strings.HasPrefix(pattern, "/") {
+			return fmt.Errorf("path %q must start with /", pattern)
+		}
+		if i := strings.Index(pattern, "**"); i >= 0 && i != len(pattern)-2 {
+			return fmt.Errorf("path %q may only end with **", pattern)
+		}
+	}
+	for _, scope := range policy.Scopes {
+		if strings.TrimSpace(scope) == "" {
+			return fmt.Errorf("scopes cannot be empty")
+		}
+	}
+	for _, cidr := range policy.CIDRs {
+		if _, ok := parseCIDR(cidr); !ok {
+			return fmt.Errorf("invalid CIDR %q", cidr)
+		}
+	}
+	if policy.Window != "" {
+		window, err := time.ParseDuration(policy.Window)
+		if err != nil || window <= 0 {
+			return fmt.Errorf("invalid window %q", policy.Window)
+		}
+	}
+	if policy.Algorithm != "" {
+		if _, err := ParseRateLimitAlgorithm(string(policy.Algorithm)); err != nil {
+			return err
+		}
+	}
+	if policy.Burst < 0 {
+		return fmt.Errorf("burst cannot be negative")
+	}
+	return nil
+}
+
+// parseCIDR parses an IP range, or a single address as a range holding only it
+func parseCIDR(cidr string) (netip.Prefix, bool) {
+	if prefix, err := netip.ParsePrefix(cidr); err == nil {
+		return prefix.Masked(), true
+	}
+	if addr, err := netip.ParseAddr(cidr); err == nil {
+		return netip.PrefixFrom(addr, addr.BitLen()), true
+	}
+	return netip.Prefix{}, false
+}
+
+// ratePolicy is a policy ready to count requests
+type ratePolicy struct {
+	name      string
+	methods   []string
+	paths     [][]string // Path patterns split into segments
+	scopes    []string
+	cidrs     []netip.Prefix
+	limit     int
+	window    time.Duration
+	algorithm RateLimitAlgorithm
+	burst     int
+	counter   limitAlgorithm // Counts requests against the limit
+}
+
+// newRatePolicy prepares policy, filling unset settings from config (Factory Pattern)
+// Malformed values are skipped; Validate reports them when the policies are loaded.
+func newRatePolicy(policy RateLimitPolicy, config RateLimitConfig) *ratePolicy {
+	rp := &ratePolicy{
+		name:      policy.Name,
+		scopes:    policy.Scopes,
+		limit:     policy.Limit,
+		window:    config.WindowSize,
+		algorithm: policy.Algorithm,
+		burst:     policy.Burst,
+	}
+	for _, method := range policy.Methods {
+		rp.methods = append(rp.methods, strings.ToUpper(strings.TrimSpace(method)))
+	}
+	for _, pattern := range policy.Paths {
+		rp.paths = append(rp.paths, pathSegments(pattern))
+	}
+	for _, cidr := range policy.CIDRs {
+		if prefix, ok := parseCIDR(cidr); ok {
+			rp.cidrs = append(rp.cidrs, prefix)
+		}
+	}
+	if window, err := time.ParseDuration(policy.Window); err == nil && window > 0 {
+		rp.window = window
+	}
+	if rp.algorithm == "" {
+		rp.algorithm = config.Algorithm
+	}
+	if rp.burst == 0 {
+		rp.burst = config.Burst
+	}
+	rp.counter = newLimitAlgorithm(rp.algorithm, rp.window, rp.burst)
+	return rp
+}
+
+// matches reports whether the request of the client at clientIP meets every condition of the policy
+func (rp *ratePolicy) matches(c *gin.Context, clientIP string) bool {
+	if len(rp.methods) > 0 && !slices.Contains(rp.methods, c.Request.Method) {
+		return false
+	}
+	if len(rp.paths) > 0 && !rp.matchesPath(c.Request.URL.Path) {
+		return false
+	}
+	if len(rp.scopes) > 0 && !rp.matchesScope(c) {
+		return false
+	}
+	if len(rp.cidrs) > 0 && !rp.matchesIP(clientIP) {
+		return false
+	}
+	return true
+}
+
+// matchesPath reports whether path matches one of the policy's patterns
+func (rp *ratePolicy) matchesPath(path string) bool {
+	segments := pathSegments(path)
+	for _, pattern := range rp.paths {
+		if matchSegments(pattern, segments) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesScope reports whether the caller authenticated with an API key holding one of the policy's scopes
+func (rp *ratePolicy) matchesScope(c *gin.Context) bool {
+	principal := GetPrincipal(c)
+	if principal == nil || principal.APIKeyID == "" {
+		return false
+	}
+	for _, scope := range rp.scopes {
+		if slices.Contains(principal.Scopes, scope) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesIP reports whether clientIP lies in one of the policy's ranges
+func (rp *ratePolicy) matchesIP(clientIP string) bool {
+	addr, err := netip.ParseAddr(clientIP)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range rp.cidrs {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// requestLimit returns the requests a client may send per window
+// Every client may send at least one request per window, whatever the limit.
+func (rp *ratePolicy) requestLimit() int {
+	return max(rp.limit, 1)
+}
+
+// recordTTL returns how long the policy's records are kept after a client's last request
+// Records are kept for two windows, or until a token bucket with a large burst has refilled.
+func (rp *ratePolicy) recordTTL() time.Duration {
+	ttl := rp.window * 2
+	if rp.algorithm == AlgorithmTokenBucket && rp.limit > 0 {
+		ttl = max(ttl, rp.window*time.Duration(rp.limit+rp.burst)/time.Duration(rp.limit))
+	}
+	return ttl
+}
+
+// stats describes the policy's quota
+func (rp *ratePolicy) stats() map[string]interface{} {
+	return map[string]interface{}{
+		"name":      rp.name,
+		"limit":     rp.limit,
+		"window":    rp.window.String(),
+		"algorithm": string(rp.algorithm),
+	}
+}
+
+// pathSegments splits a path into its segments, ignoring empty ones
+func pathSegments(path string) []string {
+	return strings.FieldsFunc(path, func(r rune) bool { return r == '/' })
+}
+
+// matchSegments reports whether a path matches a pattern, both split into segments
+func matchSegments(pattern, path []string) bool {
+	for i, segment := range pattern {
+		if segment == "**" {
+			return true
+		}
+		if i >= len(path) {
+			return false
+		}
+		if segment != "*" && !strings.HasPrefix(segment, ":") && segment != path[i] {
+			return false
+		}
+	}
+	return len(pattern) == len(path)
+}
@@ -0,0 +1,207 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"task-api/internal/apikeys"
+	"task-api/internal/models"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupPolicyRouter serves GET and POST /api/v1/tasks and GET /health behind APIKeyAuth and
+// SmartRateLimit with policies, driven by a fake clock
+func setupPolicyRouter(t *testing.T, policies *RateLimitPolicies) (*gin.Engine, *apikeys.Store, *fakeClock) {
+	t.Helper()
+
+	store, err := apikeys.NewStore("")
+	require.NoError(t, err)
+	require.NoError(t, policies.Validate())
+
+	clock := newFakeClock()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(testClientIP(t))
+	router.Use(APIKeyAuth(store))
+	router.Use(SmartRateLimit(RateLimitConfig{
+		Enabled:         true,
+		PerIP:           3,
+		PerAPIKey:       100,
+		CleanupInterval: time.Hour,
+		WindowSize:      time.Minute,
+		Clock:           clock.Now,
+		Policies:        policies,
+	}))
+	router.GET("/health", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.GET("/api/v1/tasks", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.POST("/api/v1/tasks", func(c *gin.Context) { c.Status(http.StatusCreated) })
+	router.GET("/api/v1/tasks/:id/history", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return router, store, clock
+}
+
+// sendN performs n requests and returns how many were not rate limited
+func sendN(router *gin.Engine, n int, method, path string, headers map[string]string) int {
+	allowed := 0
+	for i := 0; i < n; i++ {
+		if send(router, method, path, headers).Code != http.StatusTooManyRequests {
+			allowed++
+		}
+	}
+	return allowed
+}
+
+func TestLoadRateLimitPolicies(t *testing.T) {
+	t.Run("loads the example policies", func(t *testing.T) {
+		policies, err := LoadRateLimitPolicies(filepath.Join("..", "..", "examples", "rate-limit-policies.json"))
+		require.NoError(t, err)
+		assert.NotEmpty(t, policies.Policies)
+	})
+
+	t.Run("reports unreadable and malformed files", func(t *testing.T) {
+		dir := t.TempDir()
+		_, err := LoadRateLimitPolicies(filepath.Join(dir, "missing.json"))
+		assert.ErrorContains(t, err, "failed to read rate limit policy file")
+
+		path := filepath.Join(dir, "broken.json")
+		require.NoError(t, os.WriteFile(path, []byte("{"), 0o600))
+		_, err = LoadRateLimitPolicies(path)
+		assert.ErrorContains(t, err, "failed to decode rate limit policy file")
+	})
+
+	t.Run("rejects invalid policies", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "policies.json")
+		data, err := json.Marshal(RateLimitPolicies{Policies: []RateLimitPolicy{{Name: "reads", Limit: 0}}})
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(path, data, 0o600))
+
+		_, err = LoadRateLimitPolicies(path)
+		assert.ErrorContains(t, err, `policy "reads": limit must be positive`)
+	})
+}
+
+func TestRateLimitPolicies_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		policies RateLimitPolicies
+		err      string
+	}{
+		{"unnamed policy", RateLimitPolicies{Policies: []RateLimitPolicy{{Limit: 1}}}, "policy 1 has no name"},
+		{"duplicate names", RateLimitPolicies{Policies: []RateLimitPolicy{{Name: "a", Limit: 1}, {Name: "a", Limit: 2}}}, `policy "a" is defined twice`},
+		{"default name taken", RateLimitPolicies{Policies: []RateLimitPolicy{{Name: "default", Limit: 1}}}, `policy "default" is defined twice`},
+		{"relative path", RateLimitPolicies{Policies: []RateLimitPolicy{{Name: "a", Limit: 1, Paths: []string{"tasks"}}}}, `policy "a": path "tasks" must start with /`},
+		{"inner double star", RateLimitPolicies{Policies: []RateLimitPolicy{{Name: "a", Limit: 1, Paths: []string{"/api/**/history"}}}}, `policy "a": path "/api/**/history" may only end with **`},
+		{"bad CIDR", RateLimitPolicies{Policies: []RateLimitPolicy{{Name: "a", Limit: 1, CIDRs: []string{"10.0.0.0/33"}}}}, `policy "a": invalid CIDR "10.0.0.0/33"`},
+		{"bad window", RateLimitPolicies{Policies: []RateLimitPolicy{{Name: "a", Limit: 1, Window: "soon"}}}, `policy "a": invalid window "soon"`},
+		{"bad algorithm", RateLimitPolicies{Policies: []RateLimitPolicy{{Name: "a", Limit: 1, Algorithm: "leaky_bucket"}}}, `policy "a": unknown rate limit algorithm: leaky_bucket`},
+		{"negative burst", RateLimitPolicies{Policies: []RateLimitPolicy{{Name: "a", Limit: 1, Burst: -1}}}, `policy "a": burst cannot be negative`},
+		{"conditional default", RateLimitPolicies{Default: RateLimitPolicy{Methods: []string{"GET"}}}, "default policy cannot have match conditions"},
+		{"negative default limit", RateLimitPolicies{Default: RateLimitPolicy{Limit: -1}}, "default policy: limit cannot be negative"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.EqualError(t, tt.policies.Validate(), tt.err)
+		})
+	}
+
+	policies := DefaultRateLimitPolicies(DefaultRateLimitConfig())
+	assert.NoError(t, policies.Validate())
+}
+
+func TestMatchSegments(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		matches bool
+	}{
+		{"/api/v1/tasks", "/api/v1/tasks", true},
+		{"/api/v1/tasks", "/api/v1/tasks/", true},
+		{"/api/v1/tasks", "/api/v1/tasks/42", false},
+		{"/api/v1/tasks/:id", "/api/v1/tasks/42", true},
+		{"/api/v1/tasks/*/history", "/api/v1/tasks/42/history", true},
+		{"/api/v1/tasks/*/history", "/api/v1/tasks/42/versions", false},
+		{"/api/v1/**", "/api/v1", true},
+		{"/api/v1/**", "/api/v1/tasks/42/history", true},
+		{"/api/v1/**", "/api/v2/tasks", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.matches, matchSegments(pathSegments(tt.pattern), pathSegments(tt.path)), "%s against %s", tt.path, tt.pattern)
+	}
+}
+
+func TestSmartRateLimit_Policies(t *testing.T) {
+	router, store, clock := setupPolicyRouter(t, &RateLimitPolicies{
+		Policies: []RateLimitPolicy{
+			{Name: "office", CIDRs: []string{"10.1.0.0/16", "192.168.1.99"}, Limit: 50},
+			{Name: "integrations", Scopes: []string{models.ScopeTasksWrite}, Limit: 20},
+			{Name: "history", Paths: []string{"/api/v1/tasks/:id/history"}, Limit: 1, Window: "10s"},
+			{Name: "writes", Methods: []string{"post"}, Paths: []string{"/api/v1/**"}, Limit: 2, Algorithm: AlgorithmSlidingLog},
+		},
+		Default: RateLimitPolicy{Name: "reads"},
+	})
+
+	t.Run("unmatched requests get the default policy", func(t *testing.T) {
+		headers := map[string]string{"X-Forwarded-For": "203.0.113.1"}
+		w := send(router, http.MethodGet, "/api/v1/tasks", headers)
+		assert.Equal(t, `"reads";q=3;w=60`, w.Header().Get("RateLimit-Policy"))
+		assert.Equal(t, 2, sendN(router, 5, http.MethodGet, "/health", headers))
+	})
+
+	t.Run("methods and paths select a policy", func(t *testing.T) {
+		headers := map[string]string{"X-Forwarded-For": "203.0.113.2"}
+		assert.Equal(t, 2, sendN(router, 5, http.MethodPost, "/api/v1/tasks", headers))
+
+		// Every policy counts its own requests
+		assert.Equal(t, 3, sendN(router, 5, http.MethodGet, "/api/v1/tasks", headers))
+	})
+
+	t.Run("policies have their own window", func(t *testing.T) {
+		headers := map[string]string{"X-Forwarded-For": "203.0.113.3"}
+		w := send(router, http.MethodGet, "/api/v1/tasks/42/history", headers)
+		assert.Equal(t, `"history";q=1;w=10`, w.Header().Get("RateLimit-Policy"))
+		assert.Equal(t, 0, sendN(router, 1, http.MethodGet, "/api/v1/tasks/7/history", headers))
+
+		clock.Advance(11 * time.Second)
+		assert.Equal(t, 1, sendN(router, 2, http.MethodGet, "/api/v1/tasks/42/history", headers))
+	})
+
+	t.Run("client ranges select a policy", func(t *testing.T) {
+		assert.Equal(t, 10, sendN(router, 10, http.MethodPost, "/api/v1/tasks", map[string]string{"X-Forwarded-For": "10.1.2.3"}))
+		assert.Equal(t, 10, sendN(router, 10, http.MethodPost, "/api/v1/tasks", map[string]string{"X-Forwarded-For": "192.168.1.99"}))
+	})
+
+	t.Run("API key scopes select a policy", func(t *testing.T) {
+		writer := issueKey(t, store, models.ScopeTasksWrite)
+		reader := issueKey(t, store, models.ScopeTasksRead)
+
+		w := send(router, http.MethodPost, "/api/v1/tasks", map[string]string{"X-Forwarded-For": "203.0.113.4", "X-API-Key": writer.Key})
+		assert.Equal(t, `"integrations";q=20;w=60`, w.Header().Get("RateLimit-Policy"))
+
+		w = send(router, http.MethodPost, "/api/v1/tasks", map[string]string{"X-Forwarded-For": "203.0.113.5", "X-API-Key": reader.Key})
+		assert.Equal(t, `"writes";q=2;w=60`, w.Header().Get("RateLimit-Policy"))
+	})
+
+	t.Run("rejections name the policy", func(t *testing.T) {
+		headers := map[string]string{"X-Forwarded-For": "203.0.113.6"}
+		sendN(router, 2, http.MethodPost, "/api/v1/tasks", headers)
+
+		w := send(router, http.MethodPost, "/api/v1/tasks", headers)
+		require.Equal(t, http.StatusTooManyRequests, w.Code)
+
+		var body struct {
+			Details map[string]interface{} `json:"details"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, map[string]interface{}{
+			"path":   "/api/v1/tasks",
+			"method": "POST",
+			"policy": "writes",
+			"limit":  float64(2),
+		}, body.Details)
+	})
+}
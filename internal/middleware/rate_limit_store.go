@@ -0,0 +1,295 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"task-api/internal/resp"
+	"time"
+)
+
+// RateLimitStore keeps the request records of rate limited clients
+// Limiters sharing a store count requests together, so Take must be atomic across all of them.
+type RateLimitStore interface {
+	// Take counts a request of the record key against policy at now, on a zero record when there
+	// is none or it expired, and keeps the record for the policy's record TTL
+	Take(ctx context.Context, key string, policy *ratePolicy, now time.Time) (limitResult, error)
+	// Count returns the number of records whose key starts with prefix
+	Count(ctx context.Context, prefix string) (int, error)
+	// Cleanup removes the records that expired by now; stores expiring records themselves do nothing
+	Cleanup(now time.Time)
+}
+
+// Record key prefixes
+const (
+	ipRecordPrefix  = "ip:"  // Client IP records, followed by the policy and the IP
+	keyRecordPrefix = "key:" // API key records, followed by the key's SHA-256
+)
+
+// ipRecordKey returns the key of the record counting a client IP's requests under policy
+func ipRecordKey(policy, clientIP string) string {
+	return ipRecordPrefix + policy + ":" + clientIP
+}
+
+// apiKeyRecordKey returns the key of the record counting an API key's requests
+// Keys are hashed so shared stores never hold them.
+func apiKeyRecordKey(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return keyRecordPrefix + hex.EncodeToString(sum[:])
+}
+
+// memoryEntry is a record of a MemoryRateLimitStore
+type memoryEntry struct {
+	record  RequestRecord
+	expires time.Time // When the record is dropped
+}
+
+// MemoryRateLimitStore keeps records in process memory, counting the requests of one instance
+type MemoryRateLimitStore struct {
+	records map[string]*memoryEntry // Records by key
+	mutex   sync.Mutex              // Protects records
+}
+
+// NewMemoryRateLimitStore creates an empty in-memory store (Factory Pattern)
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{records: make(map[string]*memoryEntry)}
+}
+
+// Take counts a request of the record key with the policy's algorithm under the store's lock
+func (s *MemoryRateLimitStore) Take(_ context.Context, key string, policy *ratePolicy, now time.Time) (limitResult, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	entry, exists := s.records[key]
+	if !exists || !now.Before(entry.expires) {
+		entry = &memoryEntry{}
+		s.records[key] = entry
+	}
+	counted := policy.counter.take(&entry.record, policy.requestLimit(), now)
+	entry.record.LastSeen = now
+	entry.expires = now.Add(policy.recordTTL())
+	return counted, nil
+}
+
+// Count returns the number of records whose key starts with prefix
+func (s *MemoryRateLimitStore) Count(_ context.Context, prefix string) (int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	count := 0
+	for key := range s.records {
+		if strings.HasPrefix(key, prefix) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// Cleanup removes the records that expired by now
+func (s *MemoryRateLimitStore) Cleanup(now time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for key, entry := range s.records {
+		if !now.Before(entry.expires) {
+			delete(s.records, key)
+		}
+	}
+}
+
+// DefaultRedisKeyPrefix starts the keys of the rate limit records kept in Redis
+const DefaultRedisKeyPrefix = "task-api:ratelimit:"
+
+// takeScript counts a request against a record kept as a hash, the way the algorithms of
+// rate_limit_algorithm.go do, and expires the record
+// KEYS[1] is the record's key; ARGV holds the algorithm, the limit, the current time in
+// microseconds, the window in microseconds, the burst and the record's time to live in
+// milliseconds. Returns {allowed (0 or 1), remaining, reset, retry after}, durations in microseconds.
+const takeScript = `
+local key = KEYS[1]
+local algorithm = ARGV[1]
+local limit = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local window = tonumber(ARGV[4])
+local burst = tonumber(ARGV[5])
+
+local fields = redis.call('HMGET', key, 'last', 'count', 'first', 'prev', 'tokens', 'hits')
+local last = tonumber(fields[1])
+local count = tonumber(fields[2]) or 0
+local first = tonumber(fields[3]) or 0
+local prev = tonumber(fields[4]) or 0
+local tokens = tonumber(fields[5]) or 0
+local hits = fields[6] or ''
+local allowed, remaining, reset, retry = 0, 0, 0, 0
+
+if algorithm == 'token_bucket' then
+	local capacity = limit + burst
+	local per_token = window / limit
+	if not last then
+		tokens = capacity
+	elseif now > last then
+		tokens = math.min(capacity, tokens + (now - last) / per_token)
+	end
+	if tokens < 1 then
+		retry = (1 - tokens) * per_token
+	else
+		tokens = tokens - 1
+		allowed, remaining = 1, math.floor(tokens)
+	end
+	reset = (capacity - tokens) * per_token
+elseif algorithm == 'sliding_log' then
+	local kept = {}
+	for hit in string.gmatch(hits, '%d+') do
+		hit = tonumber(hit)
+		if now - hit < window then
+			kept[#kept + 1] = hit
+		end
+	end
+	if #kept >= limit then
+		retry = kept[#kept - limit + 1] + window - now
+	else
+		kept[#kept + 1] = now
+		allowed, remaining = 1, limit - #kept
+	end
+	reset = kept[#kept] + window - now
+	for i, hit in ipairs(kept) do
+		kept[i] = string.format('%d', hit)
+	end
+	hits = table.concat(kept, ' ')
+elseif algorithm == 'sliding_window' then
+	if not last then
+		count, prev, first = 0, 0, now
+	elseif now - first >= window then
+		local windows = math.floor((now - first) / window)
+		prev = windows == 1 and count or 0
+		count = 0
+		first = first + windows * window
+	end
+	local elapsed = now - first
+	local estimate = prev * (1 - elapsed / window) + count
+	if estimate >= limit then
+		if count < limit then
+			retry = window * (1 - (limit - count) / prev) - elapsed
+		else
+			retry = window - elapsed + window * (1 - limit / count)
+		end
+	else
+		count = count + 1
+		allowed, remaining = 1, math.max(math.floor(limit - (estimate + 1)), 0)
+	end
+	reset = (count > 0 and 2 or 1) * window - elapsed
+else
+	if not last or now - first > window then
+		count, first = 0, now
+	end
+	reset = first + window - now
+	if count >= limit then
+		retry = reset
+	else
+		count = count + 1
+		allowed, remaining = 1, limit - count
+	end
+end
+
+redis.call('HSET', key, 'last', now, 'count', count, 'first', first, 'prev', prev, 'tokens', tokens, 'hits', hits)
+redis.call('PEXPIRE', key, ARGV[6])
+return {allowed, remaining, reset, retry}
+`
+
+// takeRequest is the script counting the requests of RedisRateLimitStore
+var takeRequest = resp.NewScript(takeScript)
+
+// RedisRateLimitStore keeps records in a Redis-compatible server shared by every instance
+// Requests are counted by a script running on the server, so every algorithm counts exactly
+// across instances in one round trip, provided their clocks agree. Redis expires the records.
+type RedisRateLimitStore struct {
+	client *resp.Client
+	prefix string // Prefix of every key
+}
+
+// NewRedisRateLimitStore creates a store keeping records in client's server under keys starting
+// with prefix ("" = DefaultRedisKeyPrefix) (Factory Pattern)
+func NewRedisRateLimitStore(client *resp.Client, prefix string) *RedisRateLimitStore {
+	if prefix == "" {
+		prefix = DefaultRedisKeyPrefix
+	}
+	return &RedisRateLimitStore{client: client, prefix: prefix}
+}
+
+// Take counts a request of the record key with takeScript
+func (s *RedisRateLimitStore) Take(ctx context.Context, key string, policy *ratePolicy, now time.Time) (limitResult, error) {
+	reply, err := takeRequest.Run(ctx, s.client, []string{s.prefix + key},
+		string(policy.algorithm),
+		strconv.Itoa(policy.requestLimit()),
+		strconv.FormatInt(now.UnixMicro(), 10),
+		strconv.FormatInt(policy.window.Microseconds(), 10),
+		strconv.Itoa(policy.burst),
+		strconv.FormatInt(max(policy.recordTTL().Milliseconds(), 1), 10),
+	)
+	if err != nil {
+		return limitResult{}, fmt.Errorf("failed to count request: %w", err)
+	}
+
+	counted, _ := reply.([]interface{})
+	values := make([]int64, len(counted))
+	for i, value := range counted {
+		values[i], _ = value.(int64)
+	}
+	if len(values) != 4 {
+		return limitResult{}, fmt.Errorf("failed to count request: unexpected script reply")
+	}
+	return limitResult{
+		allowed:    values[0] == 1,
+		remaining:  int(values[1]),
+		reset:      time.Duration(values[2]) * time.Microsecond,
+		retryAfter: time.Duration(values[3]) * time.Microsecond,
+	}, nil
+}
+
+// Count returns the number of records whose key starts with prefix, scanning the server's keys
+func (s *RedisRateLimitStore) Count(ctx context.Context, prefix string) (int, error) {
+	pattern := escapeGlob(s.prefix+prefix) + "*"
+	count := 0
+	cursor := "0"
+	for {
+		reply, err := s.client.Do(ctx, "SCAN", cursor, "MATCH", pattern, "COUNT", "1000")
+		if err != nil {
+			return 0, fmt.Errorf("failed to count rate limit records: %w", err)
+		}
+		page, ok := reply.([]interface{})
+		if !ok || len(page) != 2 {
+			return 0, fmt.Errorf("failed to count rate limit records: unexpected SCAN reply")
+		}
+		keys, _ := page[1].([]interface{})
+		count += len(keys)
+
+		if cursor, _ = page[0].(string); cursor == "0" || cursor == "" {
+			return count, nil
+		}
+	}
+}
+
+// Cleanup does nothing: Redis expires the records
+func (s *RedisRateLimitStore) Cleanup(time.Time) {}
+
+// Close closes the connections to the server
+func (s *RedisRateLimitStore) Close() error {
+	return s.client.Close()
+}
+
+// escapeGlob escapes the characters Redis glob patterns treat specially
+func escapeGlob(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '*', '?', '[', ']', '\\':
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,359 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"task-api/internal/resp"
+	"task-api/internal/resp/resptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// emulatedTakeScriptHash is the hash of the takeScript newFakeRedis reimplements in Go
+// TestTakeScript fails when takeScript changes, so the emulation is updated with it.
+const emulatedTakeScriptHash = "83221869f2569609c7dff35162c8c45ef6ad25a1"
+
+// newFakeRedis starts a RESP server running takeScript, counting with the algorithms of
+// rate_limit_algorithm.go over records kept as JSON
+func newFakeRedis(t *testing.T) *resptest.Server {
+	t.Helper()
+
+	server := resptest.NewServer("")
+	t.Cleanup(server.Close)
+	server.HandleScript(takeScript, func(tx *resptest.Tx, keys, args []string) (interface{}, error) {
+		var record RequestRecord
+		if current, ok := tx.Get(keys[0]); ok {
+			if err := json.Unmarshal([]byte(current), &record); err != nil {
+				return nil, err
+			}
+		}
+		numbers := make([]int64, len(args)-1) // Limit, now, window, burst and time to live
+		for i, arg := range args[1:] {
+			number, err := strconv.ParseInt(arg, 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			numbers[i] = number
+		}
+
+		now := time.UnixMicro(numbers[1])
+		algorithm := newLimitAlgorithm(RateLimitAlgorithm(args[0]), time.Duration(numbers[2])*time.Microsecond, int(numbers[3]))
+		counted := algorithm.take(&record, int(numbers[0]), now)
+		record.LastSeen = now
+		data, err := json.Marshal(record)
+		if err != nil {
+			return nil, err
+		}
+		tx.Set(keys[0], string(data), time.Duration(numbers[4])*time.Millisecond)
+
+		allowed := int64(0)
+		if counted.allowed {
+			allowed = 1
+		}
+		return []interface{}{allowed, int64(counted.remaining), counted.reset.Microseconds(), counted.retryAfter.Microseconds()}, nil
+	})
+	return server
+}
+
+// newRedisStore creates a store with its own connections to server
+func newRedisStore(t *testing.T, server *resptest.Server) *RedisRateLimitStore {
+	t.Helper()
+
+	store := NewRedisRateLimitStore(resp.NewClient(resp.Options{Addr: server.Addr()}), "")
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+// newTestPolicy creates a policy of limit requests per minute counted with algorithm
+func newTestPolicy(algorithm RateLimitAlgorithm, limit int) *ratePolicy {
+	return newRatePolicy(RateLimitPolicy{Name: DefaultPolicyName, Limit: limit, Burst: 2}, RateLimitConfig{WindowSize: time.Minute, Algorithm: algorithm})
+}
+
+// takeConcurrently counts 60 requests of key from 12 goroutines spread over stores, returning
+// how many were allowed
+func takeConcurrently(t *testing.T, stores []RateLimitStore, key string, policy *ratePolicy, now time.Time) int {
+	t.Helper()
+
+	var allowed atomic.Int64
+	var wg sync.WaitGroup
+	for i := 0; i < 12; i++ {
+		wg.Add(1)
+		go func(store RateLimitStore) {
+			defer wg.Done()
+			for j := 0; j < 5; j++ {
+				counted, err := store.Take(context.Background(), key, policy, now)
+				assert.NoError(t, err)
+				if counted.allowed {
+					allowed.Add(1)
+				}
+			}
+		}(stores[i%len(stores)])
+	}
+	wg.Wait()
+	return int(allowed.Load())
+}
+
+func TestTakeScript(t *testing.T) {
+	assert.Equal(t, emulatedTakeScriptHash, takeRequest.Hash(), "update the emulation in newFakeRedis along with the script")
+}
+
+func TestMemoryRateLimitStore(t *testing.T) {
+	store := NewMemoryRateLimitStore()
+	ctx := context.Background()
+	now := time.Date(2025, 6, 9, 22, 0, 0, 0, time.UTC)
+	policy := newTestPolicy(AlgorithmFixedWindow, 5)
+
+	var counted limitResult
+	for i := 0; i < 3; i++ {
+		var err error
+		counted, err = store.Take(ctx, "ip:default:192.168.1.80", policy, now)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, counted.remaining)
+	_, err := store.Take(ctx, "key:abc", policy, now)
+	require.NoError(t, err)
+
+	count, err := store.Count(ctx, ipRecordPrefix)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	t.Run("expired records start over", func(t *testing.T) {
+		counted, err := store.Take(ctx, "ip:default:192.168.1.80", policy, now.Add(2*time.Minute))
+		require.NoError(t, err)
+		assert.Equal(t, 4, counted.remaining)
+	})
+
+	t.Run("cleanup drops expired records", func(t *testing.T) {
+		store.Cleanup(now.Add(3 * time.Minute))
+		count, err := store.Count(ctx, "")
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+	})
+}
+
+func TestRedisRateLimitStore(t *testing.T) {
+	server := newFakeRedis(t)
+	store := newRedisStore(t, server)
+	ctx := context.Background()
+	now := time.Date(2025, 6, 9, 22, 0, 0, 0, time.UTC)
+	policy := newTestPolicy(AlgorithmSlidingLog, 2)
+
+	counted, err := store.Take(ctx, "ip:default:192.168.1.81", policy, now)
+	require.NoError(t, err)
+	assert.Equal(t, limitResult{allowed: true, remaining: 1, reset: time.Minute}, counted)
+
+	counted, err = store.Take(ctx, "ip:default:192.168.1.81", policy, now.Add(time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, limitResult{allowed: true, remaining: 0, reset: time.Minute}, counted)
+
+	counted, err = store.Take(ctx, "ip:default:192.168.1.81", policy, now.Add(2*time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, limitResult{reset: 59 * time.Second, retryAfter: 58 * time.Second}, counted)
+
+	// One script call per request, sent in full only while the server does not know it
+	assert.Equal(t, 3, server.Calls("EVALSHA"))
+	assert.Equal(t, 1, server.Calls("EVAL"))
+	assert.Zero(t, server.Calls("GET"))
+
+	t.Run("keys are prefixed and API keys hashed", func(t *testing.T) {
+		_, err := store.Take(ctx, apiKeyRecordKey("tk_secret"), policy, now)
+		require.NoError(t, err)
+		keys := server.Keys()
+		assert.Contains(t, keys, DefaultRedisKeyPrefix+"ip:default:192.168.1.81")
+		for _, key := range keys {
+			assert.True(t, strings.HasPrefix(key, DefaultRedisKeyPrefix))
+			assert.NotContains(t, key, "tk_secret")
+		}
+
+		count, err := store.Count(ctx, keyRecordPrefix)
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+	})
+}
+
+func TestRedisRateLimitStore_ConcurrentStores(t *testing.T) {
+	now := time.Date(2025, 6, 9, 22, 0, 0, 0, time.UTC)
+
+	for _, algorithm := range []RateLimitAlgorithm{AlgorithmFixedWindow, AlgorithmTokenBucket, AlgorithmSlidingLog, AlgorithmSlidingWindow} {
+		t.Run(string(algorithm), func(t *testing.T) {
+			server := newFakeRedis(t)
+			stores := []RateLimitStore{newRedisStore(t, server), newRedisStore(t, server), newRedisStore(t, server)}
+			policy := newTestPolicy(algorithm, 25)
+
+			// Token buckets also allow the burst
+			want := 25
+			if algorithm == AlgorithmTokenBucket {
+				want += policy.burst
+			}
+			assert.Equal(t, want, takeConcurrently(t, stores, "ip:default:192.168.1.82", policy, now))
+			assert.Equal(t, 60, server.Calls("EVALSHA"))
+		})
+	}
+}
+
+// TestRedisRateLimitStore_RealServer runs takeScript on the server at REDIS_URL
+// (e.g. redis://localhost:6379/15), under a key prefix of its own; skipped when unset.
+func TestRedisRateLimitStore_RealServer(t *testing.T) {
+	url := os.Getenv("REDIS_URL")
+	if url == "" {
+		t.Skip("REDIS_URL is not set")
+	}
+	options, err := resp.ParseURL(url)
+	require.NoError(t, err)
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Millisecond)
+
+	client := resp.NewClient(options)
+	require.NoError(t, client.Ping(ctx))
+	prefix := fmt.Sprintf("task-api:test:%d:", now.UnixNano())
+	t.Cleanup(func() {
+		reply, err := client.Do(context.Background(), "SCAN", "0", "MATCH", escapeGlob(prefix)+"*", "COUNT", "1000")
+		if page, ok := reply.([]interface{}); err == nil && ok && len(page) == 2 {
+			keys, _ := page[1].([]interface{})
+			for _, key := range keys {
+				if key, ok := key.(string); ok {
+					client.Do(context.Background(), "DEL", key)
+				}
+			}
+		}
+		client.Close()
+	})
+	newStore := func() RateLimitStore {
+		store := NewRedisRateLimitStore(resp.NewClient(options), prefix)
+		t.Cleanup(func() { store.Close() })
+		return store
+	}
+
+	for _, algorithm := range []RateLimitAlgorithm{AlgorithmFixedWindow, AlgorithmTokenBucket, AlgorithmSlidingLog, AlgorithmSlidingWindow} {
+		t.Run(string(algorithm)+" counts like the in-memory store", func(t *testing.T) {
+			redis, memory := newStore(), NewMemoryRateLimitStore()
+			policy := newTestPolicy(algorithm, 5)
+			key := "ip:default:" + string(algorithm)
+
+			at := now
+			for i := 0; i < 40; i++ {
+				at = at.Add(time.Duration(i%7) * 3 * time.Second)
+				want, err := memory.Take(ctx, key, policy, at)
+				require.NoError(t, err)
+				got, err := redis.Take(ctx, key, policy, at)
+				require.NoError(t, err)
+
+				assert.Equal(t, want.allowed, got.allowed, "request %d", i)
+				assert.Equal(t, want.remaining, got.remaining, "request %d", i)
+				assert.InDelta(t, want.reset, got.reset, float64(time.Millisecond), "request %d", i)
+				assert.InDelta(t, want.retryAfter, got.retryAfter, float64(time.Millisecond), "request %d", i)
+			}
+		})
+	}
+
+	t.Run("concurrent requests are counted exactly", func(t *testing.T) {
+		stores := []RateLimitStore{newStore(), newStore(), newStore()}
+		policy := newTestPolicy(AlgorithmFixedWindow, 1000)
+		assert.Equal(t, 60, takeConcurrently(t, stores, "ip:default:192.168.1.85", policy, now))
+
+		counted, err := stores[0].Take(ctx, "ip:default:192.168.1.85", policy, now)
+		require.NoError(t, err)
+		assert.Equal(t, 1000-61, counted.remaining)
+	})
+
+	t.Run("records expire after their time to live", func(t *testing.T) {
+		policy := newTestPolicy(AlgorithmFixedWindow, 5)
+		_, err := newStore().Take(ctx, "ip:default:192.168.1.86", policy, now)
+		require.NoError(t, err)
+
+		reply, err := client.Do(ctx, "PTTL", prefix+"ip:default:192.168.1.86")
+		require.NoError(t, err)
+		require.IsType(t, int64(0), reply)
+		assert.Greater(t, reply.(int64), int64(0))
+		assert.LessOrEqual(t, reply.(int64), policy.recordTTL().Milliseconds())
+	})
+}
+
+func TestRedisRateLimitStore_SharedAcrossInstances(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	for _, algorithm := range []RateLimitAlgorithm{AlgorithmFixedWindow, AlgorithmTokenBucket, AlgorithmSlidingLog, AlgorithmSlidingWindow} {
+		t.Run(string(algorithm), func(t *testing.T) {
+			server := newFakeRedis(t)
+			clock := newFakeClock()
+
+			// Three replicas behind a load balancer
+			var routers []*gin.Engine
+			for i := 0; i < 3; i++ {
+				router := gin.New()
+				router.Use(testClientIP(t))
+				router.Use(SmartRateLimit(RateLimitConfig{
+					Enabled:         true,
+					PerIP:           25,
+					PerAPIKey:       1000,
+					CleanupInterval: time.Hour,
+					WindowSize:      time.Minute,
+					Algorithm:       algorithm,
+					Clock:           clock.Now,
+					Store:           newRedisStore(t, server),
+				}))
+				router.GET("/test", func(c *gin.Context) { c.Status(http.StatusOK) })
+				routers = append(routers, router)
+			}
+
+			var allowed atomic.Int64
+			var wg sync.WaitGroup
+			for i := 0; i < 12; i++ {
+				wg.Add(1)
+				go func(router *gin.Engine) {
+					defer wg.Done()
+					allowed.Add(int64(sendN(router, 5, http.MethodGet, "/test", map[string]string{"X-Forwarded-For": "192.168.1.83"})))
+				}(routers[i%len(routers)])
+			}
+			wg.Wait()
+
+			assert.Equal(t, int64(25), allowed.Load())
+		})
+	}
+}
+
+func TestRateLimit_StoreUnavailable(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	server := newFakeRedis(t)
+	limiter := NewRateLimiter(RateLimitConfig{
+		Enabled:         true,
+		PerIP:           1,
+		PerAPIKey:       1,
+		CleanupInterval: time.Hour,
+		WindowSize:      time.Minute,
+		Store:           newRedisStore(t, server),
+	})
+	defer limiter.Stop()
+
+	router := gin.New()
+	router.Use(testClientIP(t))
+	router.GET("/test", func(c *gin.Context) {
+		result := limiter.Check(c)
+		c.JSON(http.StatusOK, gin.H{"allowed": result.Allowed, "errors": c.Errors.Errors()})
+	})
+
+	headers := map[string]string{"X-Forwarded-For": "192.168.1.84"}
+	assert.JSONEq(t, `{"allowed": true, "errors": null}`, send(router, http.MethodGet, "/test", headers).Body.String())
+	assert.JSONEq(t, `{"allowed": false, "errors": null}`, send(router, http.MethodGet, "/test", headers).Body.String())
+
+	// Requests are allowed while the store is down, and the failure is logged
+	server.Close()
+	w := send(router, http.MethodGet, "/test", headers)
+	assert.Contains(t, w.Body.String(), `"allowed":true`)
+	assert.Contains(t, w.Body.String(), "rate limit store unavailable, request allowed")
+
+	stats := limiter.GetStats()["statistics"].(map[string]interface{})
+	assert.Contains(t, stats["error"], "failed to count rate limit records")
+}
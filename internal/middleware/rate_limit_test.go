@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -23,6 +24,7 @@ func TestRateLimiter_Allow(t *testing.T) {
 
 	// Create test router with rate limiting
 	router := gin.New()
+	router.Use(testClientIP(t))
 	router.Use(RateLimit(config))
 	router.GET("/test", func(c *gin.Context) {
 		c.JSON(200, gin.H{"message": "success"})
@@ -31,21 +33,21 @@ func TestRateLimiter_Allow(t *testing.T) {
 	// Test IP-based rate limiting
 	t.Run("IP Rate Limiting", func(t *testing.T) {
 		// First request should succeed
-		req1, _ := http.NewRequest("GET", "/test", nil)
+		req1 := httptest.NewRequest("GET", "/test", nil)
 		req1.Header.Set("X-Forwarded-For", "192.168.1.1")
 		w1 := httptest.NewRecorder()
 		router.ServeHTTP(w1, req1)
 		assert.Equal(t, http.StatusOK, w1.Code)
 
 		// Second request should succeed
-		req2, _ := http.NewRequest("GET", "/test", nil)
+		req2 := httptest.NewRequest("GET", "/test", nil)
 		req2.Header.Set("X-Forwarded-For", "192.168.1.1")
 		w2 := httptest.NewRecorder()
 		router.ServeHTTP(w2, req2)
 		assert.Equal(t, http.StatusOK, w2.Code)
 
 		// Third request should be rate limited
-		req3, _ := http.NewRequest("GET", "/test", nil)
+		req3 := httptest.NewRequest("GET", "/test", nil)
 		req3.Header.Set("X-Forwarded-For", "192.168.1.1")
 		w3 := httptest.NewRecorder()
 		router.ServeHTTP(w3, req3)
@@ -54,7 +56,7 @@ func TestRateLimiter_Allow(t *testing.T) {
 
 	t.Run("Different IPs Should Have Separate Limits", func(t *testing.T) {
 		// Request from different IP should succeed
-		req, _ := http.NewRequest("GET", "/test", nil)
+		req := httptest.NewRequest("GET", "/test", nil)
 		req.Header.Set("X-Forwarded-For", "192.168.1.2")
 		w := httptest.NewRecorder()
 		router.ServeHTTP(w, req)
@@ -75,6 +77,7 @@ func TestSmartRateLimit_CustomLimits(t *testing.T) {
 
 	// Create test router with smart rate limiting
 	router := gin.New()
+	router.Use(testClientIP(t))
 	router.Use(SmartRateLimit(config))
 
 	// Health endpoint should have higher limit (4 * 5 = 20)
@@ -98,7 +101,7 @@ func TestSmartRateLimit_CustomLimits(t *testing.T) {
 
 		// Try many requests to health endpoint
 		for i := 0; i < 10; i++ {
-			req, _ := http.NewRequest("GET", "/health", nil)
+			req := httptest.NewRequest("GET", "/health", nil)
 			req.Header.Set("X-Forwarded-For", ip)
 			w := httptest.NewRecorder()
 			router.ServeHTTP(w, req)
@@ -118,7 +121,7 @@ func TestSmartRateLimit_CustomLimits(t *testing.T) {
 		// Test POST endpoint (lower limit)
 		postSuccessCount := 0
 		for i := 0; i < 5; i++ {
-			req, _ := http.NewRequest("POST", "/api/data", nil)
+			req := httptest.NewRequest("POST", "/api/data", nil)
 			req.Header.Set("X-Forwarded-For", ip)
 			w := httptest.NewRecorder()
 			router.ServeHTTP(w, req)
@@ -145,6 +148,7 @@ func TestRateLimiter_APIKeyLimit(t *testing.T) {
 	}
 
 	router := gin.New()
+	router.Use(testClientIP(t))
 	router.Use(RateLimit(config))
 	router.GET("/test", func(c *gin.Context) {
 		c.JSON(200, gin.H{"message": "success"})
@@ -155,7 +159,7 @@ func TestRateLimiter_APIKeyLimit(t *testing.T) {
 		successCount := 0
 
 		for i := 0; i < 5; i++ {
-			req, _ := http.NewRequest("GET", "/test", nil)
+			req := httptest.NewRequest("GET", "/test", nil)
 			req.Header.Set("X-API-Key", apiKey)
 			req.Header.Set("X-Forwarded-For", "192.168.1.20") // Different IP each time
 			w := httptest.NewRecorder()
@@ -188,24 +192,22 @@ func TestRateLimiter_Cleanup(t *testing.T) {
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
 	c.Request, _ = http.NewRequest("GET", "/test", nil)
-	c.Request.Header.Set("X-Forwarded-For", "192.168.1.30")
+	c.Request.RemoteAddr = "192.168.1.30:40000"
 
 	// Make a request to populate records
 	limiter.Allow(c)
 
 	// Check that records exist
-	limiter.mu.RLock()
-	initialCount := len(limiter.ipRecords)
-	limiter.mu.RUnlock()
+	initialCount, err := limiter.store.Count(context.Background(), ipRecordPrefix)
+	assert.NoError(t, err)
 	assert.Equal(t, 1, initialCount)
 
 	// Wait for cleanup
 	time.Sleep(150 * time.Millisecond)
 
 	// Records should still exist (not expired yet)
-	limiter.mu.RLock()
-	currentCount := len(limiter.ipRecords)
-	limiter.mu.RUnlock()
+	currentCount, err := limiter.store.Count(context.Background(), ipRecordPrefix)
+	assert.NoError(t, err)
 	assert.Equal(t, 1, currentCount)
 }
 
@@ -237,6 +239,7 @@ func TestRateLimiter_Disabled(t *testing.T) {
 	}
 
 	router := gin.New()
+	router.Use(testClientIP(t))
 	router.Use(RateLimit(config))
 	router.GET("/test", func(c *gin.Context) {
 		c.JSON(200, gin.H{"message": "success"})
@@ -244,10 +247,88 @@ func TestRateLimiter_Disabled(t *testing.T) {
 
 	// All requests should succeed when rate limiting is disabled
 	for i := 0; i < 10; i++ {
-		req, _ := http.NewRequest("GET", "/test", nil)
+		req := httptest.NewRequest("GET", "/test", nil)
 		req.Header.Set("X-Forwarded-For", "192.168.1.40")
 		w := httptest.NewRecorder()
 		router.ServeHTTP(w, req)
 		assert.Equal(t, http.StatusOK, w.Code)
 	}
 }
+
+func TestRateLimit_Headers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	clock := newFakeClock()
+	config := RateLimitConfig{
+		Enabled:         true,
+		PerIP:           4,
+		PerAPIKey:       2,
+		CleanupInterval: time.Hour,
+		WindowSize:      time.Minute,
+		Clock:           clock.Now,
+	}
+	router := gin.New()
+	router.Use(testClientIP(t))
+	router.Use(SmartRateLimit(config))
+	router.GET("/test", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.POST("/test", func(c *gin.Context) { c.Status(http.StatusCreated) })
+
+	request := func(method, ip, apiKey string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/test", nil)
+		req.Header.Set("X-Forwarded-For", ip)
+		if apiKey != "" {
+			req.Header.Set("X-API-Key", apiKey)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Successful Responses Describe The Quota", func(t *testing.T) {
+		w := request("GET", "192.168.1.70", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "4", w.Header().Get("X-RateLimit-Limit"))
+		assert.Equal(t, "3", w.Header().Get("X-RateLimit-Remaining"))
+		assert.Equal(t, "60", w.Header().Get("X-RateLimit-Reset"))
+		assert.Equal(t, `"default";q=4;w=60`, w.Header().Get("RateLimit-Policy"))
+		assert.Equal(t, `"default";r=3;t=60`, w.Header().Get("RateLimit"))
+		assert.Empty(t, w.Header().Get("Retry-After"))
+
+		clock.Advance(15 * time.Second)
+		w = request("GET", "192.168.1.70", "")
+		assert.Equal(t, "2", w.Header().Get("X-RateLimit-Remaining"))
+		assert.Equal(t, "45", w.Header().Get("X-RateLimit-Reset"))
+		assert.Equal(t, `"default";r=2;t=45`, w.Header().Get("RateLimit"))
+	})
+
+	t.Run("Rejections Carry Retry-After", func(t *testing.T) {
+		request("GET", "192.168.1.70", "")
+		request("GET", "192.168.1.70", "")
+
+		clock.Advance(500 * time.Millisecond)
+		w := request("GET", "192.168.1.70", "")
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
+		assert.Equal(t, "45", w.Header().Get("Retry-After")) // 44.5 seconds, rounded up
+		assert.Equal(t, `"default";r=0;t=45`, w.Header().Get("RateLimit"))
+	})
+
+	t.Run("Write Limits Are Reported", func(t *testing.T) {
+		w := request("POST", "192.168.1.71", "")
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, "2", w.Header().Get("X-RateLimit-Limit"))
+		assert.Equal(t, `"writes";q=2;w=60`, w.Header().Get("RateLimit-Policy"))
+	})
+
+	t.Run("The Most Restrictive Quota Is Reported", func(t *testing.T) {
+		w := request("GET", "192.168.1.72", "header-key")
+		assert.Equal(t, `"api_key";q=2;w=60`, w.Header().Get("RateLimit-Policy"))
+		assert.Equal(t, "1", w.Header().Get("X-RateLimit-Remaining"))
+
+		request("GET", "192.168.1.73", "header-key")
+		w = request("GET", "192.168.1.74", "header-key")
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, `"api_key";r=0;t=60`, w.Header().Get("RateLimit"))
+		assert.Equal(t, "60", w.Header().Get("Retry-After"))
+	})
+}
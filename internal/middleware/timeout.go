@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestTimeout bounds every request context with the given deadline so storage calls
+// stop once the server would no longer be able to write the response
+// Requests matching one of the longLived route patterns, such as event streams and WebSocket
+// connections, are left unbounded. The matched route decides, never the request headers, so
+// clients cannot lift the deadline of other routes.
+func RequestTimeout(timeout time.Duration, longLived ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if timeout <= 0 || (c.FullPath() != "" && slices.Contains(longLived, c.FullPath())) {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestTimeout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// hasDeadline answers whether the request context is bounded
+	hasDeadline := func(c *gin.Context) {
+		_, ok := c.Request.Context().Deadline()
+		c.String(http.StatusOK, strconv.FormatBool(ok))
+	}
+	router := gin.New()
+	router.Use(RequestTimeout(time.Minute, "/events/:topic"))
+	router.GET("/tasks", hasDeadline)
+	router.GET("/events/:topic", hasDeadline)
+
+	t.Run("requests are bounded", func(t *testing.T) {
+		assert.Equal(t, "true", send(router, http.MethodGet, "/tasks", nil).Body.String())
+	})
+
+	t.Run("long-lived routes are not", func(t *testing.T) {
+		assert.Equal(t, "false", send(router, http.MethodGet, "/events/tasks", nil).Body.String())
+	})
+
+	t.Run("headers do not lift the deadline", func(t *testing.T) {
+		headers := map[string]string{"Accept": "text/event-stream", "Connection": "Upgrade", "Upgrade": "websocket"}
+		assert.Equal(t, "true", send(router, http.MethodGet, "/tasks", headers).Body.String())
+	})
+
+	t.Run("no timeout", func(t *testing.T) {
+		unbounded := gin.New()
+		unbounded.Use(RequestTimeout(0))
+		unbounded.GET("/tasks", hasDeadline)
+		assert.Equal(t, "false", send(unbounded, http.MethodGet, "/tasks", nil).Body.String())
+	})
+}
@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"task-api/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// API versions understood by the handlers
+// Version 2 changes PUT /tasks/:id from a partial update to a full replacement.
+const (
+	APIVersion1      = 1 // PUT applies a partial update
+	APIVersion2      = 2 // PUT replaces the whole task
+	LatestAPIVersion = APIVersion2
+)
+
+// APIVersionHeader lets a client pick the API version for a single request
+const APIVersionHeader = "API-Version"
+
+// APIVersionKey is the gin context key holding the negotiated API version
+const APIVersionKey = "api_version"
+
+// APIVersion negotiates the API version of each request from the API-Version header,
+// falling back to defaultVersion, and echoes the version in effect in the response
+func APIVersion(defaultVersion int) gin.HandlerFunc {
+	if defaultVersion < APIVersion1 || defaultVersion > LatestAPIVersion {
+		defaultVersion = APIVersion1
+	}
+
+	return func(c *gin.Context) {
+		version := defaultVersion
+
+		if header := strings.TrimSpace(c.GetHeader(APIVersionHeader)); header != "" {
+			requested, err := strconv.Atoi(header)
+			if err != nil || requested < APIVersion1 || requested > LatestAPIVersion {
+				c.AbortWithStatusJSON(http.StatusBadRequest, models.NewErrorResponse(
+					"Unsupported API version",
+					fmt.Errorf("%s must be between %d and %d, got %q", APIVersionHeader, APIVersion1, LatestAPIVersion, header),
+				))
+				return
+			}
+			version = requested
+		}
+
+		c.Set(APIVersionKey, version)
+		c.Header(APIVersionHeader, strconv.Itoa(version))
+		c.Next()
+	}
+}
+
+// GetAPIVersion returns the API version negotiated for the request (version 1 if none was)
+func GetAPIVersion(c *gin.Context) int {
+	if version := c.GetInt(APIVersionKey); version > 0 {
+		return version
+	}
+	return APIVersion1
+}
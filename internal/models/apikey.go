@@ -0,0 +1,137 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// API key scopes
+const (
+	ScopeTasksRead  = "tasks:read"  // List, read, search and subscribe to tasks
+	ScopeTasksWrite = "tasks:write" // Create, change and delete tasks
+	ScopeAdmin      = "admin"       // Everything, on every owner's tasks, plus the admin endpoints
+)
+
+// APIKeyScopes lists the scopes an API key may be granted
+var APIKeyScopes = []string{ScopeTasksRead, ScopeTasksWrite, ScopeAdmin}
+
+// API key limits
+const (
+	APIKeyPrefix        = "tk_" // Marks API keys, so leaked ones are easy to spot
+	APIKeyDisplayLength = 11    // Leading characters of a key kept to recognise it (prefix included)
+	MaxAPIKeyNameLength = 100   // Longest key name accepted
+)
+
+// APIKey is a long-lived credential for scripts and services
+// Only a hash of the key is stored; the key itself is returned once, when it is issued.
+type APIKey struct {
+	ID         string     `json:"id"`                     // Unique identifier
+	Name       string     `json:"name"`                   // What the key is used for
+	Prefix     string     `json:"prefix"`                 // Leading characters of the key, to recognise it
+	Subject    string     `json:"subject"`                // Owner the key acts as
+	Scopes     []string   `json:"scopes"`                 // Granted scopes
+	Key        string     `json:"key,omitempty"`          // The key (only returned when issued)
+	CreatedBy  string     `json:"created_by,omitempty"`   // Subject of the caller who issued the key
+	CreatedAt  time.Time  `json:"created_at"`             // Issue timestamp
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`   // The key is rejected from then on (nil = never expires)
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"` // Last request authenticated with the key
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`   // The key is rejected since then
+}
+
+// Clone returns a copy of the key so callers cannot mutate stored keys
+func (k *APIKey) Clone() *APIKey {
+	clone := *k
+	clone.Scopes = append([]string(nil), k.Scopes...)
+	return &clone
+}
+
+// Redacted returns a copy of the key without the key itself, for responses
+func (k *APIKey) Redacted() *APIKey {
+	clone := k.Clone()
+	clone.Key = ""
+	return clone
+}
+
+// Usable reports whether the key is neither revoked nor expired at now
+func (k *APIKey) Usable(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+// HasScope reports whether the key grants scope; the admin scope grants every scope
+func (k *APIKey) HasScope(scope string) bool {
+	return containsString(k.Scopes, scope) || containsString(k.Scopes, ScopeAdmin)
+}
+
+// IssueAPIKeyRequest represents the DTO for issuing an API key
+type IssueAPIKeyRequest struct {
+	Name      string     `json:"name" binding:"required"`   // What the key is used for
+	Subject   string     `json:"subject,omitempty"`         // Owner the key acts as (default: the caller)
+	Scopes    []string   `json:"scopes" binding:"required"` // Granted scopes
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`      // Expiry (omit for a key that never expires)
+}
+
+// Validate validates the issue request
+func (req *IssueAPIKeyRequest) Validate() error {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return fmt.Errorf("api key name cannot be empty")
+	}
+	if len(req.Name) > MaxAPIKeyNameLength {
+		return fmt.Errorf("api key name cannot exceed %d characters", MaxAPIKeyNameLength)
+	}
+	if strings.TrimSpace(req.Subject) != req.Subject {
+		return fmt.Errorf("api key subject cannot start or end with whitespace")
+	}
+
+	if len(req.Scopes) == 0 {
+		return fmt.Errorf("at least one scope is required")
+	}
+	seen := make(map[string]bool, len(req.Scopes))
+	for _, scope := range req.Scopes {
+		if !containsString(APIKeyScopes, scope) {
+			return fmt.Errorf("unknown scope %q (supported: %s)", scope, strings.Join(APIKeyScopes, ", "))
+		}
+		if seen[scope] {
+			return fmt.Errorf("scope %q is listed twice", scope)
+		}
+		seen[scope] = true
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return fmt.Errorf("expires_at must be in the future")
+	}
+	return nil
+}
+
+// APIKeyResponse represents the DTO for a single API key
+type APIKeyResponse struct {
+	Success bool    `json:"success"`           // Whether the operation was successful
+	Message string  `json:"message,omitempty"` // Response message
+	Data    *APIKey `json:"data,omitempty"`    // API key data
+}
+
+// APIKeyListResponse represents the DTO for an API key list
+type APIKeyListResponse struct {
+	Success bool      `json:"success"`        // Whether the operation was successful
+	Data    []*APIKey `json:"data,omitempty"` // API keys, oldest first
+	Count   int       `json:"count"`          // Number of API keys
+}
+
+// NewAPIKeyResponse creates a single API key response (Factory Pattern)
+func NewAPIKeyResponse(key *APIKey, message string) *APIKeyResponse {
+	return &APIKeyResponse{
+		Success: true,
+		Message: message,
+		Data:    key,
+	}
+}
+
+// NewAPIKeyListResponse creates an API key list response (Factory Pattern)
+func NewAPIKeyListResponse(keys []*APIKey) *APIKeyListResponse {
+	return &APIKeyListResponse{
+		Success: true,
+		Data:    keys,
+		Count:   len(keys),
+	}
+}
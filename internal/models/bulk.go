@@ -0,0 +1,144 @@
+package models
+
+import "fmt"
+
+// MaxBulkOperations limits the number of operations accepted in one bulk request
+const MaxBulkOperations = 1000
+
+// BulkOperationType identifies what a single bulk operation does
+type BulkOperationType string
+
+const (
+	BulkCreate BulkOperationType = "create" // Create a new task
+	BulkUpdate BulkOperationType = "update" // Partially update an existing task
+	BulkDelete BulkOperationType = "delete" // Delete an existing task
+)
+
+// BulkMode selects how a bulk request reacts to a failing operation
+type BulkMode string
+
+const (
+	BulkAtomic     BulkMode = "atomic"      // All operations are applied or none is
+	BulkBestEffort BulkMode = "best_effort" // Every operation that succeeds is applied
+)
+
+// BulkOperation is one create, update or delete inside a bulk request
+type BulkOperation struct {
+	Op      BulkOperationType  `json:"op"`                // Operation type
+	ID      string             `json:"id,omitempty"`      // Target task ID (update and delete)
+	Version int                `json:"version,omitempty"` // Only apply if the task is at this version (update and delete, optional)
+	Task    *CreateTaskRequest `json:"task,omitempty"`    // New task (create)
+	Changes *UpdateTaskRequest `json:"changes,omitempty"` // Fields to change (update)
+}
+
+// Validate checks that the operation carries the fields its type requires
+// The task contents themselves are validated by storage, per operation.
+func (op *BulkOperation) Validate() error {
+	switch op.Op {
+	case BulkCreate:
+		if op.Task == nil {
+			return fmt.Errorf("create requires a task")
+		}
+		if op.ID != "" || op.Version != 0 || op.Changes != nil {
+			return fmt.Errorf("create only accepts a task")
+		}
+	case BulkUpdate:
+		if op.ID == "" || op.Changes == nil {
+			return fmt.Errorf("update requires an id and changes")
+		}
+		if op.Task != nil {
+			return fmt.Errorf("update does not accept a task, use changes")
+		}
+	case BulkDelete:
+		if op.ID == "" {
+			return fmt.Errorf("delete requires an id")
+		}
+		if op.Task != nil || op.Changes != nil {
+			return fmt.Errorf("delete does not accept a task or changes")
+		}
+	default:
+		return fmt.Errorf("unknown operation %q", op.Op)
+	}
+
+	if op.Version < 0 {
+		return fmt.Errorf("version cannot be negative")
+	}
+	return nil
+}
+
+// BulkRequest represents the DTO for a bulk task request
+type BulkRequest struct {
+	Mode       BulkMode        `json:"mode,omitempty"`                // atomic (default) or best_effort
+	Operations []BulkOperation `json:"operations" binding:"required"` // Operations, applied in order
+}
+
+// Validate validates the bulk request and defaults the mode to atomic
+func (req *BulkRequest) Validate() error {
+	switch req.Mode {
+	case "":
+		req.Mode = BulkAtomic
+	case BulkAtomic, BulkBestEffort:
+	default:
+		return fmt.Errorf("invalid mode %q, must be %s or %s", req.Mode, BulkAtomic, BulkBestEffort)
+	}
+
+	if len(req.Operations) == 0 {
+		return fmt.Errorf("at least one operation is required")
+	}
+	if len(req.Operations) > MaxBulkOperations {
+		return fmt.Errorf("a bulk request cannot exceed %d operations", MaxBulkOperations)
+	}
+
+	for i := range req.Operations {
+		if err := req.Operations[i].Validate(); err != nil {
+			return fmt.Errorf("operation %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// BatchResult is the storage-level outcome of one bulk operation
+// Err is nil when the operation was applied; Task is the created or updated task.
+type BatchResult struct {
+	Task *Task // Resulting task (nil for deletes and failures)
+	Err  error // Why the operation was not applied
+}
+
+// BulkOperationResult represents the outcome of one operation in a bulk response
+type BulkOperationResult struct {
+	Index  int               `json:"index"`           // Position of the operation in the request
+	Op     BulkOperationType `json:"op"`              // Operation type
+	ID     string            `json:"id,omitempty"`    // Task ID the operation applied to
+	Status int               `json:"status"`          // HTTP status the operation would have had on its own
+	Data   *Task             `json:"data,omitempty"`  // Created or updated task
+	Error  string            `json:"error,omitempty"` // Detailed error information
+}
+
+// BulkResponse represents the DTO for a bulk task response
+type BulkResponse struct {
+	Success   bool                  `json:"success"`   // Whether every operation was applied
+	Mode      BulkMode              `json:"mode"`      // Mode the request was executed with
+	Results   []BulkOperationResult `json:"results"`   // Per-operation results, in request order
+	Succeeded int                   `json:"succeeded"` // Number of applied operations
+	Failed    int                   `json:"failed"`    // Number of operations not applied
+}
+
+// NewBulkResponse creates a bulk response from per-operation results (Factory Pattern)
+// An operation counts as succeeded when its status is 2xx.
+func NewBulkResponse(mode BulkMode, results []BulkOperationResult) *BulkResponse {
+	response := &BulkResponse{
+		Mode:    mode,
+		Results: results,
+	}
+
+	for _, result := range results {
+		if result.Status >= 200 && result.Status < 300 {
+			response.Succeeded++
+		} else {
+			response.Failed++
+		}
+	}
+	response.Success = response.Failed == 0
+
+	return response
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TaskCursor is a position in the creation order of tasks (creation time, then ID)
+// Creation time and ID never change, so a cursor stays valid while tasks are added or removed.
+type TaskCursor struct {
+	CreatedAt time.Time `json:"c"`  // Creation time of the task at this position
+	ID        string    `json:"id"` // ID of the task at this position
+}
+
+// CursorOf returns the position of a task in creation order
+func CursorOf(task *Task) TaskCursor {
+	return TaskCursor{CreatedAt: task.CreatedAt, ID: task.ID}
+}
+
+// Compare returns -1, 0 or 1 as the cursor comes before, at or after other in creation order
+func (c TaskCursor) Compare(other TaskCursor) int {
+	if cmp := c.CreatedAt.Compare(other.CreatedAt); cmp != 0 {
+		return cmp
+	}
+	return strings.Compare(c.ID, other.ID)
+}
+
+// Encode returns the opaque, URL-safe representation of the cursor
+func (c TaskCursor) Encode() string {
+	data, _ := json.Marshal(TaskCursor{CreatedAt: c.CreatedAt.UTC(), ID: c.ID})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeTaskCursor parses a cursor produced by TaskCursor.Encode
+func DecodeTaskCursor(value string) (*TaskCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+
+	var cursor TaskCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID == "" || cursor.CreatedAt.IsZero() {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	return &cursor, nil
+}
+
+// TasksAfter returns the tasks positioned after the cursor (nil = all tasks)
+// tasks must already be in creation order.
+func TasksAfter(tasks []*Task, after *TaskCursor) []*Task {
+	if after == nil {
+		return tasks
+	}
+	for i, task := range tasks {
+		if CursorOf(task).Compare(*after) > 0 {
+			return tasks[i:]
+		}
+	}
+	return []*Task{}
+}
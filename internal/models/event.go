@@ -0,0 +1,70 @@
+package models
+
+import "time"
+
+// TaskEventType describes the kind of change a task event reports
+type TaskEventType string
+
+const (
+	TaskCreated TaskEventType = "created" // Task was created
+	TaskUpdated TaskEventType = "updated" // Task was updated or reverted
+	TaskDeleted TaskEventType = "deleted" // Task was deleted
+
+	// TaskEventsReset tells a resuming client that events were missed and it must reload its tasks
+	TaskEventsReset TaskEventType = "reset"
+)
+
+// TaskEvent reports one change to a task on the change feed
+type TaskEvent struct {
+	ID      string        `json:"id"`             // Position in the change feed, used to resume after a disconnect
+	Type    TaskEventType `json:"type"`           // Kind of change
+	TaskID  string        `json:"task_id"`        // ID of the changed task
+	Version int           `json:"version"`        // Task version after the change (last version for deletes)
+	Task    *Task         `json:"task,omitempty"` // New task state (absent for deletes)
+	Actor   string        `json:"actor"`          // Who made the change
+	Time    time.Time     `json:"time"`           // When the change was made
+
+	// Previous is the task state before the change (nil for creates); it is kept in process so
+	// subscribers can tell when a task stops matching their filter, and is never serialized
+	Previous *Task `json:"-"`
+}
+
+// NewTaskEvent records the change of a task from before to after made by actor (Factory Pattern)
+// before is nil for a newly created task and after is nil for a deleted one.
+// The ID is assigned when the event is published.
+func NewTaskEvent(before, after *Task, actor string) TaskEvent {
+	if actor == "" {
+		actor = AnonymousActor
+	}
+
+	event := TaskEvent{
+		Type:  TaskUpdated,
+		Actor: actor,
+		Time:  time.Now().UTC(),
+	}
+	if before != nil {
+		event.Previous = before.Clone()
+		event.TaskID, event.Version = before.ID, before.Version
+	}
+	if after != nil {
+		event.Task = after.Clone()
+		event.TaskID, event.Version = after.ID, after.Version
+	}
+
+	switch {
+	case before == nil:
+		event.Type = TaskCreated
+	case after == nil:
+		event.Type = TaskDeleted
+	}
+	return event
+}
+
+// Concerns reports whether the event is relevant to a subscriber watching the tasks that match
+// filter: the task matches it after the change, or did before
+func (e *TaskEvent) Concerns(filter *TaskFilter) bool {
+	if e.Type == TaskEventsReset || filter == nil {
+		return true
+	}
+	return (e.Task != nil && filter.Matches(e.Task)) || (e.Previous != nil && filter.Matches(e.Previous))
+}
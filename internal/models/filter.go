@@ -0,0 +1,196 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// TaskFilterKeys lists the query parameters ParseTaskFilter understands
+var TaskFilterKeys = []string{
+	"status", "priority", "tag", "name_contains",
+	"due_before", "due_after", "created_before", "created_after",
+}
+
+// TaskFilter narrows a task listing; zero-valued fields match every task
+type TaskFilter struct {
+	Status        *TaskStatus   // Only tasks with this status
+	Priority      *TaskPriority // Only tasks with this priority
+	Tags          []string      // Only tasks carrying all of these tags
+	NameContains  string        // Only tasks whose name contains this text (case-insensitive)
+	DueBefore     *time.Time    // Only tasks due strictly before this time
+	DueAfter      *time.Time    // Only tasks due strictly after this time
+	CreatedBefore *time.Time    // Only tasks created strictly before this time
+	CreatedAfter  *time.Time    // Only tasks created strictly after this time
+	OwnerID       string        // Only tasks owned by this subject; set from the caller, never from query parameters
+}
+
+// IsEmpty reports whether the filter matches every task
+func (f *TaskFilter) IsEmpty() bool {
+	return f.Status == nil && f.Priority == nil && len(f.Tags) == 0 && f.NameContains == "" &&
+		f.DueBefore == nil && f.DueAfter == nil && f.CreatedBefore == nil && f.CreatedAfter == nil &&
+		f.OwnerID == ""
+}
+
+// Matches reports whether the task satisfies every condition of the filter
+func (f *TaskFilter) Matches(task *Task) bool {
+	if f.OwnerID != "" && task.OwnerID != f.OwnerID {
+		return false
+	}
+	if f.Status != nil && task.Status != *f.Status {
+		return false
+	}
+	if f.Priority != nil && task.Priority != *f.Priority {
+		return false
+	}
+	for _, tag := range f.Tags {
+		if !task.HasTag(tag) {
+			return false
+		}
+	}
+	if f.DueBefore != nil && (task.DueDate == nil || !task.DueDate.Before(*f.DueBefore)) {
+		return false
+	}
+	if f.DueAfter != nil && (task.DueDate == nil || !task.DueDate.After(*f.DueAfter)) {
+		return false
+	}
+	if f.NameContains != "" && !strings.Contains(strings.ToLower(task.Name), strings.ToLower(f.NameContains)) {
+		return false
+	}
+	if f.CreatedBefore != nil && !task.CreatedAt.Before(*f.CreatedBefore) {
+		return false
+	}
+	if f.CreatedAfter != nil && !task.CreatedAt.After(*f.CreatedAfter) {
+		return false
+	}
+	return true
+}
+
+// Apply returns the tasks matching the filter, preserving order
+func (f *TaskFilter) Apply(tasks []*Task) []*Task {
+	if f.IsEmpty() {
+		return tasks
+	}
+
+	filtered := make([]*Task, 0, len(tasks))
+	for _, task := range tasks {
+		if f.Matches(task) {
+			filtered = append(filtered, task)
+		}
+	}
+	return filtered
+}
+
+// ParseTaskFilter builds a filter from list query parameters.
+// Supported keys: status, priority, tag (repeatable or comma-separated), name_contains,
+// due_before, due_after, created_before, created_after.
+func ParseTaskFilter(query map[string][]string) (*TaskFilter, error) {
+	filter := &TaskFilter{}
+
+	if value := firstValue(query, "status"); value != "" {
+		status, err := ParseTaskStatus(value)
+		if err != nil {
+			return nil, err
+		}
+		filter.Status = &status
+	}
+
+	if value := firstValue(query, "priority"); value != "" {
+		priority, err := ParseTaskPriority(value)
+		if err != nil {
+			return nil, err
+		}
+		filter.Priority = &priority
+	}
+
+	var tags []string
+	for _, value := range query["tag"] {
+		tags = append(tags, strings.Split(value, ",")...)
+	}
+	if len(tags) > 0 {
+		if err := validateTags(tags); err != nil {
+			return nil, fmt.Errorf("invalid tag filter: %w", err)
+		}
+		filter.Tags = NormalizeTags(tags)
+	}
+
+	if _, ok := query["name_contains"]; ok {
+		filter.NameContains = strings.TrimSpace(firstValue(query, "name_contains"))
+		if filter.NameContains == "" {
+			return nil, fmt.Errorf("name_contains filter cannot be empty")
+		}
+		if len(filter.NameContains) > MaxNameLength {
+			return nil, fmt.Errorf("name_contains filter cannot exceed %d characters", MaxNameLength)
+		}
+	}
+
+	var err error
+	if filter.DueBefore, err = parseTimeFilter(query, "due_before"); err != nil {
+		return nil, err
+	}
+	if filter.DueAfter, err = parseTimeFilter(query, "due_after"); err != nil {
+		return nil, err
+	}
+	if filter.CreatedBefore, err = parseTimeFilter(query, "created_before"); err != nil {
+		return nil, err
+	}
+	if filter.CreatedAfter, err = parseTimeFilter(query, "created_after"); err != nil {
+		return nil, err
+	}
+	if err := checkTimeRange("created", filter.CreatedAfter, filter.CreatedBefore); err != nil {
+		return nil, err
+	}
+	if err := checkTimeRange("due", filter.DueAfter, filter.DueBefore); err != nil {
+		return nil, err
+	}
+
+	return filter, nil
+}
+
+// ParseFilterExpression builds a filter from an expression written as a URL query string,
+// such as "status=completed&tag=urgent"; unlike ParseTaskFilter it rejects unknown keys
+func ParseFilterExpression(expression string) (*TaskFilter, error) {
+	query, err := url.ParseQuery(expression)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter expression: %w", err)
+	}
+
+	for key := range query {
+		if !containsString(TaskFilterKeys, key) {
+			return nil, fmt.Errorf("unknown filter key %q (supported: %s)", key, strings.Join(TaskFilterKeys, ", "))
+		}
+	}
+
+	return ParseTaskFilter(query)
+}
+
+// parseTimeFilter parses an optional RFC 3339 query parameter
+func parseTimeFilter(query map[string][]string, key string) (*time.Time, error) {
+	value := firstValue(query, key)
+	if value == "" {
+		return nil, nil
+	}
+
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s filter (expected RFC 3339): %q", key, value)
+	}
+	return &parsed, nil
+}
+
+// checkTimeRange rejects an after/before pair that no task can satisfy
+func checkTimeRange(name string, after, before *time.Time) error {
+	if after != nil && before != nil && !after.Before(*before) {
+		return fmt.Errorf("%s_after must be earlier than %s_before", name, name)
+	}
+	return nil
+}
+
+// firstValue returns the first value for key, or an empty string
+func firstValue(query map[string][]string, key string) string {
+	if values := query[key]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
@@ -0,0 +1,172 @@
+package models
+
+import (
+	"context"
+	"reflect"
+	"time"
+)
+
+// AnonymousActor is recorded as the author of changes made without an identified caller
+const AnonymousActor = "anonymous"
+
+// RevisionAction describes what produced a task revision
+type RevisionAction string
+
+const (
+	RevisionCreate RevisionAction = "create" // Task was created
+	RevisionUpdate RevisionAction = "update" // Task was updated
+	RevisionRevert RevisionAction = "revert" // Task was restored from an earlier revision
+)
+
+// FieldChange records the old and new value of a single task field
+type FieldChange struct {
+	Field string      `json:"field"`          // JSON name of the changed field
+	From  interface{} `json:"from,omitempty"` // Value before the change (absent if unset)
+	To    interface{} `json:"to,omitempty"`   // Value after the change (absent if cleared)
+}
+
+// TaskRevision is an immutable record of a task at one version
+type TaskRevision struct {
+	Version      int            `json:"version"`                 // Task version this revision produced
+	Action       RevisionAction `json:"action"`                  // What produced the revision
+	Actor        string         `json:"actor"`                   // Who made the change
+	ChangedAt    time.Time      `json:"changed_at"`              // When the change was made
+	RevertedFrom int            `json:"reverted_from,omitempty"` // Source version of a revert
+	Changes      []FieldChange  `json:"changes,omitempty"`       // Fields that differ from the previous version
+	Task         *Task          `json:"task"`                    // Full task state at this version
+}
+
+// Clone returns a deep copy of the revision so callers cannot mutate stored history
+func (r *TaskRevision) Clone() *TaskRevision {
+	clone := *r
+	clone.Changes = append([]FieldChange(nil), r.Changes...)
+	if r.Task != nil {
+		clone.Task = r.Task.Clone()
+	}
+	return &clone
+}
+
+// NewTaskRevision records the transition from before to after (Factory Pattern)
+// before is nil for a newly created task.
+func NewTaskRevision(action RevisionAction, before, after *Task, actor string) *TaskRevision {
+	if actor == "" {
+		actor = AnonymousActor
+	}
+
+	return &TaskRevision{
+		Version:   after.Version,
+		Action:    action,
+		Actor:     actor,
+		ChangedAt: after.UpdatedAt,
+		Changes:   DiffTasks(before, after),
+		Task:      after.Clone(),
+	}
+}
+
+// DiffTasks lists the user-editable fields that differ between two versions of a task
+// A nil before is treated as an empty task, so every set field of after is reported.
+func DiffTasks(before, after *Task) []FieldChange {
+	if before == nil {
+		before = &Task{}
+	}
+
+	var changes []FieldChange
+	add := func(field string, from, to interface{}, changed bool) {
+		if changed {
+			changes = append(changes, FieldChange{Field: field, From: from, To: to})
+		}
+	}
+
+	add("name", optionalString(before.Name), optionalString(after.Name), before.Name != after.Name)
+	add("description", optionalString(before.Description), optionalString(after.Description),
+		before.Description != after.Description)
+	add("status", before.Status, after.Status, before.Status != after.Status)
+	add("priority", before.Priority, after.Priority, before.Priority != after.Priority)
+	add("due_date", optionalTime(before.DueDate), optionalTime(after.DueDate), !sameTime(before.DueDate, after.DueDate))
+	add("tags", optionalTags(before.Tags), optionalTags(after.Tags), !reflect.DeepEqual(before.Tags, after.Tags))
+
+	return changes
+}
+
+// optionalString maps an empty string to nil so it is omitted from a FieldChange
+func optionalString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// optionalTime maps a nil time to an untyped nil so it is omitted from a FieldChange
+func optionalTime(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return *t
+}
+
+// optionalTags maps an empty tag set to nil so it is omitted from a FieldChange
+func optionalTags(tags []string) interface{} {
+	if len(tags) == 0 {
+		return nil
+	}
+	return append([]string(nil), tags...)
+}
+
+// sameTime reports whether two optional times are both unset or equal
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+// RevertRequest builds the update that restores every user-editable field to this revision's values
+func (r *TaskRevision) RevertRequest() *UpdateTaskRequest {
+	return r.Task.ReplaceRequest()
+}
+
+// TaskHistoryResponse represents the DTO for a task's revision history
+type TaskHistoryResponse struct {
+	Success bool            `json:"success"`        // Whether the operation was successful
+	Data    []*TaskRevision `json:"data,omitempty"` // Revisions, oldest first
+	Count   int             `json:"count"`          // Number of revisions
+}
+
+// TaskRevisionResponse represents the DTO for a single task revision
+type TaskRevisionResponse struct {
+	Success bool          `json:"success"`        // Whether the operation was successful
+	Data    *TaskRevision `json:"data,omitempty"` // Revision data
+}
+
+// NewTaskHistoryResponse creates a revision history response (Factory Pattern)
+func NewTaskHistoryResponse(revisions []*TaskRevision) *TaskHistoryResponse {
+	return &TaskHistoryResponse{
+		Success: true,
+		Data:    revisions,
+		Count:   len(revisions),
+	}
+}
+
+// NewTaskRevisionResponse creates a single revision response (Factory Pattern)
+func NewTaskRevisionResponse(revision *TaskRevision) *TaskRevisionResponse {
+	return &TaskRevisionResponse{
+		Success: true,
+		Data:    revision,
+	}
+}
+
+// actorContextKey is the context key under which the acting user is stored
+type actorContextKey struct{}
+
+// WithActor returns a context that attributes storage changes to actor
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorContextKey{}, actor)
+}
+
+// ActorFromContext returns the actor stored in ctx, or AnonymousActor if there is none
+func ActorFromContext(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorContextKey{}).(string); ok && actor != "" {
+		return actor
+	}
+	return AnonymousActor
+}
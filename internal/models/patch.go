@@ -0,0 +1,433 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Patch media types accepted by PATCH /tasks/:id
+const (
+	MergePatchContentType = "application/merge-patch+json" // RFC 7396 JSON Merge Patch
+	JSONPatchContentType  = "application/json-patch+json"  // RFC 6902 JSON Patch
+)
+
+// Patch application errors
+var (
+	ErrInvalidPatch    = errors.New("patch cannot be applied") // The patch is malformed or targets invalid fields
+	ErrPatchTestFailed = errors.New("patch test failed")       // A JSON Patch "test" operation did not match
+)
+
+// TaskPatch is a parsed patch document that can be applied to a task
+type TaskPatch interface {
+	// Apply returns a patched copy of the task; the original is never modified
+	Apply(task *Task) (*Task, error)
+}
+
+// MergePatch is an RFC 7396 JSON Merge Patch
+type MergePatch struct {
+	patch interface{} // Decoded patch document
+}
+
+// ParseMergePatch decodes a JSON Merge Patch document
+func ParseMergePatch(data []byte) (*MergePatch, error) {
+	var patch interface{}
+	if err := json.Unmarshal(data, &patch); err != nil {
+		return nil, fmt.Errorf("invalid merge patch: %w", err)
+	}
+	return &MergePatch{patch: patch}, nil
+}
+
+// Apply merges the patch into the task's JSON document
+func (p *MergePatch) Apply(task *Task) (*Task, error) {
+	return patchTaskDocument(task, func(doc interface{}) (interface{}, error) {
+		return mergePatch(doc, p.patch), nil
+	})
+}
+
+// mergePatch implements the MergePatch algorithm of RFC 7396 section 2
+func mergePatch(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = make(map[string]interface{})
+	}
+
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+		} else {
+			targetObject[key] = mergePatch(targetObject[key], value)
+		}
+	}
+
+	return targetObject
+}
+
+// JSONPatchOperation is a single RFC 6902 operation
+type JSONPatchOperation struct {
+	Op    string          `json:"op"`              // add, remove, replace, move, copy or test
+	Path  string          `json:"path"`            // JSON Pointer to the target location
+	From  string          `json:"from,omitempty"`  // JSON Pointer to the source (move and copy)
+	Value json.RawMessage `json:"value,omitempty"` // Operand (add, replace and test)
+}
+
+// JSONPatch is an ordered RFC 6902 JSON Patch document
+type JSONPatch []JSONPatchOperation
+
+// ParseJSONPatch decodes a JSON Patch document and checks every operation is well formed
+func ParseJSONPatch(data []byte) (JSONPatch, error) {
+	var patch JSONPatch
+	if err := json.Unmarshal(data, &patch); err != nil {
+		return nil, fmt.Errorf("invalid JSON patch: %w", err)
+	}
+
+	for i, op := range patch {
+		if _, err := parsePointer(op.Path); err != nil {
+			return nil, fmt.Errorf("operation %d: %w", i, err)
+		}
+
+		switch op.Op {
+		case "add", "replace", "test":
+			// Distinguish an explicit null from a missing value
+			if op.Value == nil {
+				return nil, fmt.Errorf("operation %d: %q requires a value", i, op.Op)
+			}
+		case "move", "copy":
+			if _, err := parsePointer(op.From); err != nil {
+				return nil, fmt.Errorf("operation %d: from: %w", i, err)
+			}
+		case "remove":
+		default:
+			return nil, fmt.Errorf("operation %d: unknown op %q", i, op.Op)
+		}
+	}
+
+	return patch, nil
+}
+
+// Apply runs the operations in order against the task's JSON document
+// Operations are all-or-nothing: if any fails, the task is left unchanged.
+func (p JSONPatch) Apply(task *Task) (*Task, error) {
+	return patchTaskDocument(task, func(doc interface{}) (interface{}, error) {
+		var err error
+		for i, op := range p {
+			if doc, err = op.apply(doc); err != nil {
+				return nil, fmt.Errorf("operation %d (%s %s): %w", i, op.Op, op.Path, err)
+			}
+		}
+		return doc, nil
+	})
+}
+
+// apply runs a single operation and returns the new document
+func (op JSONPatchOperation) apply(doc interface{}) (interface{}, error) {
+	path, _ := parsePointer(op.Path) // Validated by ParseJSONPatch
+
+	switch op.Op {
+	case "add":
+		value, err := decodeValue(op.Value)
+		if err != nil {
+			return nil, err
+		}
+		return addValue(doc, path, value)
+
+	case "remove":
+		return removeValue(doc, path)
+
+	case "replace":
+		value, err := decodeValue(op.Value)
+		if err != nil {
+			return nil, err
+		}
+		if doc, err = removeValue(doc, path); err != nil {
+			return nil, err
+		}
+		return addValue(doc, path, value)
+
+	case "move":
+		from, _ := parsePointer(op.From)
+		if from.isProperPrefixOf(path) {
+			return nil, fmt.Errorf("%w: cannot move a value into one of its children", ErrInvalidPatch)
+		}
+		value, err := getValue(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		if doc, err = removeValue(doc, from); err != nil {
+			return nil, err
+		}
+		return addValue(doc, path, value)
+
+	case "copy":
+		from, _ := parsePointer(op.From)
+		value, err := getValue(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		return addValue(doc, path, deepCopy(value))
+
+	case "test":
+		expected, err := decodeValue(op.Value)
+		if err != nil {
+			return nil, err
+		}
+		actual, err := getValue(doc, path)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrPatchTestFailed, err)
+		}
+		if !reflect.DeepEqual(actual, expected) {
+			return nil, fmt.Errorf("%w: value at %q differs", ErrPatchTestFailed, op.Path)
+		}
+		return doc, nil
+	}
+
+	return nil, fmt.Errorf("%w: unknown op %q", ErrInvalidPatch, op.Op)
+}
+
+// decodeValue decodes an operation operand into the generic JSON representation
+func decodeValue(raw json.RawMessage) (interface{}, error) {
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil, fmt.Errorf("%w: invalid value: %w", ErrInvalidPatch, err)
+	}
+	return value, nil
+}
+
+// jsonPointer is a parsed RFC 6901 JSON Pointer; an empty pointer refers to the whole document
+type jsonPointer []string
+
+// parsePointer parses and unescapes a JSON Pointer
+func parsePointer(pointer string) (jsonPointer, error) {
+	if pointer == "" {
+		return jsonPointer{}, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer %q: must be empty or start with /", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// isProperPrefixOf reports whether p points to an ancestor of other
+func (p jsonPointer) isProperPrefixOf(other jsonPointer) bool {
+	if len(p) >= len(other) {
+		return false
+	}
+	for i := range p {
+		if p[i] != other[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// arrayIndex resolves an array index token; "-" (past the end) is only allowed when appending
+func arrayIndex(token string, length int, appending bool) (int, error) {
+	if token == "-" && appending {
+		return length, nil
+	}
+	// Leading zeros and signs are not valid array indexes (RFC 6901 section 4)
+	if token == "" || (len(token) > 1 && token[0] == '0') || strings.IndexFunc(token, func(r rune) bool { return r < '0' || r > '9' }) >= 0 {
+		return 0, fmt.Errorf("%w: invalid array index %q", ErrInvalidPatch, token)
+	}
+
+	index, err := strconv.Atoi(token)
+	if err != nil {
+		return 0, fmt.Errorf("%w: invalid array index %q", ErrInvalidPatch, token)
+	}
+
+	limit := length - 1
+	if appending {
+		limit = length
+	}
+	if index > limit {
+		return 0, fmt.Errorf("%w: array index %d out of bounds", ErrInvalidPatch, index)
+	}
+	return index, nil
+}
+
+// getValue returns the value the pointer refers to
+func getValue(doc interface{}, path jsonPointer) (interface{}, error) {
+	current := doc
+	for _, token := range path {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			value, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("%w: member %q does not exist", ErrInvalidPatch, token)
+			}
+			current = value
+		case []interface{}:
+			index, err := arrayIndex(token, len(node), false)
+			if err != nil {
+				return nil, err
+			}
+			current = node[index]
+		default:
+			return nil, fmt.Errorf("%w: cannot traverse into a scalar at %q", ErrInvalidPatch, token)
+		}
+	}
+	return current, nil
+}
+
+// updateParent walks to the container holding the pointer's last token, lets update
+// replace that container, and writes the result back up the tree
+func updateParent(doc interface{}, path jsonPointer, update func(parent interface{}, key string) (interface{}, error)) (interface{}, error) {
+	if len(path) == 1 {
+		return update(doc, path[0])
+	}
+
+	child, err := getValue(doc, path[:1])
+	if err != nil {
+		return nil, err
+	}
+	newChild, err := updateParent(child, path[1:], update)
+	if err != nil {
+		return nil, err
+	}
+
+	switch node := doc.(type) {
+	case map[string]interface{}:
+		node[path[0]] = newChild
+		return node, nil
+	case []interface{}:
+		index, _ := arrayIndex(path[0], len(node), false) // Resolved by getValue above
+		node[index] = newChild
+		return node, nil
+	}
+	return nil, fmt.Errorf("%w: cannot traverse into a scalar at %q", ErrInvalidPatch, path[0])
+}
+
+// addValue implements the "add" operation
+func addValue(doc interface{}, path jsonPointer, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	return updateParent(doc, path, func(parent interface{}, key string) (interface{}, error) {
+		switch node := parent.(type) {
+		case map[string]interface{}:
+			node[key] = value
+			return node, nil
+		case []interface{}:
+			index, err := arrayIndex(key, len(node), true)
+			if err != nil {
+				return nil, err
+			}
+			node = append(node, nil)
+			copy(node[index+1:], node[index:])
+			node[index] = value
+			return node, nil
+		}
+		return nil, fmt.Errorf("%w: cannot add a member to a scalar", ErrInvalidPatch)
+	})
+}
+
+// removeValue implements the "remove" operation
+func removeValue(doc interface{}, path jsonPointer) (interface{}, error) {
+	if len(path) == 0 {
+		return nil, fmt.Errorf("%w: cannot remove the whole document", ErrInvalidPatch)
+	}
+
+	return updateParent(doc, path, func(parent interface{}, key string) (interface{}, error) {
+		switch node := parent.(type) {
+		case map[string]interface{}:
+			if _, ok := node[key]; !ok {
+				return nil, fmt.Errorf("%w: member %q does not exist", ErrInvalidPatch, key)
+			}
+			delete(node, key)
+			return node, nil
+		case []interface{}:
+			index, err := arrayIndex(key, len(node), false)
+			if err != nil {
+				return nil, err
+			}
+			return append(node[:index], node[index+1:]...), nil
+		}
+		return nil, fmt.Errorf("%w: cannot remove a member of a scalar", ErrInvalidPatch)
+	})
+}
+
+// deepCopy copies a generic JSON value so copies do not alias each other
+func deepCopy(value interface{}) interface{} {
+	switch node := value.(type) {
+	case map[string]interface{}:
+		clone := make(map[string]interface{}, len(node))
+		for key, child := range node {
+			clone[key] = deepCopy(child)
+		}
+		return clone
+	case []interface{}:
+		clone := make([]interface{}, len(node))
+		for i, child := range node {
+			clone[i] = deepCopy(child)
+		}
+		return clone
+	}
+	return value
+}
+
+// patchTaskDocument converts the task to its JSON document, lets patch transform it and
+// converts the result back, rejecting unknown fields and changes to read-only fields
+// Optional fields are present in the document (description "", due_date null, tags [])
+// so patches can address them even when unset.
+func patchTaskDocument(task *Task, patch func(doc interface{}) (interface{}, error)) (*Task, error) {
+	data, err := json.Marshal(task)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode task: %w", err)
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to decode task: %w", err)
+	}
+	if _, ok := doc["description"]; !ok {
+		doc["description"] = ""
+	}
+	if _, ok := doc["due_date"]; !ok {
+		doc["due_date"] = nil
+	}
+	if _, ok := doc["tags"]; !ok {
+		doc["tags"] = []interface{}{}
+	}
+
+	patched, err := patch(doc)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := patched.(map[string]interface{}); !ok {
+		return nil, fmt.Errorf("%w: the patched task must be a JSON object", ErrInvalidPatch)
+	}
+
+	data, err = json.Marshal(patched)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode patched task: %w", err)
+	}
+
+	var result Task
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&result); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPatch, err)
+	}
+
+	if result.ID != task.ID || result.OwnerID != task.OwnerID || result.Version != task.Version ||
+		!result.CreatedAt.Equal(task.CreatedAt) || !result.UpdatedAt.Equal(task.UpdatedAt) {
+		return nil, fmt.Errorf("%w: id, owner_id, version, created_at and updated_at are read-only", ErrInvalidPatch)
+	}
+
+	return &result, nil
+}
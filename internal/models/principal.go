@@ -0,0 +1,49 @@
+package models
+
+import "context"
+
+// Principal is the authenticated caller of a request
+type Principal struct {
+	Subject  string   // Token subject, recorded as the owner of the tasks the caller creates
+	Admin    bool     // Whether the caller may see and change every task
+	Roles    []string // Roles the caller holds, checked against the RBAC policy (empty = the policy's default roles)
+	Scopes   []string // Scopes of the API key the caller used (nil = unrestricted, for bearer tokens)
+	APIKeyID string   // ID of the API key the caller used ("" for bearer tokens)
+}
+
+// HasScope reports whether the principal may act within scope
+// Bearer tokens carry no scopes and are unrestricted; the admin scope grants every scope.
+func (p *Principal) HasScope(scope string) bool {
+	if p == nil || p.Scopes == nil {
+		return true
+	}
+	return containsString(p.Scopes, scope) || containsString(p.Scopes, ScopeAdmin)
+}
+
+// CanAccess reports whether the principal may see and change the task
+// A nil principal stands for a server without authentication, where every task is shared.
+func (p *Principal) CanAccess(task *Task) bool {
+	return p == nil || p.Admin || task.OwnerID == p.Subject
+}
+
+// OwnerScope returns the owner the principal's listings are restricted to, or "" when it sees every task
+func (p *Principal) OwnerScope() string {
+	if p == nil || p.Admin {
+		return ""
+	}
+	return p.Subject
+}
+
+// principalContextKey is the context key under which the authenticated caller is stored
+type principalContextKey struct{}
+
+// WithPrincipal returns a context carrying the authenticated caller
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, principal)
+}
+
+// PrincipalFromContext returns the authenticated caller stored in ctx, or nil if there is none
+func PrincipalFromContext(ctx context.Context) *Principal {
+	principal, _ := ctx.Value(principalContextKey{}).(*Principal)
+	return principal
+}
@@ -0,0 +1,211 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SortableTaskFields lists the task fields a listing can be sorted by
+var SortableTaskFields = []string{"id", "name", "status", "priority", "due_date", "created_at", "updated_at", "version"}
+
+// SelectableTaskFields lists the task fields a sparse fieldset can select
+var SelectableTaskFields = []string{
+	"id", "name", "description", "status", "priority", "due_date", "tags", "version", "created_at", "updated_at",
+}
+
+// SortField is one key of a task ordering
+type SortField struct {
+	Field string // JSON name of the task field
+	Desc  bool   // Whether the order is descending
+}
+
+// TaskQuery describes a task listing: which tasks, in which order and with which fields
+type TaskQuery struct {
+	Filter *TaskFilter // Tasks to include
+	Sort   []SortField // Ordering keys, most significant first (empty = creation order)
+	Fields []string    // Fields to return (empty = all)
+}
+
+// ParseTaskQuery builds a query from list query parameters.
+// On top of the filter keys of ParseTaskFilter it supports sort (comma-separated fields,
+// "-" prefix for descending) and fields (comma-separated sparse fieldset).
+func ParseTaskQuery(query map[string][]string) (*TaskQuery, error) {
+	filter, err := ParseTaskFilter(query)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &TaskQuery{Filter: filter}
+	if _, ok := query["sort"]; ok {
+		if result.Sort, err = ParseSort(firstValue(query, "sort")); err != nil {
+			return nil, err
+		}
+	}
+	if _, ok := query["fields"]; ok {
+		if result.Fields, err = ParseFields(firstValue(query, "fields")); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// ParseSort parses a sort expression such as "-updated_at,name"
+func ParseSort(value string) ([]SortField, error) {
+	var fields []SortField
+	seen := make(map[string]bool)
+
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		field := SortField{Field: strings.TrimPrefix(part, "-"), Desc: strings.HasPrefix(part, "-")}
+		if field.Field == "" {
+			return nil, fmt.Errorf("sort contains an empty field")
+		}
+		if !containsString(SortableTaskFields, field.Field) {
+			return nil, fmt.Errorf("cannot sort by %q (sortable fields: %s)", field.Field, strings.Join(SortableTaskFields, ", "))
+		}
+		if seen[field.Field] {
+			return nil, fmt.Errorf("sort lists %q more than once", field.Field)
+		}
+		seen[field.Field] = true
+		fields = append(fields, field)
+	}
+
+	return fields, nil
+}
+
+// ParseFields parses a sparse fieldset such as "id,name"; duplicates are ignored
+func ParseFields(value string) ([]string, error) {
+	var fields []string
+
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			return nil, fmt.Errorf("fields contains an empty field")
+		}
+		if !containsString(SelectableTaskFields, field) {
+			return nil, fmt.Errorf("unknown field %q (available fields: %s)", field, strings.Join(SelectableTaskFields, ", "))
+		}
+		if !containsString(fields, field) {
+			fields = append(fields, field)
+		}
+	}
+
+	return fields, nil
+}
+
+// containsString reports whether values contains value
+func containsString(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
+
+// SortTasks orders tasks in place by the given keys
+// Ties are broken by creation time and then ID, so every ordering is deterministic.
+// Tasks without a due date sort after all dated tasks in ascending order.
+func SortTasks(tasks []*Task, order []SortField) {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		for _, key := range order {
+			if c := compareTaskField(tasks[i], tasks[j], key.Field); c != 0 {
+				return (c < 0) != key.Desc
+			}
+		}
+		if c := compareTaskField(tasks[i], tasks[j], "created_at"); c != 0 {
+			return c < 0
+		}
+		return tasks[i].ID < tasks[j].ID
+	})
+}
+
+// compareTaskField compares one sortable field of two tasks, returning -1, 0 or 1
+func compareTaskField(a, b *Task, field string) int {
+	switch field {
+	case "id":
+		return strings.Compare(a.ID, b.ID)
+	case "name":
+		return strings.Compare(a.Name, b.Name)
+	case "status":
+		return compareInts(int(a.Status), int(b.Status))
+	case "priority":
+		return compareInts(int(a.Priority), int(b.Priority))
+	case "version":
+		return compareInts(a.Version, b.Version)
+	case "created_at":
+		return a.CreatedAt.Compare(b.CreatedAt)
+	case "updated_at":
+		return a.UpdatedAt.Compare(b.UpdatedAt)
+	case "due_date":
+		switch {
+		case a.DueDate == nil && b.DueDate == nil:
+			return 0
+		case a.DueDate == nil:
+			return 1
+		case b.DueDate == nil:
+			return -1
+		}
+		return a.DueDate.Compare(*b.DueDate)
+	}
+	return 0
+}
+
+// compareInts returns -1, 0 or 1 as a is less than, equal to or greater than b
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// SelectFields returns the JSON representation of a task restricted to the given fields
+// Selected fields the task leaves unset (e.g. no due date) are returned as null.
+func SelectFields(task *Task, fields []string) map[string]interface{} {
+	selected := make(map[string]interface{}, len(fields))
+
+	data, err := json.Marshal(task)
+	if err != nil {
+		return selected
+	}
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return selected
+	}
+
+	for _, field := range fields {
+		if value, ok := all[field]; ok {
+			selected[field] = value
+		} else {
+			selected[field] = nil
+		}
+	}
+	return selected
+}
+
+// TaskFieldsListResponse represents the DTO for a task list restricted to a sparse fieldset
+type TaskFieldsListResponse struct {
+	Success bool                     `json:"success"`        // Whether the operation was successful
+	Data    []map[string]interface{} `json:"data,omitempty"` // Selected fields of each task
+	Count   int                      `json:"count"`          // Total number of tasks
+}
+
+// NewTaskFieldsListResponse creates a task list response containing only the given fields (Factory Pattern)
+func NewTaskFieldsListResponse(tasks []*Task, fields []string) *TaskFieldsListResponse {
+	data := make([]map[string]interface{}, len(tasks))
+	for i, task := range tasks {
+		data[i] = SelectFields(task, fields)
+	}
+
+	return &TaskFieldsListResponse{
+		Success: true,
+		Data:    data,
+		Count:   len(tasks),
+	}
+}
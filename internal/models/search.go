@@ -0,0 +1,37 @@
+package models
+
+// Search limits
+const (
+	MaxSearchQueryLength = 256 // Maximum length of a search query in bytes
+	DefaultSearchLimit   = 20  // Results returned when no limit is given
+	MaxSearchLimit       = 100 // Maximum number of results per search
+)
+
+// SearchResult represents one task matched by a full-text search
+type SearchResult struct {
+	Task       *Task             `json:"task"`                 // Matched task
+	Score      float64           `json:"score"`                // Relevance; higher is better
+	Highlights map[string]string `json:"highlights,omitempty"` // Matching fields (name, description) with the query words marked
+}
+
+// SearchResponse represents the DTO for a full-text search response
+type SearchResponse struct {
+	Success bool           `json:"success"` // Whether the operation was successful
+	Query   string         `json:"query"`   // Query as received
+	Data    []SearchResult `json:"data"`    // Results, best match first
+	Count   int            `json:"count"`   // Number of results
+}
+
+// NewSearchResponse creates a search response (Factory Pattern)
+func NewSearchResponse(query string, results []SearchResult) *SearchResponse {
+	if results == nil {
+		results = []SearchResult{}
+	}
+
+	return &SearchResponse{
+		Success: true,
+		Query:   query,
+		Data:    results,
+		Count:   len(results),
+	}
+}
@@ -0,0 +1,43 @@
+package models
+
+// SocketMessageType names the kind of a WebSocket message
+type SocketMessageType string
+
+// Messages sent by the client
+const (
+	SocketSubscribe   SocketMessageType = "subscribe"   // Start or replace a subscription
+	SocketUnsubscribe SocketMessageType = "unsubscribe" // End a subscription
+	SocketPing        SocketMessageType = "ping"        // Application-level keepalive, answered with pong
+)
+
+// Messages sent by the server
+const (
+	SocketSubscribed   SocketMessageType = "subscribed"   // Subscription is active
+	SocketUnsubscribed SocketMessageType = "unsubscribed" // Subscription has ended
+	SocketEvent        SocketMessageType = "event"        // Task change matching one or more subscriptions
+	SocketPong         SocketMessageType = "pong"         // Answer to a ping
+	SocketError        SocketMessageType = "error"        // Request could not be handled
+)
+
+// WebSocket limits
+const (
+	MaxSocketSubscriptions   = 32   // Subscriptions per connection
+	MaxSubscriptionIDLength  = 64   // Length of a subscription ID
+	MaxFilterExpressionBytes = 1024 // Length of a subscription's filter expression
+)
+
+// SocketRequest represents a message sent by a WebSocket client
+type SocketRequest struct {
+	Type   SocketMessageType `json:"type"`             // Message kind
+	ID     string            `json:"id,omitempty"`     // Subscription ID chosen by the client (subscribe, unsubscribe)
+	Filter string            `json:"filter,omitempty"` // Filter expression, e.g. "status=completed&tag=urgent" (subscribe, empty = every task)
+}
+
+// SocketMessage represents a message sent to a WebSocket client
+type SocketMessage struct {
+	Type          SocketMessageType `json:"type"`                    // Message kind
+	ID            string            `json:"id,omitempty"`            // Subscription the message answers
+	Subscriptions []string          `json:"subscriptions,omitempty"` // Subscriptions matching the event (event)
+	Event         *TaskEvent        `json:"event,omitempty"`         // Task change (event)
+	Error         string            `json:"error,omitempty"`         // What went wrong (error)
+}
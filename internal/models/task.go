@@ -1,95 +1,331 @@
 package models
 
 import (
+	"encoding/json"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 )
 
-// TaskStatus defines the enumeration values for task status
+// TaskStatus identifies a workflow state by its position in the current workflow
 type TaskStatus int
 
 const (
-	// TaskIncomplete represents an incomplete task
+	// TaskIncomplete represents an incomplete task (first state of the default workflow)
 	TaskIncomplete TaskStatus = 0
-	// TaskCompleted represents a completed task
+	// TaskCompleted represents a completed task (second state of the default workflow)
 	TaskCompleted TaskStatus = 1
 )
 
-// String implements the Stringer interface, providing string representation of status
+// String implements the Stringer interface, returning the workflow state name
 func (ts TaskStatus) String() string {
-	switch ts {
-	case TaskIncomplete:
-		return "incomplete"
-	case TaskCompleted:
-		return "completed"
+	return CurrentWorkflow().Name(ts)
+}
+
+// IsValid checks if the status is a state of the current workflow
+func (ts TaskStatus) IsValid() bool {
+	return CurrentWorkflow().Contains(ts)
+}
+
+// UnmarshalJSON accepts either the numeric status or a workflow state name
+func (ts *TaskStatus) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		var name string
+		if err := json.Unmarshal(data, &name); err != nil {
+			return err
+		}
+		status, err := ParseTaskStatus(name)
+		if err != nil {
+			return err
+		}
+		*ts = status
+		return nil
+	}
+
+	var n int
+	if err := json.Unmarshal(data, &n); err != nil {
+		return fmt.Errorf("task status must be a number or state name: %w", err)
+	}
+	*ts = TaskStatus(n)
+	return nil
+}
+
+// TaskPriority defines the enumeration values for task priority
+type TaskPriority int
+
+const (
+	// PriorityLow is the default priority
+	PriorityLow TaskPriority = 0
+	// PriorityMedium represents a medium priority task
+	PriorityMedium TaskPriority = 1
+	// PriorityHigh represents a high priority task
+	PriorityHigh TaskPriority = 2
+	// PriorityUrgent represents an urgent task
+	PriorityUrgent TaskPriority = 3
+)
+
+// String implements the Stringer interface, providing string representation of priority
+func (tp TaskPriority) String() string {
+	switch tp {
+	case PriorityLow:
+		return "low"
+	case PriorityMedium:
+		return "medium"
+	case PriorityHigh:
+		return "high"
+	case PriorityUrgent:
+		return "urgent"
 	default:
 		return "unknown"
 	}
 }
 
-// IsValid checks if the status value is valid
-func (ts TaskStatus) IsValid() bool {
-	return ts == TaskIncomplete || ts == TaskCompleted
+// IsValid checks if the priority value is valid
+func (tp TaskPriority) IsValid() bool {
+	return tp >= PriorityLow && tp <= PriorityUrgent
 }
 
+// ParseTaskPriority parses a priority from its name ("high") or numeric value ("2")
+func ParseTaskPriority(value string) (TaskPriority, error) {
+	if n, err := strconv.Atoi(value); err == nil {
+		priority := TaskPriority(n)
+		if !priority.IsValid() {
+			return 0, fmt.Errorf("invalid task priority: %d", n)
+		}
+		return priority, nil
+	}
+
+	for priority := PriorityLow; priority <= PriorityUrgent; priority++ {
+		if strings.EqualFold(value, priority.String()) {
+			return priority, nil
+		}
+	}
+
+	return 0, fmt.Errorf("invalid task priority: %q", value)
+}
+
+// Field limits enforced by the Validate methods
+const (
+	MaxNameLength        = 255   // Maximum task name length
+	MaxDescriptionLength = 10000 // Maximum markdown description length
+	MaxTags              = 20    // Maximum number of tags per task
+	MaxTagLength         = 50    // Maximum length of a single tag
+)
+
 // Task represents a task entity
 type Task struct {
-	ID        string     `json:"id"`                      // Unique identifier
-	Name      string     `json:"name" binding:"required"` // Task name (required)
-	Status    TaskStatus `json:"status"`                  // Task status
-	CreatedAt time.Time  `json:"created_at"`              // Creation time
-	UpdatedAt time.Time  `json:"updated_at"`              // Last update time
+	ID          string       `json:"id"`                      // Unique identifier
+	Name        string       `json:"name" binding:"required"` // Task name (required)
+	Description string       `json:"description,omitempty"`   // Markdown description
+	Status      TaskStatus   `json:"status"`                  // Task status
+	Priority    TaskPriority `json:"priority"`                // Task priority
+	DueDate     *time.Time   `json:"due_date,omitempty"`      // Optional due date
+	Tags        []string     `json:"tags,omitempty"`          // Normalized, sorted tag set
+	OwnerID     string       `json:"owner_id,omitempty"`      // Subject of the caller who created the task (empty when created without authentication)
+	Version     int          `json:"version"`                 // Revision number, starts at 1 and increases on every change
+	CreatedAt   time.Time    `json:"created_at"`              // Creation time
+	UpdatedAt   time.Time    `json:"updated_at"`              // Last update time
+}
+
+// Clone returns a deep copy of the task so callers cannot mutate stored state
+func (t *Task) Clone() *Task {
+	clone := *t
+
+	if t.DueDate != nil {
+		dueDate := *t.DueDate
+		clone.DueDate = &dueDate
+	}
+	if t.Tags != nil {
+		clone.Tags = append([]string(nil), t.Tags...)
+	}
+
+	return &clone
+}
+
+// HasTag reports whether the task carries the given (normalized) tag
+func (t *Task) HasTag(tag string) bool {
+	i := sort.SearchStrings(t.Tags, tag)
+	return i < len(t.Tags) && t.Tags[i] == tag
+}
+
+// NormalizeTags trims, lowercases, de-duplicates and sorts tags so they behave as a set
+func NormalizeTags(tags []string) []string {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	seen := make(map[string]struct{}, len(tags))
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+		seen[tag] = struct{}{}
+		normalized = append(normalized, tag)
+	}
+	sort.Strings(normalized)
+
+	return normalized
+}
+
+// validateTags checks the tag count and each tag's format
+func validateTags(tags []string) error {
+	if len(tags) > MaxTags {
+		return fmt.Errorf("a task cannot have more than %d tags", MaxTags)
+	}
+
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			return fmt.Errorf("tags cannot be empty")
+		}
+		if len(tag) > MaxTagLength {
+			return fmt.Errorf("tag %q exceeds %d characters", tag, MaxTagLength)
+		}
+		for _, r := range tag {
+			if !isTagRune(r) {
+				return fmt.Errorf("tag %q contains invalid character %q", tag, r)
+			}
+		}
+	}
+
+	return nil
+}
+
+// isTagRune reports whether r may appear in a tag
+func isTagRune(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' ||
+		r == '-' || r == '_' || r == ':' || r == '.'
+}
+
+// validateName checks the task name length
+func validateName(name string) error {
+	if name == "" {
+		return fmt.Errorf("task name cannot be empty")
+	}
+	if len(name) > MaxNameLength {
+		return fmt.Errorf("task name cannot exceed %d characters", MaxNameLength)
+	}
+	return nil
+}
+
+// validateDescription checks the description length
+func validateDescription(description string) error {
+	if len(description) > MaxDescriptionLength {
+		return fmt.Errorf("task description cannot exceed %d characters", MaxDescriptionLength)
+	}
+	return nil
 }
 
 // CreateTaskRequest represents the DTO for creating a task
 type CreateTaskRequest struct {
-	Name   string     `json:"name" binding:"required"` // Task name (required)
-	Status TaskStatus `json:"status"`                  // Task status (optional, defaults to incomplete)
+	Name        string       `json:"name" binding:"required"` // Task name (required)
+	Description string       `json:"description,omitempty"`   // Markdown description (optional)
+	Status      TaskStatus   `json:"status"`                  // Task status (optional, defaults to incomplete)
+	Priority    TaskPriority `json:"priority"`                // Task priority (optional, defaults to low)
+	DueDate     *time.Time   `json:"due_date,omitempty"`      // Due date (optional, RFC 3339)
+	Tags        []string     `json:"tags,omitempty"`          // Tags (optional)
+	OwnerID     string       `json:"-"`                       // Owner recorded on the task, set from the authenticated caller
 }
 
 // Validate validates the create request
 func (req *CreateTaskRequest) Validate() error {
-	if req.Name == "" {
-		return fmt.Errorf("task name cannot be empty")
+	if err := validateName(req.Name); err != nil {
+		return err
 	}
-	if len(req.Name) > 255 {
-		return fmt.Errorf("task name cannot exceed 255 characters")
+	if err := validateDescription(req.Description); err != nil {
+		return err
 	}
 	if !req.Status.IsValid() {
 		return fmt.Errorf("invalid task status: %d", req.Status)
 	}
-	return nil
+	if !req.Priority.IsValid() {
+		return fmt.Errorf("invalid task priority: %d", req.Priority)
+	}
+	if req.DueDate != nil && req.DueDate.IsZero() {
+		return fmt.Errorf("task due date cannot be the zero time")
+	}
+	return validateTags(req.Tags)
+}
+
+// ToTask builds a new task entity from the request
+func (req *CreateTaskRequest) ToTask() *Task {
+	task := NewTask(req.Name, req.Status)
+	task.Description = req.Description
+	task.Priority = req.Priority
+	task.Tags = NormalizeTags(req.Tags)
+	task.OwnerID = req.OwnerID
+
+	if req.DueDate != nil {
+		dueDate := req.DueDate.UTC()
+		task.DueDate = &dueDate
+	}
+
+	return task
 }
 
 // UpdateTaskRequest represents the DTO for updating a task
+// Tags replaces the whole tag set when present; an empty list removes all tags.
 type UpdateTaskRequest struct {
-	Name   *string     `json:"name,omitempty"`   // Task name (optional)
-	Status *TaskStatus `json:"status,omitempty"` // Task status (optional)
+	Name         *string       `json:"name,omitempty"`           // Task name (optional)
+	Description  *string       `json:"description,omitempty"`    // Markdown description (optional)
+	Status       *TaskStatus   `json:"status,omitempty"`         // Task status (optional)
+	Priority     *TaskPriority `json:"priority,omitempty"`       // Task priority (optional)
+	DueDate      *time.Time    `json:"due_date,omitempty"`       // Due date (optional)
+	ClearDueDate bool          `json:"clear_due_date,omitempty"` // Remove the due date (optional)
+	Tags         *[]string     `json:"tags,omitempty"`           // Replacement tag set (optional)
 }
 
 // Validate validates the update request
 func (req *UpdateTaskRequest) Validate() error {
 	if req.Name != nil {
-		if *req.Name == "" {
-			return fmt.Errorf("task name cannot be empty")
+		if err := validateName(*req.Name); err != nil {
+			return err
 		}
-		if len(*req.Name) > 255 {
-			return fmt.Errorf("task name cannot exceed 255 characters")
+	}
+	if req.Description != nil {
+		if err := validateDescription(*req.Description); err != nil {
+			return err
 		}
 	}
 	if req.Status != nil && !req.Status.IsValid() {
 		return fmt.Errorf("invalid task status: %d", *req.Status)
 	}
+	if req.Priority != nil && !req.Priority.IsValid() {
+		return fmt.Errorf("invalid task priority: %d", *req.Priority)
+	}
+	if req.DueDate != nil {
+		if req.ClearDueDate {
+			return fmt.Errorf("due_date and clear_due_date cannot be combined")
+		}
+		if req.DueDate.IsZero() {
+			return fmt.Errorf("task due date cannot be the zero time")
+		}
+	}
+	if req.Tags != nil {
+		return validateTags(*req.Tags)
+	}
 	return nil
 }
 
 // HasUpdates checks if there are any fields to update
 func (req *UpdateTaskRequest) HasUpdates() bool {
-	return req.Name != nil || req.Status != nil
+	return req.Name != nil || req.Description != nil || req.Status != nil || req.Priority != nil ||
+		req.DueDate != nil || req.ClearDueDate || req.Tags != nil
 }
 
 // ApplyTo applies the update request to an existing task
-func (req *UpdateTaskRequest) ApplyTo(task *Task) {
+// A status change the workflow does not allow fails with ErrInvalidTransition and leaves the task untouched.
+func (req *UpdateTaskRequest) ApplyTo(task *Task) error {
+	if req.Status != nil {
+		if err := CurrentWorkflow().CheckTransition(task.Status, *req.Status); err != nil {
+			return err
+		}
+	}
+
 	now := time.Now()
 
 	if req.Name != nil {
@@ -97,10 +333,59 @@ func (req *UpdateTaskRequest) ApplyTo(task *Task) {
 		task.UpdatedAt = now
 	}
 
+	if req.Description != nil {
+		task.Description = *req.Description
+		task.UpdatedAt = now
+	}
+
 	if req.Status != nil {
 		task.Status = *req.Status
 		task.UpdatedAt = now
 	}
+
+	if req.Priority != nil {
+		task.Priority = *req.Priority
+		task.UpdatedAt = now
+	}
+
+	if req.DueDate != nil {
+		dueDate := req.DueDate.UTC()
+		task.DueDate = &dueDate
+		task.UpdatedAt = now
+	} else if req.ClearDueDate {
+		task.DueDate = nil
+		task.UpdatedAt = now
+	}
+
+	if req.Tags != nil {
+		task.Tags = NormalizeTags(*req.Tags)
+		task.UpdatedAt = now
+	}
+
+	return nil
+}
+
+// ReplaceRequest builds the update that sets every user-editable field to this task's values
+func (t *Task) ReplaceRequest() *UpdateTaskRequest {
+	task := t.Clone()
+	tags := task.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	req := &UpdateTaskRequest{
+		Name:        &task.Name,
+		Description: &task.Description,
+		Status:      &task.Status,
+		Priority:    &task.Priority,
+		DueDate:     task.DueDate,
+		Tags:        &tags,
+	}
+	if task.DueDate == nil {
+		req.ClearDueDate = true
+	}
+
+	return req
 }
 
 // TaskResponse represents the DTO for single task response
@@ -112,9 +397,10 @@ type TaskResponse struct {
 
 // TaskListResponse represents the DTO for task list response
 type TaskListResponse struct {
-	Success bool    `json:"success"`        // Whether the operation was successful
-	Data    []*Task `json:"data,omitempty"` // Task list
-	Count   int     `json:"count"`          // Total number of tasks
+	Success    bool    `json:"success"`               // Whether the operation was successful
+	Data       []*Task `json:"data,omitempty"`        // Task list
+	Count      int     `json:"count"`                 // Total number of tasks
+	NextCursor string  `json:"next_cursor,omitempty"` // Cursor of the next page (paginated listings with more tasks only)
 }
 
 // ErrorResponse represents the DTO for error response
@@ -124,6 +410,18 @@ type ErrorResponse struct {
 	Error   string `json:"error,omitempty"` // Detailed error information
 }
 
+// ProblemContentType is the media type of RFC 7807 problem details
+const ProblemContentType = "application/problem+json"
+
+// ProblemDetails represents an RFC 7807 error response, returned when the client accepts application/problem+json
+type ProblemDetails struct {
+	Type     string `json:"type"`               // URI identifying the problem type
+	Title    string `json:"title"`              // Short summary of the problem type
+	Status   int    `json:"status"`             // HTTP status code
+	Detail   string `json:"detail,omitempty"`   // Explanation specific to this occurrence
+	Instance string `json:"instance,omitempty"` // URI reference of the request that failed
+}
+
 // HealthResponse represents the DTO for health check response
 type HealthResponse struct {
 	Status    string    `json:"status"`    // Service status
@@ -131,12 +429,22 @@ type HealthResponse struct {
 	Version   string    `json:"version"`   // Service version
 }
 
+// StorageStats represents statistics about the storage
+type StorageStats struct {
+	TotalTasks      int    `json:"total_tasks"`      // Total number of tasks
+	CompletedTasks  int    `json:"completed_tasks"`  // Number of completed tasks
+	IncompleteTasks int    `json:"incomplete_tasks"` // Number of incomplete tasks
+	LastID          int    `json:"last_id"`          // Last generated ID
+	StorageType     string `json:"storage_type"`     // Type of storage (sharded_memory, database, etc.)
+}
+
 // NewTask creates a new task entity (Factory Pattern)
 func NewTask(name string, status TaskStatus) *Task {
 	now := time.Now()
 	return &Task{
 		Name:      name,
 		Status:    status,
+		Version:   1,
 		CreatedAt: now,
 		UpdatedAt: now,
 	}
@@ -174,6 +482,22 @@ func NewErrorResponse(message string, err error) *ErrorResponse {
 	return response
 }
 
+// NewProblemDetails creates an RFC 7807 problem response (Factory Pattern)
+func NewProblemDetails(status int, title string, err error, instance string) *ProblemDetails {
+	problem := &ProblemDetails{
+		Type:     "about:blank",
+		Title:    title,
+		Status:   status,
+		Instance: instance,
+	}
+
+	if err != nil {
+		problem.Detail = err.Error()
+	}
+
+	return problem
+}
+
 // NewHealthResponse creates a health check response (Factory Pattern)
 func NewHealthResponse(version string) *HealthResponse {
 	return &HealthResponse{
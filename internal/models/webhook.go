@@ -0,0 +1,271 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// Webhook limits
+const (
+	MinWebhookSecretLength    = 16   // Shortest signing secret accepted from clients
+	MaxWebhookURLLength       = 2048 // Longest delivery URL accepted
+	MaxWebhookDescriptionSize = 500  // Longest description accepted
+)
+
+// WebhookEventTypes lists the task event types a webhook may subscribe to
+var WebhookEventTypes = []TaskEventType{TaskCreated, TaskUpdated, TaskDeleted}
+
+// Webhook is a subscription delivering task change events to an HTTP endpoint
+type Webhook struct {
+	ID          string          `json:"id"`                    // Unique identifier
+	URL         string          `json:"url"`                   // Endpoint receiving the POST requests
+	Events      []TaskEventType `json:"events"`                // Event types delivered (empty = all)
+	Description string          `json:"description,omitempty"` // Free-form note about the receiver
+	Active      bool            `json:"active"`                // Inactive webhooks receive nothing
+	Secret      string          `json:"secret,omitempty"`      // Signing secret (only returned when created or rotated)
+	CreatedAt   time.Time       `json:"created_at"`            // Creation timestamp
+	UpdatedAt   time.Time       `json:"updated_at"`            // Last update timestamp
+}
+
+// Clone returns a copy of the webhook so callers cannot mutate stored subscriptions
+func (w *Webhook) Clone() *Webhook {
+	clone := *w
+	clone.Events = append([]TaskEventType(nil), w.Events...)
+	return &clone
+}
+
+// Redacted returns a copy of the webhook without its secret, for responses
+func (w *Webhook) Redacted() *Webhook {
+	clone := w.Clone()
+	clone.Secret = ""
+	return clone
+}
+
+// Wants reports whether the webhook is active and subscribed to the event type
+func (w *Webhook) Wants(eventType TaskEventType) bool {
+	if !w.Active {
+		return false
+	}
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, subscribed := range w.Events {
+		if subscribed == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateWebhookRequest represents the DTO for creating a webhook
+type CreateWebhookRequest struct {
+	URL         string          `json:"url" binding:"required"` // Endpoint receiving the POST requests
+	Events      []TaskEventType `json:"events,omitempty"`       // Event types to deliver (empty = all)
+	Description string          `json:"description,omitempty"`  // Free-form note about the receiver
+	Secret      string          `json:"secret,omitempty"`       // Signing secret (generated if empty)
+	Active      *bool           `json:"active,omitempty"`       // Whether deliveries start right away (default true)
+}
+
+// Validate validates the create request
+func (req *CreateWebhookRequest) Validate() error {
+	if err := validateWebhookURL(req.URL); err != nil {
+		return err
+	}
+	if err := validateWebhookEvents(req.Events); err != nil {
+		return err
+	}
+	if len(req.Description) > MaxWebhookDescriptionSize {
+		return fmt.Errorf("webhook description cannot exceed %d characters", MaxWebhookDescriptionSize)
+	}
+	if req.Secret != "" {
+		return validateWebhookSecret(req.Secret)
+	}
+	return nil
+}
+
+// UpdateWebhookRequest represents the DTO for updating a webhook; only the fields present change
+type UpdateWebhookRequest struct {
+	URL         *string          `json:"url,omitempty"`         // Endpoint receiving the POST requests
+	Events      *[]TaskEventType `json:"events,omitempty"`      // Replacement event types (empty = all)
+	Description *string          `json:"description,omitempty"` // Free-form note about the receiver
+	Secret      *string          `json:"secret,omitempty"`      // Replacement signing secret ("" = generate one)
+	Active      *bool            `json:"active,omitempty"`      // Pause or resume deliveries
+}
+
+// Validate validates the update request
+func (req *UpdateWebhookRequest) Validate() error {
+	if req.URL == nil && req.Events == nil && req.Description == nil && req.Secret == nil && req.Active == nil {
+		return fmt.Errorf("no updates provided")
+	}
+	if req.URL != nil {
+		if err := validateWebhookURL(*req.URL); err != nil {
+			return err
+		}
+	}
+	if req.Events != nil {
+		if err := validateWebhookEvents(*req.Events); err != nil {
+			return err
+		}
+	}
+	if req.Description != nil && len(*req.Description) > MaxWebhookDescriptionSize {
+		return fmt.Errorf("webhook description cannot exceed %d characters", MaxWebhookDescriptionSize)
+	}
+	if req.Secret != nil && *req.Secret != "" {
+		return validateWebhookSecret(*req.Secret)
+	}
+	return nil
+}
+
+// validateWebhookURL accepts absolute http and https URLs
+func validateWebhookURL(raw string) error {
+	if len(raw) > MaxWebhookURLLength {
+		return fmt.Errorf("webhook url cannot exceed %d characters", MaxWebhookURLLength)
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("invalid webhook url: %w", err)
+	}
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("webhook url must be an absolute http or https URL")
+	}
+	return nil
+}
+
+// validateWebhookEvents rejects unknown and repeated event types
+func validateWebhookEvents(events []TaskEventType) error {
+	seen := make(map[TaskEventType]bool, len(events))
+	for _, event := range events {
+		known := false
+		for _, eventType := range WebhookEventTypes {
+			known = known || event == eventType
+		}
+		if !known {
+			return fmt.Errorf("unknown webhook event type %q (supported: created, updated, deleted)", event)
+		}
+		if seen[event] {
+			return fmt.Errorf("webhook event type %q is listed twice", event)
+		}
+		seen[event] = true
+	}
+	return nil
+}
+
+// validateWebhookSecret enforces a minimum secret length so signatures cannot be guessed
+func validateWebhookSecret(secret string) error {
+	if len(secret) < MinWebhookSecretLength {
+		return fmt.Errorf("webhook secret must be at least %d characters", MinWebhookSecretLength)
+	}
+	return nil
+}
+
+// WebhookDeliveryStatus describes where a delivery is in its retry cycle
+type WebhookDeliveryStatus string
+
+const (
+	DeliveryPending   WebhookDeliveryStatus = "pending"   // Waiting for its first attempt
+	DeliveryRetrying  WebhookDeliveryStatus = "retrying"  // Failed at least once and will be attempted again
+	DeliverySucceeded WebhookDeliveryStatus = "succeeded" // Receiver answered with a 2xx status
+	DeliveryDead      WebhookDeliveryStatus = "dead"      // Every attempt failed; only a manual retry sends it again
+)
+
+// WebhookAttempt records one HTTP request of a delivery
+type WebhookAttempt struct {
+	Time       time.Time `json:"time"`                  // When the request was sent
+	StatusCode int       `json:"status_code,omitempty"` // Response status (absent if no response was received)
+	Error      string    `json:"error,omitempty"`       // Why the attempt failed
+	DurationMs int64     `json:"duration_ms"`           // Time until the response or the failure
+}
+
+// WebhookDelivery is the delivery of one task event to one webhook
+type WebhookDelivery struct {
+	ID            string                `json:"id"`                        // Unique identifier, sent as X-Webhook-Delivery
+	WebhookID     string                `json:"webhook_id"`                // Receiving webhook
+	Event         TaskEvent             `json:"event"`                     // Delivered event
+	Status        WebhookDeliveryStatus `json:"status"`                    // Current state
+	Attempts      []WebhookAttempt      `json:"attempts"`                  // Requests sent so far, oldest first
+	NextAttemptAt *time.Time            `json:"next_attempt_at,omitempty"` // When the next attempt is due (pending and retrying only)
+	CreatedAt     time.Time             `json:"created_at"`                // When the event was queued
+}
+
+// Clone returns a copy of the delivery so callers cannot mutate the delivery log
+func (d *WebhookDelivery) Clone() *WebhookDelivery {
+	clone := *d
+	clone.Attempts = append([]WebhookAttempt(nil), d.Attempts...)
+	if d.NextAttemptAt != nil {
+		next := *d.NextAttemptAt
+		clone.NextAttemptAt = &next
+	}
+	return &clone
+}
+
+// WebhookPayload is the JSON body POSTed to a webhook
+type WebhookPayload struct {
+	DeliveryID string    `json:"delivery_id"` // Same for every attempt of a delivery, for deduplication
+	WebhookID  string    `json:"webhook_id"`  // Receiving webhook
+	Event      TaskEvent `json:"event"`       // The task change
+}
+
+// WebhookResponse represents the DTO for a single webhook
+type WebhookResponse struct {
+	Success bool     `json:"success"`           // Whether the operation was successful
+	Message string   `json:"message,omitempty"` // Response message
+	Data    *Webhook `json:"data,omitempty"`    // Webhook data
+}
+
+// WebhookListResponse represents the DTO for a webhook list
+type WebhookListResponse struct {
+	Success bool       `json:"success"`        // Whether the operation was successful
+	Data    []*Webhook `json:"data,omitempty"` // Webhooks, oldest first
+	Count   int        `json:"count"`          // Number of webhooks
+}
+
+// WebhookDeliveryResponse represents the DTO for a single delivery
+type WebhookDeliveryResponse struct {
+	Success bool             `json:"success"`           // Whether the operation was successful
+	Message string           `json:"message,omitempty"` // Response message
+	Data    *WebhookDelivery `json:"data,omitempty"`    // Delivery data
+}
+
+// WebhookDeliveryListResponse represents the DTO for a webhook's delivery log
+type WebhookDeliveryListResponse struct {
+	Success bool               `json:"success"`        // Whether the operation was successful
+	Data    []*WebhookDelivery `json:"data,omitempty"` // Deliveries, newest first
+	Count   int                `json:"count"`          // Number of deliveries
+}
+
+// NewWebhookResponse creates a single webhook response (Factory Pattern)
+func NewWebhookResponse(webhook *Webhook, message string) *WebhookResponse {
+	return &WebhookResponse{
+		Success: true,
+		Message: message,
+		Data:    webhook,
+	}
+}
+
+// NewWebhookListResponse creates a webhook list response (Factory Pattern)
+func NewWebhookListResponse(webhooks []*Webhook) *WebhookListResponse {
+	return &WebhookListResponse{
+		Success: true,
+		Data:    webhooks,
+		Count:   len(webhooks),
+	}
+}
+
+// NewWebhookDeliveryResponse creates a single delivery response (Factory Pattern)
+func NewWebhookDeliveryResponse(delivery *WebhookDelivery, message string) *WebhookDeliveryResponse {
+	return &WebhookDeliveryResponse{
+		Success: true,
+		Message: message,
+		Data:    delivery,
+	}
+}
+
+// NewWebhookDeliveryListResponse creates a delivery log response (Factory Pattern)
+func NewWebhookDeliveryListResponse(deliveries []*WebhookDelivery) *WebhookDeliveryListResponse {
+	return &WebhookDeliveryListResponse{
+		Success: true,
+		Data:    deliveries,
+		Count:   len(deliveries),
+	}
+}
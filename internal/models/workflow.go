@@ -0,0 +1,206 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// ErrInvalidTransition is returned when an update moves a task along an edge the workflow does not allow
+var ErrInvalidTransition = errors.New("invalid status transition")
+
+// WorkflowState defines a named task status and where a task may move from it
+type WorkflowState struct {
+	Name        string   `json:"name"`                  // Unique state name used in URLs and filters
+	Transitions []string `json:"transitions,omitempty"` // States reachable from this one
+	Terminal    bool     `json:"terminal,omitempty"`    // Work is finished in this state (counted as completed)
+}
+
+// Workflow is the state machine governing task statuses
+// A TaskStatus is the index of its state in States, so states must only ever be appended:
+// persisted tasks store the index. The first state is the initial state: new tasks start in it,
+// or in a state it may move to, so creating a task never skips a step of the workflow.
+type Workflow struct {
+	States []WorkflowState `json:"states"` // Ordered state definitions
+
+	index       map[string]TaskStatus              // State name -> status
+	transitions map[TaskStatus]map[TaskStatus]bool // Allowed edges
+}
+
+// DefaultWorkflow returns the built-in incomplete/completed workflow, where tasks may move freely
+func DefaultWorkflow() *Workflow {
+	workflow, _ := NewWorkflow([]WorkflowState{
+		{Name: "incomplete", Transitions: []string{"completed"}},
+		{Name: "completed", Transitions: []string{"incomplete"}, Terminal: true},
+	})
+	return workflow
+}
+
+// NewWorkflow validates the state definitions and builds a workflow (Factory Pattern)
+func NewWorkflow(states []WorkflowState) (*Workflow, error) {
+	if len(states) == 0 {
+		return nil, fmt.Errorf("workflow must define at least one state")
+	}
+
+	workflow := &Workflow{
+		States:      states,
+		index:       make(map[string]TaskStatus, len(states)),
+		transitions: make(map[TaskStatus]map[TaskStatus]bool, len(states)),
+	}
+
+	for i, state := range states {
+		name := strings.TrimSpace(state.Name)
+		if name == "" {
+			return nil, fmt.Errorf("workflow state %d has no name", i)
+		}
+		if _, err := strconv.Atoi(name); err == nil {
+			return nil, fmt.Errorf("workflow state name %q cannot be numeric", name)
+		}
+		key := strings.ToLower(name)
+		if _, exists := workflow.index[key]; exists {
+			return nil, fmt.Errorf("workflow state %q is defined twice", name)
+		}
+		workflow.index[key] = TaskStatus(i)
+	}
+
+	for i, state := range states {
+		edges := make(map[TaskStatus]bool, len(state.Transitions))
+		for _, target := range state.Transitions {
+			to, ok := workflow.index[strings.ToLower(strings.TrimSpace(target))]
+			if !ok {
+				return nil, fmt.Errorf("workflow state %q has a transition to unknown state %q", state.Name, target)
+			}
+			edges[to] = true
+		}
+		workflow.transitions[TaskStatus(i)] = edges
+	}
+
+	return workflow, nil
+}
+
+// LoadWorkflow reads a workflow definition from a JSON file
+func LoadWorkflow(path string) (*Workflow, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read workflow file: %w", err)
+	}
+
+	var definition struct {
+		States []WorkflowState `json:"states"`
+	}
+	if err := json.Unmarshal(data, &definition); err != nil {
+		return nil, fmt.Errorf("failed to decode workflow file: %w", err)
+	}
+
+	return NewWorkflow(definition.States)
+}
+
+// Contains reports whether the status refers to a state of this workflow
+func (w *Workflow) Contains(status TaskStatus) bool {
+	return status >= 0 && int(status) < len(w.States)
+}
+
+// Name returns the state name of a status
+func (w *Workflow) Name(status TaskStatus) string {
+	if !w.Contains(status) {
+		return "unknown"
+	}
+	return w.States[status].Name
+}
+
+// Names returns all state names in order
+func (w *Workflow) Names() []string {
+	names := make([]string, len(w.States))
+	for i, state := range w.States {
+		names[i] = state.Name
+	}
+	return names
+}
+
+// Lookup resolves a state name (case-insensitive) to its status
+func (w *Workflow) Lookup(name string) (TaskStatus, bool) {
+	status, ok := w.index[strings.ToLower(strings.TrimSpace(name))]
+	return status, ok
+}
+
+// IsTerminal reports whether work is finished in the given status
+func (w *Workflow) IsTerminal(status TaskStatus) bool {
+	return w.Contains(status) && w.States[status].Terminal
+}
+
+// TerminalStatuses returns every status whose state is terminal
+func (w *Workflow) TerminalStatuses() []TaskStatus {
+	var statuses []TaskStatus
+	for i, state := range w.States {
+		if state.Terminal {
+			statuses = append(statuses, TaskStatus(i))
+		}
+	}
+	return statuses
+}
+
+// CanTransition reports whether a task may move from one status to another
+// Staying in the same status is always allowed.
+func (w *Workflow) CanTransition(from, to TaskStatus) bool {
+	if from == to {
+		return w.Contains(from)
+	}
+	return w.transitions[from][to]
+}
+
+// CheckTransition returns an ErrInvalidTransition error when the move is not allowed
+func (w *Workflow) CheckTransition(from, to TaskStatus) error {
+	if w.CanTransition(from, to) {
+		return nil
+	}
+	return fmt.Errorf("%w from %q to %q", ErrInvalidTransition, w.Name(from), w.Name(to))
+}
+
+// CheckInitial returns an ErrInvalidTransition error unless new tasks may start in status:
+// the initial state or a state the initial state may move to
+func (w *Workflow) CheckInitial(status TaskStatus) error {
+	if w.CanTransition(0, status) {
+		return nil
+	}
+	return fmt.Errorf("%w: new tasks cannot start in %q (initial state %q)", ErrInvalidTransition, w.Name(status), w.Name(0))
+}
+
+// currentWorkflow holds the workflow used by TaskStatus and request validation
+var currentWorkflow atomic.Pointer[Workflow]
+
+func init() {
+	currentWorkflow.Store(DefaultWorkflow())
+}
+
+// CurrentWorkflow returns the workflow in effect
+func CurrentWorkflow() *Workflow {
+	return currentWorkflow.Load()
+}
+
+// SetWorkflow replaces the workflow in effect; call it at startup before serving requests
+func SetWorkflow(workflow *Workflow) {
+	currentWorkflow.Store(workflow)
+}
+
+// ParseTaskStatus resolves a status from its state name or numeric value
+func ParseTaskStatus(value string) (TaskStatus, error) {
+	workflow := CurrentWorkflow()
+
+	if n, err := strconv.Atoi(value); err == nil {
+		status := TaskStatus(n)
+		if !workflow.Contains(status) {
+			return 0, fmt.Errorf("invalid task status: %d", n)
+		}
+		return status, nil
+	}
+
+	if status, ok := workflow.Lookup(value); ok {
+		return status, nil
+	}
+
+	return 0, fmt.Errorf("invalid task status %q (must be one of: %s)", value, strings.Join(workflow.Names(), ", "))
+}
@@ -0,0 +1,190 @@
+package rbac
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// RouteRule lists the permissions a route requires
+type RouteRule struct {
+	Method      string       `json:"method"`      // HTTP method
+	Path        string       `json:"path"`        // Route pattern as registered with the router, e.g. /api/v1/tasks/:id
+	Permissions []Permission `json:"permissions"` // Permissions the caller needs, all of them
+}
+
+// Key returns the "METHOD /path" form under which policies override the rule
+func (r RouteRule) Key() string {
+	return routeKey(r.Method, r.Path)
+}
+
+// Decision explains whether roles may use a route
+type Decision struct {
+	Method   string       `json:"method"`
+	Path     string       `json:"path"`
+	Roles    []string     `json:"roles"`             // Roles evaluated, default roles applied
+	Required []Permission `json:"required"`          // Permissions the route requires
+	Granted  []Permission `json:"granted"`           // Permissions the roles grant
+	Missing  []Permission `json:"missing,omitempty"` // Required permissions no role grants
+	Allowed  bool         `json:"allowed"`
+	Reason   string       `json:"reason"`
+}
+
+// Authorizer decides which roles may use which routes
+type Authorizer struct {
+	policy *Policy              // Roles and the permissions they grant
+	rules  []RouteRule          // Route rules, sorted by path then method
+	index  map[string]RouteRule // Rules by "METHOD /path"
+}
+
+// NewAuthorizer creates an authorizer for the routes of rules (Factory Pattern)
+// The policy's route entries replace the permissions of matching rules; entries naming a route
+// that is not in rules are rejected, as they would otherwise be silently ignored.
+func NewAuthorizer(policy *Policy, rules []RouteRule) (*Authorizer, error) {
+	if policy.granted == nil {
+		if err := policy.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	a := &Authorizer{policy: policy, index: make(map[string]RouteRule, len(rules))}
+	for _, rule := range rules {
+		rule.Method = strings.ToUpper(rule.Method)
+		if _, exists := a.index[rule.Key()]; exists {
+			return nil, fmt.Errorf("route %q is mapped twice", rule.Key())
+		}
+		a.index[rule.Key()] = rule
+	}
+	for route, permissions := range policy.Routes {
+		method, path, ok := strings.Cut(strings.TrimSpace(route), " ")
+		key := routeKey(method, strings.TrimSpace(path))
+		rule, exists := a.index[key]
+		if !ok || !exists {
+			return nil, fmt.Errorf("policy maps unknown route %q", route)
+		}
+		rule.Permissions = permissions
+		a.index[key] = rule
+	}
+
+	for _, rule := range a.index {
+		a.rules = append(a.rules, rule)
+	}
+	sort.Slice(a.rules, func(i, j int) bool {
+		if a.rules[i].Path != a.rules[j].Path {
+			return a.rules[i].Path < a.rules[j].Path
+		}
+		return a.rules[i].Method < a.rules[j].Method
+	})
+	return a, nil
+}
+
+// Policy returns the policy the authorizer evaluates
+func (a *Authorizer) Policy() *Policy {
+	return a.policy
+}
+
+// Rules returns the route rules, sorted by path then method
+func (a *Authorizer) Rules() []RouteRule {
+	return append([]RouteRule(nil), a.rules...)
+}
+
+// Authorize decides whether roles may use the route registered as method and pattern
+// Routes without a rule are denied, so a route added without a mapping fails closed.
+func (a *Authorizer) Authorize(roles []string, method, pattern string) Decision {
+	decision := Decision{
+		Method: strings.ToUpper(method),
+		Path:   pattern,
+		Roles:  a.policy.EffectiveRoles(roles),
+	}
+	decision.Granted = a.policy.Granted(decision.Roles)
+
+	rule, ok := a.index[routeKey(method, pattern)]
+	if !ok {
+		decision.Reason = fmt.Sprintf("no permissions are mapped to %s", routeKey(method, pattern))
+		return decision
+	}
+
+	decision.Required = append([]Permission{}, rule.Permissions...)
+	for _, required := range rule.Permissions {
+		if !grantsAny(decision.Granted, required) {
+			decision.Missing = append(decision.Missing, required)
+		}
+	}
+	decision.Allowed = len(decision.Missing) == 0
+	if decision.Allowed {
+		decision.Reason = "every required permission is granted"
+	} else {
+		decision.Reason = fmt.Sprintf("missing permission %s", joinPermissions(decision.Missing))
+	}
+	return decision
+}
+
+// Explain is Authorize for a concrete request path such as /api/v1/tasks/42
+// The path is matched against the route patterns; one that matches none is explained as unmapped.
+func (a *Authorizer) Explain(roles []string, method, path string) Decision {
+	method = strings.ToUpper(method)
+	pattern := path
+	if _, ok := a.index[routeKey(method, path)]; !ok {
+		// Like the router, prefer the pattern with the most static segments
+		best := -1
+		for _, rule := range a.rules {
+			if rule.Method != method {
+				continue
+			}
+			if static, ok := matchPattern(rule.Path, path); ok && static > best {
+				best = static
+				pattern = rule.Path
+			}
+		}
+	}
+	return a.Authorize(roles, method, pattern)
+}
+
+// grantsAny reports whether one of granted covers required
+func grantsAny(granted []Permission, required Permission) bool {
+	for _, permission := range granted {
+		if Grants(permission, required) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPattern reports whether path matches a route pattern with :param and *wildcard segments,
+// and how many of the pattern's segments are static
+func matchPattern(pattern, path string) (int, bool) {
+	patternParts := strings.Split(strings.Trim(pattern, "/"), "/")
+	pathParts := strings.Split(strings.Trim(path, "/"), "/")
+
+	static := 0
+	for i, part := range patternParts {
+		if strings.HasPrefix(part, "*") {
+			return static, true
+		}
+		if i >= len(pathParts) {
+			return 0, false
+		}
+		if strings.HasPrefix(part, ":") {
+			continue
+		}
+		if part != pathParts[i] {
+			return 0, false
+		}
+		static++
+	}
+	return static, len(patternParts) == len(pathParts)
+}
+
+// joinPermissions lists permissions separated by commas
+func joinPermissions(permissions []Permission) string {
+	names := make([]string, len(permissions))
+	for i, permission := range permissions {
+		names[i] = string(permission)
+	}
+	return strings.Join(names, ", ")
+}
+
+// routeKey returns the "METHOD /path" key of a route
+func routeKey(method, path string) string {
+	return strings.ToUpper(method) + " " + path
+}
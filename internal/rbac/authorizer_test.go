@@ -0,0 +1,110 @@
+package rbac
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testRules maps a small task API
+var testRules = []RouteRule{
+	{Method: "GET", Path: "/tasks", Permissions: []Permission{PermTasksRead}},
+	{Method: "GET", Path: "/tasks/:id", Permissions: []Permission{PermTasksRead}},
+	{Method: "GET", Path: "/tasks/search", Permissions: []Permission{PermTasksRead}},
+	{Method: "DELETE", Path: "/tasks/:id", Permissions: []Permission{PermTasksDelete}},
+	{Method: "POST", Path: "/tasks/bulk", Permissions: []Permission{PermTasksWrite, PermTasksDelete}},
+	{Method: "GET", Path: "/stats", Permissions: []Permission{PermStatsRead}},
+}
+
+func TestAuthorizer_Authorize(t *testing.T) {
+	authorizer, err := NewAuthorizer(DefaultPolicy(), testRules)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		roles   []string
+		method  string
+		path    string
+		allowed bool
+		missing []Permission
+	}{
+		{"viewer reads", []string{"viewer"}, "GET", "/tasks/:id", true, nil},
+		{"viewer cannot delete", []string{"viewer"}, "DELETE", "/tasks/:id", false, []Permission{PermTasksDelete}},
+		{"viewer lacks both bulk permissions", []string{"viewer"}, "POST", "/tasks/bulk", false, []Permission{PermTasksWrite, PermTasksDelete}},
+		{"default roles apply", nil, "DELETE", "/tasks/:id", true, nil},
+		{"editor cannot read stats", []string{"editor"}, "GET", "/stats", false, []Permission{PermStatsRead}},
+		{"admin reads stats", []string{AdminRole}, "GET", "/stats", true, nil},
+		{"unknown roles grant nothing", []string{"intern"}, "GET", "/tasks", false, []Permission{PermTasksRead}},
+		{"methods are case-insensitive", []string{"viewer"}, "get", "/tasks", true, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision := authorizer.Authorize(tt.roles, tt.method, tt.path)
+			assert.Equal(t, tt.allowed, decision.Allowed, decision.Reason)
+			assert.Equal(t, tt.missing, decision.Missing)
+		})
+	}
+
+	t.Run("unmapped routes are denied", func(t *testing.T) {
+		decision := authorizer.Authorize([]string{AdminRole}, "PUT", "/tasks/:id")
+		assert.False(t, decision.Allowed)
+		assert.Equal(t, "no permissions are mapped to PUT /tasks/:id", decision.Reason)
+	})
+
+	t.Run("missing permissions are named", func(t *testing.T) {
+		decision := authorizer.Authorize([]string{"viewer"}, "POST", "/tasks/bulk")
+		assert.Equal(t, "missing permission tasks:write, tasks:delete", decision.Reason)
+		assert.Equal(t, []string{"viewer"}, decision.Roles)
+		assert.Equal(t, []Permission{PermTasksRead}, decision.Granted)
+	})
+}
+
+func TestAuthorizer_Explain(t *testing.T) {
+	authorizer, err := NewAuthorizer(DefaultPolicy(), testRules)
+	require.NoError(t, err)
+
+	decision := authorizer.Explain([]string{"viewer"}, "delete", "/tasks/42")
+	assert.Equal(t, "/tasks/:id", decision.Path)
+	assert.Equal(t, "DELETE", decision.Method)
+	assert.False(t, decision.Allowed)
+
+	assert.Equal(t, "/tasks/search", authorizer.Explain(nil, "GET", "/tasks/search").Path)
+	assert.Equal(t, "/tasks/search", authorizer.Explain(nil, "GET", "/tasks/search/").Path)
+	assert.Equal(t, "/tasks/:id", authorizer.Explain(nil, "GET", "/tasks/7").Path)
+	assert.Equal(t, "/tasks/:id", authorizer.Explain(nil, "GET", "/tasks/:id").Path)
+	assert.Equal(t, "/tasks/42/history", authorizer.Explain(nil, "GET", "/tasks/42/history").Path)
+}
+
+func TestNewAuthorizer(t *testing.T) {
+	t.Run("policy routes override rules", func(t *testing.T) {
+		policy := DefaultPolicy()
+		policy.Routes = map[string][]Permission{"GET /stats": {PermTasksRead}}
+
+		authorizer, err := NewAuthorizer(policy, testRules)
+		require.NoError(t, err)
+		assert.True(t, authorizer.Authorize([]string{"viewer"}, "GET", "/stats").Allowed)
+	})
+
+	t.Run("policy routes must exist", func(t *testing.T) {
+		policy := DefaultPolicy()
+		policy.Routes = map[string][]Permission{"GET /reports": {PermTasksRead}}
+
+		_, err := NewAuthorizer(policy, testRules)
+		assert.ErrorContains(t, err, `unknown route "GET /reports"`)
+	})
+
+	t.Run("routes are mapped once", func(t *testing.T) {
+		_, err := NewAuthorizer(DefaultPolicy(), append(testRules, RouteRule{Method: "get", Path: "/stats"}))
+		assert.ErrorContains(t, err, `route "GET /stats" is mapped twice`)
+	})
+
+	t.Run("rules are sorted", func(t *testing.T) {
+		authorizer, err := NewAuthorizer(DefaultPolicy(), testRules)
+		require.NoError(t, err)
+		rules := authorizer.Rules()
+		require.Len(t, rules, len(testRules))
+		assert.Equal(t, "/stats", rules[0].Path)
+		assert.Equal(t, RouteRule{Method: "DELETE", Path: "/tasks/:id", Permissions: []Permission{PermTasksDelete}}, rules[2])
+	})
+}
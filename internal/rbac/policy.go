@@ -0,0 +1,210 @@
+// Package rbac decides which API routes a caller may use from the roles it holds
+// A policy grants permissions to roles, roles may inherit other roles, and every route
+// requires a set of permissions. Callers without roles get the policy's default roles.
+package rbac
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Permission is the right to use a group of routes, named resource:action
+type Permission string
+
+// Permissions checked by the API routes
+const (
+	PermTasksRead      Permission = "tasks:read"      // List, read, search and subscribe to tasks
+	PermTasksWrite     Permission = "tasks:write"     // Create, change and revert tasks
+	PermTasksDelete    Permission = "tasks:delete"    // Delete tasks
+	PermStatsRead      Permission = "stats:read"      // Read storage statistics
+	PermWebhooksManage Permission = "webhooks:manage" // Register webhooks and inspect their deliveries (administrators only)
+	PermAPIKeysManage  Permission = "apikeys:manage"  // Issue and revoke API keys (administrators only)
+)
+
+// KnownPermissions lists the permissions a policy may grant, besides wildcards
+var KnownPermissions = []Permission{
+	PermTasksRead, PermTasksWrite, PermTasksDelete, PermStatsRead, PermWebhooksManage, PermAPIKeysManage,
+}
+
+// Wildcard grants every permission; "resource:*" grants every permission of a resource
+const Wildcard Permission = "*"
+
+// AdminRole is the role held by callers with the admin claim or an admin-scoped API key
+const AdminRole = "admin"
+
+// Role is a named set of permissions
+type Role struct {
+	Permissions []Permission `json:"permissions"`        // Permissions granted by the role
+	Inherits    []string     `json:"inherits,omitempty"` // Roles whose permissions are granted too
+}
+
+// Policy defines the roles and the permissions they grant
+type Policy struct {
+	Roles        map[string]*Role        `json:"roles"`            // Roles by name
+	DefaultRoles []string                `json:"default_roles"`    // Roles of callers that hold none
+	Routes       map[string][]Permission `json:"routes,omitempty"` // Overrides of route permissions, by "METHOD /path"
+
+	granted map[string][]Permission // Permissions of each role, inherited ones included
+}
+
+// DefaultPolicy returns the built-in policy
+// Authenticated callers may work on tasks, viewers may only read them and admins may do everything.
+func DefaultPolicy() *Policy {
+	policy := &Policy{
+		Roles: map[string]*Role{
+			"viewer":  {Permissions: []Permission{PermTasksRead}},
+			"editor":  {Permissions: []Permission{PermTasksWrite, PermTasksDelete}, Inherits: []string{"viewer"}},
+			AdminRole: {Permissions: []Permission{Wildcard}},
+		},
+		DefaultRoles: []string{"editor"},
+	}
+	if err := policy.Validate(); err != nil {
+		panic(fmt.Sprintf("invalid default policy: %v", err))
+	}
+	return policy
+}
+
+// LoadPolicy reads a policy from a JSON file
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy file: %w", err)
+	}
+
+	var policy Policy
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("failed to decode policy file: %w", err)
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid policy file %s: %w", path, err)
+	}
+	return &policy, nil
+}
+
+// Validate checks the policy and resolves role inheritance
+// Every permission must be known or a wildcard, inherited and default roles must exist, and
+// inheritance must not loop.
+func (p *Policy) Validate() error {
+	if len(p.Roles) == 0 {
+		return fmt.Errorf("policy defines no roles")
+	}
+	for name, role := range p.Roles {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("role names cannot be empty")
+		}
+		if role == nil {
+			return fmt.Errorf("role %q has no definition", name)
+		}
+		for _, permission := range role.Permissions {
+			if err := validatePermission(permission); err != nil {
+				return fmt.Errorf("role %q: %w", name, err)
+			}
+		}
+		for _, parent := range role.Inherits {
+			if _, ok := p.Roles[parent]; !ok {
+				return fmt.Errorf("role %q inherits unknown role %q", name, parent)
+			}
+		}
+	}
+	for _, name := range p.DefaultRoles {
+		if _, ok := p.Roles[name]; !ok {
+			return fmt.Errorf("unknown default role %q", name)
+		}
+	}
+	for route, permissions := range p.Routes {
+		for _, permission := range permissions {
+			if err := validatePermission(permission); err != nil {
+				return fmt.Errorf("route %q: %w", route, err)
+			}
+		}
+	}
+
+	granted := make(map[string][]Permission, len(p.Roles))
+	for name := range p.Roles {
+		permissions, err := p.resolve(name, nil)
+		if err != nil {
+			return err
+		}
+		granted[name] = permissions
+	}
+	p.granted = granted
+	return nil
+}
+
+// resolve collects the permissions of a role and the roles it inherits
+// path holds the roles being resolved, to detect inheritance loops.
+func (p *Policy) resolve(name string, path []string) ([]Permission, error) {
+	for _, visiting := range path {
+		if visiting == name {
+			return nil, fmt.Errorf("role inheritance loops: %s -> %s", strings.Join(path, " -> "), name)
+		}
+	}
+	path = append(path, name)
+
+	role := p.Roles[name]
+	permissions := append([]Permission(nil), role.Permissions...)
+	for _, parent := range role.Inherits {
+		inherited, err := p.resolve(parent, path)
+		if err != nil {
+			return nil, err
+		}
+		permissions = append(permissions, inherited...)
+	}
+	return uniquePermissions(permissions), nil
+}
+
+// Granted returns the permissions held by roles, sorted; unknown roles grant nothing
+func (p *Policy) Granted(roles []string) []Permission {
+	var permissions []Permission
+	for _, role := range roles {
+		permissions = append(permissions, p.granted[role]...)
+	}
+	return uniquePermissions(permissions)
+}
+
+// EffectiveRoles returns roles, or the default roles when there are none
+func (p *Policy) EffectiveRoles(roles []string) []string {
+	if len(roles) == 0 {
+		return append([]string(nil), p.DefaultRoles...)
+	}
+	return append([]string(nil), roles...)
+}
+
+// Grants reports whether granted covers required, directly or through a wildcard
+func Grants(granted, required Permission) bool {
+	if granted == Wildcard || granted == required {
+		return true
+	}
+	resource, ok := strings.CutSuffix(string(granted), ":*")
+	return ok && strings.HasPrefix(string(required), resource+":")
+}
+
+// validatePermission accepts known permissions, "*" and "resource:*" for a known resource
+func validatePermission(permission Permission) error {
+	if permission == Wildcard {
+		return nil
+	}
+	for _, known := range KnownPermissions {
+		if Grants(permission, known) {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown permission %q", permission)
+}
+
+// uniquePermissions returns the permissions sorted and without repeats
+func uniquePermissions(permissions []Permission) []Permission {
+	seen := make(map[Permission]bool, len(permissions))
+	unique := make([]Permission, 0, len(permissions))
+	for _, permission := range permissions {
+		if !seen[permission] {
+			seen[permission] = true
+			unique = append(unique, permission)
+		}
+	}
+	sort.Slice(unique, func(i, j int) bool { return unique[i] < unique[j] })
+	return unique
+}
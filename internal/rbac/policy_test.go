@@ -0,0 +1,95 @@
+package rbac
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writePolicy writes a policy file into a temporary directory
+func writePolicy(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "policy.json")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func TestDefaultPolicy(t *testing.T) {
+	policy := DefaultPolicy()
+
+	assert.Equal(t, []Permission{PermTasksDelete, PermTasksRead, PermTasksWrite}, policy.Granted([]string{"editor"}))
+	assert.Equal(t, []Permission{PermTasksRead}, policy.Granted([]string{"viewer"}))
+	assert.Equal(t, []Permission{Wildcard}, policy.Granted([]string{AdminRole}))
+	assert.Empty(t, policy.Granted([]string{"unknown"}))
+	assert.Equal(t, []string{"editor"}, policy.EffectiveRoles(nil))
+	assert.Equal(t, []string{"viewer"}, policy.EffectiveRoles([]string{"viewer"}))
+}
+
+func TestLoadPolicy(t *testing.T) {
+	path := writePolicy(t, `{
+		"default_roles": ["viewer"],
+		"roles": {
+			"viewer": {"permissions": ["tasks:read"]},
+			"auditor": {"permissions": ["stats:read"], "inherits": ["viewer"]},
+			"operator": {"permissions": ["tasks:*", "webhooks:manage"], "inherits": ["auditor"]}
+		},
+		"routes": {"DELETE /api/v1/tasks/:id": ["tasks:delete", "stats:read"]}
+	}`)
+
+	policy, err := LoadPolicy(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"viewer"}, policy.DefaultRoles)
+	assert.Equal(t, []Permission{PermStatsRead, PermTasksRead}, policy.Granted([]string{"auditor"}))
+	assert.Equal(t,
+		[]Permission{PermStatsRead, "tasks:*", PermTasksRead, PermWebhooksManage},
+		policy.Granted([]string{"operator"}))
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := LoadPolicy(filepath.Join(t.TempDir(), "missing.json"))
+		assert.ErrorContains(t, err, "failed to read policy file")
+	})
+
+	t.Run("malformed file", func(t *testing.T) {
+		_, err := LoadPolicy(writePolicy(t, `{"roles":`))
+		assert.ErrorContains(t, err, "failed to decode policy file")
+	})
+}
+
+func TestPolicy_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy string
+		err    string
+	}{
+		{"no roles", `{"roles": {}}`, "no roles"},
+		{"unknown permission", `{"roles": {"viewer": {"permissions": ["tasks:read", "tasks:archive"]}}}`, `unknown permission "tasks:archive"`},
+		{"unknown resource wildcard", `{"roles": {"viewer": {"permissions": ["reports:*"]}}}`, `unknown permission "reports:*"`},
+		{"unknown parent", `{"roles": {"viewer": {"permissions": [], "inherits": ["reader"]}}}`, `inherits unknown role "reader"`},
+		{"unknown default role", `{"default_roles": ["reader"], "roles": {"viewer": {"permissions": []}}}`, `unknown default role "reader"`},
+		{"inheritance loop", `{"roles": {
+			"a": {"permissions": [], "inherits": ["b"]},
+			"b": {"permissions": [], "inherits": ["c"]},
+			"c": {"permissions": [], "inherits": ["a"]}
+		}}`, "role inheritance loops"},
+		{"unknown route permission", `{"roles": {"viewer": {"permissions": []}}, "routes": {"GET /api/v1/tasks": ["tasks:list"]}}`, `unknown permission "tasks:list"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadPolicy(writePolicy(t, tt.policy))
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestGrants(t *testing.T) {
+	assert.True(t, Grants(Wildcard, PermAPIKeysManage))
+	assert.True(t, Grants(PermTasksRead, PermTasksRead))
+	assert.True(t, Grants("tasks:*", PermTasksDelete))
+	assert.False(t, Grants("tasks:*", PermStatsRead))
+	assert.False(t, Grants("task:*", PermTasksRead))
+	assert.False(t, Grants(PermTasksWrite, PermTasksRead))
+}
@@ -0,0 +1,326 @@
+// Package resp is a minimal client of the Redis serialization protocol (RESP2)
+// It talks to Redis and compatible servers such as Valkey or KeyDB, pooling connections that
+// authenticate and select their database when dialed.
+package resp
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Default client settings
+const (
+	DefaultAddr        = "localhost:6379"
+	DefaultDialTimeout = 5 * time.Second // Time allowed to connect
+	DefaultIOTimeout   = 5 * time.Second // Time allowed for each command
+	DefaultPoolSize    = 8               // Idle connections kept for reuse
+)
+
+// ErrClosed is returned by commands sent through a closed client
+var ErrClosed = errors.New("resp: client is closed")
+
+// Error is an error reply of the server, such as "ERR unknown command"
+type Error string
+
+// Error returns the reply as the server sent it
+func (e Error) Error() string {
+	return string(e)
+}
+
+// Prefix returns the error kind: the reply's first word, such as ERR or NOSCRIPT
+func (e Error) Prefix() string {
+	prefix, _, _ := strings.Cut(string(e), " ")
+	return prefix
+}
+
+// Options defines how the client reaches the server
+type Options struct {
+	Addr        string        // Server host:port (empty = DefaultAddr)
+	Username    string        // ACL user (empty = the default user)
+	Password    string        // Password sent with AUTH (empty = no AUTH)
+	DB          int           // Database selected on every connection
+	DialTimeout time.Duration // Time allowed to connect (0 = DefaultDialTimeout)
+	IOTimeout   time.Duration // Time allowed for each command (0 = DefaultIOTimeout)
+	PoolSize    int           // Idle connections kept for reuse (0 = DefaultPoolSize)
+}
+
+// ParseURL parses a redis://[[user]:password@]host[:port][/db] URL
+func ParseURL(rawURL string) (Options, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return Options{}, fmt.Errorf("invalid redis URL: %w", err)
+	}
+	if u.Scheme != "redis" {
+		return Options{}, fmt.Errorf("invalid redis URL: unsupported scheme %q", u.Scheme)
+	}
+
+	options := Options{Addr: u.Host}
+	if u.Host == "" {
+		options.Addr = DefaultAddr
+	} else if u.Port() == "" {
+		options.Addr = net.JoinHostPort(u.Hostname(), "6379")
+	}
+	if u.User != nil {
+		options.Username = u.User.Username()
+		options.Password, _ = u.User.Password()
+	}
+	if db := strings.Trim(u.Path, "/"); db != "" {
+		options.DB, err = strconv.Atoi(db)
+		if err != nil || options.DB < 0 {
+			return Options{}, fmt.Errorf("invalid redis URL: invalid database %q", db)
+		}
+	}
+	return options, nil
+}
+
+// Client sends commands over a pool of connections; it is safe for concurrent use
+type Client struct {
+	options Options
+	idle    chan *conn // Connections waiting for a command
+	closed  bool       // Whether Close has been called
+	mutex   sync.Mutex // Protects closed
+}
+
+// NewClient creates a client for the server described by options (Factory Pattern)
+// Connections are dialed when commands need them.
+func NewClient(options Options) *Client {
+	if options.Addr == "" {
+		options.Addr = DefaultAddr
+	}
+	if options.DialTimeout <= 0 {
+		options.DialTimeout = DefaultDialTimeout
+	}
+	if options.IOTimeout <= 0 {
+		options.IOTimeout = DefaultIOTimeout
+	}
+	if options.PoolSize <= 0 {
+		options.PoolSize = DefaultPoolSize
+	}
+	return &Client{options: options, idle: make(chan *conn, options.PoolSize)}
+}
+
+// Do sends a command and returns its reply
+// Replies are strings (simple and bulk strings), int64s, nil (null bulk strings and arrays) or
+// []interface{} of those; error replies are returned as Error.
+func (c *Client) Do(ctx context.Context, args ...string) (interface{}, error) {
+	cn, err := c.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := cn.do(ctx, c.options.IOTimeout, args)
+	var replyErr Error
+	if err != nil && !errors.As(err, &replyErr) {
+		// The connection may be out of step with the server
+		cn.Close()
+		return nil, err
+	}
+	c.put(cn)
+	return reply, err
+}
+
+// Ping checks the server answers
+func (c *Client) Ping(ctx context.Context) error {
+	_, err := c.Do(ctx, "PING")
+	return err
+}
+
+// Close closes the idle connections; later commands fail with ErrClosed
+func (c *Client) Close() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	close(c.idle)
+	for cn := range c.idle {
+		cn.Close()
+	}
+	return nil
+}
+
+// get returns an idle connection, or dials a new one
+func (c *Client) get(ctx context.Context) (*conn, error) {
+	c.mutex.Lock()
+	closed := c.closed
+	c.mutex.Unlock()
+	if closed {
+		return nil, ErrClosed
+	}
+
+	select {
+	case cn, ok := <-c.idle:
+		if ok {
+			return cn, nil
+		}
+		return nil, ErrClosed
+	default:
+		return c.dial(ctx)
+	}
+}
+
+// put returns a connection to the pool, closing it when the pool is full or closed
+func (c *Client) put(cn *conn) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.closed {
+		cn.Close()
+		return
+	}
+	select {
+	case c.idle <- cn:
+	default:
+		cn.Close()
+	}
+}
+
+// dial connects to the server, authenticates and selects the database
+func (c *Client) dial(ctx context.Context) (*conn, error) {
+	dialer := net.Dialer{Timeout: c.options.DialTimeout}
+	netConn, err := dialer.DialContext(ctx, "tcp", c.options.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", c.options.Addr, err)
+	}
+	cn := &conn{Conn: netConn, reader: bufio.NewReader(netConn), writer: bufio.NewWriter(netConn)}
+
+	var setup [][]string
+	if c.options.Password != "" {
+		if c.options.Username != "" {
+			setup = append(setup, []string{"AUTH", c.options.Username, c.options.Password})
+		} else {
+			setup = append(setup, []string{"AUTH", c.options.Password})
+		}
+	}
+	if c.options.DB != 0 {
+		setup = append(setup, []string{"SELECT", strconv.Itoa(c.options.DB)})
+	}
+	for _, args := range setup {
+		if _, err := cn.do(ctx, c.options.IOTimeout, args); err != nil {
+			cn.Close()
+			return nil, fmt.Errorf("failed to set up connection to %s: %w", c.options.Addr, err)
+		}
+	}
+	return cn, nil
+}
+
+// conn is a connection to the server
+type conn struct {
+	net.Conn
+	reader *bufio.Reader
+	writer *bufio.Writer
+}
+
+// do writes a command and reads its reply, within timeout or the context's deadline
+func (cn *conn) do(ctx context.Context, timeout time.Duration, args []string) (interface{}, error) {
+	deadline := time.Now().Add(timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	if err := cn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	if err := WriteCommand(cn.writer, args...); err != nil {
+		return nil, err
+	}
+	if err := cn.writer.Flush(); err != nil {
+		return nil, err
+	}
+	return ReadReply(cn.reader)
+}
+
+// WriteCommand writes a command as an array of bulk strings
+func WriteCommand(w io.Writer, args ...string) error {
+	var b strings.Builder
+	b.WriteString("*" + strconv.Itoa(len(args)) + "\r\n")
+	for _, arg := range args {
+		b.WriteString("$" + strconv.Itoa(len(arg)) + "\r\n" + arg + "\r\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// ReadReply reads one reply; error replies are returned as Error
+func ReadReply(r *bufio.Reader) (interface{}, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if line == "" {
+		return nil, fmt.Errorf("resp: empty reply line")
+	}
+
+	switch kind, rest := line[0], line[1:]; kind {
+	case '+':
+		return rest, nil
+	case '-':
+		return nil, Error(rest)
+	case ':':
+		n, err := strconv.ParseInt(rest, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("resp: invalid integer %q", rest)
+		}
+		return n, nil
+	case '$':
+		size, err := strconv.Atoi(rest)
+		if err != nil || size < -1 {
+			return nil, fmt.Errorf("resp: invalid bulk string length %q", rest)
+		}
+		if size == -1 {
+			return nil, nil
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, err
+		}
+		return string(data[:size]), nil
+	case '*':
+		count, err := strconv.Atoi(rest)
+		if err != nil || count < -1 {
+			return nil, fmt.Errorf("resp: invalid array length %q", rest)
+		}
+		if count == -1 {
+			return nil, nil
+		}
+		items := make([]interface{}, count)
+		for i := range items {
+			// Errors nested in arrays are values, such as failed commands of a transaction
+			items[i], err = ReadReply(r)
+			var replyErr Error
+			if err != nil && !errors.As(err, &replyErr) {
+				return nil, err
+			}
+			if err != nil {
+				items[i] = replyErr
+			}
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("resp: unknown reply type %q", kind)
+	}
+}
+
+// readLine reads a line without its CRLF ending
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	line, ok := strings.CutSuffix(line, "\r\n")
+	if !ok {
+		return "", fmt.Errorf("resp: line not ended by CRLF")
+	}
+	return line, nil
+}
@@ -0,0 +1,455 @@
+// Package resptest provides an in-process RESP server for tests
+// The server keeps string keys in memory and understands the commands the API sends. It cannot
+// run Lua: tests register a Go function standing in for each script they use.
+package resptest
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"task-api/internal/resp"
+	"time"
+)
+
+// ScriptFunc stands in for a Lua script; it runs while the server runs nothing else, like scripts do
+// It returns a reply as resp.Client.Do returns them, or an error sent as an error reply.
+type ScriptFunc func(tx *Tx, keys, args []string) (interface{}, error)
+
+// status is a simple string reply, such as OK
+type status string
+
+// entry is a stored key
+type entry struct {
+	value   string
+	expires time.Time // Zero when the key does not expire
+}
+
+// Server is a RESP server listening on a local port
+type Server struct {
+	listener net.Listener
+	password string                // Password clients must send with AUTH (empty = none)
+	data     map[string]entry      // Keys of the selected database; every database shares them
+	scripts  map[string]ScriptFunc // Script stand-ins by the hex SHA-1 of their source
+	cached   map[string]bool       // Scripts loaded with EVAL or SCRIPT LOAD, by hash
+	calls    map[string]int        // Commands received, by upper-case name
+	conns    map[net.Conn]struct{} // Open client connections
+	closed   bool                  // Whether Close has been called
+	mutex    sync.Mutex            // Protects every field above
+	wg       sync.WaitGroup        // Running connection goroutines
+}
+
+// NewServer starts a server on a random local port (Factory Pattern)
+// The server requires AUTH with password unless it is empty.
+func NewServer(password string) *Server {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic(fmt.Sprintf("resptest: failed to listen: %v", err))
+	}
+
+	s := &Server{
+		listener: listener,
+		password: password,
+		data:     make(map[string]entry),
+		scripts:  make(map[string]ScriptFunc),
+		cached:   make(map[string]bool),
+		calls:    make(map[string]int),
+		conns:    make(map[net.Conn]struct{}),
+	}
+	s.wg.Add(1)
+	go s.serve()
+	return s
+}
+
+// Addr returns the host:port the server listens on
+func (s *Server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// HandleScript registers fn to run in place of the Lua script source
+func (s *Server) HandleScript(source string, fn ScriptFunc) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.scripts[hash(source)] = fn
+}
+
+// Calls returns how many times the server received command
+func (s *Server) Calls(command string) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.calls[strings.ToUpper(command)]
+}
+
+// Get returns the value of key
+func (s *Server) Get(key string) (string, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return (&Tx{s: s}).Get(key)
+}
+
+// Keys returns the stored keys, sorted
+func (s *Server) Keys() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.keys("*")
+}
+
+// Close stops the server and closes its connections
+func (s *Server) Close() {
+	s.listener.Close()
+	s.mutex.Lock()
+	s.closed = true
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mutex.Unlock()
+	s.wg.Wait()
+}
+
+// serve accepts connections until the listener is closed
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.mutex.Lock()
+		if s.closed {
+			s.mutex.Unlock()
+			conn.Close()
+			return
+		}
+		s.conns[conn] = struct{}{}
+		s.mutex.Unlock()
+
+		s.wg.Add(1)
+		go s.handle(conn)
+	}
+}
+
+// handle answers the commands of a connection until it is closed
+func (s *Server) handle(conn net.Conn) {
+	defer s.wg.Done()
+	defer func() {
+		s.mutex.Lock()
+		delete(s.conns, conn)
+		s.mutex.Unlock()
+		conn.Close()
+	}()
+
+	reader := bufio.NewReader(conn)
+	writer := bufio.NewWriter(conn)
+	authenticated := s.password == ""
+	for {
+		args, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+
+		var reply interface{}
+		name := strings.ToUpper(args[0])
+		switch {
+		case name == "AUTH":
+			reply, err = s.auth(args[1:])
+			authenticated = authenticated || err == nil
+		case !authenticated:
+			err = errors.New("NOAUTH Authentication required.")
+		default:
+			reply, err = s.execute(name, args[1:])
+		}
+		if err != nil {
+			reply = resp.Error(err.Error())
+		}
+
+		if writeReply(writer, reply) != nil || writer.Flush() != nil {
+			return
+		}
+	}
+}
+
+// auth checks the password of an AUTH command, with or without a user name
+func (s *Server) auth(args []string) (interface{}, error) {
+	if len(args) == 0 || len(args) > 2 {
+		return nil, errors.New("ERR wrong number of arguments for 'auth' command")
+	}
+	if s.password == "" || args[len(args)-1] != s.password {
+		return nil, errors.New("WRONGPASS invalid username-password pair or user is disabled.")
+	}
+	return status("OK"), nil
+}
+
+// execute runs a command other than AUTH
+func (s *Server) execute(name string, args []string) (interface{}, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.calls[name]++
+	tx := &Tx{s: s}
+	switch name {
+	case "PING":
+		return status("PONG"), nil
+	case "SELECT":
+		if len(args) != 1 {
+			return nil, wrongArguments(name)
+		}
+		if _, err := strconv.Atoi(args[0]); err != nil {
+			return nil, errors.New("ERR value is not an integer or out of range")
+		}
+		return status("OK"), nil
+	case "GET":
+		if len(args) != 1 {
+			return nil, wrongArguments(name)
+		}
+		if value, ok := tx.Get(args[0]); ok {
+			return value, nil
+		}
+		return nil, nil
+	case "SET":
+		return s.set(tx, args)
+	case "DEL":
+		if len(args) == 0 {
+			return nil, wrongArguments(name)
+		}
+		deleted := int64(0)
+		for _, key := range args {
+			if _, ok := tx.Get(key); ok {
+				delete(s.data, key)
+				deleted++
+			}
+		}
+		return deleted, nil
+	case "SCAN":
+		return s.scan(args)
+	case "EVAL", "EVALSHA":
+		return s.eval(tx, name, args)
+	case "SCRIPT":
+		if len(args) != 2 || strings.ToUpper(args[0]) != "LOAD" {
+			return nil, errors.New("ERR only SCRIPT LOAD is supported")
+		}
+		return s.load(args[1])
+	default:
+		return nil, fmt.Errorf("ERR unknown command '%s'", strings.ToLower(name))
+	}
+}
+
+// set runs SET key value [PX milliseconds | EX seconds]
+func (s *Server) set(tx *Tx, args []string) (interface{}, error) {
+	if len(args) != 2 && len(args) != 4 {
+		return nil, wrongArguments("SET")
+	}
+
+	var ttl time.Duration
+	if len(args) == 4 {
+		n, err := strconv.ParseInt(args[3], 10, 64)
+		if err != nil || n <= 0 {
+			return nil, errors.New("ERR invalid expire time in 'set' command")
+		}
+		switch strings.ToUpper(args[2]) {
+		case "PX":
+			ttl = time.Duration(n) * time.Millisecond
+		case "EX":
+			ttl = time.Duration(n) * time.Second
+		default:
+			return nil, errors.New("ERR syntax error")
+		}
+	}
+	tx.Set(args[0], args[1], ttl)
+	return status("OK"), nil
+}
+
+// scan runs SCAN cursor [MATCH pattern] [COUNT count], returning every match at once
+func (s *Server) scan(args []string) (interface{}, error) {
+	if len(args) == 0 || len(args)%2 != 1 {
+		return nil, wrongArguments("SCAN")
+	}
+	pattern := "*"
+	for i := 1; i < len(args); i += 2 {
+		if strings.ToUpper(args[i]) == "MATCH" {
+			pattern = args[i+1]
+		}
+	}
+
+	keys := s.keys(pattern)
+	items := make([]interface{}, len(keys))
+	for i, key := range keys {
+		items[i] = key
+	}
+	return []interface{}{"0", items}, nil
+}
+
+// eval runs EVAL source or EVALSHA hash, followed by the key count, keys and arguments
+func (s *Server) eval(tx *Tx, name string, args []string) (interface{}, error) {
+	if len(args) < 2 {
+		return nil, wrongArguments(name)
+	}
+	count, err := strconv.Atoi(args[1])
+	if err != nil || count < 0 || count > len(args)-2 {
+		return nil, errors.New("ERR Number of keys can't be greater than number of args")
+	}
+
+	scriptHash := strings.ToLower(args[0])
+	if name == "EVAL" {
+		if _, err := s.load(args[0]); err != nil {
+			return nil, err
+		}
+		scriptHash = hash(args[0])
+	} else if !s.cached[scriptHash] {
+		return nil, errors.New("NOSCRIPT No matching script. Please use EVAL.")
+	}
+	return s.scripts[scriptHash](tx, args[2:2+count], args[2+count:])
+}
+
+// load caches a script the server has a stand-in for
+func (s *Server) load(source string) (interface{}, error) {
+	scriptHash := hash(source)
+	if s.scripts[scriptHash] == nil {
+		return nil, errors.New("ERR resptest has no stand-in for this script")
+	}
+	s.cached[scriptHash] = true
+	return scriptHash, nil
+}
+
+// keys returns the live keys matching a glob pattern, sorted
+func (s *Server) keys(pattern string) []string {
+	tx := &Tx{s: s}
+	var keys []string
+	for key := range s.data {
+		if _, ok := tx.Get(key); !ok {
+			continue
+		}
+		if matchGlob(pattern, key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Tx gives script stand-ins access to the keys
+type Tx struct {
+	s *Server
+}
+
+// Get returns the value of key, unless it is missing or expired
+func (tx *Tx) Get(key string) (string, bool) {
+	e, ok := tx.s.data[key]
+	if !ok {
+		return "", false
+	}
+	if !e.expires.IsZero() && !time.Now().Before(e.expires) {
+		delete(tx.s.data, key)
+		return "", false
+	}
+	return e.value, true
+}
+
+// Set stores the value of key, to expire after ttl (0 = never)
+func (tx *Tx) Set(key, value string, ttl time.Duration) {
+	e := entry{value: value}
+	if ttl > 0 {
+		e.expires = time.Now().Add(ttl)
+	}
+	tx.s.data[key] = e
+}
+
+// readCommand reads a command sent as an array of bulk strings
+func readCommand(r *bufio.Reader) ([]string, error) {
+	reply, err := resp.ReadReply(r)
+	if err != nil {
+		return nil, err
+	}
+	items, ok := reply.([]interface{})
+	if !ok || len(items) == 0 {
+		return nil, fmt.Errorf("resptest: commands must be non-empty arrays")
+	}
+
+	args := make([]string, len(items))
+	for i, item := range items {
+		if args[i], ok = item.(string); !ok {
+			return nil, fmt.Errorf("resptest: command arguments must be strings")
+		}
+	}
+	return args, nil
+}
+
+// writeReply writes a reply of a command or script stand-in
+func writeReply(w io.Writer, reply interface{}) error {
+	var err error
+	switch v := reply.(type) {
+	case nil:
+		_, err = io.WriteString(w, "$-1\r\n")
+	case status:
+		_, err = io.WriteString(w, "+"+string(v)+"\r\n")
+	case resp.Error:
+		_, err = io.WriteString(w, "-"+string(v)+"\r\n")
+	case string:
+		_, err = io.WriteString(w, "$"+strconv.Itoa(len(v))+"\r\n"+v+"\r\n")
+	case int64:
+		_, err = io.WriteString(w, ":"+strconv.FormatInt(v, 10)+"\r\n")
+	case int:
+		_, err = io.WriteString(w, ":"+strconv.Itoa(v)+"\r\n")
+	case bool:
+		// Lua true is the integer 1 and false a null reply
+		if v {
+			_, err = io.WriteString(w, ":1\r\n")
+		} else {
+			_, err = io.WriteString(w, "$-1\r\n")
+		}
+	case []interface{}:
+		if _, err = io.WriteString(w, "*"+strconv.Itoa(len(v))+"\r\n"); err != nil {
+			return err
+		}
+		for _, item := range v {
+			if err = writeReply(w, item); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("resptest: cannot encode %T", reply)
+	}
+	return err
+}
+
+// matchGlob reports whether s matches a Redis glob pattern
+// Only * (any characters) and \ (escaping the next character) are supported.
+func matchGlob(pattern, s string) bool {
+	for pattern != "" {
+		switch pattern[0] {
+		case '*':
+			for i := len(s); i >= 0; i-- {
+				if matchGlob(pattern[1:], s[i:]) {
+					return true
+				}
+			}
+			return false
+		case '\\':
+			if len(pattern) > 1 {
+				pattern = pattern[1:]
+			}
+		}
+		if s == "" || s[0] != pattern[0] {
+			return false
+		}
+		pattern, s = pattern[1:], s[1:]
+	}
+	return s == ""
+}
+
+// wrongArguments returns the error reply of a command called with the wrong number of arguments
+func wrongArguments(command string) error {
+	return fmt.Errorf("ERR wrong number of arguments for '%s' command", strings.ToLower(command))
+}
+
+// hash returns the hex SHA-1 naming a script
+func hash(source string) string {
+	sum := sha1.Sum([]byte(source))
+	return hex.EncodeToString(sum[:])
+}
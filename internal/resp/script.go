@@ -0,0 +1,49 @@
+package resp
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"strconv"
+)
+
+// Script is a Lua script the server runs atomically
+// Scripts are sent by hash with EVALSHA, and in full with EVAL when the server does not know them yet.
+type Script struct {
+	source string
+	hash   string // Hex SHA-1 of the source, the script's name on the server
+}
+
+// NewScript creates a script from its Lua source (Factory Pattern)
+func NewScript(source string) *Script {
+	sum := sha1.Sum([]byte(source))
+	return &Script{source: source, hash: hex.EncodeToString(sum[:])}
+}
+
+// Source returns the script's Lua source
+func (s *Script) Source() string {
+	return s.source
+}
+
+// Hash returns the hex SHA-1 of the script's source
+func (s *Script) Hash() string {
+	return s.hash
+}
+
+// Run runs the script with keys and args through client
+func (s *Script) Run(ctx context.Context, client *Client, keys []string, args ...string) (interface{}, error) {
+	command := make([]string, 0, 3+len(keys)+len(args))
+	command = append(command, "EVALSHA", s.hash, strconv.Itoa(len(keys)))
+	command = append(command, keys...)
+	command = append(command, args...)
+
+	reply, err := client.Do(ctx, command...)
+	var replyErr Error
+	if errors.As(err, &replyErr) && replyErr.Prefix() == "NOSCRIPT" {
+		// EVAL also caches the script for the next EVALSHA
+		command[0], command[1] = "EVAL", s.source
+		return client.Do(ctx, command...)
+	}
+	return reply, err
+}
@@ -1,9 +1,16 @@
 package routes
 
 import (
+	"strings"
+	"task-api/internal/apikeys"
+	"task-api/internal/auth"
+	"task-api/internal/events"
 	"task-api/internal/handlers"
 	"task-api/internal/interfaces"
 	"task-api/internal/middleware"
+	"task-api/internal/models"
+	"task-api/internal/rbac"
+	"task-api/internal/webhooks"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -18,10 +25,19 @@ type RouterConfig struct {
 	EnableSecurity  bool                       `json:"enable_security"`   // Enable security headers
 	EnableRequestID bool                       `json:"enable_request_id"` // Enable request ID generation
 	EnableRateLimit bool                       `json:"enable_rate_limit"` // Enable rate limiting
-	TrustedProxies  []string                   `json:"trusted_proxies"`   // Trusted proxy IPs
+	TrustedProxies  []string                   `json:"trusted_proxies"`   // Trusted proxy CIDRs or IPs
+	ProxyHeader     string                     `json:"proxy_header"`      // Header trusted proxies report the client IP in (default X-Forwarded-For)
 	AllowedOrigins  []string                   `json:"allowed_origins"`   // CORS allowed origins
 	DevelopmentMode bool                       `json:"development_mode"`  // Development mode flag
 	RateLimitConfig middleware.RateLimitConfig `json:"rate_limit_config"` // Rate limiting configuration
+	RequestTimeout  time.Duration              `json:"request_timeout"`   // Deadline applied to each request context (0 = none)
+	APIVersion      int                        `json:"api_version"`       // Default API version (0 = version 1)
+	EventBus        *events.Bus                `json:"-"`                 // Change feed bus (nil = a default bus is created)
+	EventHeartbeat  time.Duration              `json:"event_heartbeat"`   // Time between keep-alive comments on event streams (0 = default)
+	Webhooks        *webhooks.Dispatcher       `json:"-"`                 // Webhook registry and delivery (nil = webhook endpoints answer 501)
+	Authenticator   *auth.Verifier             `json:"-"`                 // Bearer token verifier (nil = authentication disabled)
+	APIKeys         *apikeys.Store             `json:"-"`                 // Issued API keys (nil = X-API-Key is not checked and key endpoints answer 501)
+	Authorizer      *rbac.Authorizer           `json:"-"`                 // Role-based route permissions (nil = the built-in policy)
 }
 
 // SetupRouterWithConfig configures and returns a Gin router with custom configuration
@@ -36,15 +52,32 @@ func SetupRouterWithConfig(storage interfaces.TaskStorage, config RouterConfig)
 	// Create router
 	router := gin.New()
 
-	// Set trusted proxies
-	if len(config.TrustedProxies) > 0 {
-		// Set trusted proxies and ignore error for router configuration
-		_ = router.SetTrustedProxies(config.TrustedProxies)
-	}
+	// Set trusted proxies; none are trusted when the list is empty
+	// Ignore error for router configuration
+	_ = router.SetTrustedProxies(config.TrustedProxies)
 
 	// Recovery middleware (always enabled)
 	router.Use(gin.Recovery())
 
+	// Client IP middleware (always enabled): forwarding headers count only from trusted proxies
+	// An invalid list leaves a nil resolver trusting no proxy; the application validates it at startup.
+	clientIP, _ := middleware.NewClientIPResolver(middleware.ClientIPConfig{
+		TrustedProxies: config.TrustedProxies,
+		Header:         config.ProxyHeader,
+	})
+	router.Use(middleware.ClientIP(clientIP))
+
+	// Request deadline middleware
+	if config.RequestTimeout > 0 {
+		router.Use(middleware.RequestTimeout(config.RequestTimeout, longLivedRoutes...))
+	}
+
+	// Revision author middleware (always enabled)
+	router.Use(middleware.Actor())
+
+	// API version negotiation middleware (always enabled)
+	router.Use(middleware.APIVersion(config.APIVersion))
+
 	// Request ID middleware
 	if config.EnableRequestID {
 		router.Use(middleware.RequestID())
@@ -66,6 +99,11 @@ func SetupRouterWithConfig(storage interfaces.TaskStorage, config RouterConfig)
 		}
 	}
 
+	// API key middleware (before rate limiting so only valid keys get the per-key quota)
+	if config.APIKeys != nil {
+		router.Use(middleware.APIKeyAuth(config.APIKeys))
+	}
+
 	// Rate limiting middleware (before logging to avoid logging blocked requests)
 	if config.EnableRateLimit {
 		if config.DevelopmentMode {
@@ -91,38 +129,164 @@ func SetupRouterWithConfig(storage interfaces.TaskStorage, config RouterConfig)
 	router.Use(middleware.ErrorLogger())
 
 	// Setup routes
-	setupAPIRoutes(router, storage)
+	setupAPIRoutes(router, storage, config)
 
 	return router
 }
 
+// longLivedRoutes are the route patterns holding connections open, exempt from the request deadline
+var longLivedRoutes = []string{"/api/v1/tasks/events", "/api/v1/ws"}
+
+// routePermissions lists the permissions each authenticated API route requires
+// Every route registered on the api group below needs an entry: Authorize denies unmapped
+// routes. A policy file may replace the permissions of an entry but cannot add routes.
+var routePermissions = []rbac.RouteRule{
+	{Method: "GET", Path: "/api/v1/stats", Permissions: []rbac.Permission{rbac.PermStatsRead}},
+	{Method: "GET", Path: "/api/v1/ws", Permissions: []rbac.Permission{rbac.PermTasksRead}},
+
+	{Method: "GET", Path: "/api/v1/tasks", Permissions: []rbac.Permission{rbac.PermTasksRead}},
+	{Method: "POST", Path: "/api/v1/tasks", Permissions: []rbac.Permission{rbac.PermTasksWrite}},
+	{Method: "GET", Path: "/api/v1/tasks/:id", Permissions: []rbac.Permission{rbac.PermTasksRead}},
+	{Method: "PUT", Path: "/api/v1/tasks/:id", Permissions: []rbac.Permission{rbac.PermTasksWrite}},
+	{Method: "PATCH", Path: "/api/v1/tasks/:id", Permissions: []rbac.Permission{rbac.PermTasksWrite}},
+	{Method: "DELETE", Path: "/api/v1/tasks/:id", Permissions: []rbac.Permission{rbac.PermTasksDelete}},
+	{Method: "GET", Path: "/api/v1/tasks/status/:status", Permissions: []rbac.Permission{rbac.PermTasksRead}},
+	{Method: "GET", Path: "/api/v1/tasks/paginated", Permissions: []rbac.Permission{rbac.PermTasksRead}},
+	{Method: "GET", Path: "/api/v1/tasks/search", Permissions: []rbac.Permission{rbac.PermTasksRead}},
+	{Method: "GET", Path: "/api/v1/tasks/events", Permissions: []rbac.Permission{rbac.PermTasksRead}},
+	{Method: "POST", Path: "/api/v1/tasks/bulk", Permissions: []rbac.Permission{rbac.PermTasksWrite, rbac.PermTasksDelete}},
+	{Method: "GET", Path: "/api/v1/tasks/:id/history", Permissions: []rbac.Permission{rbac.PermTasksRead}},
+	{Method: "GET", Path: "/api/v1/tasks/:id/versions/:version", Permissions: []rbac.Permission{rbac.PermTasksRead}},
+	{Method: "POST", Path: "/api/v1/tasks/:id/versions/:version/revert", Permissions: []rbac.Permission{rbac.PermTasksWrite}},
+
+	{Method: "GET", Path: "/api/v1/webhooks", Permissions: []rbac.Permission{rbac.PermWebhooksManage}},
+	{Method: "POST", Path: "/api/v1/webhooks", Permissions: []rbac.Permission{rbac.PermWebhooksManage}},
+	{Method: "GET", Path: "/api/v1/webhooks/:id", Permissions: []rbac.Permission{rbac.PermWebhooksManage}},
+	{Method: "PATCH", Path: "/api/v1/webhooks/:id", Permissions: []rbac.Permission{rbac.PermWebhooksManage}},
+	{Method: "DELETE", Path: "/api/v1/webhooks/:id", Permissions: []rbac.Permission{rbac.PermWebhooksManage}},
+	{Method: "GET", Path: "/api/v1/webhooks/:id/deliveries", Permissions: []rbac.Permission{rbac.PermWebhooksManage}},
+	{Method: "POST", Path: "/api/v1/webhooks/:id/deliveries/:delivery_id/retry", Permissions: []rbac.Permission{rbac.PermWebhooksManage}},
+
+	{Method: "GET", Path: "/api/v1/apikeys", Permissions: []rbac.Permission{rbac.PermAPIKeysManage}},
+	{Method: "POST", Path: "/api/v1/apikeys", Permissions: []rbac.Permission{rbac.PermAPIKeysManage}},
+	{Method: "GET", Path: "/api/v1/apikeys/:id", Permissions: []rbac.Permission{rbac.PermAPIKeysManage}},
+	{Method: "DELETE", Path: "/api/v1/apikeys/:id", Permissions: []rbac.Permission{rbac.PermAPIKeysManage}},
+}
+
+// NewAuthorizer creates the authorizer of the API routes for policy (Factory Pattern)
+// A nil policy stands for the built-in one; policies mapping routes the API does not have are rejected.
+func NewAuthorizer(policy *rbac.Policy) (*rbac.Authorizer, error) {
+	if policy == nil {
+		policy = rbac.DefaultPolicy()
+	}
+	return rbac.NewAuthorizer(policy, routePermissions)
+}
+
+// defaultAuthorizer returns the authorizer of the built-in policy, which always builds
+func defaultAuthorizer() *rbac.Authorizer {
+	authorizer, err := NewAuthorizer(nil)
+	if err != nil {
+		panic(err)
+	}
+	return authorizer
+}
+
 // setupAPIRoutes configures all API routes
-func setupAPIRoutes(router *gin.Engine, storage interfaces.TaskStorage) {
+func setupAPIRoutes(router *gin.Engine, storage interfaces.TaskStorage, config RouterConfig) {
 	// Create task handler
 	taskHandler := handlers.NewTaskHandler(storage)
 
+	// Connect the storage to the change feed, if it reports its changes
+	var bus *events.Bus
+	if notifier, ok := storage.(interfaces.ChangeNotifier); ok {
+		bus = config.EventBus
+		if bus == nil {
+			bus = events.NewBus(events.BusConfig{})
+		}
+		notifier.SetEventPublisher(bus)
+	}
+	eventHandler := handlers.NewEventHandler(bus, config.EventHeartbeat)
+
+	// Browsers may open WebSockets from any page, so production only accepts the CORS origins
+	var socketOrigins []string
+	if !config.DevelopmentMode {
+		socketOrigins = config.AllowedOrigins
+	}
+	socketHandler := handlers.NewWebSocketHandler(bus, config.EventHeartbeat, socketOrigins)
+	webhookHandler := handlers.NewWebhookHandler(config.Webhooks)
+	apiKeyHandler := handlers.NewAPIKeyHandler(config.APIKeys)
+
+	authorizer := config.Authorizer
+	if authorizer == nil {
+		authorizer = defaultAuthorizer()
+	}
+
 	// API v1 group
 	v1 := router.Group("/api/v1")
 	{
 		// Health check endpoint (outside of tasks group)
 		v1.GET("/health", taskHandler.HealthCheck)
 
-		// Statistics endpoint
-		v1.GET("/stats", taskHandler.GetStorageStats)
+		// Workflow definition endpoint
+		v1.GET("/workflow", taskHandler.GetWorkflow)
+
+		// Everything else requires a bearer token or API key when authentication is enabled,
+		// and a role granting the permissions listed in routePermissions
+		api := v1.Group("")
+		if config.Authenticator != nil {
+			api.Use(middleware.Authenticate(config.Authenticator))
+		}
+		api.Use(middleware.Authorize(authorizer))
 
-		// Tasks group
-		tasks := v1.Group("/tasks")
+		// Statistics endpoint (non-admins count their own tasks)
+		api.GET("/stats", taskHandler.GetStorageStats)
+
+		// Change subscriptions over WebSocket
+		api.GET("/ws", middleware.RequireScope(models.ScopeTasksRead), socketHandler.Connect)
+
+		// Tasks group (API keys need tasks:read to read and tasks:write to change)
+		tasks := api.Group("/tasks", middleware.RequireTaskScope())
 		{
 			// Basic CRUD operations
 			tasks.GET("", taskHandler.GetAllTasks)       // GET /api/v1/tasks
 			tasks.POST("", taskHandler.CreateTask)       // POST /api/v1/tasks
 			tasks.GET("/:id", taskHandler.GetTaskByID)   // GET /api/v1/tasks/:id
 			tasks.PUT("/:id", taskHandler.UpdateTask)    // PUT /api/v1/tasks/:id
+			tasks.PATCH("/:id", taskHandler.PatchTask)   // PATCH /api/v1/tasks/:id
 			tasks.DELETE("/:id", taskHandler.DeleteTask) // DELETE /api/v1/tasks/:id
 
 			// Additional endpoints
 			tasks.GET("/status/:status", taskHandler.GetTasksByStatus) // GET /api/v1/tasks/status/:status
 			tasks.GET("/paginated", taskHandler.GetTasksPaginated)     // GET /api/v1/tasks/paginated
+			tasks.GET("/search", taskHandler.SearchTasks)              // GET /api/v1/tasks/search
+			tasks.GET("/events", eventHandler.StreamEvents)            // GET /api/v1/tasks/events
+			tasks.POST("/bulk", taskHandler.BulkTasks)                 // POST /api/v1/tasks/bulk
+
+			// Revision history
+			tasks.GET("/:id/history", taskHandler.GetTaskHistory)               // GET /api/v1/tasks/:id/history
+			tasks.GET("/:id/versions/:version", taskHandler.GetTaskVersion)     // GET /api/v1/tasks/:id/versions/:version
+			tasks.POST("/:id/versions/:version/revert", taskHandler.RevertTask) // POST /api/v1/tasks/:id/versions/:version/revert
+		}
+
+		// Webhooks group (administrators only: webhooks receive every owner's changes)
+		hooks := api.Group("/webhooks", middleware.RequireAdmin())
+		{
+			hooks.GET("", webhookHandler.ListWebhooks)                                     // GET /api/v1/webhooks
+			hooks.POST("", webhookHandler.CreateWebhook)                                   // POST /api/v1/webhooks
+			hooks.GET("/:id", webhookHandler.GetWebhook)                                   // GET /api/v1/webhooks/:id
+			hooks.PATCH("/:id", webhookHandler.UpdateWebhook)                              // PATCH /api/v1/webhooks/:id
+			hooks.DELETE("/:id", webhookHandler.DeleteWebhook)                             // DELETE /api/v1/webhooks/:id
+			hooks.GET("/:id/deliveries", webhookHandler.ListDeliveries)                    // GET /api/v1/webhooks/:id/deliveries
+			hooks.POST("/:id/deliveries/:delivery_id/retry", webhookHandler.RetryDelivery) // POST /api/v1/webhooks/:id/deliveries/:delivery_id/retry
+		}
+
+		// API keys group (administrators only, even without bearer authentication)
+		keys := api.Group("/apikeys", middleware.RequireAdmin())
+		{
+			keys.GET("", apiKeyHandler.ListAPIKeys)         // GET /api/v1/apikeys
+			keys.POST("", apiKeyHandler.IssueAPIKey)        // POST /api/v1/apikeys
+			keys.GET("/:id", apiKeyHandler.GetAPIKey)       // GET /api/v1/apikeys/:id
+			keys.DELETE("/:id", apiKeyHandler.RevokeAPIKey) // DELETE /api/v1/apikeys/:id
 		}
 	}
 
@@ -138,18 +302,42 @@ func setupAPIRoutes(router *gin.Engine, storage interfaces.TaskStorage) {
 			"message": "Task API",
 			"version": "1.0.0",
 			"endpoints": map[string]interface{}{
-				"health":  "/health or /api/v1/health",
-				"stats":   "/api/v1/stats",
-				"swagger": "/swagger/index.html",
-				"docs":    "/docs/swagger.json",
+				"health":    "/health or /api/v1/health",
+				"stats":     "/api/v1/stats",
+				"workflow":  "/api/v1/workflow",
+				"websocket": "GET /api/v1/ws",
+				"swagger":   "/swagger/index.html",
+				"docs":      "/docs/swagger.json",
 				"tasks": map[string]string{
 					"list":      "GET /api/v1/tasks",
 					"create":    "POST /api/v1/tasks",
 					"get":       "GET /api/v1/tasks/:id",
 					"update":    "PUT /api/v1/tasks/:id",
+					"patch":     "PATCH /api/v1/tasks/:id",
 					"delete":    "DELETE /api/v1/tasks/:id",
 					"by_status": "GET /api/v1/tasks/status/:status",
 					"paginated": "GET /api/v1/tasks/paginated",
+					"search":    "GET /api/v1/tasks/search?q=",
+					"events":    "GET /api/v1/tasks/events",
+					"bulk":      "POST /api/v1/tasks/bulk",
+					"history":   "GET /api/v1/tasks/:id/history",
+					"version":   "GET /api/v1/tasks/:id/versions/:version",
+					"revert":    "POST /api/v1/tasks/:id/versions/:version/revert",
+				},
+				"webhooks": map[string]string{
+					"list":       "GET /api/v1/webhooks",
+					"create":     "POST /api/v1/webhooks",
+					"get":        "GET /api/v1/webhooks/:id",
+					"update":     "PATCH /api/v1/webhooks/:id",
+					"delete":     "DELETE /api/v1/webhooks/:id",
+					"deliveries": "GET /api/v1/webhooks/:id/deliveries",
+					"retry":      "POST /api/v1/webhooks/:id/deliveries/:delivery_id/retry",
+				},
+				"apikeys": map[string]string{
+					"list":   "GET /api/v1/apikeys",
+					"issue":  "POST /api/v1/apikeys",
+					"get":    "GET /api/v1/apikeys/:id",
+					"revoke": "DELETE /api/v1/apikeys/:id",
 				},
 			},
 		})
@@ -177,20 +365,36 @@ func SetupTestRouter(storage interfaces.TaskStorage) *gin.Engine {
 
 // ConfigInterface defines the interface for app configuration
 type ConfigInterface interface {
+	GetClientIPConfig() middleware.ClientIPConfig
 	GetRateLimitEnabled() bool
 	GetRateLimitPerIP() int
 	GetRateLimitPerAPIKey() int
 	GetRateLimitCleanupTime() int
+	GetRateLimitAlgorithm() string
+	GetRateLimitBurst() int
+	GetWriteTimeout() int
+	GetAPIVersion() int
+	GetEventHeartbeat() int
 }
 
 // SetupDevelopmentRouterWithConfig creates a router with development-friendly settings using app config
-func SetupDevelopmentRouterWithConfig(storage interfaces.TaskStorage, appConfig ConfigInterface) *gin.Engine {
+// Changes reported by the storage are published to bus; hooks serves the webhook endpoints.
+// A non-nil verifier requires a bearer token on every endpoint but health and workflow; keys
+// authenticates X-API-Key headers and serves the API key endpoints; authorizer checks the
+// caller's roles (nil = the built-in policy); limits declares the rate limit policies (nil =
+// the built-in policies, derived from the doubled per-IP limit) and limitStore keeps their
+// counters (nil = in memory).
+func SetupDevelopmentRouterWithConfig(storage interfaces.TaskStorage, bus *events.Bus, hooks *webhooks.Dispatcher, verifier *auth.Verifier, keys *apikeys.Store, authorizer *rbac.Authorizer, limits *middleware.RateLimitPolicies, limitStore middleware.RateLimitStore, appConfig ConfigInterface) *gin.Engine {
 	rateLimitConfig := middleware.RateLimitConfig{
 		Enabled:         appConfig.GetRateLimitEnabled(),
 		PerIP:           appConfig.GetRateLimitPerIP() * 2, // More lenient for development
 		PerAPIKey:       appConfig.GetRateLimitPerAPIKey() * 2,
 		CleanupInterval: time.Duration(appConfig.GetRateLimitCleanupTime()) * time.Minute,
 		WindowSize:      1 * time.Minute,
+		Algorithm:       middleware.RateLimitAlgorithm(appConfig.GetRateLimitAlgorithm()),
+		Burst:           appConfig.GetRateLimitBurst() * 2,
+		Policies:        limits,
+		Store:           limitStore,
 	}
 
 	config := RouterConfig{
@@ -199,23 +403,41 @@ func SetupDevelopmentRouterWithConfig(storage interfaces.TaskStorage, appConfig
 		EnableSecurity:  false, // Disable for easier debugging
 		EnableRequestID: true,
 		EnableRateLimit: true,
-		TrustedProxies:  []string{"127.0.0.1", "::1"},
+		TrustedProxies:  appConfig.GetClientIPConfig().TrustedProxies,
+		ProxyHeader:     appConfig.GetClientIPConfig().Header,
 		AllowedOrigins:  []string{"*"},
 		DevelopmentMode: true,
 		RateLimitConfig: rateLimitConfig,
+		RequestTimeout:  time.Duration(appConfig.GetWriteTimeout()) * time.Second,
+		APIVersion:      appConfig.GetAPIVersion(),
+		EventBus:        bus,
+		EventHeartbeat:  time.Duration(appConfig.GetEventHeartbeat()) * time.Second,
+		Webhooks:        hooks,
+		Authenticator:   verifier,
+		APIKeys:         keys,
+		Authorizer:      authorizer,
 	}
 
 	return SetupRouterWithConfig(storage, config)
 }
 
 // SetupProductionRouterWithConfig creates a router with production-ready settings using app config
-func SetupProductionRouterWithConfig(storage interfaces.TaskStorage, allowedOrigins []string, appConfig ConfigInterface) *gin.Engine {
+// Changes reported by the storage are published to bus; hooks serves the webhook endpoints.
+// A non-nil verifier requires a bearer token on every endpoint but health and workflow; keys
+// authenticates X-API-Key headers and serves the API key endpoints; authorizer checks the
+// caller's roles (nil = the built-in policy); limits declares the rate limit policies (nil =
+// the built-in policies) and limitStore keeps their counters (nil = in memory).
+func SetupProductionRouterWithConfig(storage interfaces.TaskStorage, bus *events.Bus, hooks *webhooks.Dispatcher, verifier *auth.Verifier, keys *apikeys.Store, authorizer *rbac.Authorizer, limits *middleware.RateLimitPolicies, limitStore middleware.RateLimitStore, allowedOrigins []string, appConfig ConfigInterface) *gin.Engine {
 	rateLimitConfig := middleware.RateLimitConfig{
 		Enabled:         appConfig.GetRateLimitEnabled(),
 		PerIP:           appConfig.GetRateLimitPerIP(),
 		PerAPIKey:       appConfig.GetRateLimitPerAPIKey(),
 		CleanupInterval: time.Duration(appConfig.GetRateLimitCleanupTime()) * time.Minute,
 		WindowSize:      1 * time.Minute,
+		Algorithm:       middleware.RateLimitAlgorithm(appConfig.GetRateLimitAlgorithm()),
+		Burst:           appConfig.GetRateLimitBurst(),
+		Policies:        limits,
+		Store:           limitStore,
 	}
 
 	config := RouterConfig{
@@ -224,10 +446,19 @@ func SetupProductionRouterWithConfig(storage interfaces.TaskStorage, allowedOrig
 		EnableSecurity:  true,
 		EnableRequestID: true,
 		EnableRateLimit: true,
-		TrustedProxies:  []string{"127.0.0.1"},
+		TrustedProxies:  appConfig.GetClientIPConfig().TrustedProxies,
+		ProxyHeader:     appConfig.GetClientIPConfig().Header,
 		AllowedOrigins:  allowedOrigins,
 		DevelopmentMode: false,
 		RateLimitConfig: rateLimitConfig,
+		RequestTimeout:  time.Duration(appConfig.GetWriteTimeout()) * time.Second,
+		APIVersion:      appConfig.GetAPIVersion(),
+		EventBus:        bus,
+		EventHeartbeat:  time.Duration(appConfig.GetEventHeartbeat()) * time.Second,
+		Webhooks:        hooks,
+		Authenticator:   verifier,
+		APIKeys:         keys,
+		Authorizer:      authorizer,
 	}
 
 	return SetupRouterWithConfig(storage, config)
@@ -237,7 +468,8 @@ func SetupProductionRouterWithConfig(storage interfaces.TaskStorage, allowedOrig
 func SetupMetricsEndpoint(router *gin.Engine, storage interfaces.TaskStorage) {
 	router.GET("/metrics", func(c *gin.Context) {
 		// Basic metrics - could be extended to Prometheus format
-		count, _ := storage.Count()
+		ctx := c.Request.Context()
+		count, _ := storage.Count(ctx)
 
 		metrics := map[string]interface{}{
 			"total_tasks": count,
@@ -246,8 +478,15 @@ func SetupMetricsEndpoint(router *gin.Engine, storage interfaces.TaskStorage) {
 		}
 
 		// If storage supports more detailed stats
-		if statsProvider, ok := storage.(interface{ GetStats() interface{} }); ok {
-			metrics["storage_stats"] = statsProvider.GetStats()
+		if statsProvider, ok := storage.(interfaces.StatsProvider); ok {
+			if stats, err := statsProvider.GetStats(ctx); err == nil {
+				metrics["storage_stats"] = stats
+			}
+		}
+
+		// If storage reports capacity and usage
+		if usageReporter, ok := storage.(interfaces.UsageReporter); ok {
+			metrics["storage_usage"] = usageReporter.GetUsage()
 		}
 
 		c.JSON(200, gin.H{
@@ -287,7 +526,12 @@ func GetRegisteredRoutes(router *gin.Engine) []RouteInfo {
 }
 
 // SetupDebugRoutes adds debug endpoints for development
-func SetupDebugRoutes(router *gin.Engine) {
+// authorizer is explained by /debug/authz (nil = the built-in policy).
+func SetupDebugRoutes(router *gin.Engine, authorizer *rbac.Authorizer) {
+	if authorizer == nil {
+		authorizer = defaultAuthorizer()
+	}
+
 	debug := router.Group("/debug")
 	{
 		// List all registered routes
@@ -299,6 +543,38 @@ func SetupDebugRoutes(router *gin.Engine) {
 			})
 		})
 
+		// Explain authorization decisions: ?roles=viewer,auditor&method=DELETE&path=/api/v1/tasks/42
+		// Without method and path every mapped route is explained; without roles the default roles apply.
+		debug.GET("/authz", func(c *gin.Context) {
+			var roles []string
+			for _, role := range strings.Split(c.Query("roles"), ",") {
+				if role = strings.TrimSpace(role); role != "" {
+					roles = append(roles, role)
+				}
+			}
+
+			method, path := c.Query("method"), c.Query("path")
+			if path != "" {
+				if method == "" {
+					method = "GET"
+				}
+				c.JSON(200, gin.H{"decision": authorizer.Explain(roles, method, path)})
+				return
+			}
+
+			var decisions []rbac.Decision
+			for _, rule := range authorizer.Rules() {
+				decisions = append(decisions, authorizer.Authorize(roles, rule.Method, rule.Path))
+			}
+			policy := authorizer.Policy()
+			c.JSON(200, gin.H{
+				"roles":         policy.Roles,
+				"default_roles": policy.DefaultRoles,
+				"decisions":     decisions,
+				"count":         len(decisions),
+			})
+		})
+
 		// Echo endpoint for testing
 		debug.POST("/echo", func(c *gin.Context) {
 			var body interface{}
@@ -0,0 +1,177 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"task-api/internal/apikeys"
+	"task-api/internal/auth"
+	"task-api/internal/middleware"
+	"task-api/internal/models"
+	"task-api/internal/rbac"
+	"task-api/internal/storage"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// publicRoutes are the API routes served without authentication, and so without permissions
+var publicRoutes = map[string]bool{
+	"GET /api/v1/health":   true,
+	"GET /api/v1/workflow": true,
+}
+
+func TestRoutePermissions(t *testing.T) {
+	router := SetupTestRouter(storage.NewMemoryStorage(100))
+	authorizer := defaultAuthorizer()
+
+	mapped := make(map[string]bool)
+	for _, rule := range authorizer.Rules() {
+		mapped[rule.Key()] = true
+	}
+
+	registered := make(map[string]bool)
+	for _, route := range router.Routes() {
+		key := route.Method + " " + route.Path
+		if !strings.HasPrefix(route.Path, "/api/v1/") || publicRoutes[key] {
+			continue
+		}
+		registered[key] = true
+		assert.True(t, mapped[key], "%s has no entry in routePermissions", key)
+	}
+	for key := range mapped {
+		assert.True(t, registered[key], "routePermissions maps %s, which is not registered", key)
+	}
+}
+
+func TestSetupDebugRoutes_Authz(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	SetupDebugRoutes(router, nil)
+
+	explain := func(query string) map[string]json.RawMessage {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/authz"+query, nil))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var body map[string]json.RawMessage
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		return body
+	}
+
+	t.Run("single route", func(t *testing.T) {
+		var decision rbac.Decision
+		require.NoError(t, json.Unmarshal(explain("?roles=viewer&method=DELETE&path=/api/v1/tasks/42")["decision"], &decision))
+		assert.Equal(t, "/api/v1/tasks/:id", decision.Path)
+		assert.False(t, decision.Allowed)
+		assert.Equal(t, []rbac.Permission{rbac.PermTasksDelete}, decision.Missing)
+	})
+
+	t.Run("every route", func(t *testing.T) {
+		var decisions []rbac.Decision
+		require.NoError(t, json.Unmarshal(explain("?roles=admin")["decisions"], &decisions))
+		require.Len(t, decisions, len(routePermissions))
+		for _, decision := range decisions {
+			assert.True(t, decision.Allowed, decision.Path)
+		}
+	})
+}
+
+func TestNewAuthorizer_ExamplePolicy(t *testing.T) {
+	policy, err := rbac.LoadPolicy("../../examples/rbac-policy.json")
+	require.NoError(t, err)
+	authorizer, err := NewAuthorizer(policy)
+	require.NoError(t, err)
+
+	assert.False(t, authorizer.Authorize(nil, "POST", "/api/v1/tasks").Allowed)
+	assert.False(t, authorizer.Authorize([]string{"editor"}, "POST", "/api/v1/tasks/:id/versions/:version/revert").Allowed)
+	assert.True(t, authorizer.Authorize([]string{"maintainer"}, "POST", "/api/v1/tasks/:id/versions/:version/revert").Allowed)
+}
+
+func TestAPIKeyRoutes_RequireAdmin(t *testing.T) {
+	keys, err := apikeys.NewStore("")
+	require.NoError(t, err)
+	admin, err := keys.Issue(&models.IssueAPIKeyRequest{Name: "root", Subject: "root", Scopes: []string{models.ScopeAdmin}}, "")
+	require.NoError(t, err)
+
+	// Authentication is off: no bearer token verifier
+	gin.SetMode(gin.TestMode)
+	router := SetupRouterWithConfig(storage.NewMemoryStorage(100), RouterConfig{APIKeys: keys})
+
+	issue := func(headers map[string]string) *httptest.ResponseRecorder {
+		body := `{"name":"x","subject":"eve","scopes":["admin"]}`
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/apikeys", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, issue(nil).Code)
+	assert.Equal(t, http.StatusCreated, issue(map[string]string{middleware.APIKeyHeader: admin.Key}).Code)
+}
+
+func TestAnonymousAccess(t *testing.T) {
+	// Authentication is off: anonymous callers hold the default roles
+	router := SetupTestRouter(storage.NewMemoryStorage(100))
+
+	tests := []struct {
+		method string
+		path   string
+		status int
+	}{
+		{http.MethodGet, "/api/v1/tasks", http.StatusOK},
+		{http.MethodGet, "/api/v1/stats", http.StatusUnauthorized},
+		{http.MethodGet, "/api/v1/webhooks", http.StatusUnauthorized},
+		{http.MethodPost, "/api/v1/webhooks", http.StatusUnauthorized},
+		{http.MethodGet, "/api/v1/apikeys", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+			assert.Equal(t, tt.status, w.Code, w.Body.String())
+		})
+	}
+}
+
+func TestWebhookRoutes_AdminOnly(t *testing.T) {
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	keys := auth.NewKeySet()
+	require.NoError(t, keys.AddHMAC("", secret))
+
+	// A policy granting webhooks:manage to a role that is not admin
+	policy := rbac.DefaultPolicy()
+	policy.Roles["integrator"] = &rbac.Role{Permissions: []rbac.Permission{rbac.PermWebhooksManage}}
+	require.NoError(t, policy.Validate())
+	authorizer, err := NewAuthorizer(policy)
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	router := SetupRouterWithConfig(storage.NewMemoryStorage(100), RouterConfig{
+		Authenticator: auth.NewVerifier(keys, auth.VerifierConfig{}),
+		Authorizer:    authorizer,
+	})
+	list := func(claims map[string]interface{}) int {
+		claims["exp"] = time.Now().Add(time.Hour).Unix()
+		token, err := auth.SignHS256(claims, "", secret)
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/webhooks", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusForbidden, list(map[string]interface{}{"sub": "alice", "roles": []string{"integrator"}}))
+	// Administrators get through to the handler, which has no webhook registry here
+	assert.Equal(t, http.StatusNotImplemented, list(map[string]interface{}{"sub": "root", "admin": true}))
+}
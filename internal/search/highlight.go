@@ -0,0 +1,89 @@
+package search
+
+import (
+	"html"
+	"strings"
+	"unicode/utf8"
+)
+
+// Snippet limits
+const (
+	maxSnippetLength = 160 // Maximum length of a snippet in bytes, ellipses excluded
+	snippetContext   = 40  // Bytes of text kept before the first match of a long text
+)
+
+// Highlight returns a snippet of text with the words matching the query terms wrapped in <mark> tags
+// Long texts are cut to a window around the first match, marked with ellipses. The text is
+// HTML-escaped so the snippet can be rendered as is. Returns "" when nothing matches.
+func Highlight(text string, queryTerms []string) string {
+	tokens := Tokenize(text)
+
+	first := -1
+	for i, token := range tokens {
+		if matchesAny(token.Term, queryTerms) {
+			first = i
+			break
+		}
+	}
+	if first < 0 {
+		return ""
+	}
+
+	start, end := 0, len(text)
+	if len(text) > maxSnippetLength {
+		// Start on a word shortly before the first match
+		start = tokens[first].Start - snippetContext
+		if start <= 0 {
+			start = 0
+		} else {
+			for _, token := range tokens[:first+1] {
+				if token.Start >= start {
+					start = token.Start
+					break
+				}
+			}
+		}
+
+		// End after the last whole word that fits
+		end = start + maxSnippetLength
+		if end >= len(text) {
+			end = len(text)
+		} else {
+			cut := 0
+			for _, token := range tokens[first:] {
+				if token.End > end {
+					break
+				}
+				cut = token.End
+			}
+			if cut > start {
+				end = cut
+			}
+			for end > start && !utf8.RuneStart(text[end]) {
+				end--
+			}
+		}
+	}
+
+	var snippet strings.Builder
+	if start > 0 {
+		snippet.WriteString("…")
+	}
+	pos := start
+	for _, token := range tokens {
+		if token.Start < start || token.End > end || !matchesAny(token.Term, queryTerms) {
+			continue
+		}
+		snippet.WriteString(html.EscapeString(text[pos:token.Start]))
+		snippet.WriteString("<mark>")
+		snippet.WriteString(html.EscapeString(text[token.Start:token.End]))
+		snippet.WriteString("</mark>")
+		pos = token.End
+	}
+	snippet.WriteString(html.EscapeString(text[pos:end]))
+	if end < len(text) {
+		snippet.WriteString("…")
+	}
+
+	return snippet.String()
+}
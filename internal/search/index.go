@@ -0,0 +1,261 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"task-api/internal/models"
+)
+
+// Indexed task fields
+const (
+	fieldName        = iota // Task name
+	fieldDescription        // Task description
+	fieldCount              // Number of indexed fields
+)
+
+// Ranking parameters (BM25 with per-field weights)
+const (
+	bm25K1       = 1.2  // Term frequency saturation
+	bm25B        = 0.75 // Strength of the field length normalization
+	prefixWeight = 0.5  // Share of the score a prefix match earns compared to an exact match
+)
+
+// fieldWeights makes a match in the name count twice as much as one in the description
+var fieldWeights = [fieldCount]float64{fieldName: 2, fieldDescription: 1}
+
+// Hit is one task matched by a search
+type Hit struct {
+	ID    string  // Task ID
+	Score float64 // Relevance; higher is better
+}
+
+// document is the indexed form of one task
+type document struct {
+	frequencies map[string]*[fieldCount]int // Occurrences of each word per field
+	lengths     [fieldCount]int             // Number of words per field
+}
+
+// Index is an inverted index from words to the tasks containing them
+// It is safe for concurrent use. Words are kept in a sorted vocabulary so that prefix
+// matches are found with a binary search.
+type Index struct {
+	documents   map[string]*document           // Indexed tasks by ID
+	postings    map[string]map[string]struct{} // IDs of the tasks containing each word
+	vocabulary  []string                       // Every indexed word, sorted
+	totalLength [fieldCount]int                // Number of words per field over all tasks
+	mutex       sync.RWMutex                   // Protects every field above
+}
+
+// NewIndex creates an empty index (Factory Pattern)
+func NewIndex() *Index {
+	return &Index{
+		documents: make(map[string]*document),
+		postings:  make(map[string]map[string]struct{}),
+	}
+}
+
+// Len returns the number of indexed tasks
+func (idx *Index) Len() int {
+	idx.mutex.RLock()
+	defer idx.mutex.RUnlock()
+
+	return len(idx.documents)
+}
+
+// Add indexes a task, replacing any previous version of it
+func (idx *Index) Add(task *models.Task) {
+	doc := &document{frequencies: make(map[string]*[fieldCount]int)}
+	for field, text := range [fieldCount]string{fieldName: task.Name, fieldDescription: task.Description} {
+		for _, token := range Tokenize(text) {
+			counts, ok := doc.frequencies[token.Term]
+			if !ok {
+				counts = &[fieldCount]int{}
+				doc.frequencies[token.Term] = counts
+			}
+			counts[field]++
+			doc.lengths[field]++
+		}
+	}
+
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+
+	idx.remove(task.ID)
+	idx.documents[task.ID] = doc
+	for field, length := range doc.lengths {
+		idx.totalLength[field] += length
+	}
+	for term := range doc.frequencies {
+		ids, ok := idx.postings[term]
+		if !ok {
+			ids = make(map[string]struct{})
+			idx.postings[term] = ids
+			idx.insertWord(term)
+		}
+		ids[task.ID] = struct{}{}
+	}
+}
+
+// Remove drops a task from the index, ignoring unknown IDs
+func (idx *Index) Remove(id string) {
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+
+	idx.remove(id)
+}
+
+// Clear drops every task from the index
+func (idx *Index) Clear() {
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+
+	idx.documents = make(map[string]*document)
+	idx.postings = make(map[string]map[string]struct{})
+	idx.vocabulary = nil
+	idx.totalLength = [fieldCount]int{}
+}
+
+// remove drops a task from the index; must be called with the write lock held
+func (idx *Index) remove(id string) {
+	doc, ok := idx.documents[id]
+	if !ok {
+		return
+	}
+
+	delete(idx.documents, id)
+	for field, length := range doc.lengths {
+		idx.totalLength[field] -= length
+	}
+	for term := range doc.frequencies {
+		ids := idx.postings[term]
+		delete(ids, id)
+		if len(ids) == 0 {
+			delete(idx.postings, term)
+			idx.removeWord(term)
+		}
+	}
+}
+
+// insertWord adds a new word to the sorted vocabulary
+func (idx *Index) insertWord(term string) {
+	i := sort.SearchStrings(idx.vocabulary, term)
+	idx.vocabulary = append(idx.vocabulary, "")
+	copy(idx.vocabulary[i+1:], idx.vocabulary[i:])
+	idx.vocabulary[i] = term
+}
+
+// removeWord drops a word from the sorted vocabulary
+func (idx *Index) removeWord(term string) {
+	i := sort.SearchStrings(idx.vocabulary, term)
+	if i < len(idx.vocabulary) && idx.vocabulary[i] == term {
+		idx.vocabulary = append(idx.vocabulary[:i], idx.vocabulary[i+1:]...)
+	}
+}
+
+// expand returns the indexed words starting with the query term, the term itself included
+func (idx *Index) expand(queryTerm string) []string {
+	start := sort.SearchStrings(idx.vocabulary, queryTerm)
+	end := start
+	for end < len(idx.vocabulary) && strings.HasPrefix(idx.vocabulary[end], queryTerm) {
+		end++
+	}
+	return idx.vocabulary[start:end]
+}
+
+// Search returns up to limit tasks (0 = no limit) containing every word of the query, best match first
+// Each query word matches the same word or, at a lower score, any word it is a prefix of.
+// Ties are broken by ID so results are deterministic.
+func (idx *Index) Search(query string, limit int) []Hit {
+	terms := QueryTerms(query)
+	if len(terms) == 0 {
+		return nil
+	}
+
+	idx.mutex.RLock()
+	defer idx.mutex.RUnlock()
+
+	var scores map[string]float64
+	for _, queryTerm := range terms {
+		termScores := make(map[string]float64)
+		for _, term := range idx.expand(queryTerm) {
+			weight := 1.0
+			if term != queryTerm {
+				weight = prefixWeight
+			}
+			idf := idx.idf(term)
+
+			for id := range idx.postings[term] {
+				if scores != nil {
+					if _, ok := scores[id]; !ok {
+						continue
+					}
+				}
+				// A query word counts once per task, with its best matching word
+				if score := weight * idf * idx.termScore(idx.documents[id], term); score > termScores[id] {
+					termScores[id] = score
+				}
+			}
+		}
+
+		if scores == nil {
+			scores = termScores
+		} else {
+			for id := range scores {
+				if score, ok := termScores[id]; ok {
+					scores[id] += score
+				} else {
+					delete(scores, id)
+				}
+			}
+		}
+		if len(scores) == 0 {
+			return nil
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for id, score := range scores {
+		hits = append(hits, Hit{ID: id, Score: score})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID < hits[j].ID
+	})
+
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits
+}
+
+// idf returns the inverse document frequency of an indexed word
+func (idx *Index) idf(term string) float64 {
+	n := float64(len(idx.documents))
+	df := float64(len(idx.postings[term]))
+	return math.Log(1 + (n-df+0.5)/(df+0.5))
+}
+
+// termScore returns the weighted BM25 term frequency of a word in a task
+func (idx *Index) termScore(doc *document, term string) float64 {
+	counts := doc.frequencies[term]
+	n := float64(len(idx.documents))
+
+	var score float64
+	for field, count := range counts {
+		if count == 0 {
+			continue
+		}
+		averageLength := float64(idx.totalLength[field]) / n
+		if averageLength == 0 {
+			averageLength = 1
+		}
+		tf := float64(count)
+		norm := 1 - bm25B + bm25B*float64(doc.lengths[field])/averageLength
+		score += fieldWeights[field] * tf * (bm25K1 + 1) / (tf + bm25K1*norm)
+	}
+	return score
+}
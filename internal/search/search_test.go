@@ -0,0 +1,120 @@
+package search
+
+import (
+	"strings"
+	"task-api/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// hitIDs lists the task IDs of search hits, in order
+func hitIDs(hits []Hit) []string {
+	ids := make([]string, len(hits))
+	for i, hit := range hits {
+		ids[i] = hit.ID
+	}
+	return ids
+}
+
+func TestTokenize(t *testing.T) {
+	tokens := Tokenize("Fix the Café-menu (v2)!")
+
+	terms := make([]string, len(tokens))
+	for i, token := range tokens {
+		terms[i] = token.Term
+	}
+	assert.Equal(t, []string{"fix", "the", "café", "menu", "v2"}, terms)
+	assert.Equal(t, "Café", "Fix the Café-menu (v2)!"[tokens[2].Start:tokens[2].End])
+
+	assert.Empty(t, Tokenize(" -- !? "))
+	assert.Equal(t, []string{"report", "q1"}, QueryTerms("Report q1 REPORT"))
+}
+
+func TestIndex_Search(t *testing.T) {
+	index := NewIndex()
+	index.Add(&models.Task{ID: "name", Name: "Quarterly report", Description: "Numbers for the board"})
+	index.Add(&models.Task{ID: "description", Name: "Board meeting", Description: "Present the quarterly report"})
+	index.Add(&models.Task{ID: "prefix", Name: "Reporting pipeline", Description: "Quarterly numbers"})
+	index.Add(&models.Task{ID: "other", Name: "Buy milk"})
+
+	tests := []struct {
+		name     string
+		query    string
+		expected []string
+	}{
+		{"single word", "report", []string{"name", "description", "prefix"}},
+		{"every word must match", "quarterly board", []string{"name", "description"}},
+		{"prefix match", "pipe", []string{"prefix"}},
+		{"case insensitive", "MILK", []string{"other"}},
+		{"no match", "taxes", []string{}},
+		{"no words", "!!", []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ElementsMatch(t, tt.expected, hitIDs(index.Search(tt.query, 0)))
+		})
+	}
+
+	t.Run("name matches outrank description matches", func(t *testing.T) {
+		hits := index.Search("board", 0)
+		require.Len(t, hits, 2)
+		assert.Equal(t, "description", hits[0].ID)
+		assert.Greater(t, hits[0].Score, hits[1].Score)
+	})
+
+	t.Run("exact matches outrank prefix matches", func(t *testing.T) {
+		// Both match in a two-word name: "Quarterly report" and "Reporting pipeline"
+		hits := index.Search("report", 0)
+		require.Len(t, hits, 3)
+		assert.Equal(t, "name", hits[0].ID)
+		for _, hit := range hits[1:] {
+			assert.Greater(t, hits[0].Score, hit.Score)
+		}
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		assert.Equal(t, []string{"name"}, hitIDs(index.Search("report", 1)))
+	})
+}
+
+func TestIndex_AddRemove(t *testing.T) {
+	index := NewIndex()
+	index.Add(&models.Task{ID: "1", Name: "Write report"})
+	index.Add(&models.Task{ID: "2", Name: "Write tests"})
+
+	// Re-adding a task replaces its words
+	index.Add(&models.Task{ID: "1", Name: "Write summary"})
+	assert.Empty(t, index.Search("report", 0))
+	assert.Equal(t, []string{"1"}, hitIDs(index.Search("summary", 0)))
+	assert.Equal(t, 2, index.Len())
+
+	index.Remove("2")
+	index.Remove("unknown")
+	assert.Equal(t, []string{"1"}, hitIDs(index.Search("write", 0)))
+	assert.NotContains(t, index.vocabulary, "tests")
+
+	index.Clear()
+	assert.Equal(t, 0, index.Len())
+	assert.Empty(t, index.Search("write", 0))
+}
+
+func TestHighlight(t *testing.T) {
+	terms := []string{"report"}
+
+	assert.Equal(t, "Send the <mark>Report</mark> &amp; the <mark>reporting</mark> notes",
+		Highlight("Send the Report & the reporting notes", terms))
+	assert.Empty(t, Highlight("Nothing to see here", terms))
+
+	long := strings.Repeat("lorem ipsum ", 20) + "final report " + strings.Repeat("dolor sit ", 20)
+	snippet := Highlight(long, terms)
+	assert.True(t, strings.HasPrefix(snippet, "…"))
+	assert.True(t, strings.HasSuffix(snippet, "…"))
+	assert.Contains(t, snippet, "final <mark>report</mark> dolor")
+	assert.LessOrEqual(t, len(snippet), maxSnippetLength+len("<mark></mark>")+2*len("…"))
+
+	start := "report " + strings.Repeat("word ", 50)
+	assert.True(t, strings.HasPrefix(Highlight(start, terms), "<mark>report</mark> word"))
+}
@@ -0,0 +1,64 @@
+// Package search implements the in-process full-text index used to find tasks by words
+// in their name and description.
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Token is one word of a text
+type Token struct {
+	Term  string // Lower-cased word, as stored in the index
+	Start int    // Byte offset of the word in the text
+	End   int    // Byte offset just past the word
+}
+
+// Tokenize splits text into words: maximal runs of letters and digits, lower-cased
+func Tokenize(text string) []Token {
+	var tokens []Token
+	start := -1
+
+	for i, r := range text {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			tokens = append(tokens, Token{Term: strings.ToLower(text[start:i]), Start: start, End: i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, Token{Term: strings.ToLower(text[start:]), Start: start, End: len(text)})
+	}
+
+	return tokens
+}
+
+// QueryTerms returns the distinct words of a search query, in order
+func QueryTerms(query string) []string {
+	var terms []string
+	seen := make(map[string]bool)
+
+	for _, token := range Tokenize(query) {
+		if !seen[token.Term] {
+			seen[token.Term] = true
+			terms = append(terms, token.Term)
+		}
+	}
+	return terms
+}
+
+// matchesAny reports whether an indexed word matches one of the query terms
+// Every query term also matches the words it is a prefix of.
+func matchesAny(term string, queryTerms []string) bool {
+	for _, queryTerm := range queryTerms {
+		if strings.HasPrefix(term, queryTerm) {
+			return true
+		}
+	}
+	return false
+}
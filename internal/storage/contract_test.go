@@ -0,0 +1,1074 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"task-api/internal/interfaces"
+	"task-api/internal/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// storageFactory creates a fresh, empty TaskStorage with the given task limit
+type storageFactory func(t *testing.T, maxTasks int) interfaces.TaskStorage
+
+// runStorageContract runs the behavioural test suite every TaskStorage implementation must pass
+func runStorageContract(t *testing.T, newStorage storageFactory) {
+	ctx := context.Background()
+	t.Run("Create", func(t *testing.T) {
+		storage := newStorage(t, 1000)
+
+		tests := []struct {
+			name    string
+			request *models.CreateTaskRequest
+			wantErr bool
+			errMsg  string
+		}{
+			{
+				name:    "valid task creation",
+				request: &models.CreateTaskRequest{Name: "Test Task", Status: models.TaskIncomplete},
+			},
+			{
+				name:    "valid task with completed status",
+				request: &models.CreateTaskRequest{Name: "Completed Task", Status: models.TaskCompleted},
+			},
+			{
+				name:    "empty name should fail",
+				request: &models.CreateTaskRequest{Name: "", Status: models.TaskIncomplete},
+				wantErr: true,
+				errMsg:  "validation failed",
+			},
+			{
+				name:    "name too long should fail",
+				request: &models.CreateTaskRequest{Name: string(make([]byte, 256)), Status: models.TaskIncomplete},
+				wantErr: true,
+				errMsg:  "validation failed",
+			},
+			{
+				name:    "invalid status should fail",
+				request: &models.CreateTaskRequest{Name: "Test Task", Status: models.TaskStatus(99)},
+				wantErr: true,
+				errMsg:  "validation failed",
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				task, err := storage.Create(ctx, tt.request)
+
+				if tt.wantErr {
+					assert.Error(t, err)
+					assert.Contains(t, err.Error(), tt.errMsg)
+					assert.ErrorIs(t, err, ErrValidation)
+					assert.Nil(t, task)
+					return
+				}
+
+				require.NoError(t, err)
+				assert.Len(t, task.ID, 36)
+				assert.Equal(t, tt.request.Name, task.Name)
+				assert.Equal(t, tt.request.Status, task.Status)
+				assert.False(t, task.CreatedAt.IsZero())
+				assert.False(t, task.UpdatedAt.IsZero())
+			})
+		}
+	})
+
+	t.Run("GetAll", func(t *testing.T) {
+		storage := newStorage(t, 1000)
+
+		tasks, err := storage.GetAll(ctx)
+		assert.NoError(t, err)
+		assert.Empty(t, tasks)
+
+		task1, err := storage.Create(ctx, &models.CreateTaskRequest{Name: "Task 1", Status: models.TaskIncomplete})
+		require.NoError(t, err)
+		task2, err := storage.Create(ctx, &models.CreateTaskRequest{Name: "Task 2", Status: models.TaskCompleted})
+		require.NoError(t, err)
+
+		tasks, err = storage.GetAll(ctx)
+		assert.NoError(t, err)
+		assert.Len(t, tasks, 2)
+
+		taskIDs := make(map[string]bool)
+		for _, task := range tasks {
+			taskIDs[task.ID] = true
+		}
+		assert.True(t, taskIDs[task1.ID])
+		assert.True(t, taskIDs[task2.ID])
+	})
+
+	t.Run("GetByID", func(t *testing.T) {
+		storage := newStorage(t, 1000)
+
+		created, err := storage.Create(ctx, &models.CreateTaskRequest{Name: "Test Task", Status: models.TaskIncomplete})
+		require.NoError(t, err)
+
+		task, err := storage.GetByID(ctx, created.ID)
+		assert.NoError(t, err)
+		require.NotNil(t, task)
+		assert.Equal(t, created.ID, task.ID)
+		assert.Equal(t, created.Name, task.Name)
+		assert.Equal(t, created.Status, task.Status)
+
+		// Returned tasks must be copies
+		task.Name = "Mutated"
+		again, err := storage.GetByID(ctx, created.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Test Task", again.Name)
+
+		task, err = storage.GetByID(ctx, "non-existing")
+		assert.Error(t, err)
+		assert.Nil(t, task)
+		assert.Contains(t, err.Error(), "not found")
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		storage := newStorage(t, 1000)
+
+		created, err := storage.Create(ctx, &models.CreateTaskRequest{Name: "Original Task", Status: models.TaskIncomplete})
+		require.NoError(t, err)
+
+		updated, err := storage.Update(ctx, created.ID, &models.UpdateTaskRequest{
+			Name:   stringPtr("Updated Task"),
+			Status: taskStatusPtr(models.TaskCompleted),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, created.ID, updated.ID)
+		assert.Equal(t, "Updated Task", updated.Name)
+		assert.Equal(t, models.TaskCompleted, updated.Status)
+		assert.False(t, updated.UpdatedAt.Before(created.UpdatedAt))
+
+		fetched, err := storage.GetByID(ctx, created.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Updated Task", fetched.Name)
+
+		_, err = storage.Update(ctx, "non-existing", &models.UpdateTaskRequest{Name: stringPtr("x")})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "not found")
+		assert.ErrorIs(t, err, ErrNotFound)
+
+		_, err = storage.Update(ctx, created.ID, &models.UpdateTaskRequest{})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "no updates provided")
+		assert.ErrorIs(t, err, ErrValidation)
+
+		_, err = storage.Update(ctx, created.ID, &models.UpdateTaskRequest{Name: stringPtr("")})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "validation failed")
+		assert.ErrorIs(t, err, ErrValidation)
+
+		_, err = storage.Update(ctx, created.ID, &models.UpdateTaskRequest{Status: taskStatusPtr(models.TaskStatus(99))})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "validation failed")
+		assert.ErrorIs(t, err, ErrValidation)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		storage := newStorage(t, 1000)
+
+		created, err := storage.Create(ctx, &models.CreateTaskRequest{Name: "Test Task", Status: models.TaskIncomplete})
+		require.NoError(t, err)
+
+		require.NoError(t, storage.Delete(ctx, created.ID))
+
+		task, err := storage.GetByID(ctx, created.ID)
+		assert.Error(t, err)
+		assert.Nil(t, task)
+
+		err = storage.Delete(ctx, "non-existing")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "not found")
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("CountAndClear", func(t *testing.T) {
+		storage := newStorage(t, 1000)
+
+		count, err := storage.Count(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, count)
+
+		for i := 0; i < 5; i++ {
+			_, err := storage.Create(ctx, &models.CreateTaskRequest{Name: "Test Task", Status: models.TaskIncomplete})
+			require.NoError(t, err)
+		}
+
+		count, err = storage.Count(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 5, count)
+
+		require.NoError(t, storage.Clear(ctx))
+
+		count, err = storage.Count(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, count)
+
+		tasks, err := storage.GetAll(ctx)
+		assert.NoError(t, err)
+		assert.Empty(t, tasks)
+	})
+
+	t.Run("MaxTasksLimit", func(t *testing.T) {
+		storage := newStorage(t, 3)
+		req := &models.CreateTaskRequest{Name: "Test Task", Status: models.TaskIncomplete}
+
+		var first *models.Task
+		for i := 0; i < 3; i++ {
+			task, err := storage.Create(ctx, req)
+			require.NoError(t, err)
+			if first == nil {
+				first = task
+			}
+		}
+
+		task, err := storage.Create(ctx, req)
+		assert.Error(t, err)
+		assert.Nil(t, task)
+		assert.Contains(t, err.Error(), "maximum tasks limit reached")
+		assert.ErrorIs(t, err, ErrQuotaExceeded)
+
+		require.NoError(t, storage.Delete(ctx, first.ID))
+
+		task, err = storage.Create(ctx, req)
+		assert.NoError(t, err)
+		assert.NotNil(t, task)
+	})
+
+	t.Run("HealthCheck", func(t *testing.T) {
+		storage := newStorage(t, 1000)
+
+		if checker, ok := storage.(interfaces.HealthChecker); ok {
+			assert.NoError(t, checker.HealthCheck(ctx))
+		}
+	})
+
+	t.Run("RichFields", func(t *testing.T) {
+		storage := newStorage(t, 1000)
+		dueDate := time.Date(2030, 1, 2, 15, 4, 5, 0, time.FixedZone("CET", 3600))
+
+		created, err := storage.Create(ctx, &models.CreateTaskRequest{
+			Name:        "Rich Task",
+			Description: "# Heading\n\n- item",
+			Status:      models.TaskIncomplete,
+			Priority:    models.PriorityHigh,
+			DueDate:     &dueDate,
+			Tags:        []string{"Work", "urgent", " work "},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "# Heading\n\n- item", created.Description)
+		assert.Equal(t, models.PriorityHigh, created.Priority)
+		require.NotNil(t, created.DueDate)
+		assert.True(t, created.DueDate.Equal(dueDate))
+		assert.Equal(t, []string{"urgent", "work"}, created.Tags)
+
+		// Returned tags must not alias stored state
+		created.Tags[0] = "mutated"
+
+		fetched, err := storage.GetByID(ctx, created.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "# Heading\n\n- item", fetched.Description)
+		assert.Equal(t, models.PriorityHigh, fetched.Priority)
+		require.NotNil(t, fetched.DueDate)
+		assert.True(t, fetched.DueDate.Equal(dueDate))
+		assert.Equal(t, []string{"urgent", "work"}, fetched.Tags)
+
+		// Partial update leaves untouched fields alone
+		updated, err := storage.Update(ctx, created.ID, &models.UpdateTaskRequest{
+			Priority:     priorityPtr(models.PriorityLow),
+			ClearDueDate: true,
+			Tags:         &[]string{"home"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "Rich Task", updated.Name)
+		assert.Equal(t, "# Heading\n\n- item", updated.Description)
+		assert.Equal(t, models.PriorityLow, updated.Priority)
+		assert.Nil(t, updated.DueDate)
+		assert.Equal(t, []string{"home"}, updated.Tags)
+
+		updated, err = storage.Update(ctx, created.ID, &models.UpdateTaskRequest{Tags: &[]string{}})
+		require.NoError(t, err)
+		assert.Empty(t, updated.Tags)
+
+		fetched, err = storage.GetByID(ctx, created.ID)
+		require.NoError(t, err)
+		assert.Nil(t, fetched.DueDate)
+		assert.Empty(t, fetched.Tags)
+
+		_, err = storage.Create(ctx, &models.CreateTaskRequest{Name: "Bad", Priority: models.TaskPriority(9)})
+		assert.ErrorIs(t, err, ErrValidation)
+		_, err = storage.Create(ctx, &models.CreateTaskRequest{Name: "Bad", Tags: []string{"no spaces"}})
+		assert.ErrorIs(t, err, ErrValidation)
+	})
+
+	t.Run("QueryTasks", func(t *testing.T) {
+		storage := newStorage(t, 1000)
+		querier, ok := storage.(interfaces.TaskQuerier)
+		require.True(t, ok, "storage must support queries")
+
+		soon := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+		later := soon.AddDate(0, 1, 0)
+		requests := []*models.CreateTaskRequest{
+			{Name: "Write report", Priority: models.PriorityHigh, DueDate: &later, Tags: []string{"work"}},
+			{Name: "buy milk", Priority: models.PriorityLow},
+			{Name: "Review REPORT", Priority: models.PriorityHigh, DueDate: &soon, Tags: []string{"work", "review"}},
+			{Name: "Call mom", Priority: models.PriorityMedium, Status: models.TaskCompleted},
+		}
+		names := func(tasks []*models.Task) []string {
+			result := make([]string, len(tasks))
+			for i, task := range tasks {
+				result[i] = task.Name
+			}
+			return result
+		}
+
+		var created []*models.Task
+		for _, req := range requests {
+			task, err := storage.Create(ctx, req)
+			require.NoError(t, err)
+			created = append(created, task)
+			time.Sleep(time.Millisecond) // Distinct creation times
+		}
+
+		tests := []struct {
+			name   string
+			filter models.TaskFilter
+			order  []models.SortField
+			want   []string
+		}{
+			{"creation order by default", models.TaskFilter{}, nil,
+				[]string{"Write report", "buy milk", "Review REPORT", "Call mom"}},
+			{"name contains ignores case", models.TaskFilter{NameContains: "rePort"}, nil,
+				[]string{"Write report", "Review REPORT"}},
+			{"created after", models.TaskFilter{CreatedAfter: &created[1].CreatedAt}, nil,
+				[]string{"Review REPORT", "Call mom"}},
+			{"created before", models.TaskFilter{CreatedBefore: &created[1].CreatedAt}, nil,
+				[]string{"Write report"}},
+			{"status and tag", models.TaskFilter{Status: taskStatusPtr(models.TaskIncomplete), Tags: []string{"work"}}, nil,
+				[]string{"Write report", "Review REPORT"}},
+			{"descending priority then name", models.TaskFilter{},
+				[]models.SortField{{Field: "priority", Desc: true}, {Field: "name"}},
+				[]string{"Review REPORT", "Write report", "Call mom", "buy milk"}},
+			{"undated tasks sort last", models.TaskFilter{}, []models.SortField{{Field: "due_date"}},
+				[]string{"Review REPORT", "Write report", "buy milk", "Call mom"}},
+			{"undated tasks sort first descending", models.TaskFilter{}, []models.SortField{{Field: "due_date", Desc: true}},
+				[]string{"buy milk", "Call mom", "Write report", "Review REPORT"}},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				tasks, err := querier.QueryTasks(ctx, &tt.filter, tt.order)
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, names(tasks))
+			})
+		}
+	})
+
+	t.Run("Ownership", func(t *testing.T) {
+		storage := newStorage(t, 100)
+
+		alice, err := storage.Create(ctx, &models.CreateTaskRequest{Name: "Alice's task", OwnerID: "alice"})
+		require.NoError(t, err)
+		assert.Equal(t, "alice", alice.OwnerID)
+		_, err = storage.Create(ctx, &models.CreateTaskRequest{Name: "Bob's task", OwnerID: "bob"})
+		require.NoError(t, err)
+		_, err = storage.Create(ctx, &models.CreateTaskRequest{Name: "Shared task"})
+		require.NoError(t, err)
+
+		// Updates keep the owner
+		updated, err := storage.Update(ctx, alice.ID, &models.UpdateTaskRequest{Name: stringPtr("Renamed")})
+		require.NoError(t, err)
+		assert.Equal(t, "alice", updated.OwnerID)
+		fetched, err := storage.GetByID(ctx, alice.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "alice", fetched.OwnerID)
+
+		filter := &models.TaskFilter{OwnerID: "alice"}
+		if querier, ok := storage.(interfaces.TaskQuerier); ok {
+			tasks, err := querier.QueryTasks(ctx, filter, nil)
+			require.NoError(t, err)
+			require.Len(t, tasks, 1)
+			assert.Equal(t, alice.ID, tasks[0].ID)
+		}
+		if paginator, ok := storage.(interfaces.CursorPaginator); ok {
+			tasks, more, err := paginator.GetTasksAfter(ctx, &models.TaskFilter{OwnerID: "bob"}, nil, 10)
+			require.NoError(t, err)
+			assert.False(t, more)
+			require.Len(t, tasks, 1)
+			assert.Equal(t, "Bob's task", tasks[0].Name)
+		}
+		if writer, ok := storage.(interfaces.BatchWriter); ok {
+			results, err := writer.ApplyBatch(ctx, []models.BulkOperation{
+				{Op: models.BulkCreate, Task: &models.CreateTaskRequest{Name: "Batched", OwnerID: "carol"}},
+			}, models.BulkAtomic)
+			require.NoError(t, err)
+			require.NoError(t, results[0].Err)
+			assert.Equal(t, "carol", results[0].Task.OwnerID)
+		}
+	})
+
+	t.Run("Pagination", func(t *testing.T) {
+		storage := newStorage(t, 1000)
+		paginator, ok := storage.(interfaces.Paginator)
+		require.True(t, ok, "storage must support offset pagination")
+		cursorPaginator, ok := storage.(interfaces.CursorPaginator)
+		require.True(t, ok, "storage must support cursor pagination")
+
+		var ids []string
+		for i := 0; i < 25; i++ {
+			req := &models.CreateTaskRequest{Name: fmt.Sprintf("Task %d", i)}
+			if i%2 == 0 {
+				req.Tags = []string{"even"}
+			}
+			task, err := storage.Create(ctx, req)
+			require.NoError(t, err)
+			ids = append(ids, task.ID)
+		}
+		idsOf := func(tasks []*models.Task) []string {
+			result := make([]string, len(tasks))
+			for i, task := range tasks {
+				result[i] = task.ID
+			}
+			return result
+		}
+
+		t.Run("offset pages follow creation order", func(t *testing.T) {
+			var paged []string
+			for offset := 0; offset < 30; offset += 10 {
+				tasks, total, err := paginator.GetTasksPaginated(ctx, offset, 10)
+				require.NoError(t, err)
+				assert.Equal(t, 25, total)
+				paged = append(paged, idsOf(tasks)...)
+			}
+			assert.Equal(t, ids, paged)
+		})
+
+		t.Run("cursor pages cover every task once", func(t *testing.T) {
+			var (
+				paged []string
+				after *models.TaskCursor
+			)
+			for page := 0; ; page++ {
+				tasks, more, err := cursorPaginator.GetTasksAfter(ctx, &models.TaskFilter{}, after, 10)
+				require.NoError(t, err)
+				paged = append(paged, idsOf(tasks)...)
+				if !more {
+					assert.Equal(t, 2, page)
+					break
+				}
+				cursor := models.CursorOf(tasks[len(tasks)-1])
+				after = &cursor
+			}
+			assert.Equal(t, ids, paged)
+		})
+
+		t.Run("cursor pages apply the filter", func(t *testing.T) {
+			filter := &models.TaskFilter{Tags: []string{"even"}}
+			tasks, more, err := cursorPaginator.GetTasksAfter(ctx, filter, nil, 12)
+			require.NoError(t, err)
+			assert.True(t, more)
+			require.Len(t, tasks, 12)
+			assert.Equal(t, ids[22], tasks[11].ID)
+
+			cursor := models.CursorOf(tasks[11])
+			tasks, more, err = cursorPaginator.GetTasksAfter(ctx, filter, &cursor, 12)
+			require.NoError(t, err)
+			assert.False(t, more)
+			assert.Equal(t, []string{ids[24]}, idsOf(tasks))
+		})
+
+		t.Run("cursor survives changes before it", func(t *testing.T) {
+			first, _, err := cursorPaginator.GetTasksAfter(ctx, &models.TaskFilter{}, nil, 5)
+			require.NoError(t, err)
+			cursor := models.CursorOf(first[4])
+
+			// Removing the cursor task itself and earlier tasks does not shift the next page
+			require.NoError(t, storage.Delete(ctx, first[0].ID))
+			require.NoError(t, storage.Delete(ctx, first[4].ID))
+
+			next, more, err := cursorPaginator.GetTasksAfter(ctx, &models.TaskFilter{}, &cursor, 5)
+			require.NoError(t, err)
+			assert.True(t, more)
+			assert.Equal(t, ids[5:10], idsOf(next))
+		})
+	})
+
+	t.Run("Search", func(t *testing.T) {
+		storage := newStorage(t, 1000)
+		searcher, ok := storage.(interfaces.TaskSearcher)
+		require.True(t, ok, "storage must support full-text search")
+
+		search := func(t *testing.T, query string) []string {
+			t.Helper()
+			results, err := searcher.SearchTasks(ctx, query, 10)
+			require.NoError(t, err)
+			names := make([]string, len(results))
+			for i, result := range results {
+				assert.Positive(t, result.Score)
+				names[i] = result.Task.Name
+			}
+			return names
+		}
+
+		report, err := storage.Create(ctx, &models.CreateTaskRequest{Name: "Quarterly report", Description: "Numbers for the board"})
+		require.NoError(t, err)
+		_, err = storage.Create(ctx, &models.CreateTaskRequest{Name: "Board meeting", Description: "Present the *quarterly* report"})
+		require.NoError(t, err)
+		milk, err := storage.Create(ctx, &models.CreateTaskRequest{Name: "Buy milk"})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"Quarterly report", "Board meeting"}, search(t, "REPORT"))
+		assert.Equal(t, []string{"Quarterly report", "Board meeting"}, search(t, "quart rep"))
+		assert.Empty(t, search(t, "taxes"))
+
+		// Updates, deletes and batches keep the index current
+		_, err = storage.Update(ctx, report.ID, &models.UpdateTaskRequest{Name: stringPtr("Annual summary")})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Board meeting"}, search(t, "report"))
+		assert.Equal(t, []string{"Annual summary"}, search(t, "annual"))
+
+		require.NoError(t, storage.Delete(ctx, milk.ID))
+		assert.Empty(t, search(t, "milk"))
+
+		if writer, ok := storage.(interfaces.BatchWriter); ok {
+			_, err := writer.ApplyBatch(ctx, []models.BulkOperation{
+				{Op: models.BulkCreate, Task: &models.CreateTaskRequest{Name: "Buy oat milk"}},
+				{Op: models.BulkDelete, ID: report.ID},
+			}, models.BulkAtomic)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"Buy oat milk"}, search(t, "milk"))
+			assert.Empty(t, search(t, "annual"))
+		}
+
+		require.NoError(t, storage.Clear(ctx))
+		assert.Empty(t, search(t, "board"))
+	})
+
+	t.Run("WorkflowTransitions", func(t *testing.T) {
+		useTestWorkflow(t)
+		storage := newStorage(t, 1000)
+
+		created, err := storage.Create(ctx, &models.CreateTaskRequest{Name: "Flow Task"})
+		require.NoError(t, err)
+		assert.Equal(t, "todo", created.Status.String())
+
+		// Skipping straight to done is not an allowed edge
+		done, _ := models.ParseTaskStatus("done")
+		_, err = storage.Update(ctx, created.ID, &models.UpdateTaskRequest{Name: stringPtr("Renamed"), Status: &done})
+		assert.ErrorIs(t, err, ErrConflict)
+		assert.ErrorIs(t, err, models.ErrInvalidTransition)
+
+		fetched, err := storage.GetByID(ctx, created.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Flow Task", fetched.Name)
+		assert.Equal(t, "todo", fetched.Status.String())
+
+		for _, name := range []string{"in-progress", "review", "done"} {
+			status, err := models.ParseTaskStatus(name)
+			require.NoError(t, err)
+			updated, err := storage.Update(ctx, created.ID, &models.UpdateTaskRequest{Status: &status})
+			require.NoError(t, err, "transition to %s", name)
+			assert.Equal(t, name, updated.Status.String())
+		}
+
+		// Terminal states have no outgoing edges in this workflow
+		todo, _ := models.ParseTaskStatus("todo")
+		_, err = storage.Update(ctx, created.ID, &models.UpdateTaskRequest{Status: &todo})
+		assert.ErrorIs(t, err, models.ErrInvalidTransition)
+
+		if statsProvider, ok := storage.(interfaces.StatsProvider); ok {
+			_, err = storage.Create(ctx, &models.CreateTaskRequest{Name: "Open Task"})
+			require.NoError(t, err)
+
+			stats, err := statsProvider.GetStats(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, 1, stats.CompletedTasks)
+			assert.Equal(t, 1, stats.IncompleteTasks)
+		}
+	})
+
+	t.Run("WorkflowInitialStatus", func(t *testing.T) {
+		useTestWorkflow(t)
+		storage := newStorage(t, 1000)
+
+		// New tasks start in the initial state or one step from it, never further along
+		done, _ := models.ParseTaskStatus("done")
+		_, err := storage.Create(ctx, &models.CreateTaskRequest{Name: "Finished Task", Status: done})
+		assert.ErrorIs(t, err, ErrConflict)
+		assert.ErrorIs(t, err, models.ErrInvalidTransition)
+
+		inProgress, _ := models.ParseTaskStatus("in-progress")
+		created, err := storage.Create(ctx, &models.CreateTaskRequest{Name: "Started Task", Status: inProgress})
+		require.NoError(t, err)
+		assert.Equal(t, "in-progress", created.Status.String())
+
+		if writer, ok := storage.(interfaces.BatchWriter); ok {
+			results, err := writer.ApplyBatch(ctx, []models.BulkOperation{
+				{Op: models.BulkCreate, Task: &models.CreateTaskRequest{Name: "Finished Task", Status: done}},
+			}, models.BulkBestEffort)
+			require.NoError(t, err)
+			require.Len(t, results, 1)
+			assert.ErrorIs(t, results[0].Err, models.ErrInvalidTransition)
+		}
+
+		tasks, err := storage.GetAll(ctx)
+		require.NoError(t, err)
+		assert.Len(t, tasks, 1)
+	})
+
+	t.Run("History", func(t *testing.T) {
+		storage := newStorage(t, 1000)
+		historian, ok := storage.(interfaces.HistoryProvider)
+		require.True(t, ok, "storage must keep task history")
+
+		actorCtx := models.WithActor(ctx, "alice")
+		created, err := storage.Create(actorCtx, &models.CreateTaskRequest{Name: "Draft", Tags: []string{"a"}})
+		require.NoError(t, err)
+		assert.Equal(t, 1, created.Version)
+
+		updated, err := storage.Update(ctx, created.ID, &models.UpdateTaskRequest{
+			Name:     stringPtr("Final"),
+			Priority: priorityPtr(models.PriorityHigh),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 2, updated.Version)
+
+		history, err := historian.GetHistory(ctx, created.ID)
+		require.NoError(t, err)
+		require.Len(t, history, 2)
+		assert.Equal(t, models.RevisionCreate, history[0].Action)
+		assert.Equal(t, "alice", history[0].Actor)
+		assert.Equal(t, models.RevisionUpdate, history[1].Action)
+		assert.Equal(t, models.AnonymousActor, history[1].Actor)
+		require.Len(t, history[1].Changes, 2)
+		assert.Equal(t, "name", history[1].Changes[0].Field)
+		assert.Equal(t, "priority", history[1].Changes[1].Field)
+
+		first, err := historian.GetVersion(ctx, created.ID, 1)
+		require.NoError(t, err)
+		assert.Equal(t, "Draft", first.Task.Name)
+		assert.Equal(t, []string{"a"}, first.Task.Tags)
+
+		_, err = historian.GetVersion(ctx, created.ID, 9)
+		assert.ErrorIs(t, err, ErrNotFound)
+		_, err = historian.GetHistory(ctx, "non-existent-id")
+		assert.ErrorIs(t, err, ErrNotFound)
+
+		reverted, err := historian.Revert(ctx, created.ID, 1)
+		require.NoError(t, err)
+		assert.Equal(t, 3, reverted.Version)
+		assert.Equal(t, "Draft", reverted.Name)
+		assert.Equal(t, models.PriorityLow, reverted.Priority)
+
+		latest, err := historian.GetVersion(ctx, created.ID, 3)
+		require.NoError(t, err)
+		assert.Equal(t, models.RevisionRevert, latest.Action)
+		assert.Equal(t, 1, latest.RevertedFrom)
+
+		_, err = historian.Revert(ctx, created.ID, 7)
+		assert.ErrorIs(t, err, ErrNotFound)
+
+		// History goes away with its task
+		require.NoError(t, storage.Delete(ctx, created.ID))
+		_, err = historian.GetHistory(ctx, created.ID)
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("RevertRespectsWorkflow", func(t *testing.T) {
+		useTestWorkflow(t)
+		storage := newStorage(t, 1000)
+		historian, ok := storage.(interfaces.HistoryProvider)
+		require.True(t, ok, "storage must keep task history")
+
+		created, err := storage.Create(ctx, &models.CreateTaskRequest{Name: "Flow Task"})
+		require.NoError(t, err)
+		for _, name := range []string{"in-progress", "review", "done"} {
+			status, _ := models.ParseTaskStatus(name)
+			_, err := storage.Update(ctx, created.ID, &models.UpdateTaskRequest{Status: &status})
+			require.NoError(t, err)
+		}
+
+		// Going back to "todo" from a terminal state is not an allowed edge
+		_, err = historian.Revert(ctx, created.ID, 1)
+		assert.ErrorIs(t, err, models.ErrInvalidTransition)
+
+		history, err := historian.GetHistory(ctx, created.ID)
+		require.NoError(t, err)
+		assert.Len(t, history, 4)
+	})
+
+	t.Run("ConditionalWrites", func(t *testing.T) {
+		storage := newStorage(t, 1000)
+		writer, ok := storage.(interfaces.ConditionalWriter)
+		require.True(t, ok, "storage must support conditional writes")
+
+		created, err := storage.Create(ctx, &models.CreateTaskRequest{Name: "Contended"})
+		require.NoError(t, err)
+
+		// Only one of several writers holding the same version may win
+		const writers = 8
+		var (
+			wg        sync.WaitGroup
+			mu        sync.Mutex
+			succeeded int
+			rejected  int
+		)
+		wg.Add(writers)
+		for i := 0; i < writers; i++ {
+			go func(i int) {
+				defer wg.Done()
+				_, err := writer.UpdateIfVersion(ctx, created.ID, created.Version,
+					&models.UpdateTaskRequest{Name: stringPtr("Writer " + strconv.Itoa(i))})
+				mu.Lock()
+				defer mu.Unlock()
+				if err == nil {
+					succeeded++
+				} else if assert.ErrorIs(t, err, ErrPreconditionFailed) {
+					rejected++
+				}
+			}(i)
+		}
+		wg.Wait()
+		assert.Equal(t, 1, succeeded)
+		assert.Equal(t, writers-1, rejected)
+
+		current, err := storage.GetByID(ctx, created.ID)
+		require.NoError(t, err)
+		assert.Equal(t, created.Version+1, current.Version)
+
+		_, err = writer.UpdateIfVersion(ctx, "non-existent-id", 1, &models.UpdateTaskRequest{Name: stringPtr("x")})
+		assert.ErrorIs(t, err, ErrNotFound)
+
+		assert.ErrorIs(t, writer.DeleteIfVersion(ctx, created.ID, created.Version), ErrPreconditionFailed)
+		_, err = storage.GetByID(ctx, created.ID)
+		require.NoError(t, err, "a failed conditional delete must keep the task")
+
+		require.NoError(t, writer.DeleteIfVersion(ctx, created.ID, current.Version))
+		assert.ErrorIs(t, writer.DeleteIfVersion(ctx, created.ID, current.Version), ErrNotFound)
+	})
+
+	t.Run("Batch", func(t *testing.T) {
+		storage := newStorage(t, 4)
+		writer, ok := storage.(interfaces.BatchWriter)
+		require.True(t, ok, "storage must support batches")
+
+		existing, err := storage.Create(ctx, &models.CreateTaskRequest{Name: "Existing"})
+		require.NoError(t, err)
+
+		// An atomic batch with one failing operation applies nothing
+		results, err := writer.ApplyBatch(ctx, []models.BulkOperation{
+			{Op: models.BulkCreate, Task: &models.CreateTaskRequest{Name: "Never stored"}},
+			{Op: models.BulkUpdate, ID: existing.ID, Changes: &models.UpdateTaskRequest{Name: stringPtr("Never renamed")}},
+			{Op: models.BulkDelete, ID: "non-existent-id"},
+		}, models.BulkAtomic)
+		require.NoError(t, err)
+		require.Len(t, results, 3)
+		assert.ErrorIs(t, results[0].Err, ErrBatchAborted)
+		assert.ErrorIs(t, results[1].Err, ErrBatchAborted)
+		assert.ErrorIs(t, results[2].Err, ErrNotFound)
+
+		count, err := storage.Count(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+		unchanged, err := storage.GetByID(ctx, existing.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Existing", unchanged.Name)
+
+		// Later operations see earlier ones, and the task limit counts the batch's own creates
+		results, err = writer.ApplyBatch(ctx, []models.BulkOperation{
+			{Op: models.BulkCreate, Task: &models.CreateTaskRequest{Name: "First"}},
+			{Op: models.BulkCreate, Task: &models.CreateTaskRequest{Name: ""}},
+			{Op: models.BulkUpdate, ID: existing.ID, Version: existing.Version, Changes: &models.UpdateTaskRequest{Name: stringPtr("Renamed")}},
+			{Op: models.BulkUpdate, ID: existing.ID, Version: existing.Version, Changes: &models.UpdateTaskRequest{Name: stringPtr("Stale")}},
+			{Op: models.BulkCreate, Task: &models.CreateTaskRequest{Name: "Second"}},
+			{Op: models.BulkCreate, Task: &models.CreateTaskRequest{Name: "Third"}},
+			{Op: models.BulkCreate, Task: &models.CreateTaskRequest{Name: "Over limit"}},
+			{Op: models.BulkDelete, ID: existing.ID},
+			{Op: models.BulkCreate, Task: &models.CreateTaskRequest{Name: "Fits again"}},
+		}, models.BulkBestEffort)
+		require.NoError(t, err)
+		require.Len(t, results, 9)
+		assert.NoError(t, results[0].Err)
+		assert.ErrorIs(t, results[1].Err, ErrValidation)
+		require.NoError(t, results[2].Err)
+		assert.Equal(t, existing.Version+1, results[2].Task.Version)
+		assert.ErrorIs(t, results[3].Err, ErrPreconditionFailed)
+		assert.NoError(t, results[4].Err)
+		assert.NoError(t, results[5].Err)
+		assert.ErrorIs(t, results[6].Err, ErrQuotaExceeded)
+		assert.NoError(t, results[7].Err)
+		assert.NoError(t, results[8].Err)
+
+		tasks, err := storage.GetAll(ctx)
+		require.NoError(t, err)
+		names := make([]string, 0, len(tasks))
+		for _, task := range tasks {
+			names = append(names, task.Name)
+		}
+		assert.ElementsMatch(t, []string{"First", "Second", "Third", "Fits again"}, names)
+
+		created, err := storage.GetByID(ctx, results[0].Task.ID)
+		require.NoError(t, err)
+		assert.Equal(t, results[0].Task.Name, created.Name)
+		_, err = storage.GetByID(ctx, existing.ID)
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("ChangeEvents", func(t *testing.T) {
+		storage := newStorage(t, 1000)
+		notifier, ok := storage.(interfaces.ChangeNotifier)
+		require.True(t, ok, "storage must report changes")
+		recorder := &eventRecorder{}
+		notifier.SetEventPublisher(recorder)
+
+		actorCtx := models.WithActor(ctx, "alice")
+		task, err := storage.Create(actorCtx, &models.CreateTaskRequest{Name: "Watched"})
+		require.NoError(t, err)
+		_, err = storage.Update(ctx, task.ID, &models.UpdateTaskRequest{Name: stringPtr("Renamed")})
+		require.NoError(t, err)
+		if reverter, ok := storage.(interfaces.HistoryProvider); ok {
+			_, err = reverter.Revert(ctx, task.ID, 1)
+			require.NoError(t, err)
+		}
+		require.NoError(t, storage.Delete(actorCtx, task.ID))
+
+		// Failed writes report nothing
+		_, err = storage.Update(ctx, "non-existent-id", &models.UpdateTaskRequest{Name: stringPtr("Nope")})
+		require.Error(t, err)
+
+		events := recorder.all()
+		require.Len(t, events, 4)
+		assert.Equal(t, models.TaskCreated, events[0].Type)
+		assert.Equal(t, "alice", events[0].Actor)
+		assert.Equal(t, "Watched", events[0].Task.Name)
+		assert.Nil(t, events[0].Previous)
+		assert.Equal(t, models.TaskUpdated, events[1].Type)
+		assert.Equal(t, models.AnonymousActor, events[1].Actor)
+		assert.Equal(t, "Renamed", events[1].Task.Name)
+		assert.Equal(t, "Watched", events[1].Previous.Name)
+		assert.Equal(t, models.TaskUpdated, events[2].Type)
+		assert.Equal(t, "Watched", events[2].Task.Name)
+		assert.Equal(t, models.TaskDeleted, events[3].Type)
+		assert.Equal(t, "alice", events[3].Actor)
+		assert.Nil(t, events[3].Task)
+		require.NotNil(t, events[3].Previous)
+		assert.Equal(t, "Watched", events[3].Previous.Name)
+		for i, event := range events {
+			assert.Equal(t, task.ID, event.TaskID)
+			assert.Equal(t, min(i+1, 3), event.Version)
+		}
+
+		// Batches report their applied operations in order, and nothing when aborted
+		writer, ok := storage.(interfaces.BatchWriter)
+		require.True(t, ok, "storage must support batches")
+		existing, err := storage.Create(ctx, &models.CreateTaskRequest{Name: "Existing"})
+		require.NoError(t, err)
+		recorder.reset()
+
+		_, err = writer.ApplyBatch(ctx, []models.BulkOperation{
+			{Op: models.BulkCreate, Task: &models.CreateTaskRequest{Name: "Never stored"}},
+			{Op: models.BulkDelete, ID: "non-existent-id"},
+		}, models.BulkAtomic)
+		require.NoError(t, err)
+		assert.Empty(t, recorder.all())
+
+		results, err := writer.ApplyBatch(actorCtx, []models.BulkOperation{
+			{Op: models.BulkCreate, Task: &models.CreateTaskRequest{Name: "Batched"}},
+			{Op: models.BulkDelete, ID: "non-existent-id"},
+			{Op: models.BulkUpdate, ID: existing.ID, Changes: &models.UpdateTaskRequest{Name: stringPtr("Changed")}},
+			{Op: models.BulkDelete, ID: existing.ID},
+		}, models.BulkBestEffort)
+		require.NoError(t, err)
+
+		events = recorder.all()
+		require.Len(t, events, 3)
+		assert.Equal(t, models.TaskCreated, events[0].Type)
+		assert.Equal(t, results[0].Task.ID, events[0].TaskID)
+		assert.Equal(t, models.TaskUpdated, events[1].Type)
+		assert.Equal(t, 2, events[1].Version)
+		assert.Equal(t, models.TaskDeleted, events[2].Type)
+		assert.Equal(t, existing.ID, events[2].TaskID)
+		assert.Equal(t, 2, events[2].Version)
+		assert.Equal(t, "Changed", events[2].Previous.Name)
+		for _, event := range events {
+			assert.Equal(t, "alice", event.Actor)
+		}
+	})
+
+	t.Run("Cancellation", func(t *testing.T) {
+		storage := newStorage(t, 1000)
+
+		created, err := storage.Create(ctx, &models.CreateTaskRequest{Name: "Test Task", Status: models.TaskIncomplete})
+		require.NoError(t, err)
+
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+
+		_, err = storage.GetAll(cancelled)
+		assert.ErrorIs(t, err, context.Canceled)
+		_, err = storage.GetByID(cancelled, created.ID)
+		assert.ErrorIs(t, err, context.Canceled)
+		_, err = storage.Create(cancelled, &models.CreateTaskRequest{Name: "Late Task", Status: models.TaskIncomplete})
+		assert.ErrorIs(t, err, context.Canceled)
+		_, err = storage.Update(cancelled, created.ID, &models.UpdateTaskRequest{Name: stringPtr("Late")})
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.ErrorIs(t, storage.Delete(cancelled, created.ID), context.Canceled)
+		_, err = storage.Count(cancelled)
+		assert.ErrorIs(t, err, context.Canceled)
+
+		// A cancelled call must not have changed anything
+		count, err := storage.Count(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+		task, err := storage.GetByID(ctx, created.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Test Task", task.Name)
+
+		expired, cancelExpired := context.WithDeadline(ctx, time.Now().Add(-time.Second))
+		defer cancelExpired()
+
+		_, err = storage.GetAll(expired)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("ConcurrentOperations", func(t *testing.T) {
+		storage := newStorage(t, 1000)
+		const numGoroutines = 10
+		const operationsPerGoroutine = 20
+
+		var wg sync.WaitGroup
+		wg.Add(numGoroutines)
+		for i := 0; i < numGoroutines; i++ {
+			go func(id int) {
+				defer wg.Done()
+				for j := 0; j < operationsPerGoroutine; j++ {
+					task, err := storage.Create(ctx, &models.CreateTaskRequest{
+						Name:   "Task " + strconv.Itoa(id) + "-" + strconv.Itoa(j),
+						Status: models.TaskIncomplete,
+					})
+					if !assert.NoError(t, err) {
+						return
+					}
+					_, err = storage.Update(ctx, task.ID, &models.UpdateTaskRequest{Status: taskStatusPtr(models.TaskCompleted)})
+					assert.NoError(t, err)
+					_, err = storage.GetAll(ctx)
+					assert.NoError(t, err)
+				}
+			}(i)
+		}
+		wg.Wait()
+
+		count, err := storage.Count(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, numGoroutines*operationsPerGoroutine, count)
+	})
+}
+
+// useTestWorkflow installs a todo -> in-progress -> review -> done workflow for the rest of the test
+func useTestWorkflow(t *testing.T) {
+	t.Helper()
+
+	workflow, err := models.NewWorkflow([]models.WorkflowState{
+		{Name: "todo", Transitions: []string{"in-progress"}},
+		{Name: "in-progress", Transitions: []string{"todo", "review"}},
+		{Name: "review", Transitions: []string{"in-progress", "done"}},
+		{Name: "done", Terminal: true},
+	})
+	require.NoError(t, err)
+
+	previous := models.CurrentWorkflow()
+	models.SetWorkflow(workflow)
+	t.Cleanup(func() { models.SetWorkflow(previous) })
+}
+
+// checkRevisionLimit checks that a storage configured to keep three revisions per task
+// drops the oldest ones, whether the task is updated, reverted or changed in a batch
+func checkRevisionLimit(t *testing.T, storage interfaces.TaskStorage) {
+	t.Helper()
+	ctx := context.Background()
+	historian, ok := storage.(interfaces.HistoryProvider)
+	require.True(t, ok, "storage must keep task history")
+	writer, ok := storage.(interfaces.BatchWriter)
+	require.True(t, ok, "storage must support batches")
+
+	created, err := storage.Create(ctx, &models.CreateTaskRequest{Name: "v1"})
+	require.NoError(t, err)
+	for _, name := range []string{"v2", "v3", "v4"} {
+		_, err := storage.Update(ctx, created.ID, &models.UpdateTaskRequest{Name: stringPtr(name)})
+		require.NoError(t, err)
+	}
+	results, err := writer.ApplyBatch(ctx, []models.BulkOperation{
+		{Op: models.BulkUpdate, ID: created.ID, Changes: &models.UpdateTaskRequest{Name: stringPtr("v5")}},
+	}, models.BulkAtomic)
+	require.NoError(t, err)
+	require.NoError(t, results[0].Err)
+
+	history, err := historian.GetHistory(ctx, created.ID)
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+	assert.Equal(t, []int{3, 4, 5}, []int{history[0].Version, history[1].Version, history[2].Version})
+
+	// Dropped versions are gone, the kept ones can still be read and reverted to
+	_, err = historian.GetVersion(ctx, created.ID, 2)
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = historian.Revert(ctx, created.ID, 1)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	reverted, err := historian.Revert(ctx, created.ID, 3)
+	require.NoError(t, err)
+	assert.Equal(t, "v3", reverted.Name)
+	assert.Equal(t, 6, reverted.Version)
+
+	history, err = historian.GetHistory(ctx, created.ID)
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+	assert.Equal(t, 4, history[0].Version)
+	assert.Equal(t, models.RevisionRevert, history[2].Action)
+}
+
+func TestMemoryStorage_Contract(t *testing.T) {
+	runStorageContract(t, func(t *testing.T, maxTasks int) interfaces.TaskStorage {
+		return NewMemoryStorage(maxTasks)
+	})
+}
+
+func TestMemoryStorage_RevisionLimit(t *testing.T) {
+	storage := NewMemoryStorage(100)
+	storage.SetMaxRevisions(3)
+	checkRevisionLimit(t, storage)
+}
+
+// eventRecorder is an EventPublisher that keeps every event it receives
+type eventRecorder struct {
+	events []models.TaskEvent
+	mutex  sync.Mutex
+}
+
+func (r *eventRecorder) Publish(event models.TaskEvent) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.events = append(r.events, event)
+}
+
+// all returns the events received so far
+func (r *eventRecorder) all() []models.TaskEvent {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return append([]models.TaskEvent(nil), r.events...)
+}
+
+// reset forgets the events received so far
+func (r *eventRecorder) reset() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.events = nil
+}
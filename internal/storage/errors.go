@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"task-api/internal/models"
+)
+
+// Sentinel errors returned (wrapped) by every storage backend.
+// Callers should match them with errors.Is rather than inspecting messages.
+var (
+	ErrNotFound           = errors.New("not found")                   // The requested task does not exist
+	ErrValidation         = errors.New("validation failed")           // The request was rejected by model validation
+	ErrQuotaExceeded      = errors.New("maximum tasks limit reached") // The storage task limit has been reached
+	ErrConflict           = errors.New("conflict")                    // The write conflicts with the current state
+	ErrPreconditionFailed = errors.New("precondition failed")         // A conditional write found a different task version
+	ErrBatchAborted       = errors.New("batch aborted")               // Another operation of an atomic batch failed
+)
+
+// notFoundError reports a missing task
+func notFoundError(id string) error {
+	return fmt.Errorf("task with ID %s %w", id, ErrNotFound)
+}
+
+// validationError wraps a model validation failure
+func validationError(err error) error {
+	return fmt.Errorf("%w: %w", ErrValidation, err)
+}
+
+// checkCreate validates a create request, reporting a status new tasks cannot start in as a
+// workflow violation
+func checkCreate(req *models.CreateTaskRequest) error {
+	if err := req.Validate(); err != nil {
+		return validationError(err)
+	}
+	if err := models.CurrentWorkflow().CheckInitial(req.Status); err != nil {
+		return transitionError(err)
+	}
+	return nil
+}
+
+// quotaExceededError reports that maxTasks has been reached
+func quotaExceededError(maxTasks int) error {
+	return fmt.Errorf("%w (%d)", ErrQuotaExceeded, maxTasks)
+}
+
+// noUpdatesError reports an update request without any fields set
+func noUpdatesError() error {
+	return fmt.Errorf("%w: no updates provided", ErrValidation)
+}
+
+// transitionError marks a workflow violation as a conflict with the task's current status
+func transitionError(err error) error {
+	return fmt.Errorf("%w: %w", ErrConflict, err)
+}
+
+// versionNotFoundError reports a task version without a stored revision
+func versionNotFoundError(id string, version int) error {
+	return fmt.Errorf("version %d of task %s %w", version, id, ErrNotFound)
+}
+
+// versionMismatchError reports a conditional write against a task that has changed since it was read
+func versionMismatchError(id string, expected, actual int) error {
+	return fmt.Errorf("%w: task %s is at version %d, expected %d", ErrPreconditionFailed, id, actual, expected)
+}
+
+// batchAbortedError reports an operation skipped because another operation of an atomic batch failed
+func batchAbortedError(failed int) error {
+	return fmt.Errorf("%w: operation %d failed", ErrBatchAborted, failed)
+}
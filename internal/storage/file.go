@@ -0,0 +1,750 @@
+package storage
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"task-api/internal/interfaces"
+	"task-api/internal/models"
+	"time"
+)
+
+const (
+	walFileName      = "tasks.wal"      // Write-ahead log file name inside the data directory
+	snapshotFileName = "tasks.snapshot" // Snapshot file name inside the data directory
+	walHeaderSize    = 8                // 4-byte payload length + 4-byte CRC32 checksum
+	maxWALRecordSize = 16 << 20         // Upper bound for a single record, guards against corrupt lengths
+)
+
+// walOp identifies the kind of mutation recorded in the write-ahead log
+type walOp string
+
+const (
+	walOpPut    walOp = "put"    // Task was created, updated or reverted (full task state and its revision)
+	walOpDelete walOp = "delete" // Task was deleted
+	walOpClear  walOp = "clear"  // All tasks were removed
+	walOpBatch  walOp = "batch"  // Several records applied together (a bulk request)
+)
+
+// walRecord represents a single entry in the write-ahead log
+type walRecord struct {
+	Op       walOp                `json:"op"`
+	Task     *models.Task         `json:"task,omitempty"`
+	Revision *models.TaskRevision `json:"revision,omitempty"`
+	ID       string               `json:"id,omitempty"`
+	Batch    []walRecord          `json:"batch,omitempty"` // Records of a batch, in apply order
+}
+
+// snapshot represents the on-disk format of a compacted snapshot
+type snapshot struct {
+	CreatedAt time.Time                         `json:"created_at"`
+	Tasks     []*models.Task                    `json:"tasks"`
+	History   map[string][]*models.TaskRevision `json:"history,omitempty"` // Revisions keyed by task ID
+}
+
+// FileStorageConfig defines configuration for the file-backed storage
+type FileStorageConfig struct {
+	DataDir          string // Directory holding the snapshot and write-ahead log
+	MaxTasks         int    // Maximum number of tasks allowed
+	MaxRevisions     int    // Maximum number of revisions kept per task, oldest dropped first
+	SnapshotInterval int    // Number of log records written before the log is compacted into a snapshot
+	SyncWrites       bool   // fsync the log after every record for durability across power loss
+}
+
+// FileStorage implements TaskStorage interface with durable on-disk persistence
+// Every mutation is appended to a write-ahead log before it is acknowledged, the log is
+// periodically compacted into a snapshot, and snapshot+log are replayed on startup.
+// Reads are served from an in-memory sharded index.
+type FileStorage struct {
+	mem        *MemoryStorage    // In-memory index serving all reads
+	config     FileStorageConfig // Storage configuration
+	mu         sync.Mutex        // Serializes mutations so log order matches apply order
+	wal        *os.File          // Open write-ahead log (append only)
+	walSize    int64             // Size of the log after the last successful append
+	walRecords int               // Records appended since the last snapshot
+	closed     bool              // Whether Close has been called
+
+	// Changes are reported once logged; the in-memory index has no publisher because its
+	// changes may still be rolled back
+	changeNotifier
+}
+
+// Ensure FileStorage implements required interfaces at compile time
+var (
+	_ interfaces.TaskStorage       = (*FileStorage)(nil)
+	_ interfaces.HealthChecker     = (*FileStorage)(nil)
+	_ interfaces.StatusQuerier     = (*FileStorage)(nil)
+	_ interfaces.TaskQuerier       = (*FileStorage)(nil)
+	_ interfaces.Paginator         = (*FileStorage)(nil)
+	_ interfaces.CursorPaginator   = (*FileStorage)(nil)
+	_ interfaces.TaskSearcher      = (*FileStorage)(nil)
+	_ interfaces.StatsProvider     = (*FileStorage)(nil)
+	_ interfaces.UsageReporter     = (*FileStorage)(nil)
+	_ interfaces.HistoryProvider   = (*FileStorage)(nil)
+	_ interfaces.ConditionalWriter = (*FileStorage)(nil)
+	_ interfaces.BatchWriter       = (*FileStorage)(nil)
+	_ interfaces.ChangeNotifier    = (*FileStorage)(nil)
+)
+
+// NewFileStorage creates a file-backed storage, recovering any state found in the data directory
+func NewFileStorage(config FileStorageConfig) (*FileStorage, error) {
+	if config.DataDir == "" {
+		return nil, fmt.Errorf("data directory is required for file storage")
+	}
+	if config.SnapshotInterval <= 0 {
+		config.SnapshotInterval = 1000 // Default value
+	}
+
+	if err := os.MkdirAll(config.DataDir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	fs := &FileStorage{
+		mem:    NewMemoryStorage(config.MaxTasks),
+		config: config,
+	}
+	fs.config.MaxTasks = fs.mem.GetMaxTasks()
+	fs.mem.SetMaxRevisions(config.MaxRevisions)
+
+	if err := fs.loadSnapshot(); err != nil {
+		return nil, err
+	}
+
+	replayed, validSize, err := fs.replayWAL()
+	if err != nil {
+		return nil, err
+	}
+
+	wal, err := os.OpenFile(fs.walPath(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open write-ahead log: %w", err)
+	}
+
+	fs.wal = wal
+	fs.walSize = validSize
+	fs.walRecords = replayed
+
+	return fs, nil
+}
+
+// walPath returns the path of the write-ahead log
+func (fs *FileStorage) walPath() string {
+	return filepath.Join(fs.config.DataDir, walFileName)
+}
+
+// snapshotPath returns the path of the snapshot file
+func (fs *FileStorage) snapshotPath() string {
+	return filepath.Join(fs.config.DataDir, snapshotFileName)
+}
+
+// loadSnapshot restores the in-memory index from the latest snapshot, if any
+func (fs *FileStorage) loadSnapshot() error {
+	data, err := os.ReadFile(fs.snapshotPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	var snap snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return fmt.Errorf("failed to decode snapshot: %w", err)
+	}
+
+	for _, task := range snap.Tasks {
+		if task != nil && task.ID != "" {
+			fs.mem.restoreTask(task)
+			fs.mem.restoreRevisions(task.ID, snap.History[task.ID])
+		}
+	}
+
+	return nil
+}
+
+// replayWAL applies every intact log record to the in-memory index
+// A torn or corrupt record marks the end of the log: everything from that point on was
+// never acknowledged, so the file is truncated back to the last intact record.
+// Returns the number of records applied and the size of the intact log.
+func (fs *FileStorage) replayWAL() (int, int64, error) {
+	file, err := os.OpenFile(fs.walPath(), os.O_RDWR, 0o600)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, 0, nil
+	}
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to open write-ahead log: %w", err)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	var (
+		offset  int64
+		applied int
+		readErr error
+	)
+
+	for {
+		record, size, err := readWALRecord(reader)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			readErr = err
+			break
+		}
+
+		fs.applyRecord(record)
+		offset += size
+		applied++
+	}
+
+	if readErr != nil {
+		log.Printf("Write-ahead log is damaged after %d records (%v), truncating to %d bytes", applied, readErr, offset)
+		if err := file.Truncate(offset); err != nil {
+			return 0, 0, fmt.Errorf("failed to truncate damaged write-ahead log: %w", err)
+		}
+		if err := file.Sync(); err != nil {
+			return 0, 0, fmt.Errorf("failed to sync write-ahead log: %w", err)
+		}
+	}
+
+	return applied, offset, nil
+}
+
+// readWALRecord reads one framed record, returning io.EOF only on a clean record boundary
+func readWALRecord(reader io.Reader) (*walRecord, int64, error) {
+	header := make([]byte, walHeaderSize)
+	n, err := io.ReadFull(reader, header)
+	if err == io.EOF {
+		return nil, 0, io.EOF
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("torn record header (%d of %d bytes)", n, walHeaderSize)
+	}
+
+	length := binary.LittleEndian.Uint32(header[0:4])
+	checksum := binary.LittleEndian.Uint32(header[4:8])
+	if length == 0 || length > maxWALRecordSize {
+		return nil, 0, fmt.Errorf("invalid record length %d", length)
+	}
+
+	payload := make([]byte, length)
+	if n, err := io.ReadFull(reader, payload); err != nil {
+		return nil, 0, fmt.Errorf("torn record payload (%d of %d bytes)", n, length)
+	}
+
+	if crc32.ChecksumIEEE(payload) != checksum {
+		return nil, 0, fmt.Errorf("record checksum mismatch")
+	}
+
+	var record walRecord
+	if err := json.Unmarshal(payload, &record); err != nil {
+		return nil, 0, fmt.Errorf("failed to decode record: %w", err)
+	}
+
+	return &record, int64(walHeaderSize) + int64(length), nil
+}
+
+// encodeWALRecord frames a record as length + CRC32 + JSON payload
+func encodeWALRecord(record walRecord) ([]byte, error) {
+	payload, err := json.Marshal(record)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode record: %w", err)
+	}
+
+	buf := make([]byte, walHeaderSize+len(payload))
+	// #nosec G115 - payload size is far below uint32 range
+	binary.LittleEndian.PutUint32(buf[0:4], uint32(len(payload)))
+	binary.LittleEndian.PutUint32(buf[4:8], crc32.ChecksumIEEE(payload))
+	copy(buf[walHeaderSize:], payload)
+
+	return buf, nil
+}
+
+// applyRecord applies a replayed log record to the in-memory index
+// All operations are idempotent so replaying a log over a newer snapshot is safe.
+func (fs *FileStorage) applyRecord(record *walRecord) {
+	switch record.Op {
+	case walOpPut:
+		if record.Task != nil && record.Task.ID != "" {
+			fs.mem.restoreTask(record.Task)
+			if record.Revision != nil {
+				fs.mem.restoreRevisions(record.Task.ID, []*models.TaskRevision{record.Revision})
+			}
+		}
+	case walOpDelete:
+		fs.mem.removeTask(record.ID)
+	case walOpClear:
+		// Clearing the in-memory index cannot fail
+		_ = fs.mem.Clear(context.Background())
+	case walOpBatch:
+		for i := range record.Batch {
+			fs.applyRecord(&record.Batch[i])
+		}
+	default:
+		log.Printf("Ignoring unknown write-ahead log operation %q", record.Op)
+	}
+}
+
+// appendRecord durably appends a record to the log; must be called with fs.mu held
+func (fs *FileStorage) appendRecord(record walRecord) error {
+	buf, err := encodeWALRecord(record)
+	if err != nil {
+		return err
+	}
+
+	if _, err := fs.wal.Write(buf); err != nil {
+		// Drop any partially written bytes so the next append starts on a record boundary
+		_ = fs.wal.Truncate(fs.walSize)
+		return fmt.Errorf("failed to append to write-ahead log: %w", err)
+	}
+
+	if fs.config.SyncWrites {
+		if err := fs.wal.Sync(); err != nil {
+			_ = fs.wal.Truncate(fs.walSize)
+			return fmt.Errorf("failed to sync write-ahead log: %w", err)
+		}
+	}
+
+	fs.walSize += int64(len(buf))
+	fs.walRecords++
+
+	return nil
+}
+
+// maybeCompact compacts the log once enough records have accumulated; must be called with fs.mu held
+// Compaction failures are logged rather than returned since the mutation itself is already durable.
+func (fs *FileStorage) maybeCompact() {
+	if fs.walRecords < fs.config.SnapshotInterval {
+		return
+	}
+
+	if err := fs.compact(); err != nil {
+		log.Printf("Failed to compact write-ahead log: %v", err)
+	}
+}
+
+// compact writes a snapshot of the current state and truncates the log; must be called with fs.mu held
+func (fs *FileStorage) compact() error {
+	// Snapshots must be complete, so they never observe a caller's cancellation
+	tasks, err := fs.mem.GetAll(context.Background())
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(snapshot{
+		CreatedAt: time.Now(),
+		Tasks:     tasks,
+		History:   fs.mem.allHistory(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+
+	// Write to a temporary file and rename so a crash never leaves a half-written snapshot
+	tmpPath := fs.snapshotPath() + ".tmp"
+	tmp, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot: %w", err)
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close snapshot: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, fs.snapshotPath()); err != nil {
+		return fmt.Errorf("failed to install snapshot: %w", err)
+	}
+	syncDir(fs.config.DataDir)
+
+	// The snapshot now covers every logged record; a crash before this truncate
+	// simply replays the old log over the snapshot, which is idempotent
+	if err := fs.wal.Truncate(0); err != nil {
+		return fmt.Errorf("failed to truncate write-ahead log: %w", err)
+	}
+	if err := fs.wal.Sync(); err != nil {
+		return fmt.Errorf("failed to sync write-ahead log: %w", err)
+	}
+
+	fs.walSize = 0
+	fs.walRecords = 0
+
+	return nil
+}
+
+// syncDir flushes directory metadata so renames survive a crash (best effort)
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		_ = d.Sync()
+		d.Close()
+	}
+}
+
+// checkOpen returns an error if the storage has been closed; must be called with fs.mu held
+func (fs *FileStorage) checkOpen() error {
+	if fs.closed {
+		return fmt.Errorf("file storage is closed")
+	}
+	return nil
+}
+
+// GetAll retrieves all tasks
+func (fs *FileStorage) GetAll(ctx context.Context) ([]*models.Task, error) {
+	return fs.mem.GetAll(ctx)
+}
+
+// GetByID retrieves a specific task by its ID
+func (fs *FileStorage) GetByID(ctx context.Context, id string) (*models.Task, error) {
+	return fs.mem.GetByID(ctx, id)
+}
+
+// lock acquires the mutation lock and verifies the storage is still usable
+// The context is checked after the lock is acquired since waiting for it may be slow;
+// once a mutation starts it runs to completion so memory and log never diverge.
+func (fs *FileStorage) lock(ctx context.Context) error {
+	fs.mu.Lock()
+
+	if err := ctx.Err(); err != nil {
+		fs.mu.Unlock()
+		return err
+	}
+	if err := fs.checkOpen(); err != nil {
+		fs.mu.Unlock()
+		return err
+	}
+
+	return nil
+}
+
+// Create creates a new task and appends it to the write-ahead log
+func (fs *FileStorage) Create(ctx context.Context, req *models.CreateTaskRequest) (*models.Task, error) {
+	if err := fs.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer fs.mu.Unlock()
+
+	// The caller's cancellation no longer applies, but its values (the actor) still do
+	task, revision, err := fs.mem.create(context.WithoutCancel(ctx), req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := fs.appendRecord(walRecord{Op: walOpPut, Task: task, Revision: revision}); err != nil {
+		// Roll back so memory never holds unacknowledged state
+		fs.mem.removeTask(task.ID)
+		return nil, err
+	}
+
+	fs.publish(nil, task, revision.Actor)
+	fs.maybeCompact()
+	return task, nil
+}
+
+// Update updates an existing task and appends the new state to the write-ahead log
+func (fs *FileStorage) Update(ctx context.Context, id string, req *models.UpdateTaskRequest) (*models.Task, error) {
+	return fs.update(ctx, id, 0, req)
+}
+
+// UpdateIfVersion applies the update only if the task is currently at the given version
+func (fs *FileStorage) UpdateIfVersion(ctx context.Context, id string, version int, req *models.UpdateTaskRequest) (*models.Task, error) {
+	return fs.update(ctx, id, version, req)
+}
+
+// update applies a (possibly conditional) update and appends the new state to the write-ahead log
+func (fs *FileStorage) update(ctx context.Context, id string, expectedVersion int, req *models.UpdateTaskRequest) (*models.Task, error) {
+	if err := fs.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer fs.mu.Unlock()
+
+	previous, _ := fs.mem.GetByID(context.Background(), id)
+
+	task, revision, err := fs.mem.update(context.WithoutCancel(ctx), id, expectedVersion, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := fs.appendRecord(walRecord{Op: walOpPut, Task: task, Revision: revision}); err != nil {
+		fs.mem.restoreTask(previous)
+		fs.mem.truncateHistory(id, task.Version)
+		return nil, err
+	}
+
+	fs.publish(previous, task, revision.Actor)
+	fs.maybeCompact()
+	return task, nil
+}
+
+// Revert restores a task from an earlier version and appends the new state to the write-ahead log
+func (fs *FileStorage) Revert(ctx context.Context, id string, version int) (*models.Task, error) {
+	if err := fs.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer fs.mu.Unlock()
+
+	previous, _ := fs.mem.GetByID(context.Background(), id)
+
+	task, revision, err := fs.mem.revert(context.WithoutCancel(ctx), id, version)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := fs.appendRecord(walRecord{Op: walOpPut, Task: task, Revision: revision}); err != nil {
+		fs.mem.restoreTask(previous)
+		fs.mem.truncateHistory(id, task.Version)
+		return nil, err
+	}
+
+	fs.publish(previous, task, revision.Actor)
+	fs.maybeCompact()
+	return task, nil
+}
+
+// GetHistory returns every stored revision of a task, oldest first
+func (fs *FileStorage) GetHistory(ctx context.Context, id string) ([]*models.TaskRevision, error) {
+	return fs.mem.GetHistory(ctx, id)
+}
+
+// GetVersion returns the revision that produced the given task version
+func (fs *FileStorage) GetVersion(ctx context.Context, id string, version int) (*models.TaskRevision, error) {
+	return fs.mem.GetVersion(ctx, id, version)
+}
+
+// Delete removes a task and appends the deletion to the write-ahead log
+func (fs *FileStorage) Delete(ctx context.Context, id string) error {
+	return fs.delete(ctx, id, 0)
+}
+
+// DeleteIfVersion removes the task only if it is currently at the given version
+func (fs *FileStorage) DeleteIfVersion(ctx context.Context, id string, version int) error {
+	return fs.delete(ctx, id, version)
+}
+
+// delete removes a task (possibly conditionally) and appends the deletion to the write-ahead log
+func (fs *FileStorage) delete(ctx context.Context, id string, expectedVersion int) error {
+	if err := fs.lock(ctx); err != nil {
+		return err
+	}
+	defer fs.mu.Unlock()
+
+	previous, err := fs.mem.GetByID(context.Background(), id)
+	if err != nil {
+		return err
+	}
+	history, err := fs.mem.GetHistory(context.Background(), id)
+	if err != nil {
+		return err
+	}
+
+	if err := fs.mem.delete(context.Background(), id, expectedVersion); err != nil {
+		return err
+	}
+
+	if err := fs.appendRecord(walRecord{Op: walOpDelete, ID: id}); err != nil {
+		fs.mem.restoreTask(previous)
+		fs.mem.restoreRevisions(id, history)
+		return err
+	}
+
+	fs.publish(previous, nil, models.ActorFromContext(ctx))
+	fs.maybeCompact()
+	return nil
+}
+
+// ApplyBatch applies the operations in order and appends them to the write-ahead log as one record
+// The batch is planned first and only stored once its record is durable, so a failed append
+// leaves nothing to roll back and a crash never leaves part of a batch applied.
+func (fs *FileStorage) ApplyBatch(ctx context.Context, ops []models.BulkOperation, mode models.BulkMode) ([]models.BatchResult, error) {
+	if err := fs.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer fs.mu.Unlock()
+
+	ids := batchTaskIDs(ops)
+	unlock := fs.mem.lockShards(ids)
+
+	plan, err := fs.mem.planBatch(context.WithoutCancel(ctx), ops, ids, mode)
+	if err != nil {
+		unlock()
+		return nil, err
+	}
+
+	if len(plan.changes) > 0 {
+		record := walRecord{Op: walOpBatch, Batch: make([]walRecord, len(plan.changes))}
+		for i, change := range plan.changes {
+			if change.deleted != "" {
+				record.Batch[i] = walRecord{Op: walOpDelete, ID: change.deleted}
+			} else {
+				record.Batch[i] = walRecord{Op: walOpPut, Task: change.task, Revision: change.revision}
+			}
+		}
+
+		if err := fs.appendRecord(record); err != nil {
+			unlock()
+			return nil, err
+		}
+	}
+
+	fs.mem.commitBatch(plan)
+	fs.publishBatch(ctx, plan)
+	// Compaction reads every shard, so the batch's shard locks must be released first
+	unlock()
+
+	fs.maybeCompact()
+	return plan.results, nil
+}
+
+// Count returns the total number of tasks
+func (fs *FileStorage) Count(ctx context.Context) (int, error) {
+	return fs.mem.Count(ctx)
+}
+
+// Clear removes all tasks and compacts the log into an empty snapshot
+func (fs *FileStorage) Clear(ctx context.Context) error {
+	if err := fs.lock(ctx); err != nil {
+		return err
+	}
+	defer fs.mu.Unlock()
+
+	// Log first: clearing memory cannot fail, but it cannot be rolled back either
+	if err := fs.appendRecord(walRecord{Op: walOpClear}); err != nil {
+		return err
+	}
+
+	if err := fs.mem.Clear(context.Background()); err != nil {
+		return err
+	}
+
+	fs.maybeCompact()
+	return nil
+}
+
+// Compact forces a snapshot of the current state and truncates the write-ahead log
+func (fs *FileStorage) Compact() error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if err := fs.checkOpen(); err != nil {
+		return err
+	}
+
+	return fs.compact()
+}
+
+// Close writes a final snapshot and releases the write-ahead log
+func (fs *FileStorage) Close() error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if fs.closed {
+		return nil
+	}
+
+	compactErr := fs.compact()
+	closeErr := fs.wal.Close()
+	fs.closed = true
+
+	if compactErr != nil {
+		return compactErr
+	}
+	return closeErr
+}
+
+// HealthCheck verifies the in-memory index and the write-ahead log are usable
+func (fs *FileStorage) HealthCheck(ctx context.Context) error {
+	if err := fs.mem.HealthCheck(ctx); err != nil {
+		return err
+	}
+
+	if err := fs.lock(ctx); err != nil {
+		return err
+	}
+	defer fs.mu.Unlock()
+
+	if _, err := fs.wal.Stat(); err != nil {
+		return fmt.Errorf("write-ahead log is not accessible: %w", err)
+	}
+
+	return nil
+}
+
+// GetStats returns statistics about the file storage
+func (fs *FileStorage) GetStats(ctx context.Context) (StorageStats, error) {
+	stats, err := fs.mem.GetStats(ctx)
+	if err != nil {
+		return StorageStats{}, err
+	}
+
+	stats.StorageType = "file"
+	return stats, nil
+}
+
+// GetMaxTasks returns the maximum number of tasks allowed
+func (fs *FileStorage) GetMaxTasks() int {
+	return fs.mem.GetMaxTasks()
+}
+
+// GetUsage returns current storage usage information including log statistics
+func (fs *FileStorage) GetUsage() map[string]interface{} {
+	usage := fs.mem.GetUsage()
+
+	fs.mu.Lock()
+	walSize := fs.walSize
+	walRecords := fs.walRecords
+	fs.mu.Unlock()
+
+	usage["storage_type"] = "file"
+	usage["data_dir"] = fs.config.DataDir
+	usage["wal_bytes"] = walSize
+	usage["wal_records"] = walRecords
+	usage["snapshot_interval"] = fs.config.SnapshotInterval
+
+	return usage
+}
+
+// GetTasksByStatus returns all tasks with the specified status
+func (fs *FileStorage) GetTasksByStatus(ctx context.Context, status models.TaskStatus) ([]*models.Task, error) {
+	return fs.mem.GetTasksByStatus(ctx, status)
+}
+
+// GetTasksCreatedAfter returns tasks created after the specified time
+func (fs *FileStorage) GetTasksCreatedAfter(ctx context.Context, after time.Time) ([]*models.Task, error) {
+	return fs.mem.GetTasksCreatedAfter(ctx, after)
+}
+
+// QueryTasks returns the filtered tasks in the requested order
+func (fs *FileStorage) QueryTasks(ctx context.Context, filter *models.TaskFilter, order []models.SortField) ([]*models.Task, error) {
+	return fs.mem.QueryTasks(ctx, filter, order)
+}
+
+// GetTasksPaginated returns a paginated list of tasks
+func (fs *FileStorage) GetTasksPaginated(ctx context.Context, offset, limit int) ([]*models.Task, int, error) {
+	return fs.mem.GetTasksPaginated(ctx, offset, limit)
+}
+
+// GetTasksAfter returns up to limit tasks matching filter that come after the cursor in creation order
+func (fs *FileStorage) GetTasksAfter(ctx context.Context, filter *models.TaskFilter, after *models.TaskCursor, limit int) ([]*models.Task, bool, error) {
+	return fs.mem.GetTasksAfter(ctx, filter, after, limit)
+}
+
+// SearchTasks returns up to limit tasks matching the full-text query, best match first
+func (fs *FileStorage) SearchTasks(ctx context.Context, query string, limit int) ([]models.SearchResult, error) {
+	return fs.mem.SearchTasks(ctx, query, limit)
+}
@@ -0,0 +1,385 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"task-api/internal/interfaces"
+	"task-api/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestFileStorage opens a file storage in dir and closes it when the test ends
+func newTestFileStorage(t *testing.T, dir string, maxTasks, snapshotInterval int) *FileStorage {
+	t.Helper()
+
+	storage, err := NewFileStorage(FileStorageConfig{
+		DataDir:          dir,
+		MaxTasks:         maxTasks,
+		SnapshotInterval: snapshotInterval,
+		SyncWrites:       false,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = storage.Close() })
+
+	return storage
+}
+
+// crash simulates a process crash by dropping the log handle without a final snapshot
+func crash(t *testing.T, storage *FileStorage) {
+	t.Helper()
+
+	storage.mu.Lock()
+	defer storage.mu.Unlock()
+
+	require.NoError(t, storage.wal.Close())
+	storage.closed = true
+}
+
+func TestFileStorage_Contract(t *testing.T) {
+	runStorageContract(t, func(t *testing.T, maxTasks int) interfaces.TaskStorage {
+		return newTestFileStorage(t, t.TempDir(), maxTasks, 7)
+	})
+}
+
+func TestNewFileStorage(t *testing.T) {
+	_, err := NewFileStorage(FileStorageConfig{})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "data directory is required")
+
+	dir := filepath.Join(t.TempDir(), "nested", "data")
+	storage := newTestFileStorage(t, dir, 0, 0)
+	assert.Equal(t, 10000, storage.GetMaxTasks())
+	assert.Equal(t, 1000, storage.config.SnapshotInterval)
+	assert.FileExists(t, filepath.Join(dir, walFileName))
+}
+
+func TestFileStorage_PersistsAcrossRestart(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	storage := newTestFileStorage(t, dir, 100, 1000)
+
+	task1, err := storage.Create(ctx, &models.CreateTaskRequest{Name: "Keep me", Status: models.TaskIncomplete})
+	require.NoError(t, err)
+	task2, err := storage.Create(ctx, &models.CreateTaskRequest{Name: "Delete me", Status: models.TaskIncomplete})
+	require.NoError(t, err)
+	_, err = storage.Update(ctx, task1.ID, &models.UpdateTaskRequest{Status: taskStatusPtr(models.TaskCompleted)})
+	require.NoError(t, err)
+	require.NoError(t, storage.Delete(ctx, task2.ID))
+
+	// Restart without a final snapshot so state comes purely from the log
+	crash(t, storage)
+	reopened := newTestFileStorage(t, dir, 100, 1000)
+
+	count, err := reopened.Count(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	task, err := reopened.GetByID(ctx, task1.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Keep me", task.Name)
+	assert.Equal(t, models.TaskCompleted, task.Status)
+	assert.True(t, task.CreatedAt.Equal(task1.CreatedAt))
+
+	_, err = reopened.GetByID(ctx, task2.ID)
+	assert.Error(t, err)
+
+	// The search index is rebuilt while replaying
+	results, err := reopened.SearchTasks(ctx, "me", 10)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, task1.ID, results[0].Task.ID)
+}
+
+func TestFileStorage_HistoryPersists(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	storage := newTestFileStorage(t, dir, 100, 1000)
+
+	task, err := storage.Create(models.WithActor(ctx, "alice"), &models.CreateTaskRequest{Name: "v1"})
+	require.NoError(t, err)
+	_, err = storage.Update(ctx, task.ID, &models.UpdateTaskRequest{Name: stringPtr("v2")})
+	require.NoError(t, err)
+
+	// First restart recovers history from the log, the second from the snapshot Close wrote
+	crash(t, storage)
+	reopened := newTestFileStorage(t, dir, 100, 1000)
+	_, err = reopened.Revert(ctx, task.ID, 1)
+	require.NoError(t, err)
+	require.NoError(t, reopened.Close())
+	reopened = newTestFileStorage(t, dir, 100, 1000)
+
+	history, err := reopened.GetHistory(ctx, task.ID)
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+	assert.Equal(t, "alice", history[0].Actor)
+	assert.Equal(t, "v2", history[1].Task.Name)
+	assert.Equal(t, 1, history[2].RevertedFrom)
+
+	current, err := reopened.GetByID(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 3, current.Version)
+	assert.Equal(t, "v1", current.Name)
+}
+
+func TestFileStorage_RevisionLimit(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	storage, err := NewFileStorage(FileStorageConfig{DataDir: dir, MaxRevisions: 3})
+	require.NoError(t, err)
+	checkRevisionLimit(t, storage)
+
+	// Replaying the log keeps the limit, and a lower limit trims the restored history
+	crash(t, storage)
+	reopened, err := NewFileStorage(FileStorageConfig{DataDir: dir, MaxRevisions: 2})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = reopened.Close() })
+
+	tasks, err := reopened.GetAll(ctx)
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	history, err := reopened.GetHistory(ctx, tasks[0].ID)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, 5, history[0].Version)
+	assert.Equal(t, 6, history[1].Version)
+}
+
+func TestFileStorage_BatchPersists(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	storage := newTestFileStorage(t, dir, 100, 1000)
+
+	existing, err := storage.Create(ctx, &models.CreateTaskRequest{Name: "Existing"})
+	require.NoError(t, err)
+
+	results, err := storage.ApplyBatch(ctx, []models.BulkOperation{
+		{Op: models.BulkCreate, Task: &models.CreateTaskRequest{Name: "Created"}},
+		{Op: models.BulkUpdate, ID: existing.ID, Changes: &models.UpdateTaskRequest{Name: stringPtr("Renamed")}},
+		{Op: models.BulkCreate, Task: &models.CreateTaskRequest{Name: "Short lived"}},
+		{Op: models.BulkDelete, ID: "non-existent-id"},
+	}, models.BulkBestEffort)
+	require.NoError(t, err)
+	require.NoError(t, results[2].Err)
+
+	// A failed atomic batch must not reach the log
+	_, err = storage.ApplyBatch(ctx, []models.BulkOperation{
+		{Op: models.BulkDelete, ID: results[2].Task.ID},
+		{Op: models.BulkDelete, ID: "non-existent-id"},
+	}, models.BulkAtomic)
+	require.NoError(t, err)
+	assert.Equal(t, 2, storage.walRecords, "one record per applied batch")
+
+	crash(t, storage)
+	reopened := newTestFileStorage(t, dir, 100, 1000)
+
+	tasks, err := reopened.GetAll(ctx)
+	require.NoError(t, err)
+	assert.Len(t, tasks, 3)
+
+	renamed, err := reopened.GetByID(ctx, existing.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Renamed", renamed.Name)
+	assert.Equal(t, 2, renamed.Version)
+
+	history, err := reopened.GetHistory(ctx, results[0].Task.ID)
+	require.NoError(t, err)
+	assert.Len(t, history, 1)
+}
+
+func TestFileStorage_PaginationAfterRestart(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	storage := newTestFileStorage(t, dir, 100, 4)
+
+	var ids []string
+	for i := 0; i < 6; i++ {
+		task, err := storage.Create(ctx, &models.CreateTaskRequest{Name: fmt.Sprintf("Task %d", i)})
+		require.NoError(t, err)
+		ids = append(ids, task.ID)
+	}
+	require.NoError(t, storage.Delete(ctx, ids[2]))
+
+	// Part of the state comes from the snapshot and part from the log
+	crash(t, storage)
+	reopened := newTestFileStorage(t, dir, 100, 4)
+
+	tasks, more, err := reopened.GetTasksAfter(ctx, &models.TaskFilter{}, nil, 10)
+	require.NoError(t, err)
+	assert.False(t, more)
+
+	paged := make([]string, len(tasks))
+	for i, task := range tasks {
+		paged[i] = task.ID
+	}
+	assert.Equal(t, []string{ids[0], ids[1], ids[3], ids[4], ids[5]}, paged)
+}
+
+func TestFileStorage_SnapshotCompaction(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	storage := newTestFileStorage(t, dir, 100, 5)
+
+	for i := 0; i < 12; i++ {
+		_, err := storage.Create(ctx, &models.CreateTaskRequest{Name: "Task", Status: models.TaskIncomplete})
+		require.NoError(t, err)
+	}
+
+	// 12 records with interval 5: two compactions, two records left in the log
+	assert.FileExists(t, filepath.Join(dir, snapshotFileName))
+	assert.Equal(t, 2, storage.walRecords)
+
+	usage := storage.GetUsage()
+	assert.Equal(t, "file", usage["storage_type"])
+	assert.Equal(t, 2, usage["wal_records"])
+
+	crash(t, storage)
+	reopened := newTestFileStorage(t, dir, 100, 5)
+
+	count, err := reopened.Count(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 12, count)
+	assert.Equal(t, 2, reopened.walRecords)
+}
+
+func TestFileStorage_ClearPersists(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	storage := newTestFileStorage(t, dir, 100, 1000)
+
+	for i := 0; i < 3; i++ {
+		_, err := storage.Create(ctx, &models.CreateTaskRequest{Name: "Task", Status: models.TaskIncomplete})
+		require.NoError(t, err)
+	}
+	require.NoError(t, storage.Clear(ctx))
+	_, err := storage.Create(ctx, &models.CreateTaskRequest{Name: "After clear", Status: models.TaskIncomplete})
+	require.NoError(t, err)
+
+	crash(t, storage)
+	reopened := newTestFileStorage(t, dir, 100, 1000)
+
+	tasks, err := reopened.GetAll(ctx)
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, "After clear", tasks[0].Name)
+}
+
+func TestFileStorage_CloseWritesSnapshot(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	storage := newTestFileStorage(t, dir, 100, 1000)
+
+	_, err := storage.Create(ctx, &models.CreateTaskRequest{Name: "Task", Status: models.TaskIncomplete})
+	require.NoError(t, err)
+	require.NoError(t, storage.Close())
+
+	info, err := os.Stat(filepath.Join(dir, walFileName))
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), info.Size())
+
+	// Operations after Close must fail rather than silently losing data
+	_, err = storage.Create(ctx, &models.CreateTaskRequest{Name: "Task", Status: models.TaskIncomplete})
+	assert.Error(t, err)
+	assert.Error(t, storage.HealthCheck(ctx))
+
+	reopened := newTestFileStorage(t, dir, 100, 1000)
+	count, err := reopened.Count(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestFileStorage_CrashRecovery(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name    string
+		damage  func(t *testing.T, path string, lastRecordStart int64)
+		wantLen int
+	}{
+		{
+			name: "truncated mid payload",
+			damage: func(t *testing.T, path string, lastRecordStart int64) {
+				require.NoError(t, os.Truncate(path, lastRecordStart+walHeaderSize+5))
+			},
+			wantLen: 2,
+		},
+		{
+			name: "truncated mid header",
+			damage: func(t *testing.T, path string, lastRecordStart int64) {
+				require.NoError(t, os.Truncate(path, lastRecordStart+3))
+			},
+			wantLen: 2,
+		},
+		{
+			name: "corrupted payload checksum",
+			damage: func(t *testing.T, path string, lastRecordStart int64) {
+				file, err := os.OpenFile(path, os.O_RDWR, 0o600)
+				require.NoError(t, err)
+				defer file.Close()
+				_, err = file.WriteAt([]byte("XX"), lastRecordStart+walHeaderSize+2)
+				require.NoError(t, err)
+			},
+			wantLen: 2,
+		},
+		{
+			name: "garbage appended after last record",
+			damage: func(t *testing.T, path string, lastRecordStart int64) {
+				file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o600)
+				require.NoError(t, err)
+				defer file.Close()
+				_, err = file.Write([]byte{0xff, 0xff, 0xff, 0x7f, 0x01})
+				require.NoError(t, err)
+			},
+			wantLen: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			walPath := filepath.Join(dir, walFileName)
+			storage := newTestFileStorage(t, dir, 100, 1000)
+
+			var lastRecordStart int64
+			for i := 0; i < 3; i++ {
+				lastRecordStart = storage.walSize
+				_, err := storage.Create(ctx, &models.CreateTaskRequest{Name: "Task", Status: models.TaskIncomplete})
+				require.NoError(t, err)
+			}
+
+			crash(t, storage)
+			tt.damage(t, walPath, lastRecordStart)
+
+			reopened := newTestFileStorage(t, dir, 100, 1000)
+			tasks, err := reopened.GetAll(ctx)
+			require.NoError(t, err)
+			assert.Len(t, tasks, tt.wantLen)
+
+			// The damaged tail must be cut off so new records land on a clean boundary
+			info, err := os.Stat(walPath)
+			require.NoError(t, err)
+			assert.Equal(t, reopened.walSize, info.Size())
+
+			_, err = reopened.Create(ctx, &models.CreateTaskRequest{Name: "After recovery", Status: models.TaskIncomplete})
+			require.NoError(t, err)
+
+			crash(t, reopened)
+			recovered := newTestFileStorage(t, dir, 100, 1000)
+			count, err := recovered.Count(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantLen+1, count)
+		})
+	}
+}
+
+func TestFileStorage_CorruptSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, snapshotFileName), []byte("{not json"), 0o600))
+
+	_, err := NewFileStorage(FileStorageConfig{DataDir: dir})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to decode snapshot")
+}
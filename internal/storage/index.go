@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"sort"
+	"sync"
+	"task-api/internal/models"
+)
+
+// creationIndex keeps the positions of all tasks sorted in creation order (creation time, then ID)
+// It lets pages be read with a binary search instead of a scan over every shard. Tasks are
+// almost always created in time order, so inserts are usually appends.
+type creationIndex struct {
+	keys  []models.TaskCursor // Task positions in creation order
+	mutex sync.RWMutex        // Protects keys; never held while acquiring a shard lock
+}
+
+// search returns the index of the first key at or after cursor
+// Must be called with the mutex held.
+func (idx *creationIndex) search(cursor models.TaskCursor) int {
+	return sort.Search(len(idx.keys), func(i int) bool {
+		return idx.keys[i].Compare(cursor) >= 0
+	})
+}
+
+// insert adds the position of a task; inserting a known position is a no-op
+func (idx *creationIndex) insert(task *models.Task) {
+	key := models.CursorOf(task)
+
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+
+	i := len(idx.keys)
+	if i > 0 && idx.keys[i-1].Compare(key) >= 0 {
+		i = idx.search(key)
+		if i < len(idx.keys) && idx.keys[i].Compare(key) == 0 {
+			return
+		}
+	}
+	idx.keys = append(idx.keys, models.TaskCursor{})
+	copy(idx.keys[i+1:], idx.keys[i:])
+	idx.keys[i] = key
+}
+
+// remove drops the position of a task, ignoring unknown positions
+func (idx *creationIndex) remove(task *models.Task) {
+	key := models.CursorOf(task)
+
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+
+	i := idx.search(key)
+	if i < len(idx.keys) && idx.keys[i].Compare(key) == 0 {
+		idx.keys = append(idx.keys[:i], idx.keys[i+1:]...)
+	}
+}
+
+// clear drops every position
+func (idx *creationIndex) clear() {
+	idx.mutex.Lock()
+	idx.keys = nil
+	idx.mutex.Unlock()
+}
+
+// slice returns up to limit positions starting at offset
+func (idx *creationIndex) slice(offset, limit int) []models.TaskCursor {
+	idx.mutex.RLock()
+	defer idx.mutex.RUnlock()
+
+	return copyKeys(idx.keys, offset, limit)
+}
+
+// after returns up to limit positions strictly after cursor (nil = from the first position)
+func (idx *creationIndex) after(cursor *models.TaskCursor, limit int) []models.TaskCursor {
+	idx.mutex.RLock()
+	defer idx.mutex.RUnlock()
+
+	start := 0
+	if cursor != nil {
+		start = idx.search(*cursor)
+		if start < len(idx.keys) && idx.keys[start].Compare(*cursor) == 0 {
+			start++
+		}
+	}
+	return copyKeys(idx.keys, start, limit)
+}
+
+// copyKeys copies up to limit keys starting at start, so callers can use them without the lock
+func copyKeys(keys []models.TaskCursor, start, limit int) []models.TaskCursor {
+	if start >= len(keys) {
+		return nil
+	}
+	end := start + limit
+	if end > len(keys) {
+		end = len(keys)
+	}
+	return append([]models.TaskCursor(nil), keys[start:end]...)
+}
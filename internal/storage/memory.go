@@ -1,11 +1,14 @@
 package storage
 
 import (
+	"context"
 	"fmt"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"task-api/internal/interfaces"
 	"task-api/internal/models"
+	"task-api/internal/search"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,26 +16,46 @@ import (
 
 // shard represents a single shard with its own lock and task storage
 type shard struct {
-	tasks map[string]*models.Task // Task storage for this shard
-	mutex sync.RWMutex            // Per-shard read-write lock
+	tasks   map[string]*models.Task           // Task storage for this shard
+	history map[string][]*models.TaskRevision // Revisions of each task, oldest first
+	mutex   sync.RWMutex                      // Per-shard read-write lock
 }
 
 // MemoryStorage implements TaskStorage interface using sharded in-memory storage
 // This implementation is thread-safe using sharding to reduce lock contention
 type MemoryStorage struct {
-	shards     []*shard  // Array of shards
-	shardCount uint32    // Number of shards (using uint32 to match hash algorithm)
-	maxTasks   int       // Maximum number of tasks allowed
-	taskCount  int64     // Atomic task counter for fast count operations
-	taskPool   sync.Pool // Object pool to reduce GC pressure
+	shards     []*shard      // Array of shards
+	shardCount uint32        // Number of shards (using uint32 to match hash algorithm)
+	maxTasks   int           // Maximum number of tasks allowed
+	maxHistory int           // Maximum number of revisions kept per task
+	taskCount  int64         // Atomic task counter for fast count operations
+	taskPool   sync.Pool     // Object pool to reduce GC pressure
+	index      creationIndex // Task positions in creation order, for paging
+	search     *search.Index // Full-text index of task names and descriptions
+
+	changeNotifier // Reports committed changes to the event publisher
 }
 
 // Ensure MemoryStorage implements required interfaces at compile time
 var (
-	_ interfaces.TaskStorage   = (*MemoryStorage)(nil)
-	_ interfaces.HealthChecker = (*MemoryStorage)(nil)
+	_ interfaces.TaskStorage       = (*MemoryStorage)(nil)
+	_ interfaces.HealthChecker     = (*MemoryStorage)(nil)
+	_ interfaces.StatusQuerier     = (*MemoryStorage)(nil)
+	_ interfaces.TaskQuerier       = (*MemoryStorage)(nil)
+	_ interfaces.Paginator         = (*MemoryStorage)(nil)
+	_ interfaces.CursorPaginator   = (*MemoryStorage)(nil)
+	_ interfaces.TaskSearcher      = (*MemoryStorage)(nil)
+	_ interfaces.StatsProvider     = (*MemoryStorage)(nil)
+	_ interfaces.UsageReporter     = (*MemoryStorage)(nil)
+	_ interfaces.HistoryProvider   = (*MemoryStorage)(nil)
+	_ interfaces.ConditionalWriter = (*MemoryStorage)(nil)
+	_ interfaces.BatchWriter       = (*MemoryStorage)(nil)
+	_ interfaces.ChangeNotifier    = (*MemoryStorage)(nil)
 )
 
+// DefaultMaxRevisions is the number of revisions kept per task when no limit is configured
+const DefaultMaxRevisions = 100
+
 // NewMemoryStorage creates a new instance of MemoryStorage with sharding optimization
 func NewMemoryStorage(maxTasks int) *MemoryStorage {
 	if maxTasks <= 0 {
@@ -52,8 +75,9 @@ func NewMemoryStorage(maxTasks int) *MemoryStorage {
 	shards := make([]*shard, shardCount)
 	for i := range shards {
 		shards[i] = &shard{
-			tasks: make(map[string]*models.Task),
-			mutex: sync.RWMutex{},
+			tasks:   make(map[string]*models.Task),
+			history: make(map[string][]*models.TaskRevision),
+			mutex:   sync.RWMutex{},
 		}
 	}
 
@@ -71,7 +95,9 @@ func NewMemoryStorage(maxTasks int) *MemoryStorage {
 		shards:     shards,
 		shardCount: safeShardCount,
 		maxTasks:   maxTasks,
+		maxHistory: DefaultMaxRevisions,
 		taskCount:  0,
+		search:     search.NewIndex(),
 		taskPool: sync.Pool{
 			New: func() interface{} {
 				return &models.Task{}
@@ -101,18 +127,22 @@ func (ms *MemoryStorage) fnv32Hash(key string) uint32 {
 
 // GetAll retrieves all tasks from all shards
 // Returns a copy of all tasks to prevent external modifications
-func (ms *MemoryStorage) GetAll() ([]*models.Task, error) {
+// Cancellation is checked between shards so a long scan stops once the caller is gone.
+func (ms *MemoryStorage) GetAll(ctx context.Context) ([]*models.Task, error) {
 	// Pre-allocate slice with current task count for better performance
 	currentCount := atomic.LoadInt64(&ms.taskCount)
 	allTasks := make([]*models.Task, 0, currentCount)
 
 	// Iterate through all shards and collect tasks
 	for _, shard := range ms.shards {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		shard.mutex.RLock()
 		for _, task := range shard.tasks {
 			// Create a copy to prevent external modifications
-			taskCopy := *task
-			allTasks = append(allTasks, &taskCopy)
+			allTasks = append(allTasks, task.Clone())
 		}
 		shard.mutex.RUnlock()
 	}
@@ -121,66 +151,101 @@ func (ms *MemoryStorage) GetAll() ([]*models.Task, error) {
 }
 
 // GetByID retrieves a specific task by its ID from the appropriate shard
-func (ms *MemoryStorage) GetByID(id string) (*models.Task, error) {
+func (ms *MemoryStorage) GetByID(ctx context.Context, id string) (*models.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	shard := ms.getShard(id)
 	shard.mutex.RLock()
 	defer shard.mutex.RUnlock()
 
 	task, exists := shard.tasks[id]
 	if !exists {
-		return nil, fmt.Errorf("task with ID %s not found", id)
+		return nil, notFoundError(id)
 	}
 
 	// Return a copy to prevent external modifications
-	taskCopy := *task
-	return &taskCopy, nil
+	return task.Clone(), nil
 }
 
 // Create creates a new task in the appropriate shard
-func (ms *MemoryStorage) Create(req *models.CreateTaskRequest) (*models.Task, error) {
+func (ms *MemoryStorage) Create(ctx context.Context, req *models.CreateTaskRequest) (*models.Task, error) {
+	task, _, err := ms.create(ctx, req)
+	return task, err
+}
+
+// create stores a new task and its first revision, returning copies of both
+func (ms *MemoryStorage) create(ctx context.Context, req *models.CreateTaskRequest) (*models.Task, *models.TaskRevision, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
 	// Validate the request first
-	if err := req.Validate(); err != nil {
-		return nil, fmt.Errorf("validation failed: %w", err)
+	if err := checkCreate(req); err != nil {
+		return nil, nil, err
 	}
 
 	// Check if maximum tasks limit is reached using atomic operation
 	currentCount := atomic.LoadInt64(&ms.taskCount)
 	if int(currentCount) >= ms.maxTasks {
-		return nil, fmt.Errorf("maximum tasks limit reached (%d)", ms.maxTasks)
+		return nil, nil, quotaExceededError(ms.maxTasks)
 	}
 
 	// Generate UUID as task ID
 	// UUID v4 collision probability is extremely low (~10^-15), so no need to check uniqueness
 	taskID := uuid.New().String()
 
-	// Create new task using factory method
-	task := models.NewTask(req.Name, req.Status)
+	// Create new task from the request
+	task := req.ToTask()
 	task.ID = taskID
+	revision := models.NewTaskRevision(models.RevisionCreate, nil, task, models.ActorFromContext(ctx))
 
 	// Get the appropriate shard and store the task
 	shard := ms.getShard(taskID)
 	shard.mutex.Lock()
 	shard.tasks[taskID] = task
+	shard.history[taskID] = []*models.TaskRevision{revision}
+	ms.index.insert(task)
+	ms.search.Add(task)
+	ms.publish(nil, task, revision.Actor)
 	shard.mutex.Unlock()
 
 	// Increment task count atomically
 	atomic.AddInt64(&ms.taskCount, 1)
 
 	// Return a copy
-	taskCopy := *task
-	return &taskCopy, nil
+	return task.Clone(), revision.Clone(), nil
 }
 
 // Update updates an existing task in the appropriate shard
-func (ms *MemoryStorage) Update(id string, req *models.UpdateTaskRequest) (*models.Task, error) {
+func (ms *MemoryStorage) Update(ctx context.Context, id string, req *models.UpdateTaskRequest) (*models.Task, error) {
+	task, _, err := ms.update(ctx, id, 0, req)
+	return task, err
+}
+
+// UpdateIfVersion applies the update only if the task is currently at the given version
+// The version check and the write happen under the same shard lock.
+func (ms *MemoryStorage) UpdateIfVersion(ctx context.Context, id string, version int, req *models.UpdateTaskRequest) (*models.Task, error) {
+	task, _, err := ms.update(ctx, id, version, req)
+	return task, err
+}
+
+// update applies a partial update and records the new revision, returning copies of both
+// A positive expectedVersion makes the update conditional on the task's current version.
+func (ms *MemoryStorage) update(ctx context.Context, id string, expectedVersion int, req *models.UpdateTaskRequest) (*models.Task, *models.TaskRevision, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
 	// Validate the request first
 	if err := req.Validate(); err != nil {
-		return nil, fmt.Errorf("validation failed: %w", err)
+		return nil, nil, validationError(err)
 	}
 
 	// Check if there are any updates to apply
 	if !req.HasUpdates() {
-		return nil, fmt.Errorf("no updates provided")
+		return nil, nil, noUpdatesError()
 	}
 
 	shard := ms.getShard(id)
@@ -190,36 +255,182 @@ func (ms *MemoryStorage) Update(id string, req *models.UpdateTaskRequest) (*mode
 	// Check if task exists
 	task, exists := shard.tasks[id]
 	if !exists {
-		return nil, fmt.Errorf("task with ID %s not found", id)
+		return nil, nil, notFoundError(id)
 	}
+	if expectedVersion > 0 && task.Version != expectedVersion {
+		return nil, nil, versionMismatchError(id, expectedVersion, task.Version)
+	}
+
+	return ms.commitUpdate(ctx, shard, task, req, 0)
+}
+
+// commitUpdate applies req to a copy of task, stores it as the next version and appends
+// its revision; revertedFrom is the source version of a revert, or 0 for a plain update.
+// Must be called with the shard lock held.
+func (ms *MemoryStorage) commitUpdate(ctx context.Context, shard *shard, task *models.Task, req *models.UpdateTaskRequest, revertedFrom int) (*models.Task, *models.TaskRevision, error) {
+	updatedTask, revision, err := nextVersion(ctx, task, req, revertedFrom)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Store the updated task and its revision
+	shard.tasks[task.ID] = updatedTask
+	ms.appendRevision(shard, task.ID, revision)
+	ms.search.Add(updatedTask)
+	ms.publish(task, updatedTask, revision.Actor)
+
+	// Return copies
+	return updatedTask.Clone(), revision.Clone(), nil
+}
 
+// nextVersion applies req to a copy of task and builds the revision of the resulting version
+// revertedFrom is the source version of a revert, or 0 for a plain update.
+func nextVersion(ctx context.Context, task *models.Task, req *models.UpdateTaskRequest, revertedFrom int) (*models.Task, *models.TaskRevision, error) {
 	// Create a copy of the existing task to modify
-	updatedTask := *task
+	updatedTask := task.Clone()
 
 	// Apply updates to the copy
-	req.ApplyTo(&updatedTask)
+	if err := req.ApplyTo(updatedTask); err != nil {
+		return nil, nil, transitionError(err)
+	}
+	updatedTask.Version = task.Version + 1
 
-	// Store the updated task
-	shard.tasks[id] = &updatedTask
+	action := models.RevisionUpdate
+	if revertedFrom > 0 {
+		action = models.RevisionRevert
+	}
+	revision := models.NewTaskRevision(action, task, updatedTask, models.ActorFromContext(ctx))
+	revision.RevertedFrom = revertedFrom
 
-	// Return a copy
-	taskCopy := updatedTask
-	return &taskCopy, nil
+	return updatedTask, revision, nil
+}
+
+// GetHistory returns every stored revision of a task, oldest first
+func (ms *MemoryStorage) GetHistory(ctx context.Context, id string) ([]*models.TaskRevision, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	shard := ms.getShard(id)
+	shard.mutex.RLock()
+	defer shard.mutex.RUnlock()
+
+	if _, exists := shard.tasks[id]; !exists {
+		return nil, notFoundError(id)
+	}
+
+	revisions := make([]*models.TaskRevision, 0, len(shard.history[id]))
+	for _, revision := range shard.history[id] {
+		revisions = append(revisions, revision.Clone())
+	}
+
+	return revisions, nil
+}
+
+// GetVersion returns the revision that produced the given task version
+func (ms *MemoryStorage) GetVersion(ctx context.Context, id string, version int) (*models.TaskRevision, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	shard := ms.getShard(id)
+	shard.mutex.RLock()
+	defer shard.mutex.RUnlock()
+
+	if _, exists := shard.tasks[id]; !exists {
+		return nil, notFoundError(id)
+	}
+
+	revision := findRevision(shard.history[id], version)
+	if revision == nil {
+		return nil, versionNotFoundError(id, version)
+	}
+
+	return revision.Clone(), nil
+}
+
+// Revert restores the task's fields from an earlier version as a new revision
+func (ms *MemoryStorage) Revert(ctx context.Context, id string, version int) (*models.Task, error) {
+	task, _, err := ms.revert(ctx, id, version)
+	return task, err
+}
+
+// revert applies an earlier revision as the next version, returning copies of the task and revision
+func (ms *MemoryStorage) revert(ctx context.Context, id string, version int) (*models.Task, *models.TaskRevision, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	shard := ms.getShard(id)
+	shard.mutex.Lock()
+	defer shard.mutex.Unlock()
+
+	task, exists := shard.tasks[id]
+	if !exists {
+		return nil, nil, notFoundError(id)
+	}
+
+	source := findRevision(shard.history[id], version)
+	if source == nil {
+		return nil, nil, versionNotFoundError(id, version)
+	}
+
+	// Stored revisions were valid when written, but the limits may have tightened since
+	req := source.RevertRequest()
+	if err := req.Validate(); err != nil {
+		return nil, nil, validationError(err)
+	}
+
+	return ms.commitUpdate(ctx, shard, task, req, version)
+}
+
+// findRevision returns the revision with the given version, or nil
+func findRevision(revisions []*models.TaskRevision, version int) *models.TaskRevision {
+	for _, revision := range revisions {
+		if revision.Version == version {
+			return revision
+		}
+	}
+	return nil
 }
 
 // Delete removes a task from the appropriate shard
-func (ms *MemoryStorage) Delete(id string) error {
+func (ms *MemoryStorage) Delete(ctx context.Context, id string) error {
+	return ms.delete(ctx, id, 0)
+}
+
+// DeleteIfVersion removes the task only if it is currently at the given version
+// The version check and the removal happen under the same shard lock.
+func (ms *MemoryStorage) DeleteIfVersion(ctx context.Context, id string, version int) error {
+	return ms.delete(ctx, id, version)
+}
+
+// delete removes a task and its history
+// A positive expectedVersion makes the deletion conditional on the task's current version.
+func (ms *MemoryStorage) delete(ctx context.Context, id string, expectedVersion int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	shard := ms.getShard(id)
 	shard.mutex.Lock()
 	defer shard.mutex.Unlock()
 
 	// Check if task exists
-	if _, exists := shard.tasks[id]; !exists {
-		return fmt.Errorf("task with ID %s not found", id)
+	task, exists := shard.tasks[id]
+	if !exists {
+		return notFoundError(id)
+	}
+	if expectedVersion > 0 && task.Version != expectedVersion {
+		return versionMismatchError(id, expectedVersion, task.Version)
 	}
 
-	// Delete the task
+	// Delete the task together with its history
 	delete(shard.tasks, id)
+	delete(shard.history, id)
+	ms.index.remove(task)
+	ms.search.Remove(id)
+	ms.publish(task, nil, models.ActorFromContext(ctx))
 
 	// Decrement task count atomically
 	atomic.AddInt64(&ms.taskCount, -1)
@@ -227,20 +438,231 @@ func (ms *MemoryStorage) Delete(id string) error {
 	return nil
 }
 
+// batchChange is one applied operation of a planned batch
+type batchChange struct {
+	task     *models.Task         // New task state (nil for a delete)
+	revision *models.TaskRevision // Revision that produced the new state (nil for a delete)
+	deleted  string               // ID of the deleted task (delete only)
+	previous *models.Task         // Task state before the operation (nil for a create)
+}
+
+// batchPlan is a batch evaluated against the current state but not stored yet
+type batchPlan struct {
+	results []models.BatchResult // One result per operation
+	changes []batchChange        // Changes of the applied operations, in operation order
+}
+
+// ApplyBatch applies the operations in order, locking each affected shard once for the whole batch
+// In atomic mode the batch is evaluated completely before anything is stored.
+func (ms *MemoryStorage) ApplyBatch(ctx context.Context, ops []models.BulkOperation, mode models.BulkMode) ([]models.BatchResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	ids := batchTaskIDs(ops)
+	unlock := ms.lockShards(ids)
+	defer unlock()
+
+	plan, err := ms.planBatch(ctx, ops, ids, mode)
+	if err != nil {
+		return nil, err
+	}
+
+	ms.commitBatch(plan)
+	ms.publishBatch(ctx, plan)
+	return plan.results, nil
+}
+
+// batchTaskIDs returns the ID of the task each operation affects, generating IDs for creates
+func batchTaskIDs(ops []models.BulkOperation) []string {
+	ids := make([]string, len(ops))
+	for i, op := range ops {
+		if op.Op == models.BulkCreate {
+			ids[i] = uuid.New().String()
+		} else {
+			ids[i] = op.ID
+		}
+	}
+	return ids
+}
+
+// lockShards write-locks the shards owning ids, each once and in index order so concurrent
+// batches cannot deadlock, and returns the function that unlocks them
+func (ms *MemoryStorage) lockShards(ids []string) func() {
+	locked := make([]bool, len(ms.shards))
+	for _, id := range ids {
+		locked[ms.fnv32Hash(id)%ms.shardCount] = true
+	}
+
+	shards := make([]*shard, 0, len(ids))
+	for i, lock := range locked {
+		if lock {
+			ms.shards[i].mutex.Lock()
+			shards = append(shards, ms.shards[i])
+		}
+	}
+
+	return func() {
+		for _, shard := range shards {
+			shard.mutex.Unlock()
+		}
+	}
+}
+
+// planBatch evaluates the operations in order, each seeing the changes of the ones before it,
+// without storing anything; ids holds the task ID of each operation (see batchTaskIDs).
+// The error is only set when the batch as a whole fails. Must be called with the shards of ids locked.
+func (ms *MemoryStorage) planBatch(ctx context.Context, ops []models.BulkOperation, ids []string, mode models.BulkMode) (*batchPlan, error) {
+	plan := &batchPlan{results: make([]models.BatchResult, len(ops))}
+	staged := make(map[string]*models.Task) // Tasks changed by earlier operations, nil once deleted
+	count := int(atomic.LoadInt64(&ms.taskCount))
+
+	current := func(id string) *models.Task {
+		if task, ok := staged[id]; ok {
+			return task
+		}
+		return ms.getShard(id).tasks[id]
+	}
+
+	for i := range ops {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		change, err := ms.planOperation(ctx, &ops[i], ids[i], current(ids[i]), count)
+		if err != nil {
+			if mode == models.BulkAtomic {
+				return abortedPlan(len(ops), i, err), nil
+			}
+			plan.results[i].Err = err
+			continue
+		}
+
+		if change.deleted != "" {
+			staged[change.deleted] = nil
+			count--
+		} else {
+			if change.revision.Action == models.RevisionCreate {
+				count++
+			}
+			staged[change.task.ID] = change.task
+			plan.results[i].Task = change.task.Clone()
+		}
+		plan.changes = append(plan.changes, change)
+	}
+
+	return plan, nil
+}
+
+// planOperation evaluates one batch operation against the task it affects (nil if there is none)
+// count is the number of tasks after the earlier operations of the batch.
+func (ms *MemoryStorage) planOperation(ctx context.Context, op *models.BulkOperation, id string, task *models.Task, count int) (batchChange, error) {
+	if op.Op == models.BulkCreate {
+		if err := checkCreate(op.Task); err != nil {
+			return batchChange{}, err
+		}
+		if count >= ms.maxTasks {
+			return batchChange{}, quotaExceededError(ms.maxTasks)
+		}
+
+		created := op.Task.ToTask()
+		created.ID = id
+		revision := models.NewTaskRevision(models.RevisionCreate, nil, created, models.ActorFromContext(ctx))
+		return batchChange{task: created, revision: revision}, nil
+	}
+
+	if op.Op == models.BulkUpdate {
+		if err := op.Changes.Validate(); err != nil {
+			return batchChange{}, validationError(err)
+		}
+		if !op.Changes.HasUpdates() {
+			return batchChange{}, noUpdatesError()
+		}
+	}
+
+	if task == nil {
+		return batchChange{}, notFoundError(id)
+	}
+	if op.Version > 0 && task.Version != op.Version {
+		return batchChange{}, versionMismatchError(id, op.Version, task.Version)
+	}
+
+	if op.Op == models.BulkDelete {
+		return batchChange{deleted: id, previous: task}, nil
+	}
+
+	updated, revision, err := nextVersion(ctx, task, op.Changes, 0)
+	if err != nil {
+		return batchChange{}, err
+	}
+	return batchChange{task: updated, revision: revision, previous: task}, nil
+}
+
+// abortedPlan builds the plan of an atomic batch whose operation failed with err:
+// nothing is applied and every other operation reports ErrBatchAborted
+func abortedPlan(size, failed int, err error) *batchPlan {
+	plan := &batchPlan{results: make([]models.BatchResult, size)}
+	for i := range plan.results {
+		plan.results[i].Err = batchAbortedError(failed)
+	}
+	plan.results[failed].Err = err
+	return plan
+}
+
+// commitBatch stores the changes of a planned batch
+// Must be called with the shards of every changed task locked.
+func (ms *MemoryStorage) commitBatch(plan *batchPlan) {
+	for _, change := range plan.changes {
+		if change.deleted != "" {
+			shard := ms.getShard(change.deleted)
+			if task, exists := shard.tasks[change.deleted]; exists {
+				delete(shard.tasks, change.deleted)
+				delete(shard.history, change.deleted)
+				ms.index.remove(task)
+				ms.search.Remove(change.deleted)
+				atomic.AddInt64(&ms.taskCount, -1)
+			}
+			continue
+		}
+
+		shard := ms.getShard(change.task.ID)
+		if change.revision.Action == models.RevisionCreate {
+			shard.history[change.task.ID] = []*models.TaskRevision{change.revision}
+			ms.index.insert(change.task)
+			atomic.AddInt64(&ms.taskCount, 1)
+		} else {
+			ms.appendRevision(shard, change.task.ID, change.revision)
+		}
+		shard.tasks[change.task.ID] = change.task
+		ms.search.Add(change.task)
+	}
+}
+
 // Count returns the total number of tasks using atomic operation for O(1) performance
-func (ms *MemoryStorage) Count() (int, error) {
+func (ms *MemoryStorage) Count(ctx context.Context) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
 	count := atomic.LoadInt64(&ms.taskCount)
 	return int(count), nil
 }
 
 // Clear removes all tasks from all shards (primarily for testing)
-func (ms *MemoryStorage) Clear() error {
+func (ms *MemoryStorage) Clear(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	// Clear all shards
 	for _, shard := range ms.shards {
 		shard.mutex.Lock()
 		shard.tasks = make(map[string]*models.Task)
+		shard.history = make(map[string][]*models.TaskRevision)
 		shard.mutex.Unlock()
 	}
+	ms.index.clear()
+	ms.search.Clear()
 
 	// Reset task count
 	atomic.StoreInt64(&ms.taskCount, 0)
@@ -248,8 +670,115 @@ func (ms *MemoryStorage) Clear() error {
 	return nil
 }
 
+// restoreTask inserts or replaces a task exactly as given, bypassing validation and limits
+// Used by durable backends when replaying persisted state or rolling back a failed write.
+// Tasks persisted before versioning was introduced are restored as version 1.
+func (ms *MemoryStorage) restoreTask(task *models.Task) {
+	taskCopy := task.Clone()
+	if taskCopy.Version == 0 {
+		taskCopy.Version = 1
+	}
+
+	shard := ms.getShard(task.ID)
+	shard.mutex.Lock()
+	previous, exists := shard.tasks[task.ID]
+	shard.tasks[task.ID] = taskCopy
+	if exists {
+		ms.index.remove(previous)
+	}
+	ms.index.insert(taskCopy)
+	ms.search.Add(taskCopy)
+	shard.mutex.Unlock()
+
+	if !exists {
+		atomic.AddInt64(&ms.taskCount, 1)
+	}
+}
+
+// removeTask deletes a task if it exists, silently ignoring unknown IDs
+// Used by durable backends when replaying persisted state or rolling back a failed write
+func (ms *MemoryStorage) removeTask(id string) {
+	shard := ms.getShard(id)
+	shard.mutex.Lock()
+	task, exists := shard.tasks[id]
+	delete(shard.tasks, id)
+	delete(shard.history, id)
+	if exists {
+		ms.index.remove(task)
+		ms.search.Remove(id)
+	}
+	shard.mutex.Unlock()
+
+	if exists {
+		atomic.AddInt64(&ms.taskCount, -1)
+	}
+}
+
+// appendRevision appends revision to the history of task id, dropping the oldest
+// revisions beyond the per-task limit. Must be called with the shard lock held.
+func (ms *MemoryStorage) appendRevision(shard *shard, id string, revision *models.TaskRevision) {
+	history := append(shard.history[id], revision)
+	if excess := len(history) - ms.maxHistory; excess > 0 {
+		history = slices.Delete(history, 0, excess)
+	}
+	shard.history[id] = history
+}
+
+// restoreRevisions appends revisions newer than the task's latest stored revision
+// Older or duplicate versions are skipped so replaying a log over a newer snapshot is safe.
+// Used by durable backends when replaying persisted state or rolling back a failed delete
+func (ms *MemoryStorage) restoreRevisions(id string, revisions []*models.TaskRevision) {
+	shard := ms.getShard(id)
+	shard.mutex.Lock()
+	defer shard.mutex.Unlock()
+
+	for _, revision := range revisions {
+		history := shard.history[id]
+		if len(history) > 0 && history[len(history)-1].Version >= revision.Version {
+			continue
+		}
+		ms.appendRevision(shard, id, revision.Clone())
+	}
+}
+
+// truncateHistory drops every revision at or above the given version
+// Used by durable backends when rolling back a failed write
+func (ms *MemoryStorage) truncateHistory(id string, version int) {
+	shard := ms.getShard(id)
+	shard.mutex.Lock()
+	defer shard.mutex.Unlock()
+
+	history := shard.history[id]
+	for len(history) > 0 && history[len(history)-1].Version >= version {
+		history = history[:len(history)-1]
+	}
+	shard.history[id] = history
+}
+
+// allHistory returns copies of the revisions of every task, keyed by task ID
+// Used by durable backends when writing a snapshot
+func (ms *MemoryStorage) allHistory() map[string][]*models.TaskRevision {
+	all := make(map[string][]*models.TaskRevision)
+	for _, shard := range ms.shards {
+		shard.mutex.RLock()
+		for id, revisions := range shard.history {
+			copies := make([]*models.TaskRevision, len(revisions))
+			for i, revision := range revisions {
+				copies[i] = revision.Clone()
+			}
+			all[id] = copies
+		}
+		shard.mutex.RUnlock()
+	}
+	return all
+}
+
 // HealthCheck verifies if the storage is accessible and functioning
-func (ms *MemoryStorage) HealthCheck() error {
+func (ms *MemoryStorage) HealthCheck(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	// Check if shards are properly initialized
 	if len(ms.shards) == 0 {
 		return fmt.Errorf("memory storage shards are not properly initialized")
@@ -266,15 +795,21 @@ func (ms *MemoryStorage) HealthCheck() error {
 }
 
 // GetStats returns statistics about the memory storage
-func (ms *MemoryStorage) GetStats() StorageStats {
+// Cancellation is checked between shards so a long scan stops once the caller is gone.
+func (ms *MemoryStorage) GetStats(ctx context.Context) (StorageStats, error) {
 	completedCount := 0
 	incompleteCount := 0
+	workflow := models.CurrentWorkflow()
 
 	// Collect stats from all shards
 	for _, shard := range ms.shards {
+		if err := ctx.Err(); err != nil {
+			return StorageStats{}, err
+		}
+
 		shard.mutex.RLock()
 		for _, task := range shard.tasks {
-			if task.Status == models.TaskCompleted {
+			if workflow.IsTerminal(task.Status) {
 				completedCount++
 			} else {
 				incompleteCount++
@@ -291,7 +826,7 @@ func (ms *MemoryStorage) GetStats() StorageStats {
 		IncompleteTasks: incompleteCount,
 		LastID:          0, // UUID doesn't use numeric IDs, set to 0
 		StorageType:     "sharded_memory",
-	}
+	}, nil
 }
 
 // GetMaxTasks returns the maximum number of tasks allowed
@@ -299,6 +834,15 @@ func (ms *MemoryStorage) GetMaxTasks() int {
 	return ms.maxTasks
 }
 
+// SetMaxRevisions sets how many revisions are kept per task (<= 0 = DefaultMaxRevisions)
+// Once a task exceeds the limit its oldest revisions are dropped. Call it before storing tasks.
+func (ms *MemoryStorage) SetMaxRevisions(maxRevisions int) {
+	if maxRevisions <= 0 {
+		maxRevisions = DefaultMaxRevisions
+	}
+	ms.maxHistory = maxRevisions
+}
+
 // GetUsage returns current storage usage information including shard statistics
 func (ms *MemoryStorage) GetUsage() map[string]interface{} {
 	currentCount := atomic.LoadInt64(&ms.taskCount)
@@ -323,16 +867,20 @@ func (ms *MemoryStorage) GetUsage() map[string]interface{} {
 }
 
 // GetTasksByStatus returns all tasks with the specified status from all shards
-func (ms *MemoryStorage) GetTasksByStatus(status models.TaskStatus) ([]*models.Task, error) {
+// Cancellation is checked between shards so a long scan stops once the caller is gone.
+func (ms *MemoryStorage) GetTasksByStatus(ctx context.Context, status models.TaskStatus) ([]*models.Task, error) {
 	var tasks []*models.Task
 
 	// Collect tasks from all shards
 	for _, shard := range ms.shards {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		shard.mutex.RLock()
 		for _, task := range shard.tasks {
 			if task.Status == status {
-				taskCopy := *task
-				tasks = append(tasks, &taskCopy)
+				tasks = append(tasks, task.Clone())
 			}
 		}
 		shard.mutex.RUnlock()
@@ -342,16 +890,19 @@ func (ms *MemoryStorage) GetTasksByStatus(status models.TaskStatus) ([]*models.T
 }
 
 // GetTasksCreatedAfter returns tasks created after the specified time from all shards
-func (ms *MemoryStorage) GetTasksCreatedAfter(after time.Time) ([]*models.Task, error) {
+func (ms *MemoryStorage) GetTasksCreatedAfter(ctx context.Context, after time.Time) ([]*models.Task, error) {
 	var tasks []*models.Task
 
 	// Collect tasks from all shards
 	for _, shard := range ms.shards {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		shard.mutex.RLock()
 		for _, task := range shard.tasks {
 			if task.CreatedAt.After(after) {
-				taskCopy := *task
-				tasks = append(tasks, &taskCopy)
+				tasks = append(tasks, task.Clone())
 			}
 		}
 		shard.mutex.RUnlock()
@@ -360,42 +911,108 @@ func (ms *MemoryStorage) GetTasksCreatedAfter(after time.Time) ([]*models.Task,
 	return tasks, nil
 }
 
-// GetTasksPaginated returns a paginated list of tasks from all shards
-func (ms *MemoryStorage) GetTasksPaginated(offset, limit int) ([]*models.Task, int, error) {
-	// Get all tasks first (could be optimized further with shard-level pagination)
-	allTasks := make([]*models.Task, 0, atomic.LoadInt64(&ms.taskCount))
+// QueryTasks returns the filtered tasks in the requested order
+// A status or creation-time condition narrows the shard scan so only candidate tasks are copied.
+func (ms *MemoryStorage) QueryTasks(ctx context.Context, filter *models.TaskFilter, order []models.SortField) ([]*models.Task, error) {
+	var (
+		candidates []*models.Task
+		err        error
+	)
+	switch {
+	case filter.Status != nil:
+		candidates, err = ms.GetTasksByStatus(ctx, *filter.Status)
+	case filter.CreatedAfter != nil:
+		candidates, err = ms.GetTasksCreatedAfter(ctx, *filter.CreatedAfter)
+	default:
+		candidates, err = ms.GetAll(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
 
-	for _, shard := range ms.shards {
-		shard.mutex.RLock()
-		for _, task := range shard.tasks {
-			taskCopy := *task
-			allTasks = append(allTasks, &taskCopy)
+	tasks := filter.Apply(candidates)
+	models.SortTasks(tasks, order)
+	return tasks, nil
+}
+
+// GetTasksPaginated returns a page of tasks in creation order, plus the total task count
+// The page is located in the creation index, so it costs O(log n + limit) rather than a scan
+// of every shard. Tasks deleted while the page is read are left out.
+func (ms *MemoryStorage) GetTasksPaginated(ctx context.Context, offset, limit int) ([]*models.Task, int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	tasks := make([]*models.Task, 0, limit)
+	for _, key := range ms.index.slice(offset, limit) {
+		if task := ms.lookup(key.ID); task != nil {
+			tasks = append(tasks, task)
 		}
-		shard.mutex.RUnlock()
 	}
 
-	total := len(allTasks)
+	return tasks, int(atomic.LoadInt64(&ms.taskCount)), nil
+}
 
-	// Handle edge cases
-	if offset >= total {
-		return []*models.Task{}, total, nil
-	}
+// GetTasksAfter returns up to limit tasks matching filter that come after the cursor in creation order
+// Positions are read from the creation index in chunks, so an unfiltered page costs O(log n + limit).
+func (ms *MemoryStorage) GetTasksAfter(ctx context.Context, filter *models.TaskFilter, after *models.TaskCursor, limit int) ([]*models.Task, bool, error) {
+	tasks := make([]*models.Task, 0, limit)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, false, err
+		}
+
+		// Ask for one task more than needed to learn whether another page follows
+		wanted := limit + 1 - len(tasks)
+		keys := ms.index.after(after, wanted)
+		for _, key := range keys {
+			task := ms.lookup(key.ID)
+			if task == nil || !filter.Matches(task) {
+				continue
+			}
+			if len(tasks) == limit {
+				return tasks, true, nil
+			}
+			tasks = append(tasks, task)
+		}
 
-	end := offset + limit
-	if end > total {
-		end = total
+		if len(keys) < wanted {
+			return tasks, false, nil
+		}
+		after = &keys[len(keys)-1]
 	}
+}
 
-	paginatedTasks := allTasks[offset:end]
+// SearchTasks returns up to limit tasks matching the full-text query, best match first
+// The index is maintained under the shard locks on every write, so it always reflects the stored tasks.
+func (ms *MemoryStorage) SearchTasks(ctx context.Context, query string, limit int) ([]models.SearchResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
-	return paginatedTasks, total, nil
+	hits := ms.search.Search(query, limit)
+	results := make([]models.SearchResult, 0, len(hits))
+	for _, hit := range hits {
+		// Tasks deleted since the search are left out
+		if task := ms.lookup(hit.ID); task != nil {
+			results = append(results, models.SearchResult{Task: task, Score: hit.Score})
+		}
+	}
+	return results, nil
 }
 
-// StorageStats represents statistics about the storage
-type StorageStats struct {
-	TotalTasks      int    `json:"total_tasks"`      // Total number of tasks
-	CompletedTasks  int    `json:"completed_tasks"`  // Number of completed tasks
-	IncompleteTasks int    `json:"incomplete_tasks"` // Number of incomplete tasks
-	LastID          int    `json:"last_id"`          // Last generated ID
-	StorageType     string `json:"storage_type"`     // Type of storage (sharded_memory, database, etc.)
+// lookup returns a copy of the task with the given ID, or nil if it does not exist
+func (ms *MemoryStorage) lookup(id string) *models.Task {
+	shard := ms.getShard(id)
+	shard.mutex.RLock()
+	defer shard.mutex.RUnlock()
+
+	if task, exists := shard.tasks[id]; exists {
+		return task.Clone()
+	}
+	return nil
 }
+
+// StorageStats is kept as an alias so existing callers of the storage package keep compiling
+type StorageStats = models.StorageStats
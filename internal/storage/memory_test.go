@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"context"
 	"strconv"
 	"sync"
 	"task-api/internal/models"
@@ -28,6 +29,7 @@ func TestNewMemoryStorage(t *testing.T) {
 }
 
 func TestMemoryStorage_Create(t *testing.T) {
+	ctx := context.Background()
 	storage := NewMemoryStorage(1000)
 
 	tests := []struct {
@@ -83,7 +85,7 @@ func TestMemoryStorage_Create(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			task, err := storage.Create(tt.request)
+			task, err := storage.Create(ctx, tt.request)
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -103,10 +105,11 @@ func TestMemoryStorage_Create(t *testing.T) {
 }
 
 func TestMemoryStorage_GetAll(t *testing.T) {
+	ctx := context.Background()
 	storage := NewMemoryStorage(1000)
 
 	// Initially empty
-	tasks, err := storage.GetAll()
+	tasks, err := storage.GetAll(ctx)
 	assert.NoError(t, err)
 	assert.Empty(t, tasks)
 
@@ -114,13 +117,13 @@ func TestMemoryStorage_GetAll(t *testing.T) {
 	req1 := &models.CreateTaskRequest{Name: "Task 1", Status: models.TaskIncomplete}
 	req2 := &models.CreateTaskRequest{Name: "Task 2", Status: models.TaskCompleted}
 
-	task1, err := storage.Create(req1)
+	task1, err := storage.Create(ctx, req1)
 	require.NoError(t, err)
-	task2, err := storage.Create(req2)
+	task2, err := storage.Create(ctx, req2)
 	require.NoError(t, err)
 
 	// Get all tasks
-	tasks, err = storage.GetAll()
+	tasks, err = storage.GetAll(ctx)
 	assert.NoError(t, err)
 	assert.Len(t, tasks, 2)
 
@@ -134,15 +137,16 @@ func TestMemoryStorage_GetAll(t *testing.T) {
 }
 
 func TestMemoryStorage_GetByID(t *testing.T) {
+	ctx := context.Background()
 	storage := NewMemoryStorage(1000)
 
 	// Create a task
 	req := &models.CreateTaskRequest{Name: "Test Task", Status: models.TaskIncomplete}
-	createdTask, err := storage.Create(req)
+	createdTask, err := storage.Create(ctx, req)
 	require.NoError(t, err)
 
 	// Get existing task
-	task, err := storage.GetByID(createdTask.ID)
+	task, err := storage.GetByID(ctx, createdTask.ID)
 	assert.NoError(t, err)
 	assert.NotNil(t, task)
 	assert.Equal(t, createdTask.ID, task.ID)
@@ -150,18 +154,19 @@ func TestMemoryStorage_GetByID(t *testing.T) {
 	assert.Equal(t, createdTask.Status, task.Status)
 
 	// Get non-existing task
-	task, err = storage.GetByID("non-existing")
+	task, err = storage.GetByID(ctx, "non-existing")
 	assert.Error(t, err)
 	assert.Nil(t, task)
 	assert.Contains(t, err.Error(), "not found")
 }
 
 func TestMemoryStorage_Update(t *testing.T) {
+	ctx := context.Background()
 	storage := NewMemoryStorage(1000)
 
 	// Create a task first
 	createReq := &models.CreateTaskRequest{Name: "Original Task", Status: models.TaskIncomplete}
-	createdTask, err := storage.Create(createReq)
+	createdTask, err := storage.Create(ctx, createReq)
 	require.NoError(t, err)
 
 	tests := []struct {
@@ -234,7 +239,7 @@ func TestMemoryStorage_Update(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			updatedTask, err := storage.Update(tt.taskID, tt.request)
+			updatedTask, err := storage.Update(ctx, tt.taskID, tt.request)
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -261,38 +266,40 @@ func TestMemoryStorage_Update(t *testing.T) {
 }
 
 func TestMemoryStorage_Delete(t *testing.T) {
+	ctx := context.Background()
 	storage := NewMemoryStorage(1000)
 
 	// Create a task
 	req := &models.CreateTaskRequest{Name: "Test Task", Status: models.TaskIncomplete}
-	createdTask, err := storage.Create(req)
+	createdTask, err := storage.Create(ctx, req)
 	require.NoError(t, err)
 
 	// Verify task exists
-	task, err := storage.GetByID(createdTask.ID)
+	task, err := storage.GetByID(ctx, createdTask.ID)
 	assert.NoError(t, err)
 	assert.NotNil(t, task)
 
 	// Delete the task
-	err = storage.Delete(createdTask.ID)
+	err = storage.Delete(ctx, createdTask.ID)
 	assert.NoError(t, err)
 
 	// Verify task no longer exists
-	task, err = storage.GetByID(createdTask.ID)
+	task, err = storage.GetByID(ctx, createdTask.ID)
 	assert.Error(t, err)
 	assert.Nil(t, task)
 
 	// Delete non-existing task should fail
-	err = storage.Delete("non-existing")
+	err = storage.Delete(ctx, "non-existing")
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "not found")
 }
 
 func TestMemoryStorage_Count(t *testing.T) {
+	ctx := context.Background()
 	storage := NewMemoryStorage(1000)
 
 	// Initially zero
-	count, err := storage.Count()
+	count, err := storage.Count(ctx)
 	assert.NoError(t, err)
 	assert.Equal(t, 0, count)
 
@@ -300,38 +307,39 @@ func TestMemoryStorage_Count(t *testing.T) {
 	req := &models.CreateTaskRequest{Name: "Test Task", Status: models.TaskIncomplete}
 
 	for i := 0; i < 5; i++ {
-		_, err := storage.Create(req)
+		_, err := storage.Create(ctx, req)
 		require.NoError(t, err)
 	}
 
 	// Count should be 5
-	count, err = storage.Count()
+	count, err = storage.Count(ctx)
 	assert.NoError(t, err)
 	assert.Equal(t, 5, count)
 }
 
 func TestMemoryStorage_Clear(t *testing.T) {
+	ctx := context.Background()
 	storage := NewMemoryStorage(1000)
 
 	// Create some tasks
 	req := &models.CreateTaskRequest{Name: "Test Task", Status: models.TaskIncomplete}
 
 	for i := 0; i < 3; i++ {
-		_, err := storage.Create(req)
+		_, err := storage.Create(ctx, req)
 		require.NoError(t, err)
 	}
 
 	// Verify tasks exist
-	count, err := storage.Count()
+	count, err := storage.Count(ctx)
 	assert.NoError(t, err)
 	assert.Equal(t, 3, count)
 
 	// Clear storage
-	err = storage.Clear()
+	err = storage.Clear(ctx)
 	assert.NoError(t, err)
 
 	// Verify storage is empty
-	count, err = storage.Count()
+	count, err = storage.Count(ctx)
 	assert.NoError(t, err)
 	assert.Equal(t, 0, count)
 
@@ -340,20 +348,22 @@ func TestMemoryStorage_Clear(t *testing.T) {
 }
 
 func TestMemoryStorage_HealthCheck(t *testing.T) {
+	ctx := context.Background()
 	storage := NewMemoryStorage(1000)
 
 	// Normal storage should be healthy
-	err := storage.HealthCheck()
+	err := storage.HealthCheck(ctx)
 	assert.NoError(t, err)
 
 	// Nil shards should fail health check
 	storage.shards = nil
-	err = storage.HealthCheck()
+	err = storage.HealthCheck(ctx)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "not properly initialized")
 }
 
 func TestMemoryStorage_GetStats(t *testing.T) {
+	ctx := context.Background()
 	storage := NewMemoryStorage(1000)
 
 	// Create tasks with different statuses
@@ -362,16 +372,17 @@ func TestMemoryStorage_GetStats(t *testing.T) {
 
 	// Create 3 incomplete and 2 completed tasks
 	for i := 0; i < 3; i++ {
-		_, err := storage.Create(incompleteReq)
+		_, err := storage.Create(ctx, incompleteReq)
 		require.NoError(t, err)
 	}
 
 	for i := 0; i < 2; i++ {
-		_, err := storage.Create(completedReq)
+		_, err := storage.Create(ctx, completedReq)
 		require.NoError(t, err)
 	}
 
-	stats := storage.GetStats()
+	stats, err := storage.GetStats(ctx)
+	require.NoError(t, err)
 	assert.Equal(t, 5, stats.TotalTasks)
 	assert.Equal(t, 3, stats.IncompleteTasks)
 	assert.Equal(t, 2, stats.CompletedTasks)
@@ -380,6 +391,7 @@ func TestMemoryStorage_GetStats(t *testing.T) {
 }
 
 func TestMemoryStorage_GetTasksByStatus(t *testing.T) {
+	ctx := context.Background()
 	storage := NewMemoryStorage(1000)
 
 	// Create tasks with different statuses
@@ -388,15 +400,15 @@ func TestMemoryStorage_GetTasksByStatus(t *testing.T) {
 
 	// Create 2 incomplete and 1 completed task
 	for i := 0; i < 2; i++ {
-		_, err := storage.Create(incompleteReq)
+		_, err := storage.Create(ctx, incompleteReq)
 		require.NoError(t, err)
 	}
 
-	_, err := storage.Create(completedReq)
+	_, err := storage.Create(ctx, completedReq)
 	require.NoError(t, err)
 
 	// Get incomplete tasks
-	incompleteTasks, err := storage.GetTasksByStatus(models.TaskIncomplete)
+	incompleteTasks, err := storage.GetTasksByStatus(ctx, models.TaskIncomplete)
 	assert.NoError(t, err)
 	assert.Len(t, incompleteTasks, 2)
 	for _, task := range incompleteTasks {
@@ -404,7 +416,7 @@ func TestMemoryStorage_GetTasksByStatus(t *testing.T) {
 	}
 
 	// Get completed tasks
-	completedTasks, err := storage.GetTasksByStatus(models.TaskCompleted)
+	completedTasks, err := storage.GetTasksByStatus(ctx, models.TaskCompleted)
 	assert.NoError(t, err)
 	assert.Len(t, completedTasks, 1)
 	for _, task := range completedTasks {
@@ -413,35 +425,36 @@ func TestMemoryStorage_GetTasksByStatus(t *testing.T) {
 }
 
 func TestMemoryStorage_GetTasksPaginated(t *testing.T) {
+	ctx := context.Background()
 	storage := NewMemoryStorage(1000)
 
 	// Create 10 tasks
 	req := &models.CreateTaskRequest{Name: "Test Task", Status: models.TaskIncomplete}
 	for i := 0; i < 10; i++ {
-		_, err := storage.Create(req)
+		_, err := storage.Create(ctx, req)
 		require.NoError(t, err)
 	}
 
 	// Test pagination
-	tasks, total, err := storage.GetTasksPaginated(0, 5)
+	tasks, total, err := storage.GetTasksPaginated(ctx, 0, 5)
 	assert.NoError(t, err)
 	assert.Len(t, tasks, 5)
 	assert.Equal(t, 10, total)
 
 	// Test second page
-	tasks, total, err = storage.GetTasksPaginated(5, 5)
+	tasks, total, err = storage.GetTasksPaginated(ctx, 5, 5)
 	assert.NoError(t, err)
 	assert.Len(t, tasks, 5)
 	assert.Equal(t, 10, total)
 
 	// Test beyond available data
-	tasks, total, err = storage.GetTasksPaginated(10, 5)
+	tasks, total, err = storage.GetTasksPaginated(ctx, 10, 5)
 	assert.NoError(t, err)
 	assert.Len(t, tasks, 0)
 	assert.Equal(t, 10, total)
 
 	// Test partial page
-	tasks, total, err = storage.GetTasksPaginated(8, 5)
+	tasks, total, err = storage.GetTasksPaginated(ctx, 8, 5)
 	assert.NoError(t, err)
 	assert.Len(t, tasks, 2)
 	assert.Equal(t, 10, total)
@@ -449,6 +462,7 @@ func TestMemoryStorage_GetTasksPaginated(t *testing.T) {
 
 // Test thread safety with concurrent operations
 func TestMemoryStorage_ConcurrentOperations(t *testing.T) {
+	ctx := context.Background()
 	storage := NewMemoryStorage(1000)
 	const numGoroutines = 10
 	const operationsPerGoroutine = 100
@@ -465,7 +479,7 @@ func TestMemoryStorage_ConcurrentOperations(t *testing.T) {
 					Name:   "Task " + strconv.Itoa(id) + "-" + strconv.Itoa(j),
 					Status: models.TaskIncomplete,
 				}
-				_, err := storage.Create(req)
+				_, err := storage.Create(ctx, req)
 				assert.NoError(t, err)
 			}
 		}(i)
@@ -473,7 +487,7 @@ func TestMemoryStorage_ConcurrentOperations(t *testing.T) {
 	wg.Wait()
 
 	// Verify all tasks were created
-	count, err := storage.Count()
+	count, err := storage.Count(ctx)
 	assert.NoError(t, err)
 	assert.Equal(t, numGoroutines*operationsPerGoroutine, count)
 
@@ -483,7 +497,7 @@ func TestMemoryStorage_ConcurrentOperations(t *testing.T) {
 		go func() {
 			defer wg.Done()
 			for j := 0; j < operationsPerGoroutine; j++ {
-				tasks, err := storage.GetAll()
+				tasks, err := storage.GetAll(ctx)
 				assert.NoError(t, err)
 				assert.NotNil(t, tasks)
 			}
@@ -497,19 +511,24 @@ func stringPtr(s string) *string {
 	return &s
 }
 
+func priorityPtr(priority models.TaskPriority) *models.TaskPriority {
+	return &priority
+}
+
 func taskStatusPtr(status models.TaskStatus) *models.TaskStatus {
 	return &status
 }
 
 // Benchmark tests
 func BenchmarkMemoryStorage_Create(b *testing.B) {
+	ctx := context.Background()
 	// Use a much larger limit to avoid hitting the limit during benchmarks
 	storage := NewMemoryStorage(b.N + 1000)
 	req := &models.CreateTaskRequest{Name: "Benchmark Task", Status: models.TaskIncomplete}
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, err := storage.Create(req)
+		_, err := storage.Create(ctx, req)
 		if err != nil {
 			b.Fatal(err)
 		}
@@ -517,12 +536,13 @@ func BenchmarkMemoryStorage_Create(b *testing.B) {
 }
 
 func BenchmarkMemoryStorage_GetAll(b *testing.B) {
+	ctx := context.Background()
 	storage := NewMemoryStorage(2000) // Increase limit to avoid issues
 	req := &models.CreateTaskRequest{Name: "Benchmark Task", Status: models.TaskIncomplete}
 
 	// Pre-populate with 1000 tasks
 	for i := 0; i < 1000; i++ {
-		_, err := storage.Create(req)
+		_, err := storage.Create(ctx, req)
 		if err != nil {
 			b.Fatal(err)
 		}
@@ -530,7 +550,7 @@ func BenchmarkMemoryStorage_GetAll(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, err := storage.GetAll()
+		_, err := storage.GetAll(ctx)
 		if err != nil {
 			b.Fatal(err)
 		}
@@ -539,45 +559,47 @@ func BenchmarkMemoryStorage_GetAll(b *testing.B) {
 
 // TestMemoryStorage_MaxTasksLimit tests the maximum tasks limit functionality
 func TestMemoryStorage_MaxTasksLimit(t *testing.T) {
+	ctx := context.Background()
 	// Create storage with limit of 3 tasks
 	storage := NewMemoryStorage(3)
 	req := &models.CreateTaskRequest{Name: "Test Task", Status: models.TaskIncomplete}
 
 	// Create 3 tasks - should succeed
 	for i := 0; i < 3; i++ {
-		task, err := storage.Create(req)
+		task, err := storage.Create(ctx, req)
 		assert.NoError(t, err)
 		assert.NotNil(t, task)
 	}
 
 	// Fourth task should fail
-	task, err := storage.Create(req)
+	task, err := storage.Create(ctx, req)
 	assert.Error(t, err)
 	assert.Nil(t, task)
 	assert.Contains(t, err.Error(), "maximum tasks limit reached")
 	assert.Contains(t, err.Error(), "(3)")
 
 	// Verify count is still 3
-	count, err := storage.Count()
+	count, err := storage.Count(ctx)
 	assert.NoError(t, err)
 	assert.Equal(t, 3, count)
 
 	// Delete one task and try again - should succeed
-	tasks, err := storage.GetAll()
+	tasks, err := storage.GetAll(ctx)
 	assert.NoError(t, err)
 	assert.Len(t, tasks, 3)
 
-	err = storage.Delete(tasks[0].ID)
+	err = storage.Delete(ctx, tasks[0].ID)
 	assert.NoError(t, err)
 
 	// Now creating should work again
-	task, err = storage.Create(req)
+	task, err = storage.Create(ctx, req)
 	assert.NoError(t, err)
 	assert.NotNil(t, task)
 }
 
 // TestMemoryStorage_UUIDGeneration tests UUID generation and uniqueness
 func TestMemoryStorage_UUIDGeneration(t *testing.T) {
+	ctx := context.Background()
 	storage := NewMemoryStorage(1000)
 	req := &models.CreateTaskRequest{Name: "Test Task", Status: models.TaskIncomplete}
 
@@ -585,7 +607,7 @@ func TestMemoryStorage_UUIDGeneration(t *testing.T) {
 	createdIDs := make(map[string]bool)
 
 	for i := 0; i < 100; i++ {
-		task, err := storage.Create(req)
+		task, err := storage.Create(ctx, req)
 		assert.NoError(t, err)
 		assert.NotNil(t, task)
 
@@ -606,20 +628,21 @@ func TestMemoryStorage_UUIDGeneration(t *testing.T) {
 
 // TestMemoryStorage_LimitReached tests behavior when limit is reached
 func TestMemoryStorage_LimitReached(t *testing.T) {
+	ctx := context.Background()
 	storage := NewMemoryStorage(2)
 	req := &models.CreateTaskRequest{Name: "Test Task", Status: models.TaskIncomplete}
 
 	// Fill to limit
-	task1, err := storage.Create(req)
+	task1, err := storage.Create(ctx, req)
 	assert.NoError(t, err)
 	assert.NotNil(t, task1)
 
-	task2, err := storage.Create(req)
+	task2, err := storage.Create(ctx, req)
 	assert.NoError(t, err)
 	assert.NotNil(t, task2)
 
 	// Should fail now
-	task3, err := storage.Create(req)
+	task3, err := storage.Create(ctx, req)
 	assert.Error(t, err)
 	assert.Nil(t, task3)
 
@@ -636,7 +659,7 @@ func TestMemoryStorage_LimitReached(t *testing.T) {
 	assert.Equal(t, "sharded_memory", usage["storage_type"])
 
 	// Delete one task and check usage again
-	err = storage.Delete(task1.ID)
+	err = storage.Delete(ctx, task1.ID)
 	assert.NoError(t, err)
 
 	usage = storage.GetUsage()
@@ -662,6 +685,7 @@ func TestMemoryStorage_GetMaxTasks(t *testing.T) {
 
 // TestMemoryStorage_GetUsage tests the GetUsage method
 func TestMemoryStorage_GetUsage(t *testing.T) {
+	ctx := context.Background()
 	storage := NewMemoryStorage(10)
 	req := &models.CreateTaskRequest{Name: "Test Task", Status: models.TaskIncomplete}
 
@@ -677,7 +701,7 @@ func TestMemoryStorage_GetUsage(t *testing.T) {
 
 	// Add 3 tasks
 	for i := 0; i < 3; i++ {
-		_, err := storage.Create(req)
+		_, err := storage.Create(ctx, req)
 		assert.NoError(t, err)
 	}
 
@@ -692,19 +716,63 @@ func TestMemoryStorage_GetUsage(t *testing.T) {
 }
 
 func BenchmarkMemoryStorage_GetByID(b *testing.B) {
+	ctx := context.Background()
 	storage := NewMemoryStorage(2000) // Increase limit to avoid issues
 	req := &models.CreateTaskRequest{Name: "Benchmark Task", Status: models.TaskIncomplete}
 
-	task, err := storage.Create(req)
+	task, err := storage.Create(ctx, req)
 	if err != nil {
 		b.Fatal(err)
 	}
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, err := storage.GetByID(task.ID)
+		_, err := storage.GetByID(ctx, task.ID)
 		if err != nil {
 			b.Fatal(err)
 		}
 	}
 }
+
+func TestMemoryStorage_ConcurrentBatches(t *testing.T) {
+	ctx := context.Background()
+	storage := NewMemoryStorage(1000)
+
+	ids := make([]string, 16)
+	for i := range ids {
+		task, err := storage.Create(ctx, &models.CreateTaskRequest{Name: "Task " + strconv.Itoa(i)})
+		require.NoError(t, err)
+		ids[i] = task.ID
+	}
+
+	// Batches touching the same shards in opposite orders must neither deadlock nor lose updates
+	const workers = 8
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func(w int) {
+			defer wg.Done()
+			ops := make([]models.BulkOperation, len(ids))
+			for i := range ids {
+				id := ids[i]
+				if w%2 == 1 {
+					id = ids[len(ids)-1-i]
+				}
+				ops[i] = models.BulkOperation{Op: models.BulkUpdate, ID: id,
+					Changes: &models.UpdateTaskRequest{Name: stringPtr("Worker " + strconv.Itoa(w))}}
+			}
+			results, err := storage.ApplyBatch(ctx, ops, models.BulkAtomic)
+			assert.NoError(t, err)
+			for _, result := range results {
+				assert.NoError(t, result.Err)
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	for _, id := range ids {
+		task, err := storage.GetByID(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, 1+workers, task.Version)
+	}
+}
@@ -0,0 +1,34 @@
+package storage
+
+import (
+	"context"
+	"task-api/internal/interfaces"
+	"task-api/internal/models"
+)
+
+// changeNotifier reports committed changes to an event publisher
+// Storages embed it to implement interfaces.ChangeNotifier.
+type changeNotifier struct {
+	publisher interfaces.EventPublisher // Receives change events (nil = changes are not reported)
+}
+
+// SetEventPublisher sets the publisher changes are reported to
+func (n *changeNotifier) SetEventPublisher(publisher interfaces.EventPublisher) {
+	n.publisher = publisher
+}
+
+// publish reports the change of a task from before to after made by actor
+// before is nil for a created task and after is nil for a deleted one.
+func (n *changeNotifier) publish(before, after *models.Task, actor string) {
+	if n.publisher != nil {
+		n.publisher.Publish(models.NewTaskEvent(before, after, actor))
+	}
+}
+
+// publishBatch reports the changes of an applied batch, in operation order
+func (n *changeNotifier) publishBatch(ctx context.Context, plan *batchPlan) {
+	actor := models.ActorFromContext(ctx)
+	for _, change := range plan.changes {
+		n.publish(change.previous, change.task, actor)
+	}
+}
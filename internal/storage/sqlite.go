@@ -0,0 +1,1102 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"task-api/internal/interfaces"
+	"task-api/internal/models"
+	"task-api/internal/search"
+	"time"
+
+	"github.com/google/uuid"
+	"modernc.org/sqlite" // Pure Go SQLite driver (no cgo required)
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// sqliteTimeFormat is a fixed-width UTC layout so timestamps sort correctly as text
+const sqliteTimeFormat = "2006-01-02T15:04:05.000000000Z"
+
+// sqliteMigrations holds the ordered schema migrations; index + 1 is the schema version
+// Never edit an applied migration - append a new one instead.
+var sqliteMigrations = []string{
+	// 1: initial tasks table with indexes for status filtering and chronological listing
+	`CREATE TABLE IF NOT EXISTS tasks (
+		id         TEXT PRIMARY KEY,
+		name       TEXT NOT NULL,
+		status     INTEGER NOT NULL DEFAULT 0,
+		created_at TEXT NOT NULL,
+		updated_at TEXT NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_tasks_status ON tasks(status);
+	CREATE INDEX IF NOT EXISTS idx_tasks_created_at ON tasks(created_at);`,
+
+	// 2: rich task fields; tags are stored as a JSON array
+	`ALTER TABLE tasks ADD COLUMN description TEXT NOT NULL DEFAULT '';
+	ALTER TABLE tasks ADD COLUMN priority INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE tasks ADD COLUMN due_date TEXT;
+	ALTER TABLE tasks ADD COLUMN tags TEXT NOT NULL DEFAULT '[]';
+	CREATE INDEX IF NOT EXISTS idx_tasks_priority ON tasks(priority);
+	CREATE INDEX IF NOT EXISTS idx_tasks_due_date ON tasks(due_date);`,
+
+	// 3: task versions and revision history; changes and the task snapshot are stored as JSON
+	`ALTER TABLE tasks ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+	CREATE TABLE IF NOT EXISTS task_revisions (
+		task_id       TEXT NOT NULL,
+		version       INTEGER NOT NULL,
+		action        TEXT NOT NULL,
+		actor         TEXT NOT NULL,
+		changed_at    TEXT NOT NULL,
+		reverted_from INTEGER NOT NULL DEFAULT 0,
+		changes       TEXT NOT NULL DEFAULT '[]',
+		task          TEXT NOT NULL,
+		PRIMARY KEY (task_id, version)
+	);`,
+
+	// 4: task ownership; tasks created before authentication was enabled have no owner
+	`ALTER TABLE tasks ADD COLUMN owner_id TEXT NOT NULL DEFAULT '';
+	CREATE INDEX IF NOT EXISTS idx_tasks_owner_id ON tasks(owner_id);`,
+}
+
+// taskColumns is the column list shared by every task query
+const taskColumns = "id, name, description, status, priority, due_date, tags, owner_id, version, created_at, updated_at"
+
+// revisionColumns is the column list shared by every revision query
+const revisionColumns = "version, action, actor, changed_at, reverted_from, changes, task"
+
+// SQLiteStorageConfig defines configuration for the SQLite storage
+type SQLiteStorageConfig struct {
+	Path         string // Database file path
+	MaxTasks     int    // Maximum number of tasks allowed
+	MaxRevisions int    // Maximum number of revisions kept per task, oldest dropped first
+}
+
+// SQLiteStorage implements TaskStorage interface on top of an embedded SQLite database
+// Tasks live in a regular table so they can be queried with ad-hoc SQL and backed up
+// with standard SQLite tooling.
+type SQLiteStorage struct {
+	db          *sql.DB       // Database handle (connection pool)
+	path        string        // Database file path
+	maxTasks    int           // Maximum number of tasks allowed
+	maxHistory  int           // Maximum number of revisions kept per task
+	search      *search.Index // Full-text index of task names and descriptions, rebuilt on open
+	commitMutex sync.Mutex    // Orders commits with their search index changes and events
+
+	changeNotifier // Reports committed changes to the event publisher
+}
+
+// Ensure SQLiteStorage implements required interfaces at compile time
+var (
+	_ interfaces.TaskStorage       = (*SQLiteStorage)(nil)
+	_ interfaces.HealthChecker     = (*SQLiteStorage)(nil)
+	_ interfaces.StatusQuerier     = (*SQLiteStorage)(nil)
+	_ interfaces.TaskQuerier       = (*SQLiteStorage)(nil)
+	_ interfaces.Paginator         = (*SQLiteStorage)(nil)
+	_ interfaces.CursorPaginator   = (*SQLiteStorage)(nil)
+	_ interfaces.TaskSearcher      = (*SQLiteStorage)(nil)
+	_ interfaces.StatsProvider     = (*SQLiteStorage)(nil)
+	_ interfaces.UsageReporter     = (*SQLiteStorage)(nil)
+	_ interfaces.HistoryProvider   = (*SQLiteStorage)(nil)
+	_ interfaces.ConditionalWriter = (*SQLiteStorage)(nil)
+	_ interfaces.BatchWriter       = (*SQLiteStorage)(nil)
+	_ interfaces.ChangeNotifier    = (*SQLiteStorage)(nil)
+)
+
+// NewSQLiteStorage opens (or creates) the database and applies pending schema migrations
+func NewSQLiteStorage(config SQLiteStorageConfig) (*SQLiteStorage, error) {
+	if config.Path == "" {
+		return nil, fmt.Errorf("database path is required for sqlite storage")
+	}
+	if config.MaxTasks <= 0 {
+		config.MaxTasks = 10000 // Default value
+	}
+	if config.MaxRevisions <= 0 {
+		config.MaxRevisions = DefaultMaxRevisions
+	}
+
+	if dir := filepath.Dir(config.Path); dir != "" {
+		if err := os.MkdirAll(dir, 0o750); err != nil {
+			return nil, fmt.Errorf("failed to create database directory: %w", err)
+		}
+	}
+
+	// WAL journal lets readers proceed during writes; immediate transactions take the
+	// write lock up front so concurrent writers wait on busy_timeout instead of failing
+	dsn := "file:" + config.Path +
+		"?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)&_pragma=synchronous(NORMAL)&_txlock=immediate"
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	storage := &SQLiteStorage{
+		db:         db,
+		path:       config.Path,
+		maxTasks:   config.MaxTasks,
+		maxHistory: config.MaxRevisions,
+		search:     search.NewIndex(),
+	}
+
+	if err := storage.migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	if err := storage.buildSearchIndex(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return storage, nil
+}
+
+// buildSearchIndex indexes every stored task
+func (s *SQLiteStorage) buildSearchIndex() error {
+	tasks, err := s.GetAll(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to build search index: %w", err)
+	}
+
+	for _, task := range tasks {
+		s.search.Add(task)
+	}
+	return nil
+}
+
+// commit commits tx and then applies the matching search index changes and publishes the
+// change events
+// Write transactions take the database lock up front, so holding commitMutex from the commit
+// until committed returns makes the index and the change feed see writes in commit order.
+func (s *SQLiteStorage) commit(tx *sql.Tx, committed func(idx *search.Index)) error {
+	s.commitMutex.Lock()
+	defer s.commitMutex.Unlock()
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	committed(s.search)
+	return nil
+}
+
+// migrate applies every migration newer than the recorded schema version
+func (s *SQLiteStorage) migrate() error {
+	if _, err := s.db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		applied_at TEXT NOT NULL
+	)`); err != nil {
+		return fmt.Errorf("failed to create migrations table: %w", err)
+	}
+
+	current, err := s.SchemaVersion()
+	if err != nil {
+		return err
+	}
+
+	for i := current; i < len(sqliteMigrations); i++ {
+		version := i + 1
+
+		tx, err := s.db.Begin()
+		if err != nil {
+			return fmt.Errorf("failed to begin migration %d: %w", version, err)
+		}
+
+		if _, err := tx.Exec(sqliteMigrations[i]); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("failed to apply migration %d: %w", version, err)
+		}
+
+		if _, err := tx.Exec(
+			"INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)",
+			version, formatSQLiteTime(time.Now()),
+		); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("failed to record migration %d: %w", version, err)
+		}
+
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit migration %d: %w", version, err)
+		}
+	}
+
+	return nil
+}
+
+// SchemaVersion returns the latest applied migration version
+func (s *SQLiteStorage) SchemaVersion() (int, error) {
+	var version int
+	if err := s.db.QueryRow("SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return version, nil
+}
+
+// formatSQLiteTime converts a time to the stored text representation
+func formatSQLiteTime(t time.Time) string {
+	return t.UTC().Format(sqliteTimeFormat)
+}
+
+// formatSQLiteDueDate converts an optional due date to a nullable column value
+func formatSQLiteDueDate(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return formatSQLiteTime(*t)
+}
+
+// formatSQLiteTags encodes the tag set as a JSON array
+func formatSQLiteTags(tags []string) string {
+	if len(tags) == 0 {
+		return "[]"
+	}
+	encoded, _ := json.Marshal(tags) // A string slice always marshals
+	return string(encoded)
+}
+
+// rowScanner abstracts *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanTask reads a task from a row selected with taskColumns
+func scanTask(row rowScanner) (*models.Task, error) {
+	var (
+		task      models.Task
+		dueDate   sql.NullString
+		tags      string
+		createdAt string
+		updatedAt string
+	)
+
+	if err := row.Scan(&task.ID, &task.Name, &task.Description, &task.Status, &task.Priority,
+		&dueDate, &tags, &task.OwnerID, &task.Version, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if task.CreatedAt, err = time.Parse(sqliteTimeFormat, createdAt); err != nil {
+		return nil, fmt.Errorf("invalid created_at for task %s: %w", task.ID, err)
+	}
+	if task.UpdatedAt, err = time.Parse(sqliteTimeFormat, updatedAt); err != nil {
+		return nil, fmt.Errorf("invalid updated_at for task %s: %w", task.ID, err)
+	}
+	if dueDate.Valid {
+		parsed, err := time.Parse(sqliteTimeFormat, dueDate.String)
+		if err != nil {
+			return nil, fmt.Errorf("invalid due_date for task %s: %w", task.ID, err)
+		}
+		task.DueDate = &parsed
+	}
+	if err := json.Unmarshal([]byte(tags), &task.Tags); err != nil {
+		return nil, fmt.Errorf("invalid tags for task %s: %w", task.ID, err)
+	}
+	task.Tags = models.NormalizeTags(task.Tags)
+
+	return &task, nil
+}
+
+// scanRevision reads a revision from a row selected with revisionColumns
+func scanRevision(row rowScanner) (*models.TaskRevision, error) {
+	var (
+		revision  models.TaskRevision
+		action    string
+		changedAt string
+		changes   string
+		task      string
+	)
+
+	if err := row.Scan(&revision.Version, &action, &revision.Actor, &changedAt,
+		&revision.RevertedFrom, &changes, &task); err != nil {
+		return nil, err
+	}
+	revision.Action = models.RevisionAction(action)
+
+	var err error
+	if revision.ChangedAt, err = time.Parse(sqliteTimeFormat, changedAt); err != nil {
+		return nil, fmt.Errorf("invalid changed_at for revision %d: %w", revision.Version, err)
+	}
+	if err := json.Unmarshal([]byte(changes), &revision.Changes); err != nil {
+		return nil, fmt.Errorf("invalid changes for revision %d: %w", revision.Version, err)
+	}
+	if err := json.Unmarshal([]byte(task), &revision.Task); err != nil {
+		return nil, fmt.Errorf("invalid task for revision %d: %w", revision.Version, err)
+	}
+
+	return &revision, nil
+}
+
+// insertRevision stores a revision inside the transaction that produced it
+func insertRevision(ctx context.Context, tx *sql.Tx, id string, revision *models.TaskRevision) error {
+	changes, err := json.Marshal(revision.Changes)
+	if err != nil {
+		return fmt.Errorf("failed to encode revision changes: %w", err)
+	}
+	if revision.Changes == nil {
+		changes = []byte("[]")
+	}
+	task, err := json.Marshal(revision.Task)
+	if err != nil {
+		return fmt.Errorf("failed to encode revision task: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		"INSERT INTO task_revisions (task_id, "+revisionColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		id, revision.Version, string(revision.Action), revision.Actor, formatSQLiteTime(revision.ChangedAt),
+		revision.RevertedFrom, string(changes), string(task),
+	); err != nil {
+		return fmt.Errorf("failed to record revision: %w", err)
+	}
+
+	return nil
+}
+
+// queryTasks runs a query selecting taskColumns and collects the results
+func (s *SQLiteStorage) queryTasks(ctx context.Context, query string, args ...interface{}) ([]*models.Task, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tasks: %w", err)
+	}
+	defer rows.Close()
+
+	tasks := make([]*models.Task, 0)
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read task: %w", err)
+		}
+		tasks = append(tasks, task)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate tasks: %w", err)
+	}
+
+	return tasks, nil
+}
+
+// GetAll retrieves all tasks ordered by creation time
+func (s *SQLiteStorage) GetAll(ctx context.Context) ([]*models.Task, error) {
+	return s.queryTasks(ctx, "SELECT "+taskColumns+" FROM tasks ORDER BY created_at, id")
+}
+
+// GetByID retrieves a specific task by its ID
+func (s *SQLiteStorage) GetByID(ctx context.Context, id string) (*models.Task, error) {
+	task, err := scanTask(s.db.QueryRowContext(ctx, "SELECT "+taskColumns+" FROM tasks WHERE id = ?", id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, notFoundError(id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve task: %w", err)
+	}
+	return task, nil
+}
+
+// Create inserts a new task, enforcing the task limit in the same transaction
+func (s *SQLiteStorage) Create(ctx context.Context, req *models.CreateTaskRequest) (*models.Task, error) {
+	// Validate the request first
+	if err := checkCreate(req); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	task, err := s.createTx(ctx, tx, uuid.New().String(), req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.commit(tx, func(idx *search.Index) {
+		idx.Add(task)
+		s.publish(nil, task, models.ActorFromContext(ctx))
+	}); err != nil {
+		return nil, fmt.Errorf("failed to commit task: %w", err)
+	}
+
+	return task, nil
+}
+
+// createTx inserts a new task with the given ID and its first revision inside tx,
+// enforcing the task limit; req must already be validated
+func (s *SQLiteStorage) createTx(ctx context.Context, tx *sql.Tx, id string, req *models.CreateTaskRequest) (*models.Task, error) {
+	task := req.ToTask()
+	task.ID = id
+
+	var count int
+	if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM tasks").Scan(&count); err != nil {
+		return nil, fmt.Errorf("failed to count tasks: %w", err)
+	}
+	if count >= s.maxTasks {
+		return nil, quotaExceededError(s.maxTasks)
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		"INSERT INTO tasks ("+taskColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		task.ID, task.Name, task.Description, task.Status, task.Priority,
+		formatSQLiteDueDate(task.DueDate), formatSQLiteTags(task.Tags), task.OwnerID, task.Version,
+		formatSQLiteTime(task.CreatedAt), formatSQLiteTime(task.UpdatedAt),
+	); err != nil {
+		var sqliteErr *sqlite.Error
+		if errors.As(err, &sqliteErr) && sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY {
+			return nil, fmt.Errorf("%w: task with ID %s already exists", ErrConflict, task.ID)
+		}
+		return nil, fmt.Errorf("failed to insert task: %w", err)
+	}
+
+	revision := models.NewTaskRevision(models.RevisionCreate, nil, task, models.ActorFromContext(ctx))
+	if err := insertRevision(ctx, tx, task.ID, revision); err != nil {
+		return nil, err
+	}
+
+	return task, nil
+}
+
+// Update applies a partial update to an existing task
+func (s *SQLiteStorage) Update(ctx context.Context, id string, req *models.UpdateTaskRequest) (*models.Task, error) {
+	return s.update(ctx, id, 0, req)
+}
+
+// UpdateIfVersion applies the update only if the task is currently at the given version
+// The version is checked inside the write transaction.
+func (s *SQLiteStorage) UpdateIfVersion(ctx context.Context, id string, version int, req *models.UpdateTaskRequest) (*models.Task, error) {
+	return s.update(ctx, id, version, req)
+}
+
+// update applies a partial update; a positive expectedVersion makes it conditional
+func (s *SQLiteStorage) update(ctx context.Context, id string, expectedVersion int, req *models.UpdateTaskRequest) (*models.Task, error) {
+	// Validate the request first
+	if err := req.Validate(); err != nil {
+		return nil, validationError(err)
+	}
+
+	// Check if there are any updates to apply
+	if !req.HasUpdates() {
+		return nil, noUpdatesError()
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	previous, updated, err := s.updateTx(ctx, tx, id, expectedVersion, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.commit(tx, func(idx *search.Index) {
+		idx.Add(updated)
+		s.publish(previous, updated, models.ActorFromContext(ctx))
+	}); err != nil {
+		return nil, fmt.Errorf("failed to commit task: %w", err)
+	}
+
+	return updated, nil
+}
+
+// updateTx applies a validated partial update inside tx, returning the task before and after it
+// A positive expectedVersion makes the update conditional.
+func (s *SQLiteStorage) updateTx(ctx context.Context, tx *sql.Tx, id string, expectedVersion int, req *models.UpdateTaskRequest) (*models.Task, *models.Task, error) {
+	task, err := scanTask(tx.QueryRowContext(ctx, "SELECT "+taskColumns+" FROM tasks WHERE id = ?", id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, notFoundError(id)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to retrieve task: %w", err)
+	}
+	if expectedVersion > 0 && task.Version != expectedVersion {
+		return nil, nil, versionMismatchError(id, expectedVersion, task.Version)
+	}
+
+	updated, err := s.commitUpdate(ctx, tx, task, req, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	return task, updated, nil
+}
+
+// commitUpdate applies req to task, writes it as the next version and records its revision
+// inside tx, dropping the oldest revisions beyond the per-task limit; revertedFrom is the
+// source version of a revert, or 0 for a plain update
+func (s *SQLiteStorage) commitUpdate(ctx context.Context, tx *sql.Tx, task *models.Task, req *models.UpdateTaskRequest, revertedFrom int) (*models.Task, error) {
+	updated := task.Clone()
+	if err := req.ApplyTo(updated); err != nil {
+		return nil, transitionError(err)
+	}
+	updated.Version = task.Version + 1
+
+	if _, err := tx.ExecContext(ctx,
+		"UPDATE tasks SET name = ?, description = ?, status = ?, priority = ?, due_date = ?, tags = ?, version = ?, updated_at = ? WHERE id = ?",
+		updated.Name, updated.Description, updated.Status, updated.Priority,
+		formatSQLiteDueDate(updated.DueDate), formatSQLiteTags(updated.Tags), updated.Version,
+		formatSQLiteTime(updated.UpdatedAt), updated.ID,
+	); err != nil {
+		return nil, fmt.Errorf("failed to update task: %w", err)
+	}
+
+	action := models.RevisionUpdate
+	if revertedFrom > 0 {
+		action = models.RevisionRevert
+	}
+	revision := models.NewTaskRevision(action, task, updated, models.ActorFromContext(ctx))
+	revision.RevertedFrom = revertedFrom
+	if err := insertRevision(ctx, tx, updated.ID, revision); err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx,
+		"DELETE FROM task_revisions WHERE task_id = ? AND version <= ?", updated.ID, updated.Version-s.maxHistory,
+	); err != nil {
+		return nil, fmt.Errorf("failed to trim task revisions: %w", err)
+	}
+
+	return updated, nil
+}
+
+// taskExists reports whether a task with the given ID is stored
+func (s *SQLiteStorage) taskExists(ctx context.Context, id string) error {
+	var exists int
+	err := s.db.QueryRowContext(ctx, "SELECT 1 FROM tasks WHERE id = ?", id).Scan(&exists)
+	if errors.Is(err, sql.ErrNoRows) {
+		return notFoundError(id)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to retrieve task: %w", err)
+	}
+	return nil
+}
+
+// GetHistory returns every stored revision of a task, oldest first
+func (s *SQLiteStorage) GetHistory(ctx context.Context, id string) ([]*models.TaskRevision, error) {
+	if err := s.taskExists(ctx, id); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT "+revisionColumns+" FROM task_revisions WHERE task_id = ? ORDER BY version", id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query revisions: %w", err)
+	}
+	defer rows.Close()
+
+	revisions := make([]*models.TaskRevision, 0)
+	for rows.Next() {
+		revision, err := scanRevision(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read revision: %w", err)
+		}
+		revisions = append(revisions, revision)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate revisions: %w", err)
+	}
+
+	return revisions, nil
+}
+
+// GetVersion returns the revision that produced the given task version
+func (s *SQLiteStorage) GetVersion(ctx context.Context, id string, version int) (*models.TaskRevision, error) {
+	if err := s.taskExists(ctx, id); err != nil {
+		return nil, err
+	}
+
+	revision, err := scanRevision(s.db.QueryRowContext(ctx,
+		"SELECT "+revisionColumns+" FROM task_revisions WHERE task_id = ? AND version = ?", id, version))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, versionNotFoundError(id, version)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve revision: %w", err)
+	}
+
+	return revision, nil
+}
+
+// Revert restores the task's fields from an earlier version as a new revision
+func (s *SQLiteStorage) Revert(ctx context.Context, id string, version int) (*models.Task, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	task, err := scanTask(tx.QueryRowContext(ctx, "SELECT "+taskColumns+" FROM tasks WHERE id = ?", id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, notFoundError(id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve task: %w", err)
+	}
+
+	source, err := scanRevision(tx.QueryRowContext(ctx,
+		"SELECT "+revisionColumns+" FROM task_revisions WHERE task_id = ? AND version = ?", id, version))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, versionNotFoundError(id, version)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve revision: %w", err)
+	}
+
+	// Stored revisions were valid when written, but the limits may have tightened since
+	req := source.RevertRequest()
+	if err := req.Validate(); err != nil {
+		return nil, validationError(err)
+	}
+
+	updated, err := s.commitUpdate(ctx, tx, task, req, version)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.commit(tx, func(idx *search.Index) {
+		idx.Add(updated)
+		s.publish(task, updated, models.ActorFromContext(ctx))
+	}); err != nil {
+		return nil, fmt.Errorf("failed to commit task: %w", err)
+	}
+
+	return updated, nil
+}
+
+// Delete removes a task and its revision history by its ID
+func (s *SQLiteStorage) Delete(ctx context.Context, id string) error {
+	return s.delete(ctx, id, 0)
+}
+
+// DeleteIfVersion removes the task only if it is currently at the given version
+// The version is checked inside the write transaction.
+func (s *SQLiteStorage) DeleteIfVersion(ctx context.Context, id string, version int) error {
+	return s.delete(ctx, id, version)
+}
+
+// delete removes a task and its history; a positive expectedVersion makes it conditional
+func (s *SQLiteStorage) delete(ctx context.Context, id string, expectedVersion int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	deleted, err := deleteTx(ctx, tx, id, expectedVersion)
+	if err != nil {
+		return err
+	}
+
+	if err := s.commit(tx, func(idx *search.Index) {
+		idx.Remove(id)
+		s.publish(deleted, nil, models.ActorFromContext(ctx))
+	}); err != nil {
+		return fmt.Errorf("failed to commit deletion: %w", err)
+	}
+
+	return nil
+}
+
+// deleteTx removes a task and its history inside tx, returning the deleted task
+// A positive expectedVersion makes the deletion conditional.
+func deleteTx(ctx context.Context, tx *sql.Tx, id string, expectedVersion int) (*models.Task, error) {
+	task, err := scanTask(tx.QueryRowContext(ctx, "SELECT "+taskColumns+" FROM tasks WHERE id = ?", id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, notFoundError(id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve task: %w", err)
+	}
+	if expectedVersion > 0 && task.Version != expectedVersion {
+		return nil, versionMismatchError(id, expectedVersion, task.Version)
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM tasks WHERE id = ?", id); err != nil {
+		return nil, fmt.Errorf("failed to delete task: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM task_revisions WHERE task_id = ?", id); err != nil {
+		return nil, fmt.Errorf("failed to delete task history: %w", err)
+	}
+
+	return task, nil
+}
+
+// ApplyBatch applies the operations in order inside a single transaction
+// In best-effort mode each operation runs under a savepoint, so a failing one is rolled back
+// on its own; in atomic mode the first failure rolls back the whole transaction.
+func (s *SQLiteStorage) ApplyBatch(ctx context.Context, ops []models.BulkOperation, mode models.BulkMode) ([]models.BatchResult, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	results := make([]models.BatchResult, len(ops))
+	var applied []batchChange // Changes of the applied operations, in operation order
+	for i := range ops {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		if _, err := tx.ExecContext(ctx, "SAVEPOINT batch_operation"); err != nil {
+			return nil, fmt.Errorf("failed to begin batch operation: %w", err)
+		}
+
+		change, err := s.applyOperation(ctx, tx, &ops[i])
+		if err != nil {
+			if mode == models.BulkAtomic {
+				return abortedPlan(len(ops), i, err).results, nil
+			}
+			if _, err := tx.ExecContext(ctx, "ROLLBACK TO batch_operation"); err != nil {
+				return nil, fmt.Errorf("failed to roll back batch operation: %w", err)
+			}
+			results[i].Err = err
+		} else {
+			results[i].Task = change.task
+			applied = append(applied, change)
+		}
+
+		if _, err := tx.ExecContext(ctx, "RELEASE batch_operation"); err != nil {
+			return nil, fmt.Errorf("failed to release batch operation: %w", err)
+		}
+	}
+
+	if err := s.commit(tx, func(idx *search.Index) {
+		s.batchCommitted(idx, applied, models.ActorFromContext(ctx))
+	}); err != nil {
+		return nil, fmt.Errorf("failed to commit batch: %w", err)
+	}
+
+	return results, nil
+}
+
+// batchCommitted applies the search index changes and publishes the change events of the
+// applied operations of a batch, in operation order
+func (s *SQLiteStorage) batchCommitted(idx *search.Index, applied []batchChange, actor string) {
+	for _, change := range applied {
+		if change.task == nil {
+			idx.Remove(change.deleted)
+		} else {
+			idx.Add(change.task)
+		}
+		s.publish(change.previous, change.task, actor)
+	}
+}
+
+// applyOperation validates and applies one batch operation inside tx
+// The change carries no revision; the new state is nil for a delete.
+func (s *SQLiteStorage) applyOperation(ctx context.Context, tx *sql.Tx, op *models.BulkOperation) (batchChange, error) {
+	switch op.Op {
+	case models.BulkCreate:
+		if err := checkCreate(op.Task); err != nil {
+			return batchChange{}, err
+		}
+		created, err := s.createTx(ctx, tx, uuid.New().String(), op.Task)
+		return batchChange{task: created}, err
+	case models.BulkUpdate:
+		if err := op.Changes.Validate(); err != nil {
+			return batchChange{}, validationError(err)
+		}
+		if !op.Changes.HasUpdates() {
+			return batchChange{}, noUpdatesError()
+		}
+		previous, updated, err := s.updateTx(ctx, tx, op.ID, op.Version, op.Changes)
+		return batchChange{task: updated, previous: previous}, err
+	case models.BulkDelete:
+		deleted, err := deleteTx(ctx, tx, op.ID, op.Version)
+		return batchChange{deleted: op.ID, previous: deleted}, err
+	default:
+		return batchChange{}, fmt.Errorf("%w: unknown batch operation %q", ErrValidation, op.Op)
+	}
+}
+
+// Count returns the total number of tasks
+func (s *SQLiteStorage) Count(ctx context.Context) (int, error) {
+	var count int
+	if err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM tasks").Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count tasks: %w", err)
+	}
+	return count, nil
+}
+
+// Clear removes all tasks and their history (primarily for testing)
+func (s *SQLiteStorage) Clear(ctx context.Context) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM tasks"); err != nil {
+		return fmt.Errorf("failed to clear tasks: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM task_revisions"); err != nil {
+		return fmt.Errorf("failed to clear task history: %w", err)
+	}
+
+	if err := s.commit(tx, func(idx *search.Index) { idx.Clear() }); err != nil {
+		return fmt.Errorf("failed to commit clear: %w", err)
+	}
+	return nil
+}
+
+// Close releases the database handle
+func (s *SQLiteStorage) Close() error {
+	return s.db.Close()
+}
+
+// HealthCheck verifies the database is reachable and fully migrated
+func (s *SQLiteStorage) HealthCheck(ctx context.Context) error {
+	if err := s.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("sqlite database is not reachable: %w", err)
+	}
+
+	version, err := s.SchemaVersion()
+	if err != nil {
+		return err
+	}
+	if version != len(sqliteMigrations) {
+		return fmt.Errorf("sqlite schema version %d does not match expected %d", version, len(sqliteMigrations))
+	}
+
+	return nil
+}
+
+// GetStats returns statistics about the sqlite storage
+// Tasks in terminal workflow states count as completed.
+func (s *SQLiteStorage) GetStats(ctx context.Context) (StorageStats, error) {
+	terminal := models.CurrentWorkflow().TerminalStatuses()
+	completedExpr := "0"
+	args := make([]interface{}, 0, len(terminal))
+	if len(terminal) > 0 {
+		completedExpr = "COALESCE(SUM(CASE WHEN status IN (?" + strings.Repeat(", ?", len(terminal)-1) + ") THEN 1 ELSE 0 END), 0)"
+		for _, status := range terminal {
+			args = append(args, status)
+		}
+	}
+
+	var total, completed int
+	err := s.db.QueryRowContext(ctx, "SELECT COUNT(*), "+completedExpr+" FROM tasks", args...).Scan(&total, &completed)
+	if err != nil {
+		return StorageStats{}, fmt.Errorf("failed to collect stats: %w", err)
+	}
+
+	return StorageStats{
+		TotalTasks:      total,
+		CompletedTasks:  completed,
+		IncompleteTasks: total - completed,
+		LastID:          0, // UUID doesn't use numeric IDs, set to 0
+		StorageType:     "sqlite",
+	}, nil
+}
+
+// GetMaxTasks returns the maximum number of tasks allowed
+func (s *SQLiteStorage) GetMaxTasks() int {
+	return s.maxTasks
+}
+
+// GetUsage returns current storage usage information
+func (s *SQLiteStorage) GetUsage() map[string]interface{} {
+	count, _ := s.Count(context.Background())
+	version, _ := s.SchemaVersion()
+
+	return map[string]interface{}{
+		"current_tasks":  count,
+		"max_tasks":      s.maxTasks,
+		"usage_percent":  float64(count) / float64(s.maxTasks) * 100,
+		"available":      s.maxTasks - count,
+		"database_path":  s.path,
+		"schema_version": version,
+		"storage_type":   "sqlite",
+	}
+}
+
+// GetTasksByStatus returns all tasks with the specified status using the status index
+func (s *SQLiteStorage) GetTasksByStatus(ctx context.Context, status models.TaskStatus) ([]*models.Task, error) {
+	return s.queryTasks(ctx, "SELECT "+taskColumns+" FROM tasks WHERE status = ? ORDER BY created_at, id", status)
+}
+
+// GetTasksCreatedAfter returns tasks created after the specified time using the created_at index
+func (s *SQLiteStorage) GetTasksCreatedAfter(ctx context.Context, after time.Time) ([]*models.Task, error) {
+	return s.queryTasks(ctx,
+		"SELECT "+taskColumns+" FROM tasks WHERE created_at > ? ORDER BY created_at, id",
+		formatSQLiteTime(after),
+	)
+}
+
+// QueryTasks returns the filtered tasks in the requested order
+// Owner, status, priority and time conditions and the ordering run in SQL on the indexed columns;
+// tag and name conditions are applied afterwards since tags are stored as JSON and SQLite's
+// lower() only folds ASCII.
+func (s *SQLiteStorage) QueryTasks(ctx context.Context, filter *models.TaskFilter, order []models.SortField) ([]*models.Task, error) {
+	conditions, args := sqliteFilterConditions(filter)
+
+	query := "SELECT " + taskColumns + " FROM tasks"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY " + sqliteOrderBy(order)
+
+	tasks, err := s.queryTasks(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return filter.Apply(tasks), nil
+}
+
+// sqliteFilterConditions translates the filter conditions that map onto columns into SQL
+// Tag and name conditions are left to filter.Apply or filter.Matches.
+func sqliteFilterConditions(filter *models.TaskFilter) ([]string, []interface{}) {
+	var (
+		conditions []string
+		args       []interface{}
+	)
+	addCondition := func(condition string, arg interface{}) {
+		conditions = append(conditions, condition)
+		args = append(args, arg)
+	}
+
+	if filter.OwnerID != "" {
+		addCondition("owner_id = ?", filter.OwnerID)
+	}
+	if filter.Status != nil {
+		addCondition("status = ?", *filter.Status)
+	}
+	if filter.Priority != nil {
+		addCondition("priority = ?", *filter.Priority)
+	}
+	if filter.DueBefore != nil {
+		addCondition("due_date < ?", formatSQLiteTime(*filter.DueBefore))
+	}
+	if filter.DueAfter != nil {
+		addCondition("due_date > ?", formatSQLiteTime(*filter.DueAfter))
+	}
+	if filter.CreatedBefore != nil {
+		addCondition("created_at < ?", formatSQLiteTime(*filter.CreatedBefore))
+	}
+	if filter.CreatedAfter != nil {
+		addCondition("created_at > ?", formatSQLiteTime(*filter.CreatedAfter))
+	}
+
+	return conditions, args
+}
+
+// sqliteOrderBy builds the ORDER BY clause for the sort keys, matching models.SortTasks:
+// creation time and ID break ties, and tasks without a due date sort last when ascending
+// Sort fields are validated against models.SortableTaskFields, which are all column names.
+func sqliteOrderBy(order []models.SortField) string {
+	terms := make([]string, 0, len(order)+2)
+	for _, key := range order {
+		direction := ""
+		if key.Desc {
+			direction = " DESC"
+		}
+		if key.Field == "due_date" {
+			terms = append(terms, "due_date IS NULL"+direction)
+		}
+		terms = append(terms, key.Field+direction)
+	}
+	return strings.Join(append(terms, "created_at", "id"), ", ")
+}
+
+// GetTasksPaginated returns a page of tasks in creation order along with the total count
+func (s *SQLiteStorage) GetTasksPaginated(ctx context.Context, offset, limit int) ([]*models.Task, int, error) {
+	total, err := s.Count(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	tasks, err := s.queryTasks(ctx,
+		"SELECT "+taskColumns+" FROM tasks ORDER BY created_at, id LIMIT ? OFFSET ?",
+		limit, offset,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return tasks, total, nil
+}
+
+// GetTasksAfter returns up to limit tasks matching filter that come after the cursor in creation order
+// The cursor is resolved on the (created_at, id) ordering in SQL; rows are read lazily and
+// tag and name conditions are checked as they arrive, so reading stops once the page is full.
+func (s *SQLiteStorage) GetTasksAfter(ctx context.Context, filter *models.TaskFilter, after *models.TaskCursor, limit int) ([]*models.Task, bool, error) {
+	conditions, args := sqliteFilterConditions(filter)
+	if after != nil {
+		createdAt := formatSQLiteTime(after.CreatedAt)
+		conditions = append(conditions, "(created_at > ? OR (created_at = ? AND id > ?))")
+		args = append(args, createdAt, createdAt, after.ID)
+	}
+
+	query := "SELECT " + taskColumns + " FROM tasks"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY created_at, id"
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to query tasks: %w", err)
+	}
+	defer rows.Close()
+
+	tasks := make([]*models.Task, 0, limit)
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to read task: %w", err)
+		}
+		if !filter.Matches(task) {
+			continue
+		}
+		if len(tasks) == limit {
+			return tasks, true, nil
+		}
+		tasks = append(tasks, task)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, false, fmt.Errorf("failed to iterate tasks: %w", err)
+	}
+
+	return tasks, false, nil
+}
+
+// SearchTasks returns up to limit tasks matching the full-text query, best match first
+// The index lives in memory; matched tasks are then read from the database.
+func (s *SQLiteStorage) SearchTasks(ctx context.Context, query string, limit int) ([]models.SearchResult, error) {
+	hits := s.search.Search(query, limit)
+	if len(hits) == 0 {
+		return []models.SearchResult{}, nil
+	}
+
+	ids := make([]interface{}, len(hits))
+	for i, hit := range hits {
+		ids[i] = hit.ID
+	}
+	tasks, err := s.queryTasks(ctx,
+		"SELECT "+taskColumns+" FROM tasks WHERE id IN (?"+strings.Repeat(", ?", len(ids)-1)+")", ids...)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]*models.Task, len(tasks))
+	for _, task := range tasks {
+		byID[task.ID] = task
+	}
+
+	results := make([]models.SearchResult, 0, len(hits))
+	for _, hit := range hits {
+		// Tasks deleted since the search are left out
+		if task, ok := byID[hit.ID]; ok {
+			results = append(results, models.SearchResult{Task: task, Score: hit.Score})
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,202 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"task-api/internal/interfaces"
+	"task-api/internal/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestSQLiteStorage opens a sqlite storage at path and closes it when the test ends
+func newTestSQLiteStorage(t *testing.T, path string, maxTasks int) *SQLiteStorage {
+	t.Helper()
+
+	storage, err := NewSQLiteStorage(SQLiteStorageConfig{Path: path, MaxTasks: maxTasks})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = storage.Close() })
+
+	return storage
+}
+
+func TestSQLiteStorage_Contract(t *testing.T) {
+	runStorageContract(t, func(t *testing.T, maxTasks int) interfaces.TaskStorage {
+		return newTestSQLiteStorage(t, filepath.Join(t.TempDir(), "tasks.db"), maxTasks)
+	})
+}
+
+func TestSQLiteStorage_RevisionLimit(t *testing.T) {
+	storage, err := NewSQLiteStorage(SQLiteStorageConfig{Path: filepath.Join(t.TempDir(), "tasks.db"), MaxRevisions: 3})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = storage.Close() })
+
+	checkRevisionLimit(t, storage)
+}
+
+func TestNewSQLiteStorage(t *testing.T) {
+	ctx := context.Background()
+	_, err := NewSQLiteStorage(SQLiteStorageConfig{})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "database path is required")
+
+	storage := newTestSQLiteStorage(t, filepath.Join(t.TempDir(), "nested", "tasks.db"), 0)
+	assert.Equal(t, 10000, storage.GetMaxTasks())
+	assert.NoError(t, storage.HealthCheck(ctx))
+}
+
+func TestSQLiteStorage_Migrations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.db")
+	storage := newTestSQLiteStorage(t, path, 100)
+
+	version, err := storage.SchemaVersion()
+	require.NoError(t, err)
+	assert.Equal(t, len(sqliteMigrations), version)
+
+	// Indexes backing the status and chronological queries must exist
+	for _, index := range []string{"idx_tasks_status", "idx_tasks_created_at", "idx_tasks_priority", "idx_tasks_due_date", "idx_tasks_owner_id"} {
+		var name string
+		err := storage.db.QueryRow("SELECT name FROM sqlite_master WHERE type = 'index' AND name = ?", index).Scan(&name)
+		assert.NoError(t, err, "missing index %s", index)
+	}
+
+	// Reopening must not re-run migrations
+	require.NoError(t, storage.Close())
+	reopened := newTestSQLiteStorage(t, path, 100)
+
+	var applied int
+	require.NoError(t, reopened.db.QueryRow("SELECT COUNT(*) FROM schema_migrations").Scan(&applied))
+	assert.Equal(t, len(sqliteMigrations), applied)
+}
+
+func TestSQLiteStorage_UpgradesExistingDatabase(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "tasks.db")
+
+	// Build a database as the first schema version left it
+	db, err := sql.Open("sqlite", path)
+	require.NoError(t, err)
+	_, err = db.Exec(`CREATE TABLE schema_migrations (version INTEGER PRIMARY KEY, applied_at TEXT NOT NULL)`)
+	require.NoError(t, err)
+	_, err = db.Exec(sqliteMigrations[0])
+	require.NoError(t, err)
+	_, err = db.Exec("INSERT INTO schema_migrations (version, applied_at) VALUES (1, ?)", formatSQLiteTime(time.Now()))
+	require.NoError(t, err)
+	now := formatSQLiteTime(time.Now())
+	_, err = db.Exec("INSERT INTO tasks (id, name, status, created_at, updated_at) VALUES ('legacy', 'Legacy', 1, ?, ?)", now, now)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	storage := newTestSQLiteStorage(t, path, 100)
+
+	version, err := storage.SchemaVersion()
+	require.NoError(t, err)
+	assert.Equal(t, len(sqliteMigrations), version)
+
+	task, err := storage.GetByID(ctx, "legacy")
+	require.NoError(t, err)
+	assert.Equal(t, "Legacy", task.Name)
+	assert.Equal(t, models.TaskCompleted, task.Status)
+	assert.Equal(t, "", task.Description)
+	assert.Equal(t, models.PriorityLow, task.Priority)
+	assert.Nil(t, task.DueDate)
+	assert.Empty(t, task.Tags)
+	assert.Empty(t, task.OwnerID)
+	assert.Equal(t, 1, task.Version)
+
+	// Legacy tasks start their history with the first change after the upgrade
+	history, err := storage.GetHistory(ctx, "legacy")
+	require.NoError(t, err)
+	assert.Empty(t, history)
+
+	updated, err := storage.Update(ctx, "legacy", &models.UpdateTaskRequest{Name: stringPtr("Upgraded")})
+	require.NoError(t, err)
+	assert.Equal(t, 2, updated.Version)
+	history, err = storage.GetHistory(ctx, "legacy")
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, 2, history[0].Version)
+}
+
+func TestSQLiteStorage_PersistsAcrossRestart(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "tasks.db")
+	storage := newTestSQLiteStorage(t, path, 100)
+
+	created, err := storage.Create(ctx, &models.CreateTaskRequest{Name: "Persisted", Status: models.TaskIncomplete})
+	require.NoError(t, err)
+	_, err = storage.Update(ctx, created.ID, &models.UpdateTaskRequest{Status: taskStatusPtr(models.TaskCompleted)})
+	require.NoError(t, err)
+	require.NoError(t, storage.Close())
+
+	reopened := newTestSQLiteStorage(t, path, 100)
+	task, err := reopened.GetByID(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Persisted", task.Name)
+	assert.Equal(t, models.TaskCompleted, task.Status)
+	assert.True(t, task.CreatedAt.Equal(created.CreatedAt))
+
+	// The search index is rebuilt from the database
+	results, err := reopened.SearchTasks(ctx, "persist", 10)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, created.ID, results[0].Task.ID)
+}
+
+func TestSQLiteStorage_Queries(t *testing.T) {
+	ctx := context.Background()
+	storage := newTestSQLiteStorage(t, filepath.Join(t.TempDir(), "tasks.db"), 100)
+
+	start := time.Now()
+	var ids []string
+	for i := 0; i < 5; i++ {
+		status := models.TaskIncomplete
+		if i%2 == 0 {
+			status = models.TaskCompleted
+		}
+		task, err := storage.Create(ctx, &models.CreateTaskRequest{Name: "Task", Status: status})
+		require.NoError(t, err)
+		ids = append(ids, task.ID)
+	}
+
+	completed, err := storage.GetTasksByStatus(ctx, models.TaskCompleted)
+	require.NoError(t, err)
+	assert.Len(t, completed, 3)
+	for _, task := range completed {
+		assert.Equal(t, models.TaskCompleted, task.Status)
+	}
+
+	// Pages are returned in stable creation order
+	page, total, err := storage.GetTasksPaginated(ctx, 0, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 5, total)
+	require.Len(t, page, 2)
+	assert.Equal(t, ids[0], page[0].ID)
+	assert.Equal(t, ids[1], page[1].ID)
+
+	page, total, err = storage.GetTasksPaginated(ctx, 4, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 5, total)
+	require.Len(t, page, 1)
+	assert.Equal(t, ids[4], page[0].ID)
+
+	recent, err := storage.GetTasksCreatedAfter(ctx, start.Add(-time.Second))
+	require.NoError(t, err)
+	assert.Len(t, recent, 5)
+
+	stats, err := storage.GetStats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 5, stats.TotalTasks)
+	assert.Equal(t, 3, stats.CompletedTasks)
+	assert.Equal(t, 2, stats.IncompleteTasks)
+	assert.Equal(t, "sqlite", stats.StorageType)
+
+	usage := storage.GetUsage()
+	assert.Equal(t, 5, usage["current_tasks"])
+	assert.Equal(t, 95, usage["available"])
+	assert.Equal(t, "sqlite", usage["storage_type"])
+}
@@ -0,0 +1,352 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"task-api/internal/events"
+	"task-api/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Default delivery settings
+const (
+	DefaultMaxAttempts    = 8                // Attempts before a delivery is dead
+	DefaultInitialBackoff = time.Second      // Wait after the first failed attempt
+	DefaultMaxBackoff     = 10 * time.Minute // Longest wait between attempts
+	DefaultTimeout        = 10 * time.Second // Time a receiver has to answer
+	DefaultConcurrency    = 4                // Requests in flight at once
+)
+
+// userAgent identifies delivery requests
+const userAgent = "task-api-webhooks/1.0"
+
+// DispatcherConfig defines how deliveries are sent and retried
+type DispatcherConfig struct {
+	MaxAttempts    int           // Attempts before a delivery is dead
+	InitialBackoff time.Duration // Wait after the first failed attempt; doubles after each further failure
+	MaxBackoff     time.Duration // Longest wait between attempts
+	Timeout        time.Duration // Time a receiver has to answer one attempt
+	Concurrency    int           // Requests in flight at once
+	Client         *http.Client  // HTTP client (nil = a client without redirects)
+}
+
+// Dispatcher turns task events into deliveries for the subscribed webhooks and sends them
+// A delivery succeeds when the receiver answers with a 2xx status. Failed attempts are retried
+// with exponential backoff until MaxAttempts is reached, after which the delivery is dead and
+// stays in the delivery log until it is retried by hand. Deliveries are kept in memory, so the
+// ones still waiting for a retry are lost on restart.
+type Dispatcher struct {
+	store  *Store           // Webhook registry and delivery log
+	config DispatcherConfig // Delivery settings
+
+	slots  chan struct{}          // Semaphore bounding concurrent requests
+	timers map[string]*time.Timer // Scheduled attempts by delivery ID
+	closed bool                   // Whether Close has been called
+	mutex  sync.Mutex             // Protects timers and closed
+
+	ctx     context.Context    // Cancelled by Close to abort requests in flight
+	cancel  context.CancelFunc // Cancels ctx
+	pending sync.WaitGroup     // Scheduled and running attempts, and the event loop
+}
+
+// NewDispatcher creates a dispatcher delivering to the webhooks of store (Factory Pattern)
+func NewDispatcher(store *Store, config DispatcherConfig) *Dispatcher {
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = DefaultMaxAttempts
+	}
+	if config.InitialBackoff <= 0 {
+		config.InitialBackoff = DefaultInitialBackoff
+	}
+	if config.MaxBackoff < config.InitialBackoff {
+		config.MaxBackoff = max(DefaultMaxBackoff, config.InitialBackoff)
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+	if config.Concurrency <= 0 {
+		config.Concurrency = DefaultConcurrency
+	}
+	if config.Client == nil {
+		config.Client = &http.Client{
+			// A redirect would resend the signed payload to a URL nobody registered
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Dispatcher{
+		store:  store,
+		config: config,
+		slots:  make(chan struct{}, config.Concurrency),
+		timers: make(map[string]*time.Timer),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Store returns the webhook registry the dispatcher delivers to
+func (d *Dispatcher) Store() *Store {
+	return d.store
+}
+
+// Start consumes the events published to bus from now on, until the bus is closed
+func (d *Dispatcher) Start(bus *events.Bus) {
+	sub, _, _ := bus.Subscribe("")
+
+	d.pending.Add(1)
+	go func() {
+		defer d.pending.Done()
+		d.consume(bus, sub)
+	}()
+}
+
+// consume queues deliveries for every event of bus
+// The bus drops subscribers that fall behind; the dispatcher then resumes after the last event
+// it handled, so it only misses events when they have left the bus's replay buffer.
+func (d *Dispatcher) consume(bus *events.Bus, sub *events.Subscription) {
+	for {
+		lastID := sub.Start()
+		for done := false; !done; {
+			select {
+			case event := <-sub.Events():
+				d.Dispatch(event)
+				lastID = event.ID
+			case <-sub.Done():
+				done = true
+			}
+		}
+		// Events delivered before the subscription ended are still buffered
+		for drained := false; !drained; {
+			select {
+			case event := <-sub.Events():
+				d.Dispatch(event)
+				lastID = event.ID
+			default:
+				drained = true
+			}
+		}
+
+		var replay []models.TaskEvent
+		var complete bool
+		sub, replay, complete = bus.Subscribe(lastID)
+		select {
+		case <-sub.Done():
+			// The bus is closed
+			return
+		default:
+		}
+		if !complete {
+			log.Printf("webhooks: change feed overflowed, events after %s were not delivered", lastID)
+		}
+		for _, event := range replay {
+			d.Dispatch(event)
+		}
+	}
+}
+
+// Dispatch queues a delivery of the event to every active webhook subscribed to its type
+func (d *Dispatcher) Dispatch(event models.TaskEvent) {
+	event.Previous = nil
+	for _, webhook := range d.store.subscribers(event.Type) {
+		now := time.Now().UTC()
+		delivery := &models.WebhookDelivery{
+			ID:            uuid.New().String(),
+			WebhookID:     webhook.ID,
+			Event:         event,
+			Status:        models.DeliveryPending,
+			Attempts:      []models.WebhookAttempt{},
+			NextAttemptAt: &now,
+			CreatedAt:     now,
+		}
+		d.store.logDelivery(delivery)
+		d.schedule(delivery.WebhookID, delivery.ID, 0)
+	}
+}
+
+// Redeliver sends a dead delivery once more
+func (d *Dispatcher) Redeliver(webhookID, deliveryID string) (*models.WebhookDelivery, error) {
+	now := time.Now().UTC()
+	var previous *time.Time
+	delivery, err := d.store.updateDelivery(webhookID, deliveryID, func(delivery *models.WebhookDelivery) error {
+		if delivery.Status != models.DeliveryDead {
+			return fmt.Errorf("%w: delivery %s is %s", ErrNotRetryable, deliveryID, delivery.Status)
+		}
+		previous = delivery.NextAttemptAt
+		delivery.Status = models.DeliveryPending
+		delivery.NextAttemptAt = &now
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !d.schedule(webhookID, deliveryID, 0) {
+		// Shutting down: leave the delivery dead so it can be retried again later
+		_, _ = d.store.updateDelivery(webhookID, deliveryID, func(delivery *models.WebhookDelivery) error {
+			delivery.Status = models.DeliveryDead
+			delivery.NextAttemptAt = previous
+			return nil
+		})
+		return nil, ErrClosed
+	}
+	return delivery, nil
+}
+
+// schedule runs an attempt of a delivery after delay, unless the dispatcher is closed
+func (d *Dispatcher) schedule(webhookID, deliveryID string, delay time.Duration) bool {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.closed {
+		return false
+	}
+
+	d.pending.Add(1)
+	d.timers[deliveryID] = time.AfterFunc(delay, func() {
+		defer d.pending.Done()
+
+		d.mutex.Lock()
+		delete(d.timers, deliveryID)
+		d.mutex.Unlock()
+
+		d.attempt(webhookID, deliveryID)
+	})
+	return true
+}
+
+// attempt sends one request of a delivery and records the outcome
+func (d *Dispatcher) attempt(webhookID, deliveryID string) {
+	select {
+	case d.slots <- struct{}{}:
+		defer func() { <-d.slots }()
+	case <-d.ctx.Done():
+		return
+	}
+
+	webhook, ok := d.store.target(webhookID)
+	if !ok {
+		// Deleted while the delivery was waiting
+		return
+	}
+	delivery, err := d.store.Delivery(webhookID, deliveryID)
+	if err != nil {
+		return
+	}
+
+	result := d.send(webhook, delivery)
+	if d.ctx.Err() != nil {
+		// Aborted by Close; the outcome says nothing about the receiver
+		return
+	}
+
+	var retryIn time.Duration
+	_, _ = d.store.updateDelivery(webhookID, deliveryID, func(delivery *models.WebhookDelivery) error {
+		delivery.Attempts = append(delivery.Attempts, result)
+		delivery.NextAttemptAt = nil
+
+		switch {
+		case result.Error == "":
+			delivery.Status = models.DeliverySucceeded
+		case len(delivery.Attempts) >= d.config.MaxAttempts:
+			delivery.Status = models.DeliveryDead
+		default:
+			retryIn = d.backoff(len(delivery.Attempts))
+			next := result.Time.Add(retryIn)
+			delivery.Status = models.DeliveryRetrying
+			delivery.NextAttemptAt = &next
+		}
+		return nil
+	})
+
+	if retryIn > 0 {
+		d.schedule(webhookID, deliveryID, retryIn)
+	}
+}
+
+// send POSTs the signed payload of a delivery to its webhook
+func (d *Dispatcher) send(webhook *models.Webhook, delivery *models.WebhookDelivery) models.WebhookAttempt {
+	started := time.Now().UTC()
+	attempt := models.WebhookAttempt{Time: started}
+	finish := func(statusCode int, err error) models.WebhookAttempt {
+		attempt.StatusCode = statusCode
+		if err != nil {
+			attempt.Error = err.Error()
+		}
+		attempt.DurationMs = time.Since(started).Milliseconds()
+		return attempt
+	}
+
+	body, err := json.Marshal(models.WebhookPayload{
+		DeliveryID: delivery.ID,
+		WebhookID:  webhook.ID,
+		Event:      delivery.Event,
+	})
+	if err != nil {
+		return finish(0, fmt.Errorf("failed to encode payload: %w", err))
+	}
+
+	ctx, cancel := context.WithTimeout(d.ctx, d.config.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return finish(0, err)
+	}
+
+	timestamp := started.Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set(HeaderWebhookID, webhook.ID)
+	req.Header.Set(HeaderDelivery, delivery.ID)
+	req.Header.Set(HeaderEvent, string(delivery.Event.Type))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(webhook.Secret, timestamp, body))
+
+	resp, err := d.config.Client.Do(req)
+	if err != nil {
+		return finish(0, err)
+	}
+	defer resp.Body.Close()
+	// Drain a little of the body so the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return finish(resp.StatusCode, fmt.Errorf("receiver answered %s", resp.Status))
+	}
+	return finish(resp.StatusCode, nil)
+}
+
+// backoff returns the wait after the given number of failed attempts
+func (d *Dispatcher) backoff(failures int) time.Duration {
+	wait := d.config.InitialBackoff
+	for i := 1; i < failures && wait < d.config.MaxBackoff; i++ {
+		wait *= 2
+	}
+	return min(wait, d.config.MaxBackoff)
+}
+
+// Close stops scheduling attempts, aborts the requests in flight and waits for them
+// Call it after the bus has been closed so the event loop has ended.
+func (d *Dispatcher) Close() {
+	d.mutex.Lock()
+	d.closed = true
+	for id, timer := range d.timers {
+		if timer.Stop() {
+			d.pending.Done()
+		}
+		delete(d.timers, id)
+	}
+	d.mutex.Unlock()
+
+	d.cancel()
+	d.pending.Wait()
+}
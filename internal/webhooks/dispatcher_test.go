@@ -0,0 +1,322 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"task-api/internal/events"
+	"task-api/internal/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testSecret signs the deliveries of the test webhooks
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+// receivedRequest is one request seen by a test receiver
+type receivedRequest struct {
+	header  http.Header
+	body    []byte
+	payload models.WebhookPayload
+}
+
+// receiver is a local webhook endpoint answering with scripted status codes
+type receiver struct {
+	server   *httptest.Server
+	statuses []int // Status of each request in turn; 200 once exhausted
+	requests []receivedRequest
+	mutex    sync.Mutex
+}
+
+// newReceiver starts a receiver answering the given statuses in turn
+func newReceiver(t *testing.T, statuses ...int) *receiver {
+	t.Helper()
+
+	r := &receiver{statuses: statuses}
+	r.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		var payload models.WebhookPayload
+		_ = json.Unmarshal(body, &payload)
+
+		r.mutex.Lock()
+		status := http.StatusOK
+		if len(r.requests) < len(r.statuses) {
+			status = r.statuses[len(r.requests)]
+		}
+		r.requests = append(r.requests, receivedRequest{header: req.Header.Clone(), body: body, payload: payload})
+		r.mutex.Unlock()
+
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(r.server.Close)
+	return r
+}
+
+// received returns the requests seen so far
+func (r *receiver) received() []receivedRequest {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return append([]receivedRequest(nil), r.requests...)
+}
+
+// setupDispatcher starts a dispatcher with fast retries consuming a new bus
+func setupDispatcher(t *testing.T, maxAttempts int) (*Store, *Dispatcher, *events.Bus) {
+	t.Helper()
+
+	store, err := NewStore("", 0)
+	require.NoError(t, err)
+
+	bus := events.NewBus(events.BusConfig{})
+	dispatcher := NewDispatcher(store, DispatcherConfig{
+		MaxAttempts:    maxAttempts,
+		InitialBackoff: 10 * time.Millisecond,
+		MaxBackoff:     40 * time.Millisecond,
+		Timeout:        time.Second,
+	})
+	dispatcher.Start(bus)
+	t.Cleanup(func() {
+		bus.Close()
+		dispatcher.Close()
+	})
+	return store, dispatcher, bus
+}
+
+// register creates a webhook delivering to the receiver
+func register(t *testing.T, store *Store, r *receiver, eventTypes ...models.TaskEventType) *models.Webhook {
+	t.Helper()
+
+	webhook, err := store.Create(&models.CreateWebhookRequest{URL: r.server.URL, Events: eventTypes, Secret: testSecret})
+	require.NoError(t, err)
+	return webhook
+}
+
+// taskEvent builds a change event of the given type
+func taskEvent(eventType models.TaskEventType, id string) models.TaskEvent {
+	task := &models.Task{ID: id, Name: "Task " + id, Version: 1}
+	switch eventType {
+	case models.TaskCreated:
+		return models.NewTaskEvent(nil, task, "tester")
+	case models.TaskDeleted:
+		return models.NewTaskEvent(task, nil, "tester")
+	default:
+		return models.NewTaskEvent(task, task, "tester")
+	}
+}
+
+// waitForStatus waits until the only logged delivery of a webhook reaches status
+func waitForStatus(t *testing.T, store *Store, webhookID string, status models.WebhookDeliveryStatus) *models.WebhookDelivery {
+	t.Helper()
+
+	var delivery *models.WebhookDelivery
+	require.Eventually(t, func() bool {
+		deliveries, err := store.Deliveries(webhookID, status)
+		if err != nil || len(deliveries) != 1 {
+			return false
+		}
+		delivery = deliveries[0]
+		return true
+	}, 5*time.Second, 5*time.Millisecond)
+	return delivery
+}
+
+func TestDispatcher_SignedDelivery(t *testing.T) {
+	store, _, bus := setupDispatcher(t, 3)
+	r := newReceiver(t)
+	webhook := register(t, store, r)
+
+	bus.Publish(taskEvent(models.TaskCreated, "1"))
+	delivery := waitForStatus(t, store, webhook.ID, models.DeliverySucceeded)
+
+	require.Len(t, delivery.Attempts, 1)
+	assert.Equal(t, http.StatusOK, delivery.Attempts[0].StatusCode)
+	assert.Empty(t, delivery.Attempts[0].Error)
+	assert.Nil(t, delivery.NextAttemptAt)
+
+	requests := r.received()
+	require.Len(t, requests, 1)
+	request := requests[0]
+	assert.Equal(t, "application/json", request.header.Get("Content-Type"))
+	assert.Equal(t, webhook.ID, request.header.Get(HeaderWebhookID))
+	assert.Equal(t, delivery.ID, request.header.Get(HeaderDelivery))
+	assert.Equal(t, "created", request.header.Get(HeaderEvent))
+
+	timestamp, err := strconv.ParseInt(request.header.Get(HeaderTimestamp), 10, 64)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), time.Unix(timestamp, 0), time.Minute)
+	assert.True(t, Verify(testSecret, timestamp, request.body, request.header.Get(HeaderSignature)))
+	assert.False(t, Verify("another secret entirely!", timestamp, request.body, request.header.Get(HeaderSignature)))
+	assert.False(t, Verify(testSecret, timestamp+1, request.body, request.header.Get(HeaderSignature)))
+
+	assert.Equal(t, delivery.ID, request.payload.DeliveryID)
+	assert.Equal(t, webhook.ID, request.payload.WebhookID)
+	assert.Equal(t, models.TaskCreated, request.payload.Event.Type)
+	assert.Equal(t, "1", request.payload.Event.TaskID)
+	assert.NotEmpty(t, request.payload.Event.ID)
+}
+
+func TestDispatcher_EventFilters(t *testing.T) {
+	store, _, bus := setupDispatcher(t, 3)
+	all := newReceiver(t)
+	deletes := newReceiver(t)
+	paused := newReceiver(t)
+
+	register(t, store, all)
+	register(t, store, deletes, models.TaskDeleted)
+	pausedHook := register(t, store, paused)
+	inactive := false
+	_, err := store.Update(pausedHook.ID, &models.UpdateWebhookRequest{Active: &inactive})
+	require.NoError(t, err)
+
+	bus.Publish(taskEvent(models.TaskCreated, "1"))
+	bus.Publish(taskEvent(models.TaskUpdated, "1"))
+	bus.Publish(taskEvent(models.TaskDeleted, "1"))
+
+	require.Eventually(t, func() bool { return len(all.received()) == 3 && len(deletes.received()) == 1 },
+		5*time.Second, 5*time.Millisecond)
+	assert.Equal(t, models.TaskDeleted, deletes.received()[0].payload.Event.Type)
+	assert.Empty(t, paused.received())
+}
+
+func TestDispatcher_RetriesWithBackoff(t *testing.T) {
+	store, _, bus := setupDispatcher(t, 5)
+	r := newReceiver(t, http.StatusInternalServerError, http.StatusServiceUnavailable)
+	webhook := register(t, store, r)
+
+	bus.Publish(taskEvent(models.TaskUpdated, "1"))
+	delivery := waitForStatus(t, store, webhook.ID, models.DeliverySucceeded)
+
+	require.Len(t, delivery.Attempts, 3)
+	assert.Equal(t, http.StatusInternalServerError, delivery.Attempts[0].StatusCode)
+	assert.Contains(t, delivery.Attempts[0].Error, "500")
+	assert.Equal(t, http.StatusServiceUnavailable, delivery.Attempts[1].StatusCode)
+	assert.Equal(t, http.StatusOK, delivery.Attempts[2].StatusCode)
+
+	// The wait doubles after each failure
+	first := delivery.Attempts[1].Time.Sub(delivery.Attempts[0].Time)
+	second := delivery.Attempts[2].Time.Sub(delivery.Attempts[1].Time)
+	assert.GreaterOrEqual(t, first, 10*time.Millisecond)
+	assert.GreaterOrEqual(t, second, 20*time.Millisecond)
+
+	// Every attempt carries the same delivery
+	requests := r.received()
+	require.Len(t, requests, 3)
+	for _, request := range requests {
+		assert.Equal(t, delivery.ID, request.header.Get(HeaderDelivery))
+	}
+}
+
+func TestDispatcher_DeadLetter(t *testing.T) {
+	store, dispatcher, bus := setupDispatcher(t, 3)
+	r := newReceiver(t, 500, 500, 500, 500)
+	webhook := register(t, store, r)
+
+	bus.Publish(taskEvent(models.TaskCreated, "1"))
+	dead := waitForStatus(t, store, webhook.ID, models.DeliveryDead)
+	assert.Len(t, dead.Attempts, 3)
+	assert.Nil(t, dead.NextAttemptAt)
+	assert.Len(t, r.received(), 3)
+
+	t.Run("only dead deliveries can be retried", func(t *testing.T) {
+		_, err := dispatcher.Redeliver(webhook.ID, "unknown")
+		assert.ErrorIs(t, err, ErrDeliveryNotFound)
+		_, err = dispatcher.Redeliver("unknown", dead.ID)
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("a failed manual retry is dead again", func(t *testing.T) {
+		queued, err := dispatcher.Redeliver(webhook.ID, dead.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.DeliveryPending, queued.Status)
+
+		require.Eventually(t, func() bool { return len(r.received()) == 4 }, 5*time.Second, 5*time.Millisecond)
+		again := waitForStatus(t, store, webhook.ID, models.DeliveryDead)
+		assert.Len(t, again.Attempts, 4)
+	})
+
+	t.Run("a successful manual retry", func(t *testing.T) {
+		_, err := dispatcher.Redeliver(webhook.ID, dead.ID)
+		require.NoError(t, err)
+
+		delivered := waitForStatus(t, store, webhook.ID, models.DeliverySucceeded)
+		assert.Len(t, delivered.Attempts, 5)
+
+		_, err = dispatcher.Redeliver(webhook.ID, dead.ID)
+		assert.ErrorIs(t, err, ErrNotRetryable)
+	})
+}
+
+func TestDispatcher_RedeliverOnShutdown(t *testing.T) {
+	store, dispatcher, bus := setupDispatcher(t, 1)
+	r := newReceiver(t, 500)
+	webhook := register(t, store, r)
+
+	bus.Publish(taskEvent(models.TaskCreated, "1"))
+	dead := waitForStatus(t, store, webhook.ID, models.DeliveryDead)
+
+	// A retry refused by a closing dispatcher leaves the delivery dead, retryable later
+	bus.Close()
+	dispatcher.Close()
+	_, err := dispatcher.Redeliver(webhook.ID, dead.ID)
+	assert.ErrorIs(t, err, ErrClosed)
+
+	unchanged := waitForStatus(t, store, webhook.ID, models.DeliveryDead)
+	assert.Nil(t, unchanged.NextAttemptAt)
+}
+
+func TestDispatcher_UnreachableReceiver(t *testing.T) {
+	store, _, bus := setupDispatcher(t, 2)
+	r := newReceiver(t)
+	webhook := register(t, store, r)
+	r.server.Close()
+
+	bus.Publish(taskEvent(models.TaskCreated, "1"))
+	dead := waitForStatus(t, store, webhook.ID, models.DeliveryDead)
+	require.Len(t, dead.Attempts, 2)
+	assert.Zero(t, dead.Attempts[0].StatusCode)
+	assert.NotEmpty(t, dead.Attempts[0].Error)
+}
+
+func TestDispatcher_Backoff(t *testing.T) {
+	dispatcher := NewDispatcher(nil, DispatcherConfig{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second})
+
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, wait := range expected {
+		assert.Equal(t, wait, dispatcher.backoff(i+1), "after %d failures", i+1)
+	}
+}
+
+func TestDispatcher_Close(t *testing.T) {
+	store, err := NewStore("", 0)
+	require.NoError(t, err)
+	bus := events.NewBus(events.BusConfig{})
+	dispatcher := NewDispatcher(store, DispatcherConfig{InitialBackoff: time.Hour})
+	dispatcher.Start(bus)
+
+	r := newReceiver(t, 500)
+	webhook := register(t, store, r)
+	bus.Publish(taskEvent(models.TaskCreated, "1"))
+	waitForStatus(t, store, webhook.ID, models.DeliveryRetrying)
+
+	// Close does not wait for the scheduled retry
+	bus.Close()
+	closed := make(chan struct{})
+	go func() {
+		dispatcher.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close did not return")
+	}
+
+	_, err = dispatcher.Redeliver(webhook.ID, "any")
+	assert.ErrorIs(t, err, ErrDeliveryNotFound)
+}
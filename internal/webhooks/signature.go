@@ -0,0 +1,37 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+)
+
+// Headers set on every delivery request
+const (
+	HeaderWebhookID = "X-Webhook-ID"        // ID of the receiving webhook
+	HeaderDelivery  = "X-Webhook-Delivery"  // Delivery ID, the same on every attempt
+	HeaderEvent     = "X-Webhook-Event"     // Task event type
+	HeaderTimestamp = "X-Webhook-Timestamp" // Unix time the attempt was signed
+	HeaderSignature = "X-Webhook-Signature" // "sha256=" followed by the hex HMAC of the signed content
+)
+
+// signaturePrefix names the algorithm in the signature header
+const signaturePrefix = "sha256="
+
+// Sign returns the signature header value for a request body sent at timestamp (Unix seconds)
+// The HMAC-SHA256 covers "<timestamp>.<body>", so a receiver that rejects old timestamps also
+// rejects replayed requests.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is the valid signature of body sent at timestamp
+// Receivers should also check that the timestamp is recent.
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
@@ -0,0 +1,369 @@
+// Package webhooks delivers task change events to HTTP endpoints registered by other tools,
+// signing every request and retrying failed deliveries with exponential backoff
+package webhooks
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"task-api/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultDeliveryLogSize is the number of deliveries kept per webhook
+const DefaultDeliveryLogSize = 100
+
+// Sentinel errors returned (wrapped) by the store and the dispatcher
+var (
+	ErrNotFound         = errors.New("webhook not found")         // The requested webhook does not exist
+	ErrDeliveryNotFound = errors.New("delivery not found")        // The delivery is unknown or no longer logged
+	ErrNotRetryable     = errors.New("delivery is not dead")      // Only dead deliveries can be retried by hand
+	ErrClosed           = errors.New("webhook dispatcher closed") // The dispatcher no longer sends requests
+)
+
+// storeFile is the on-disk format of the webhook registry
+type storeFile struct {
+	Webhooks []*models.Webhook `json:"webhooks"`
+}
+
+// Store keeps the registered webhooks and the log of their recent deliveries
+// Webhooks are saved to a JSON file after every change when the store has a path; the
+// delivery log only lives in memory and keeps the most recent deliveries of each webhook.
+type Store struct {
+	path       string                               // Registry file ("" = not persisted)
+	logSize    int                                  // Deliveries kept per webhook
+	webhooks   map[string]*models.Webhook           // Registered webhooks by ID
+	deliveries map[string][]*models.WebhookDelivery // Delivery log by webhook ID, oldest first
+	mutex      sync.RWMutex                         // Protects the maps and the file
+}
+
+// NewStore creates a webhook store, loading the registry file at path if it exists (Factory Pattern)
+// An empty path keeps the webhooks in memory only.
+func NewStore(path string, logSize int) (*Store, error) {
+	if logSize <= 0 {
+		logSize = DefaultDeliveryLogSize
+	}
+
+	store := &Store{
+		path:       path,
+		logSize:    logSize,
+		webhooks:   make(map[string]*models.Webhook),
+		deliveries: make(map[string][]*models.WebhookDelivery),
+	}
+	if path == "" {
+		return store, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read webhooks: %w", err)
+	}
+
+	var file storeFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse webhooks file %s: %w", path, err)
+	}
+	for _, webhook := range file.Webhooks {
+		store.webhooks[webhook.ID] = webhook
+	}
+	return store, nil
+}
+
+// List returns every webhook in creation order, without secrets
+func (s *Store) List() []*models.Webhook {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	webhooks := make([]*models.Webhook, 0, len(s.webhooks))
+	for _, webhook := range s.sorted() {
+		webhooks = append(webhooks, webhook.Redacted())
+	}
+	return webhooks
+}
+
+// Get returns a webhook without its secret
+func (s *Store) Get(id string) (*models.Webhook, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	webhook, ok := s.webhooks[id]
+	if !ok {
+		return nil, notFoundError(id)
+	}
+	return webhook.Redacted(), nil
+}
+
+// Create registers a webhook and returns it with its secret, generating one if the request has none
+func (s *Store) Create(req *models.CreateWebhookRequest) (*models.Webhook, error) {
+	secret := req.Secret
+	if secret == "" {
+		var err error
+		if secret, err = generateSecret(); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now().UTC()
+	webhook := &models.Webhook{
+		ID:          uuid.New().String(),
+		URL:         req.URL,
+		Events:      append([]models.TaskEventType(nil), req.Events...),
+		Description: req.Description,
+		Active:      req.Active == nil || *req.Active,
+		Secret:      secret,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.webhooks[webhook.ID] = webhook
+	if err := s.save(); err != nil {
+		delete(s.webhooks, webhook.ID)
+		return nil, err
+	}
+	return webhook.Clone(), nil
+}
+
+// Update changes the fields present in the request
+// The returned webhook includes the secret only when the request rotated it.
+func (s *Store) Update(id string, req *models.UpdateWebhookRequest) (*models.Webhook, error) {
+	var secret string
+	if req.Secret != nil {
+		secret = *req.Secret
+		if secret == "" {
+			var err error
+			if secret, err = generateSecret(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	current, ok := s.webhooks[id]
+	if !ok {
+		return nil, notFoundError(id)
+	}
+
+	updated := current.Clone()
+	if req.URL != nil {
+		updated.URL = *req.URL
+	}
+	if req.Events != nil {
+		updated.Events = append([]models.TaskEventType(nil), (*req.Events)...)
+	}
+	if req.Description != nil {
+		updated.Description = *req.Description
+	}
+	if req.Active != nil {
+		updated.Active = *req.Active
+	}
+	if req.Secret != nil {
+		updated.Secret = secret
+	}
+	updated.UpdatedAt = time.Now().UTC()
+
+	s.webhooks[id] = updated
+	if err := s.save(); err != nil {
+		s.webhooks[id] = current
+		return nil, err
+	}
+
+	if req.Secret != nil {
+		return updated.Clone(), nil
+	}
+	return updated.Redacted(), nil
+}
+
+// Delete removes a webhook and its delivery log
+func (s *Store) Delete(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	webhook, ok := s.webhooks[id]
+	if !ok {
+		return notFoundError(id)
+	}
+
+	delete(s.webhooks, id)
+	if err := s.save(); err != nil {
+		s.webhooks[id] = webhook
+		return err
+	}
+	delete(s.deliveries, id)
+	return nil
+}
+
+// Deliveries returns the logged deliveries of a webhook, newest first
+// A non-empty status keeps only the deliveries in that state.
+func (s *Store) Deliveries(webhookID string, status models.WebhookDeliveryStatus) ([]*models.WebhookDelivery, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if _, ok := s.webhooks[webhookID]; !ok {
+		return nil, notFoundError(webhookID)
+	}
+
+	logged := s.deliveries[webhookID]
+	deliveries := make([]*models.WebhookDelivery, 0, len(logged))
+	for i := len(logged) - 1; i >= 0; i-- {
+		if status == "" || logged[i].Status == status {
+			deliveries = append(deliveries, logged[i].Clone())
+		}
+	}
+	return deliveries, nil
+}
+
+// Delivery returns one logged delivery of a webhook
+func (s *Store) Delivery(webhookID, deliveryID string) (*models.WebhookDelivery, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	delivery, err := s.findDelivery(webhookID, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	return delivery.Clone(), nil
+}
+
+// subscribers returns the active webhooks subscribed to an event type, with their secrets
+func (s *Store) subscribers(eventType models.TaskEventType) []*models.Webhook {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var webhooks []*models.Webhook
+	for _, webhook := range s.sorted() {
+		if webhook.Wants(eventType) {
+			webhooks = append(webhooks, webhook.Clone())
+		}
+	}
+	return webhooks
+}
+
+// target returns a webhook with its secret, for sending a delivery
+func (s *Store) target(id string) (*models.Webhook, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	webhook, ok := s.webhooks[id]
+	if !ok {
+		return nil, false
+	}
+	return webhook.Clone(), true
+}
+
+// logDelivery appends a delivery to its webhook's log, dropping the oldest beyond the log size
+func (s *Store) logDelivery(delivery *models.WebhookDelivery) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.webhooks[delivery.WebhookID]; !ok {
+		return
+	}
+
+	logged := append(s.deliveries[delivery.WebhookID], delivery.Clone())
+	if len(logged) > s.logSize {
+		logged = append([]*models.WebhookDelivery(nil), logged[len(logged)-s.logSize:]...)
+	}
+	s.deliveries[delivery.WebhookID] = logged
+}
+
+// updateDelivery applies change to a logged delivery and returns a copy of the result
+// change returns an error to leave the delivery untouched.
+func (s *Store) updateDelivery(webhookID, deliveryID string, change func(*models.WebhookDelivery) error) (*models.WebhookDelivery, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delivery, err := s.findDelivery(webhookID, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := delivery.Clone()
+	if err := change(updated); err != nil {
+		return nil, err
+	}
+	*delivery = *updated
+	return updated.Clone(), nil
+}
+
+// findDelivery looks a delivery up; must be called with the store lock held
+func (s *Store) findDelivery(webhookID, deliveryID string) (*models.WebhookDelivery, error) {
+	if _, ok := s.webhooks[webhookID]; !ok {
+		return nil, notFoundError(webhookID)
+	}
+	for _, delivery := range s.deliveries[webhookID] {
+		if delivery.ID == deliveryID {
+			return delivery, nil
+		}
+	}
+	return nil, fmt.Errorf("delivery %s %w", deliveryID, ErrDeliveryNotFound)
+}
+
+// sorted returns the webhooks in creation order; must be called with the store lock held
+func (s *Store) sorted() []*models.Webhook {
+	webhooks := make([]*models.Webhook, 0, len(s.webhooks))
+	for _, webhook := range s.webhooks {
+		webhooks = append(webhooks, webhook)
+	}
+	sort.Slice(webhooks, func(i, j int) bool {
+		if !webhooks[i].CreatedAt.Equal(webhooks[j].CreatedAt) {
+			return webhooks[i].CreatedAt.Before(webhooks[j].CreatedAt)
+		}
+		return webhooks[i].ID < webhooks[j].ID
+	})
+	return webhooks
+}
+
+// save writes the registry file atomically; must be called with the store lock held
+// The file holds the signing secrets, so only the owner may read it.
+func (s *Store) save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(storeFile{Webhooks: s.sorted()}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode webhooks: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create webhooks directory: %w", err)
+	}
+	temp := s.path + ".tmp"
+	if err := os.WriteFile(temp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write webhooks: %w", err)
+	}
+	if err := os.Rename(temp, s.path); err != nil {
+		return fmt.Errorf("failed to replace webhooks file: %w", err)
+	}
+	return nil
+}
+
+// notFoundError reports a missing webhook
+func notFoundError(id string) error {
+	return fmt.Errorf("webhook %s: %w", id, ErrNotFound)
+}
+
+// generateSecret returns a random 256-bit signing secret
+func generateSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
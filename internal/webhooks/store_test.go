@@ -0,0 +1,138 @@
+package webhooks
+
+import (
+	"os"
+	"path/filepath"
+	"task-api/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_CRUD(t *testing.T) {
+	store, err := NewStore("", 0)
+	require.NoError(t, err)
+
+	created, err := store.Create(&models.CreateWebhookRequest{
+		URL:    "https://hooks.example.com/tasks",
+		Events: []models.TaskEventType{models.TaskCreated},
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, created.ID)
+	assert.True(t, created.Active)
+	assert.Len(t, created.Secret, 64, "a secret is generated when none is given")
+
+	fetched, err := store.Get(created.ID)
+	require.NoError(t, err)
+	assert.Empty(t, fetched.Secret)
+	assert.Equal(t, created.URL, fetched.URL)
+
+	t.Run("update", func(t *testing.T) {
+		url := "https://hooks.example.com/v2"
+		events := []models.TaskEventType{}
+		updated, err := store.Update(created.ID, &models.UpdateWebhookRequest{URL: &url, Events: &events})
+		require.NoError(t, err)
+		assert.Equal(t, url, updated.URL)
+		assert.Empty(t, updated.Events)
+		assert.Empty(t, updated.Secret)
+
+		target, _ := store.target(created.ID)
+		assert.Equal(t, created.Secret, target.Secret, "the secret is kept")
+	})
+
+	t.Run("rotate secret", func(t *testing.T) {
+		rotate := ""
+		rotated, err := store.Update(created.ID, &models.UpdateWebhookRequest{Secret: &rotate})
+		require.NoError(t, err)
+		assert.Len(t, rotated.Secret, 64)
+		assert.NotEqual(t, created.Secret, rotated.Secret)
+	})
+
+	t.Run("list", func(t *testing.T) {
+		second, err := store.Create(&models.CreateWebhookRequest{URL: "http://localhost:9000/hook"})
+		require.NoError(t, err)
+
+		webhooks := store.List()
+		require.Len(t, webhooks, 2)
+		assert.Equal(t, created.ID, webhooks[0].ID)
+		assert.Equal(t, second.ID, webhooks[1].ID)
+		for _, webhook := range webhooks {
+			assert.Empty(t, webhook.Secret)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, store.Delete(created.ID))
+
+		_, err := store.Get(created.ID)
+		assert.ErrorIs(t, err, ErrNotFound)
+		assert.ErrorIs(t, store.Delete(created.ID), ErrNotFound)
+		_, err = store.Deliveries(created.ID, "")
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+}
+
+func TestStore_Persistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hooks", "webhooks.json")
+
+	store, err := NewStore(path, 0)
+	require.NoError(t, err)
+	created, err := store.Create(&models.CreateWebhookRequest{URL: "https://hooks.example.com", Secret: testSecret})
+	require.NoError(t, err)
+	removed, err := store.Create(&models.CreateWebhookRequest{URL: "https://old.example.com"})
+	require.NoError(t, err)
+	require.NoError(t, store.Delete(removed.ID))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm(), "the file holds the secrets")
+
+	reopened, err := NewStore(path, 0)
+	require.NoError(t, err)
+	webhooks := reopened.List()
+	require.Len(t, webhooks, 1)
+	assert.Equal(t, created.ID, webhooks[0].ID)
+
+	target, ok := reopened.target(created.ID)
+	require.True(t, ok)
+	assert.Equal(t, testSecret, target.Secret)
+
+	t.Run("corrupt file", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(path, []byte("{"), 0o600))
+		_, err := NewStore(path, 0)
+		assert.Error(t, err)
+	})
+}
+
+func TestStore_DeliveryLog(t *testing.T) {
+	store, err := NewStore("", 3)
+	require.NoError(t, err)
+	webhook, err := store.Create(&models.CreateWebhookRequest{URL: "https://hooks.example.com"})
+	require.NoError(t, err)
+
+	for _, id := range []string{"1", "2", "3", "4"} {
+		store.logDelivery(&models.WebhookDelivery{ID: id, WebhookID: webhook.ID, Status: models.DeliveryPending})
+	}
+	_, err = store.updateDelivery(webhook.ID, "3", func(delivery *models.WebhookDelivery) error {
+		delivery.Status = models.DeliveryDead
+		return nil
+	})
+	require.NoError(t, err)
+
+	deliveries, err := store.Deliveries(webhook.ID, "")
+	require.NoError(t, err)
+	ids := make([]string, len(deliveries))
+	for i, delivery := range deliveries {
+		ids[i] = delivery.ID
+	}
+	assert.Equal(t, []string{"4", "3", "2"}, ids, "newest first, oldest dropped")
+
+	dead, err := store.Deliveries(webhook.ID, models.DeliveryDead)
+	require.NoError(t, err)
+	require.Len(t, dead, 1)
+	assert.Equal(t, "3", dead[0].ID)
+
+	_, err = store.Delivery(webhook.ID, "1")
+	assert.ErrorIs(t, err, ErrDeliveryNotFound)
+}